@@ -1,14 +1,19 @@
 package common
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
+	"io"
 	"net"
 	"net/http"
 
 	"github.com/spf13/viper"
 	flowpipeapiclient "github.com/turbot/flowpipe-sdk-go"
 	"github.com/turbot/flowpipe/internal/util"
+	"github.com/turbot/pipe-fittings/perr"
 )
 
 type customTransport struct {
@@ -44,3 +49,52 @@ func GetApiClient() *flowpipeapiclient.APIClient {
 
 	return apiClient
 }
+
+// CallApi calls an API endpoint that is not (yet) covered by the flowpipe SDK. The body (if any) is sent as JSON
+// and the response is decoded into result (if not nil). Error responses are returned as perr.ErrorModel.
+func CallApi(ctx context.Context, method, path string, body, result any) error {
+	configuration := GetApiClient().GetConfig()
+
+	var reqBody io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return perr.InternalWithMessage("Error marshalling request body: " + err.Error())
+		}
+		reqBody = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, configuration.Servers[0].URL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := configuration.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		var errorModel perr.ErrorModel
+		if err := json.Unmarshal(respBody, &errorModel); err != nil || errorModel.Status == 0 {
+			return perr.InternalWithMessage(resp.Status + ": " + string(respBody))
+		}
+		return errorModel
+	}
+
+	if result == nil || len(respBody) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(respBody, result)
+}
@@ -864,7 +864,7 @@ func pollServerEventLog(ctx context.Context, executionId string, last int) (bool
 				last = index
 
 				// TODO: execution paused
-				if e.Message == event.HandlerExecutionFinished || e.Message == event.HandlerExecutionFailed || e.Message == event.HandlerExecutionPaused {
+				if e.Message == event.HandlerExecutionFinished || e.Message == event.HandlerExecutionFailed || e.Message == event.HandlerExecutionPaused || e.Message == event.HandlerExecutionCancelled {
 					jsonData, err := json.Marshal(item.Detail)
 					if err != nil {
 						return false, 0, nil, perr.InternalWithMessage("error marshalling log detail")
@@ -907,7 +907,7 @@ func pollLocalEventLog(ctx context.Context, executionId string, last int) (bool,
 			slog.Info("poll local event log - execution paused")
 
 			complete = true
-		} else if item.Message == event.HandlerExecutionFinished || item.Message == event.HandlerExecutionFailed {
+		} else if item.Message == event.HandlerExecutionFinished || item.Message == event.HandlerExecutionFailed || item.Message == event.HandlerExecutionCancelled {
 
 			jsonData, err := json.Marshal(item.Detail)
 			if err != nil {
@@ -7,13 +7,17 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/turbot/flowpipe/internal/cmd/common"
+	localconstants "github.com/turbot/flowpipe/internal/constants"
 	"github.com/turbot/flowpipe/internal/es/command"
 	"github.com/turbot/flowpipe/internal/es/event"
 	"github.com/turbot/flowpipe/internal/es/execution"
@@ -45,6 +49,12 @@ func processCmd() *cobra.Command {
 	cmd.AddCommand(processListCmd())
 	cmd.AddCommand(processTailCmd())
 	cmd.AddCommand(processResumeCmd())
+	cmd.AddCommand(processPauseCmd())
+	cmd.AddCommand(processCancelCmd())
+	cmd.AddCommand(processPruneCmd())
+	cmd.AddCommand(processExportCmd())
+	cmd.AddCommand(processImportCmd())
+	cmd.AddCommand(processReplayCmd())
 
 	return cmd
 }
@@ -63,6 +73,97 @@ func processResumeCmd() *cobra.Command {
 	return cmd
 }
 
+func processReplayCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "replay <execution-id>",
+		Args:  cobra.ExactArgs(1),
+		Run:   replayProcessFunc,
+		Short: "Replay a process against the current mod",
+		Long:  `Run the pipeline of a process again with its original args against the current mod. The steps are served the outputs recorded by the process instead of running, use --from-step to run live from the given step (e.g. http.get_data) onwards.`,
+	}
+	// initialize hooks
+	cmdconfig.OnCmd(cmd).
+		AddStringFlag(localconstants.ArgFromStep, "", "The step to run live from, the steps started before it in the original process are served their recorded output.").
+		AddBoolFlag(constants.ArgVerbose, false, "Enable verbose output.").
+		AddBoolFlag(constants.ArgDetach, false, "Run the replay in detached mode.")
+
+	return cmd
+}
+
+func processPauseCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "pause <execution-id>",
+		Args:  cobra.ExactArgs(1),
+		Run:   pauseProcessFunc,
+		Short: "Pause a running process",
+		Long:  `Pause a running process. No new steps are started, steps already running are left to finish. Use "process resume" to continue the process.`,
+	}
+	// initialize hooks
+	cmdconfig.OnCmd(cmd).
+		AddStringFlag(localconstants.ArgProcessReason, "", "Reason for pausing the process.")
+
+	return cmd
+}
+
+func processCancelCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "cancel <execution-id>",
+		Args:  cobra.ExactArgs(1),
+		Run:   cancelProcessFunc,
+		Short: "Cancel a running or paused process",
+		Long:  `Cancel a running or paused process, including any child pipelines and in-flight steps.`,
+	}
+	// initialize hooks
+	cmdconfig.OnCmd(cmd).
+		AddStringFlag(localconstants.ArgProcessReason, "", "Reason for canceling the process.")
+
+	return cmd
+}
+
+func processPruneCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "prune",
+		Args:  cobra.NoArgs,
+		Run:   pruneProcessFunc,
+		Short: "Delete the processes past their retention",
+		Long:  `Delete the processes past the retention policies set in the "retention" options of the flowpipe config, and the processes that never reached a terminal state. Use --dry-run to list the processes that would be deleted.`,
+	}
+	// initialize hooks
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgDryRun, false, "List the processes that would be deleted without deleting them.")
+
+	return cmd
+}
+
+func processExportCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "export <execution-id>",
+		Args:  cobra.ExactArgs(1),
+		Run:   exportProcessFunc,
+		Short: "Export a process to a bundle file",
+		Long:  `Export a process to a self-contained bundle file: its event log, the definitions of the pipelines it ran and their outputs, with the secrets redacted. Use "flowpipe process import" to load the bundle into another server.`,
+	}
+	// initialize hooks
+	cmdconfig.OnCmd(cmd).
+		AddStringFlag(localconstants.ArgFile, "", "The bundle file to write, defaults to <execution-id>.json.")
+
+	return cmd
+}
+
+func processImportCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "import <file>",
+		Args:  cobra.ExactArgs(1),
+		Run:   importProcessFunc,
+		Short: "Import a process from a bundle file",
+		Long:  `Import a process from a bundle file written by "flowpipe process export", so it can be shown and tailed.`,
+	}
+	// initialize hooks
+	cmdconfig.OnCmd(cmd)
+
+	return cmd
+}
+
 func processShowCmd() *cobra.Command {
 	var cmd = &cobra.Command{
 		Use:   "show <execution-id>",
@@ -150,6 +251,100 @@ func resumeProcessFunc(cmd *cobra.Command, args []string) {
 
 }
 
+func replayProcessFunc(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	var err error
+	executionId := args[0]
+	fromStep := viper.GetString(localconstants.ArgFromStep)
+
+	isDetach := viper.GetBool(constants.ArgDetach)
+	isRemote := viper.IsSet(constants.ArgHost)
+	isVerbose := viper.IsSet(constants.ArgVerbose)
+	if !isRemote && isDetach {
+		error_helpers.ShowError(ctx, fmt.Errorf("unable to use --detach with local execution"))
+		return
+	}
+	output := viper.GetString(constants.ArgOutput)
+	streamLogs := (output == "plain" || output == "pretty") && (o.IsServerMode || isRemote || isVerbose)
+	progressLogs := (output == "plain" || output == "pretty") && !o.IsServerMode && !isRemote && !isVerbose
+	if progressLogs {
+		o.PipelineProgress = o.NewProgress("Replaying...")
+	}
+
+	var m *manager.Manager
+	var resp types.PipelineExecutionResponse
+	var pollLogFunc pollEventLogFunc
+
+	if isRemote {
+		resp, err = replayProcessRemote(ctx, executionId, fromStep)
+		pollLogFunc = pollServerEventLog
+	} else {
+		m, resp, err = replayProcessLocal(ctx, executionId, fromStep)
+		pollLogFunc = pollLocalEventLog
+	}
+	if err != nil {
+		if m != nil {
+			_ = m.Stop()
+		}
+		error_helpers.ShowError(ctx, err)
+		return
+	}
+
+	exitCode := 0
+	lastStatus := ""
+
+	defer func() {
+		if m != nil {
+			_ = m.Stop()
+		}
+		slog.Debug("Completed execution from replayProcessFunc", "status", lastStatus, "exitCode", exitCode)
+		os.Exit(exitCode)
+	}()
+
+	switch {
+	case isDetach:
+		err := displayDetached(ctx, cmd, resp)
+		if err != nil {
+			error_helpers.FailOnErrorWithMessage(err, "failed printing execution information")
+			return
+		}
+	case streamLogs:
+		lastStatus = displayStreamingLogs(ctx, cmd, resp, pollLogFunc)
+	case progressLogs:
+		lastStatus = displayProgressLogs(ctx, cmd, resp, pollLogFunc)
+	default:
+		lastStatus = displayBasicOutput(ctx, cmd, resp, pollLogFunc)
+	}
+
+	switch lastStatus {
+	case event.HandlerExecutionFailed:
+		exitCode = fperr.ExitCodeExecutionFailed
+	case event.HandlerExecutionCancelled:
+		exitCode = fperr.ExitCodeExecutionCancelled
+	case event.HandlerExecutionPaused:
+		exitCode = fperr.ExitCodeExecutionPaused
+	}
+}
+
+func replayProcessRemote(ctx context.Context, executionId, fromStep string) (types.PipelineExecutionResponse, error) {
+	input := types.CmdReplayProcess{
+		FromStep: fromStep,
+	}
+
+	var resp types.PipelineExecutionResponse
+	err := common.CallApi(ctx, http.MethodPost, "/process/"+executionId+"/replay", input, &resp)
+	return resp, err
+}
+
+func replayProcessLocal(ctx context.Context, executionId, fromStep string) (*manager.Manager, types.PipelineExecutionResponse, error) {
+	// create and start the manager with ES service, and Docker, but no API server
+	m, err := manager.NewManager(ctx, manager.WithESService()).Start()
+	error_helpers.FailOnError(err)
+
+	resp, err := api.ReplayProcess(executionId, fromStep, m.ESService)
+	return m, resp, err
+}
+
 func showProcessFunc(cmd *cobra.Command, args []string) {
 	ctx := cmd.Context()
 	var resp *types.Process
@@ -181,6 +376,279 @@ func showProcessFunc(cmd *cobra.Command, args []string) {
 	}
 }
 
+func pauseProcessFunc(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	var resp *types.Process
+	var err error
+	executionId := args[0]
+	reason := viper.GetString(localconstants.ArgProcessReason)
+
+	if viper.IsSet(constants.ArgHost) {
+		resp, err = commandProcessRemote(ctx, executionId, "pause", reason)
+	} else {
+		resp, err = pauseProcessLocal()
+	}
+	if err != nil {
+		error_helpers.ShowError(ctx, err)
+		return
+	}
+
+	printProcess(cmd, resp)
+}
+
+func cancelProcessFunc(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	var resp *types.Process
+	var err error
+	executionId := args[0]
+	reason := viper.GetString(localconstants.ArgProcessReason)
+
+	if viper.IsSet(constants.ArgHost) {
+		resp, err = commandProcessRemote(ctx, executionId, "cancel", reason)
+	} else {
+		resp, err = cancelProcessLocal(ctx, executionId, reason)
+	}
+	if err != nil {
+		error_helpers.ShowError(ctx, err)
+		return
+	}
+
+	printProcess(cmd, resp)
+}
+
+func pruneProcessFunc(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	var resp *types.PruneProcessResponse
+	var err error
+	dryRun := viper.GetBool(constants.ArgDryRun)
+
+	if viper.IsSet(constants.ArgHost) {
+		resp, err = pruneProcessRemote(ctx, dryRun)
+	} else {
+		resp, err = pruneProcessLocal(ctx, dryRun)
+	}
+	if err != nil {
+		error_helpers.ShowError(ctx, err)
+		return
+	}
+
+	printer, err := printers.GetPrinter[types.PrunedProcess](cmd)
+	if err != nil {
+		error_helpers.ShowErrorWithMessage(ctx, err, "failed obtaining printer")
+		return
+	}
+	err = printer.PrintResource(ctx, types.NewPrintablePrunedProcess(resp), cmd.OutOrStdout())
+	if err != nil {
+		error_helpers.ShowErrorWithMessage(ctx, err, "failed when printing")
+		return
+	}
+
+	if viper.GetString(constants.ArgOutput) == constants.OutputFormatPretty || viper.GetString(constants.ArgOutput) == constants.OutputFormatPlain {
+		if resp.DryRun {
+			fmt.Fprintf(cmd.OutOrStdout(), "%d processes would be pruned\n", len(resp.Items)) //nolint:forbidigo // summary of the dry run
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "%d processes pruned\n", len(resp.Items)) //nolint:forbidigo // summary of the prune
+		}
+	}
+}
+
+func pruneProcessRemote(ctx context.Context, dryRun bool) (*types.PruneProcessResponse, error) {
+	input := types.CmdPruneProcess{
+		DryRun: dryRun,
+	}
+
+	var resp types.PruneProcessResponse
+	err := common.CallApi(ctx, http.MethodPost, "/process/prune", input, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func pruneProcessLocal(ctx context.Context, dryRun bool) (*types.PruneProcessResponse, error) {
+	// create and start the manager in local mode (i.e. do not set listen address), it loads the retention policies
+	m, err := manager.NewManager(ctx, manager.WithoutCleanup()).Start()
+	error_helpers.FailOnError(err)
+	defer func() {
+		_ = m.Stop()
+	}()
+
+	return api.PruneProcesses(dryRun)
+}
+
+func exportProcessFunc(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	var bundle *types.ProcessBundle
+	var err error
+	executionId := args[0]
+
+	if viper.IsSet(constants.ArgHost) {
+		bundle, err = exportProcessRemote(ctx, executionId)
+	} else {
+		bundle, err = exportProcessLocal(ctx, executionId)
+	}
+	if err != nil {
+		error_helpers.ShowError(ctx, err)
+		return
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		error_helpers.ShowErrorWithMessage(ctx, err, "failed marshalling the process bundle")
+		return
+	}
+
+	fileName := viper.GetString(localconstants.ArgFile)
+	if fileName == "" {
+		fileName = executionId + ".json"
+	}
+
+	//nolint:gosec // the bundle is redacted
+	err = os.WriteFile(fileName, data, 0644)
+	if err != nil {
+		error_helpers.ShowErrorWithMessage(ctx, err, "failed writing the process bundle")
+		return
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Exported process %s with %d events to %s\n", executionId, len(bundle.Events), fileName) //nolint:forbidigo // summary of the export
+}
+
+func exportProcessRemote(ctx context.Context, executionId string) (*types.ProcessBundle, error) {
+	var resp types.ProcessBundle
+	err := common.CallApi(ctx, http.MethodGet, "/process/"+executionId+"/export", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func exportProcessLocal(ctx context.Context, executionId string) (*types.ProcessBundle, error) {
+	// create and start the manager in local mode (i.e. do not set listen address), it loads the pipeline definitions
+	m, err := manager.NewManager(ctx, manager.WithoutCleanup()).Start()
+	error_helpers.FailOnError(err)
+	defer func() {
+		_ = m.Stop()
+	}()
+
+	return api.ExportProcess(executionId)
+}
+
+func importProcessFunc(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		error_helpers.ShowErrorWithMessage(ctx, err, "failed reading the process bundle")
+		return
+	}
+
+	var bundle types.ProcessBundle
+	err = json.Unmarshal(data, &bundle)
+	if err != nil {
+		error_helpers.ShowError(ctx, perr.BadRequestWithMessage("invalid process bundle: "+err.Error()))
+		return
+	}
+
+	var resp *types.Process
+	if viper.IsSet(constants.ArgHost) {
+		resp, err = importProcessRemote(ctx, &bundle)
+	} else {
+		resp, err = importProcessLocal(ctx, &bundle)
+	}
+	if err != nil {
+		error_helpers.ShowError(ctx, err)
+		return
+	}
+
+	printProcess(cmd, resp)
+}
+
+func importProcessRemote(ctx context.Context, bundle *types.ProcessBundle) (*types.Process, error) {
+	var resp types.Process
+	err := common.CallApi(ctx, http.MethodPost, "/process/import", bundle, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func importProcessLocal(ctx context.Context, bundle *types.ProcessBundle) (*types.Process, error) {
+	// create and start the manager in local mode (i.e. do not set listen address), it initializes the process store
+	m, err := manager.NewManager(ctx, manager.WithoutCleanup()).Start()
+	error_helpers.FailOnError(err)
+	defer func() {
+		_ = m.Stop()
+	}()
+
+	return api.ImportProcess(bundle)
+}
+
+func printProcess(cmd *cobra.Command, resp *types.Process) {
+	ctx := cmd.Context()
+	if resp == nil {
+		return
+	}
+
+	printer, err := printers.GetPrinter[types.Process](cmd)
+	if err != nil {
+		error_helpers.ShowErrorWithMessage(ctx, err, "failed obtaining printer")
+		return
+	}
+	printableResource := types.NewPrintableProcessFromSingle(resp)
+	err = printer.PrintResource(ctx, printableResource, cmd.OutOrStdout())
+	if err != nil {
+		error_helpers.ShowErrorWithMessage(ctx, err, "failed when printing")
+		return
+	}
+}
+
+func commandProcessRemote(ctx context.Context, executionId, command, reason string) (*types.Process, error) {
+	input := types.CmdProcess{
+		Command: command,
+		Reason:  reason,
+	}
+
+	var resp types.Process
+	err := common.CallApi(ctx, http.MethodPost, "/process/"+executionId+"/command", input, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func pauseProcessLocal() (*types.Process, error) {
+	// a process only runs for the lifetime of the flowpipe instance that started it
+	return nil, fmt.Errorf("pause requires a remote server via --host <host>")
+}
+
+func cancelProcessLocal(ctx context.Context, executionId, reason string) (*types.Process, error) {
+	// create and start the manager in local mode (i.e. do not set listen address)
+	m, err := manager.NewManager(ctx, manager.WithESService()).Start()
+	error_helpers.FailOnError(err)
+	defer func() {
+		_ = m.Stop()
+	}()
+
+	err = api.CancelProcess(executionId, reason, m.ESService)
+	if err != nil {
+		return nil, err
+	}
+
+	// wait for the cancellation to propagate to the child pipelines and running steps, but not forever
+	for i := 0; i < 60; i++ {
+		ex, err := execution.GetExecution(executionId)
+		if err != nil {
+			return nil, err
+		}
+		if ex.Status == localconstants.StateCanceled || ex.Status == "failed" || ex.Status == "finished" {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	return api.GetProcess(executionId)
+}
+
 func getProcessRemote(executionId string) (*types.Process, error) {
 	apiClient := common.GetApiClient()
 	resp, _, err := apiClient.ProcessApi.Get(context.Background(), executionId).Execute()
@@ -315,10 +783,17 @@ func processListCmd() *cobra.Command {
 		Args:  cobra.NoArgs,
 		Run:   listProcessFunc,
 		Short: "List processes",
-		Long:  `List processes.`,
+		Long:  `List processes, newest first. The processes are only recorded with a process retention, with a process-retention of 0 they are all listed and can't be filtered or paged.`,
 	}
 	// initialize hooks
-	cmdconfig.OnCmd(cmd)
+	cmdconfig.OnCmd(cmd).
+		AddStringFlag(localconstants.ArgStatus, "", "Only list the processes in this status: queued, started, paused, finished, failed or canceled.").
+		AddStringFlag(localconstants.ArgPipeline, "", "Only list the processes of this pipeline.").
+		AddStringFlag(localconstants.ArgTrigger, "", "Only list the processes started by this trigger.").
+		AddStringFlag(localconstants.ArgSince, "", "Only list the processes started since this time, either a RFC 3339 timestamp or a duration such as 24h or 7d.").
+		AddStringFlag(localconstants.ArgUntil, "", "Only list the processes started before this time, either a RFC 3339 timestamp or a duration such as 24h or 7d.").
+		AddStringFlag(localconstants.ArgSort, "desc", "Sort order on the start time: asc or desc.").
+		AddIntFlag(localconstants.ArgLimit, 25, "Maximum number of processes to list.")
 
 	return cmd
 }
@@ -327,11 +802,22 @@ func listProcessFunc(cmd *cobra.Command, args []string) {
 	ctx := cmd.Context()
 	var resp *types.ListProcessResponse
 	var err error
+
+	query := types.ListProcessRequestQuery{
+		Status:   viper.GetString(localconstants.ArgStatus),
+		Pipeline: viper.GetString(localconstants.ArgPipeline),
+		Trigger:  viper.GetString(localconstants.ArgTrigger),
+		Since:    viper.GetString(localconstants.ArgSince),
+		Until:    viper.GetString(localconstants.ArgUntil),
+		Sort:     viper.GetString(localconstants.ArgSort),
+	}
+	limit := viper.GetInt(localconstants.ArgLimit)
+
 	// if a host is set, use it to connect to API server
 	if viper.IsSet(constants.ArgHost) {
-		resp, err = listProcessRemote()
+		resp, err = listProcessRemote(ctx, query, limit)
 	} else {
-		resp, err = listProcessLocal(cmd, args)
+		resp, err = listProcessLocal(cmd, query, limit)
 	}
 	if err != nil {
 		error_helpers.ShowError(ctx, err)
@@ -353,23 +839,42 @@ func listProcessFunc(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listProcessRemote() (*types.ListProcessResponse, error) {
-	ctx := context.Background()
-	limit := int32(25) // int32 | The max number of items to fetch per page of data, subject to a min and max of 1 and 100 respectively. If not specified will default to 25. (optional) (default to 25)
-	nextToken := ""    // string | When list results are truncated, next_token will be returned, which is a cursor to fetch the next page of data. Pass next_token to the subsequent list request to fetch the next page of data. (optional)
-
-	apiClient := common.GetApiClient()
+func listProcessRemote(ctx context.Context, query types.ListProcessRequestQuery, limit int) (*types.ListProcessResponse, error) {
+	params := url.Values{}
+	params.Set("limit", strconv.Itoa(limit))
+	for key, value := range map[string]string{
+		"status":   query.Status,
+		"pipeline": query.Pipeline,
+		"trigger":  query.Trigger,
+		"since":    query.Since,
+		"until":    query.Until,
+		"sort":     query.Sort,
+	} {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
 
-	resp, _, err := apiClient.ProcessApi.List(ctx).Limit(limit).NextToken(nextToken).Execute()
+	var resp types.ListProcessResponse
+	err := common.CallApi(ctx, http.MethodGet, "/process?"+params.Encode(), nil, &resp)
 	if err != nil {
 		return nil, err
 	}
-	// map the API data type into the internal data type
-	return types.ListProcessResponseFromAPIResponse(resp)
+	return &resp, nil
 }
 
-func listProcessLocal(cmd *cobra.Command, args []string) (*types.ListProcessResponse, error) {
+func listProcessLocal(cmd *cobra.Command, query types.ListProcessRequestQuery, limit int) (*types.ListProcessResponse, error) {
 	ctx := cmd.Context()
+
+	// the API validates the query when listing remotely
+	if query.Status != "" && !slices.Contains([]string{"queued", "started", "paused", "finished", "failed", localconstants.StateCanceled}, query.Status) {
+		return nil, perr.BadRequestWithMessage("invalid status: " + query.Status)
+	}
+
+	if query.Sort != "" && query.Sort != "asc" && query.Sort != "desc" {
+		return nil, perr.BadRequestWithMessage("invalid sort order: " + query.Sort)
+	}
+
 	// create and start the manager in local mode (i.e. do not set listen address)
 	m, err := manager.NewManager(ctx).Start()
 	error_helpers.FailOnError(err)
@@ -378,7 +883,7 @@ func listProcessLocal(cmd *cobra.Command, args []string) (*types.ListProcessResp
 		_ = m.Stop()
 	}()
 
-	return api.ListProcesses()
+	return api.ListProcesses(query, "", limit)
 }
 
 // tail
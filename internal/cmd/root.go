@@ -57,7 +57,10 @@ func rootCommand() *cobra.Command {
 		AddPersistentIntFlag(constants.ArgMaxConcurrencyHttp, 500, "Maximum number of concurrent HTTP step").
 		AddPersistentIntFlag(constants.ArgMaxConcurrencyQuery, 50, "Maximum number of concurrent Query steps").
 		AddPersistentIntFlag(constants.ArgMaxConcurrencyContainer, 25, "Maximum number of concurrent Container steps").
-		AddPersistentIntFlag(constants.ArgMaxConcurrencyFunction, 50, "Maximum number of concurrent Function steps")
+		AddPersistentIntFlag(constants.ArgMaxConcurrencyFunction, 50, "Maximum number of concurrent Function steps").
+		AddPersistentIntFlag(localconstants.ArgQueryPoolMaxOpen, localconstants.DefaultQueryPoolMaxOpen, "Maximum number of open connections per database of the Query steps and triggers").
+		AddPersistentIntFlag(localconstants.ArgQueryPoolMaxIdle, localconstants.DefaultQueryPoolMaxIdle, "Maximum number of idle connections per database of the Query steps and triggers").
+		AddPersistentIntFlag(localconstants.ArgQueryPoolMaxIdleTime, localconstants.DefaultQueryPoolMaxIdleTime, "Seconds after which the idle connections, and the databases no longer queried, are closed")
 
 	// disable auto completion generation, since we don't want to support
 	// powershell yet - and there's no way to disable powershell in the default generator
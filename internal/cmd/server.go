@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -14,6 +15,8 @@ import (
 	"github.com/turbot/flowpipe/internal/output"
 	serviceConfig "github.com/turbot/flowpipe/internal/service/config"
 	"github.com/turbot/flowpipe/internal/service/manager"
+	"github.com/turbot/flowpipe/internal/store"
+	"github.com/turbot/flowpipe/internal/trigger"
 	"github.com/turbot/flowpipe/internal/types"
 	"github.com/turbot/pipe-fittings/cmdconfig"
 	"github.com/turbot/pipe-fittings/constants"
@@ -39,6 +42,11 @@ func serverCmd() *cobra.Command {
 		AddStringFlag(constants.ArgListen, localconstants.DefaultListen, "Listen address port.").
 		AddStringFlag(constants.ArgBaseUrl, localconstants.DefaultFlowpipeHost, "Base URL for the webhook triggers and http input ("+localconstants.DefaultFlowpipeHost+").").
 		AddBoolFlag(constants.ArgWatch, true, "Watch mod files for changes when running Flowpipe server").
+		AddStringFlag(localconstants.ArgEventBus, localconstants.DefaultEventBus, "Event bus used to queue commands and events, one of memory or sqlite. Commands and events queued on the sqlite event bus are redelivered after a restart, it requires a process retention.").
+		AddStringFlag(localconstants.ArgRecoveryPolicy, localconstants.DefaultRecoveryPolicy, "How processes interrupted by a server restart are recovered on start, one of resume, fail or ignore. Resume runs the steps that were in flight again, only use it if they are safe to repeat. With leader election the processes of a stopped server are recovered by the leader. The processes are only recorded with a process retention, with a process-retention of 0 only ignore is accepted and it's the default.").
+		AddBoolFlag(localconstants.ArgLeaderElection, false, "Run scheduled and query triggers only on the server holding the scheduler lease, allowing several servers to share the same process store.").
+		AddStringFlag(localconstants.ArgProcessStore, "", "Connection string of a Postgres database (postgres://...) used to store the processes instead of flowpipe.db in the mod location.").
+		AddStringFlag(localconstants.ArgTrustedProxies, "", "Comma separated IPs and CIDRs of the reverse proxies whose X-Forwarded-For header gives the client IP of the HTTP trigger requests. The header is ignored by default.").
 		AddBoolFlag(constants.ArgVerbose, false, "Enable verbose output")
 
 	return cmd
@@ -64,10 +72,51 @@ func startServerFunc() func(cmd *cobra.Command, args []string) {
 			os.Exit(constants.ExitCodeInsufficientOrWrongInputs)
 		}
 
+		recoveryPolicy := viper.GetString(localconstants.ArgRecoveryPolicy)
+		if !slices.Contains([]string{localconstants.RecoveryPolicyResume, localconstants.RecoveryPolicyFail, localconstants.RecoveryPolicyIgnore}, recoveryPolicy) {
+			errMsg := fmt.Sprintf("invalid recovery policy '%s', must be one of resume, fail or ignore", recoveryPolicy)
+			output.RenderServerOutput(ctx, types.NewServerOutputError(types.NewServerOutputPrefix(time.Now(), "flowpipe"), "unable to start server", errors.New(errMsg)))
+			os.Exit(constants.ExitCodeInsufficientOrWrongInputs)
+		}
+
+		// the interrupted processes are loaded from the recorded runs, which aren't recorded without a process retention
+		if viper.GetInt(constants.ArgProcessRetention) == 0 && recoveryPolicy != localconstants.RecoveryPolicyIgnore {
+			if viper.IsSet(localconstants.ArgRecoveryPolicy) {
+				errMsg := fmt.Sprintf("recovery policy '%s' requires a process retention, the processes are not recorded with a %s of 0, use --%s ignore", recoveryPolicy, constants.ArgProcessRetention, localconstants.ArgRecoveryPolicy)
+				output.RenderServerOutput(ctx, types.NewServerOutputError(types.NewServerOutputPrefix(time.Now(), "flowpipe"), "unable to start server", errors.New(errMsg)))
+				os.Exit(constants.ExitCodeInsufficientOrWrongInputs)
+			}
+			recoveryPolicy = localconstants.RecoveryPolicyIgnore
+		}
+
+		// the executions of the redelivered commands and events are loaded from the recorded events
+		eventBus := viper.GetString(localconstants.ArgEventBus)
+		if eventBus == localconstants.EventBusSQLite && viper.GetInt(constants.ArgProcessRetention) == 0 {
+			errMsg := fmt.Sprintf("the sqlite event bus requires a process retention, the processes are not recorded with a %s of 0", constants.ArgProcessRetention)
+			output.RenderServerOutput(ctx, types.NewServerOutputError(types.NewServerOutputPrefix(time.Now(), "flowpipe"), "unable to start server", errors.New(errMsg)))
+			os.Exit(constants.ExitCodeInsufficientOrWrongInputs)
+		}
+
+		if !store.IsValidProcessStore(viper.GetString(localconstants.ArgProcessStore)) {
+			errMsg := "invalid process store, only postgres:// connection strings are supported"
+			output.RenderServerOutput(ctx, types.NewServerOutputError(types.NewServerOutputPrefix(time.Now(), "flowpipe"), "unable to start server", errors.New(errMsg)))
+			os.Exit(constants.ExitCodeInsufficientOrWrongInputs)
+		}
+
+		trustedProxies, err := trigger.ParseTrustedProxies(viper.GetString(localconstants.ArgTrustedProxies))
+		if err != nil {
+			output.RenderServerOutput(ctx, types.NewServerOutputError(types.NewServerOutputPrefix(time.Now(), "flowpipe"), "unable to start server", err))
+			os.Exit(constants.ExitCodeInsufficientOrWrongInputs)
+		}
+
 		// start manager, passing server config
 		// (this will ensure manager starts API, ES, Scheduling and docker services
 		m, err := manager.NewManager(ctx,
 			manager.WithServerConfig(viper.GetString(constants.ArgListen), viper.GetInt(constants.ArgPort)),
+			manager.WithEventBus(eventBus),
+			manager.WithRecoveryPolicy(recoveryPolicy),
+			manager.WithLeaderElection(viper.GetBool(localconstants.ArgLeaderElection)),
+			manager.WithTrustedProxies(trustedProxies),
 		).Start()
 		if err != nil {
 			output.RenderServerOutput(ctx, types.NewServerOutputError(types.NewServerOutputPrefix(time.Now(), "flowpipe"), "unable to start server", err))
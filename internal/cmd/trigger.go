@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
+	"net/http"
+	"net/url"
 	"os"
 	"reflect"
+	"strconv"
 	"time"
 
 	flowpipeapiclient "github.com/turbot/flowpipe-sdk-go"
@@ -17,6 +20,7 @@ import (
 	"github.com/turbot/pipe-fittings/utils"
 
 	"github.com/spf13/viper"
+	localconstants "github.com/turbot/flowpipe/internal/constants"
 	"github.com/turbot/flowpipe/internal/es/event"
 	"github.com/turbot/flowpipe/internal/fperr"
 	o "github.com/turbot/flowpipe/internal/output"
@@ -41,6 +45,10 @@ func triggerCmd() *cobra.Command {
 	cmd.AddCommand(triggerListCmd())
 	cmd.AddCommand(triggerShowCmd())
 	cmd.AddCommand(triggerRunCmd())
+	cmd.AddCommand(triggerHistoryCmd())
+	cmd.AddCommand(triggerStateCmd("enable", "Enable a trigger", "Enable a trigger, whatever its enabled attribute, and resume it if paused."))
+	cmd.AddCommand(triggerStateCmd("disable", "Disable a trigger", "Disable a trigger, whatever its enabled attribute. A disabled trigger is unscheduled and its webhook rejects the requests."))
+	cmd.AddCommand(triggerStateCmd("pause", "Pause a trigger", "Pause a trigger. A paused trigger stays scheduled but skips its firings, its webhook rejects the requests and a queue trigger leaves the messages in the queue."))
 
 	return cmd
 }
@@ -125,11 +133,12 @@ func triggerShowCmd() *cobra.Command {
 		Args:  cobra.ExactArgs(1),
 		Run:   showTriggerFunc,
 		Short: "Show details of a trigger from the current mod",
-		Long:  `Show details of a trigger from the current mod.`,
+		Long:  `Show details of a trigger from the current mod, with the next fire times of a scheduled trigger.`,
 	}
 
 	// initialize hooks
-	cmdconfig.OnCmd(triggerShowCmd)
+	cmdconfig.OnCmd(triggerShowCmd).
+		AddIntFlag(localconstants.ArgFireTimes, localconstants.DefaultTriggerFireTimes, "The number of next fire times of a scheduled trigger to show.")
 
 	return triggerShowCmd
 }
@@ -139,11 +148,12 @@ func showTriggerFunc(cmd *cobra.Command, args []string) {
 	var resp *types.FpTrigger
 	var err error
 	triggerName := args[0]
+	fireTimes := viper.GetInt(localconstants.ArgFireTimes)
 	// if a host is set, use it to connect to API server
 	if viper.IsSet(constants.ArgHost) {
-		resp, err = getTriggerRemote(ctx, triggerName)
+		resp, err = getTriggerRemote(ctx, triggerName, fireTimes)
 	} else {
-		resp, err = getTriggerLocal(ctx, triggerName)
+		resp, err = getTriggerLocal(ctx, triggerName, fireTimes)
 	}
 
 	if err != nil {
@@ -164,17 +174,17 @@ func showTriggerFunc(cmd *cobra.Command, args []string) {
 	}
 }
 
-func getTriggerRemote(ctx context.Context, name string) (*types.FpTrigger, error) {
-	apiClient := common.GetApiClient()
-	resp, _, err := apiClient.TriggerApi.Get(ctx, name).Execute()
+func getTriggerRemote(ctx context.Context, name string, fireTimes int) (*types.FpTrigger, error) {
+	// the API client doesn't know the runtime state and the fire times of the trigger
+	var resp types.FpTrigger
+	err := common.CallApi(ctx, http.MethodGet, "/trigger/"+url.PathEscape(name)+"?fire_times="+strconv.Itoa(fireTimes), nil, &resp)
 	if err != nil {
 		return nil, err
 	}
-	t := types.FpTriggerFromAPI(*resp)
-	return &t, nil
+	return &resp, nil
 }
 
-func getTriggerLocal(ctx context.Context, triggerName string) (*types.FpTrigger, error) {
+func getTriggerLocal(ctx context.Context, triggerName string, fireTimes int) (*types.FpTrigger, error) {
 	// create and start the manager in local mode (i.e. do not set listen address)
 	m, err := manager.NewManager(ctx).Start()
 	error_helpers.FailOnError(err)
@@ -184,7 +194,164 @@ func getTriggerLocal(ctx context.Context, triggerName string) (*types.FpTrigger,
 	}()
 
 	// try to fetch the pipeline from the cache
-	return api.GetTrigger(triggerName, m.RootMod.Name())
+	return api.GetTrigger(triggerName, m.RootMod.Name(), fireTimes)
+}
+
+// enable, disable and pause
+func triggerStateCmd(command, short, long string) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   command + " <trigger-name>",
+		Args:  cobra.ExactArgs(1),
+		Run:   triggerStateFunc(command),
+		Short: short,
+		Long:  long + " The state is kept in flowpipe.db and applies until the next enable, disable or pause command, or the --until time.",
+	}
+	// initialize hooks
+	cmdconfig.OnCmd(cmd).
+		AddStringFlag(localconstants.ArgUntil, "", "End the command at this time, either a RFC 3339 timestamp or a duration such as 2h or 1d.")
+
+	return cmd
+}
+
+func triggerStateFunc(command string) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		var resp *types.FpTrigger
+		var err error
+
+		triggerName := args[0]
+		until := viper.GetString(localconstants.ArgUntil)
+
+		// if a host is set, use it to connect to API server
+		if viper.IsSet(constants.ArgHost) {
+			resp, err = triggerStateRemote(ctx, triggerName, command, until)
+		} else {
+			resp, err = triggerStateLocal(ctx, triggerName, command, until)
+		}
+		if err != nil {
+			error_helpers.ShowError(ctx, err)
+			return
+		}
+
+		if resp != nil {
+			printer, err := printers.GetPrinter[types.FpTrigger](cmd)
+			if err != nil {
+				error_helpers.ShowErrorWithMessage(ctx, err, "Error obtaining printer")
+				return
+			}
+			err = printer.PrintResource(ctx, types.NewPrintableTriggerFromSingle(resp), cmd.OutOrStdout())
+			if err != nil {
+				error_helpers.ShowErrorWithMessage(ctx, err, "Error when printing")
+			}
+		}
+	}
+}
+
+func triggerStateRemote(ctx context.Context, name, command, until string) (*types.FpTrigger, error) {
+	input := types.CmdTrigger{
+		Command: command,
+		Until:   until,
+	}
+
+	var resp types.FpTrigger
+	err := common.CallApi(ctx, http.MethodPost, "/trigger/"+url.PathEscape(name)+"/command", input, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func triggerStateLocal(ctx context.Context, name, command, until string) (*types.FpTrigger, error) {
+	// create and start the manager in local mode (i.e. do not set listen address)
+	m, err := manager.NewManager(ctx).Start()
+	error_helpers.FailOnError(err)
+	defer func() {
+		_ = m.Stop()
+	}()
+
+	// the running servers apply the new state within a minute
+	return api.SetTriggerState(api.ConstructTriggerFullyQualifiedName(name), command, until, m.RootMod.Name(), nil)
+}
+
+// history
+func triggerHistoryCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "history <trigger-name>",
+		Args:  cobra.ExactArgs(1),
+		Run:   triggerHistoryFunc,
+		Short: "List the runs of a trigger",
+		Long:  `List the runs of a trigger, newest first, with the process each run started.`,
+	}
+	// initialize hooks
+	cmdconfig.OnCmd(cmd).
+		AddIntFlag(localconstants.ArgLimit, 25, "Maximum number of runs to list.")
+
+	return cmd
+}
+
+func triggerHistoryFunc(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	var resp *types.ListTriggerRunResponse
+	var err error
+
+	triggerName := args[0]
+	limit := viper.GetInt(localconstants.ArgLimit)
+
+	// if a host is set, use it to connect to API server
+	if viper.IsSet(constants.ArgHost) {
+		resp, err = triggerHistoryRemote(ctx, triggerName, limit)
+	} else {
+		resp, err = triggerHistoryLocal(ctx, triggerName, limit)
+	}
+	if err != nil {
+		error_helpers.ShowError(ctx, err)
+		return
+	}
+
+	if resp == nil {
+		return
+	}
+
+	// the fire times are only known by the server running the schedule
+	if viper.GetString(constants.ArgOutput) == constants.OutputFormatPretty || viper.GetString(constants.ArgOutput) == constants.OutputFormatPlain {
+		if resp.PreviousFireTime != nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "Previous fire time: %s\n", resp.PreviousFireTime.Local().Format(time.DateTime)) //nolint:forbidigo // schedule of the trigger
+		}
+		if resp.NextFireTime != nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "Next fire time:     %s\n", resp.NextFireTime.Local().Format(time.DateTime)) //nolint:forbidigo // schedule of the trigger
+		}
+	}
+
+	printer, err := printers.GetPrinter[types.TriggerRun](cmd)
+	if err != nil {
+		error_helpers.ShowErrorWithMessage(ctx, err, "Error obtaining printer")
+		return
+	}
+	err = printer.PrintResource(ctx, types.NewPrintableTriggerRun(resp), cmd.OutOrStdout())
+	if err != nil {
+		error_helpers.ShowErrorWithMessage(ctx, err, "Error when printing")
+	}
+}
+
+func triggerHistoryRemote(ctx context.Context, name string, limit int) (*types.ListTriggerRunResponse, error) {
+	var resp types.ListTriggerRunResponse
+	err := common.CallApi(ctx, http.MethodGet, "/trigger/"+url.PathEscape(name)+"/runs?limit="+strconv.Itoa(limit), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func triggerHistoryLocal(ctx context.Context, name string, limit int) (*types.ListTriggerRunResponse, error) {
+	// create and start the manager in local mode (i.e. do not set listen address)
+	m, err := manager.NewManager(ctx).Start()
+	error_helpers.FailOnError(err)
+	defer func() {
+		_ = m.Stop()
+	}()
+
+	// the scheduler is not running locally, there are no fire times
+	return api.ListTriggerRuns(api.ConstructTriggerFullyQualifiedName(name), "", limit, nil)
 }
 
 func triggerRunCmd() *cobra.Command {
@@ -1,6 +1,7 @@
 package cmdconfig
 
 import (
+	localconstants "github.com/turbot/flowpipe/internal/constants"
 	serviceconfig "github.com/turbot/flowpipe/internal/service/config"
 	"github.com/turbot/pipe-fittings/app_specific"
 	"github.com/turbot/pipe-fittings/cmdconfig"
@@ -50,5 +51,10 @@ func envMappings() map[string]cmdconfig.EnvMapping {
 		"FLOWPIPE_MAX_CONCURRENCY_FUNCTION":  {ConfigVar: []string{constants.ArgMaxConcurrencyFunction}, VarType: cmdconfig.EnvVarTypeInt},
 		"FLOWPIPE_PROCESS_RETENTION":         {ConfigVar: []string{constants.ArgProcessRetention}, VarType: cmdconfig.EnvVarTypeInt},
 		"FLOWPIPE_BASE_URL":                  {ConfigVar: []string{constants.ArgBaseUrl}, VarType: cmdconfig.EnvVarTypeString},
+		"FLOWPIPE_PROCESS_STORE":             {ConfigVar: []string{localconstants.ArgProcessStore}, VarType: cmdconfig.EnvVarTypeString},
+		"FLOWPIPE_TRUSTED_PROXIES":           {ConfigVar: []string{localconstants.ArgTrustedProxies}, VarType: cmdconfig.EnvVarTypeString},
+		"FLOWPIPE_QUERY_POOL_MAX_OPEN":       {ConfigVar: []string{localconstants.ArgQueryPoolMaxOpen}, VarType: cmdconfig.EnvVarTypeInt},
+		"FLOWPIPE_QUERY_POOL_MAX_IDLE":       {ConfigVar: []string{localconstants.ArgQueryPoolMaxIdle}, VarType: cmdconfig.EnvVarTypeInt},
+		"FLOWPIPE_QUERY_POOL_MAX_IDLE_TIME":  {ConfigVar: []string{localconstants.ArgQueryPoolMaxIdleTime}, VarType: cmdconfig.EnvVarTypeInt},
 	}
 }
@@ -5,4 +5,30 @@ const (
 
 	ArgPipelineExecutionMode = "execution-mode"
 	ArgPipelineWaitTime      = "wait-time"
+
+	ArgProcessReason = "reason"
+
+	ArgStatus   = "status"
+	ArgPipeline = "pipeline"
+	ArgTrigger  = "trigger"
+	ArgSince    = "since"
+	ArgUntil    = "until"
+	ArgSort     = "sort"
+	ArgLimit    = "limit"
+
+	ArgFireTimes = "fire-times"
+
+	ArgFile = "file"
+
+	ArgFromStep = "from-step"
+
+	ArgEventBus       = "event-bus"
+	ArgRecoveryPolicy = "recovery-policy"
+	ArgLeaderElection = "leader-election"
+	ArgProcessStore   = "process-store"
+	ArgTrustedProxies = "trusted-proxies"
+
+	ArgQueryPoolMaxOpen     = "query-pool-max-open"
+	ArgQueryPoolMaxIdle     = "query-pool-max-idle"
+	ArgQueryPoolMaxIdleTime = "query-pool-max-idle-time"
 )
@@ -10,14 +10,45 @@ const (
 	DefaultListen             = "network"
 	DefaultExecutionMode      = ExecutionModeAsynchronous
 	DefaultWaitRetry          = 60
+	DefaultTriggerFireTimes   = 5
 	ExecutionModeSynchronous  = "synchronous"
 	ExecutionModeAsynchronous = "asynchronous"
+	DefaultEventBus           = EventBusMemory
+	EventBusMemory            = "memory"
+	EventBusSQLite            = "sqlite"
+	DefaultRecoveryPolicy     = RecoveryPolicyFail
+	RecoveryPolicyResume      = "resume"
+	RecoveryPolicyFail        = "fail"
+	RecoveryPolicyIgnore      = "ignore"
+	OptionsTypeRetention      = "retention"
+
+	// The connection pools of the query steps and triggers, the idle time is in seconds
+	DefaultQueryPoolMaxOpen     = 10
+	DefaultQueryPoolMaxIdle     = 2
+	DefaultQueryPoolMaxIdleTime = 300
 
 	MaxScanSize = bufio.MaxScanTokenSize * 40
 
 	FormUrl = "form_url"
 )
 
+// How a trigger was fired, recorded in the run history of the trigger
+const (
+	TriggerSourceSchedule = "schedule"
+	TriggerSourceManual   = "manual"
+	TriggerSourceWebhook  = "webhook"
+	TriggerSourceQueue    = "queue"
+	TriggerSourceFile     = "file"
+	TriggerSourceCatchUp  = "catch_up"
+)
+
+// The runtime overrides of a trigger, set by the trigger enable, disable and pause commands
+const (
+	TriggerOverrideEnabled  = "enabled"
+	TriggerOverrideDisabled = "disabled"
+	TriggerOverridePaused   = "paused"
+)
+
 const FlowpipeSampleContent = `
 #
 # For detailed descriptions, see the reference documentation
@@ -31,4 +62,17 @@ const FlowpipeSampleContent = `
 #     integration = integration.http.default
 #   }
 # }
+
+# options "retention" {
+#   max_age        = "7d"
+#   failed_max_age = "30d"
+#   orphan_max_age = "1d"
+#   keep_last      = 10
+#   archive        = false
+#
+#   rule {
+#     pipeline  = "mymod.pipeline.*"
+#     keep_last = 100
+#   }
+# }
 `
@@ -4,6 +4,7 @@ const (
 	StateFailed   = "failed"
 	StateFinished = "finished"
 	StateSkipped  = "skipped"
+	StateCanceled = "canceled"
 
 	FailureModeIgnored  = "ignored" // ignored=true
 	FailureModeStandard = "normal"  // "normal" failure, retry or ignored=true will be followed
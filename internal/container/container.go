@@ -201,8 +201,17 @@ func (c *Container) SetRunStatus(containerID string, newStatus string) error {
 }
 
 func (c *Container) Run(cConfig ContainerRunConfig) (string, int, error) {
+	return c.RunWithContext(c.runCtx, cConfig)
+}
+
+// RunWithContext runs the container, stopping it if runCtx is canceled before the container exits.
+func (c *Container) RunWithContext(runCtx context.Context, cConfig ContainerRunConfig) (string, int, error) {
 	containerID := ""
 
+	if runCtx == nil {
+		runCtx = context.Background()
+	}
+
 	start := time.Now()
 
 	// Pull the Docker image if it's not already available
@@ -369,6 +378,23 @@ func (c *Container) Run(cConfig ContainerRunConfig) (string, int, error) {
 	case status := <-statusCh:
 		// Set the status code of the container run
 		exitCode = status.StatusCode
+	case <-runCtx.Done():
+		slog.Info("container run canceled, stopping container", "image", c.Image, "container", containerID)
+		err := c.dockerClient.CLI.ContainerStop(c.ctx, containerID, container.StopOptions{})
+		if err != nil {
+			slog.Error("Error stopping container", "error", err, "container", containerID)
+		}
+		if !cConfig.RetainArtifacts {
+			err = c.dockerClient.CLI.ContainerRemove(c.ctx, containerID, container.RemoveOptions{Force: true})
+			if err != nil {
+				slog.Error("Error removing container", "error", err, "container", containerID)
+			}
+		}
+		err = c.SetRunStatus(containerID, "canceled")
+		if err != nil {
+			return containerID, -1, perr.InternalWithMessage("Error setting run status to canceled: " + err.Error())
+		}
+		return containerID, -1, perr.ExecutionErrorWithMessage("Container run canceled: " + runCtx.Err().Error())
 	}
 	slog.Debug("container wait", "elapsed", time.Since(containerWaitStart), "image", c.Image, "container", containerResp.ID)
 
@@ -76,7 +76,7 @@ func LogEventMessage(ctx context.Context, evt interface{}, lock *sync.Mutex) err
 
 	db, err := store.OpenFlowpipeDB()
 	if err != nil {
-		return perr.InternalWithMessage("Error opening SQLite database " + err.Error())
+		return perr.InternalWithMessage("Error opening process store " + err.Error())
 	}
 	defer db.Close()
 
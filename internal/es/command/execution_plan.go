@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"slices"
 
+	"github.com/turbot/flowpipe/internal/constants"
 	"github.com/turbot/flowpipe/internal/es/event"
 	"github.com/turbot/flowpipe/internal/es/execution"
 	"github.com/turbot/pipe-fittings/perr"
@@ -41,6 +42,11 @@ func (h ExecutionPlanHandler) Handle(ctx context.Context, c interface{}) error {
 		return err
 	}
 
+	// A canceled execution is final, there is nothing left to plan
+	if ex.Status == constants.StateCanceled {
+		return nil
+	}
+
 	// Check if we have started the trigger execution
 	if cmd.TriggerQueue != nil && ex.TriggerExecution == nil && len(ex.PipelineExecutions) == 0 {
 
@@ -83,6 +89,7 @@ func (h ExecutionPlanHandler) Handle(ctx context.Context, c interface{}) error {
 	if allFinished {
 
 		failure := false
+		canceled := false
 
 		// any failure?
 		for _, pex := range ex.PipelineExecutions {
@@ -90,6 +97,19 @@ func (h ExecutionPlanHandler) Handle(ctx context.Context, c interface{}) error {
 				failure = true
 				break
 			}
+			if pex.Status == constants.StateCanceled {
+				canceled = true
+			}
+		}
+
+		if canceled && !failure {
+			// raise execution canceled
+			cmd := event.ExecutionCanceledFromExecutionPlan(cmd)
+			err = h.EventBus.Publish(ctx, cmd)
+			if err != nil {
+				slog.Error("Error publishing event", "error", err)
+			}
+			return nil
 		}
 
 		if ex.TriggerExecution != nil {
@@ -188,6 +208,12 @@ func (h ExecutionPlanHandler) Handle(ctx context.Context, c interface{}) error {
 		return nil
 	}
 
+	// Some pipelines are still running and there is nothing new to start. Planning the execution now would
+	// finish it while its pipelines are still running.
+	if cmd.TriggerQueue == nil && cmd.PipelineQueue == nil {
+		return nil
+	}
+
 	// Right now there's not much to do in execution plan, we still need to start with either a single
 	// pipeline or a trigger
 	evt := event.ExecutionPlannedFromExecutionPlan(cmd)
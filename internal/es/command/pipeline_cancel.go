@@ -3,6 +3,7 @@ package command
 import (
 	"context"
 	"log/slog"
+	"slices"
 
 	"github.com/turbot/flowpipe/internal/es/event"
 	"github.com/turbot/flowpipe/internal/es/execution"
@@ -19,6 +20,8 @@ func (h PipelineCancelHandler) NewCommand() interface{} {
 	return &event.PipelineCancel{}
 }
 
+// pipeline_cancel command handler
+// issue this to cancel a pipeline execution, child pipeline executions are canceled by the pipeline_canceled handler
 func (h PipelineCancelHandler) Handle(ctx context.Context, c interface{}) error {
 	cmd, ok := c.(*event.PipelineCancel)
 	if !ok {
@@ -32,6 +35,36 @@ func (h PipelineCancelHandler) Handle(ctx context.Context, c interface{}) error
 		plannerMutex.Unlock()
 	}()
 
+	ex, err := execution.GetExecution(cmd.Event.ExecutionID)
+	if err != nil {
+		slog.Error("pipeline_cancel: Error loading pipeline execution", "error", err)
+		err2 := h.EventBus.Publish(ctx, event.NewPipelineFailed(ctx, event.ForPipelineCancelToPipelineFailed(cmd, err)))
+		if err2 != nil {
+			slog.Error("Error publishing PipelineFailed event", "error", err2)
+		}
+		return nil
+	}
+
+	pex := ex.PipelineExecutions[cmd.PipelineExecutionID]
+	if pex == nil {
+		slog.Error("Can't cancel unknown pipeline execution", "pipeline_execution_id", cmd.PipelineExecutionID)
+		return perr.NotFoundWithMessage("pipeline execution " + cmd.PipelineExecutionID + " not found")
+	}
+
+	if slices.Contains(event.EndEvents, pex.Status) {
+		slog.Info("Pipeline execution already completed, nothing to cancel", "pipeline_execution_id", cmd.PipelineExecutionID, "pipelineStatus", pex.Status)
+		return nil
+	}
+
 	e := event.NewPipelineCanceledFromPipelineCancel(cmd)
-	return h.EventBus.Publish(ctx, e)
+	err = h.EventBus.Publish(ctx, e)
+	if err != nil {
+		return err
+	}
+
+	// The pipeline execution is now marked as canceled, any step that finishes from here on is ignored by the
+	// step_finished handler. Stop the steps that are still running.
+	execution.CancelPipelineExecutionSteps(cmd.PipelineExecutionID)
+
+	return nil
 }
@@ -46,6 +46,10 @@ func (h PipelinePauseHandler) Handle(ctx context.Context, c interface{}) error {
 	}
 
 	pex := ex.PipelineExecutions[cmd.PipelineExecutionID]
+	if pex == nil {
+		slog.Error("Can't pause unknown pipeline execution", "pipeline_execution_id", cmd.PipelineExecutionID)
+		return perr.NotFoundWithMessage("pipeline execution " + cmd.PipelineExecutionID + " not found")
+	}
 
 	if pex.Status != "started" && pex.Status != "queued" {
 		slog.Error("Can't pause pipeline execution that is not started or queued", "pipeline_execution_id", cmd.PipelineExecutionID, "pipelineStatus", pex.Status)
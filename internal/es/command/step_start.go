@@ -156,75 +156,101 @@ func (h StepStartHandler) Handle(ctx context.Context, c interface{}) error {
 		plannerMutex.Unlock()
 		plannerMutex = nil
 
+		// The step context is canceled if the pipeline execution is canceled while the step is running
+		stepCtx, stepDone := execution.NewStepExecutionContext(ctx, cmd.PipelineExecutionID, cmd.StepExecutionID)
+		defer stepDone()
+
+		// A replay serves the recorded output of the step instead of running it. Pipeline steps always run, they launch
+		// the child pipeline.
+		replayed := false
+		if replay := execution.GetReplay(executionID); replay != nil && stepDefn.GetType() != schema.BlockTypePipelineStepPipeline {
+			output, replayed = replay.StepOutput(&ex.Execution, cmd.PipelineExecutionID, cmd.StepName, cmd.StepForEach, cmd.StepLoop, cmd.StepRetry)
+		}
+
 		var primitiveError error
-		switch stepDefn.GetType() {
-		case schema.BlockTypePipelineStepHttp:
-			p := primitive.HTTPRequest{}
-			output, primitiveError = p.Run(ctx, cmd.StepInput)
-		case schema.BlockTypePipelineStepPipeline:
-			p := primitive.RunPipeline{}
-			output, primitiveError = p.Run(ctx, cmd.StepInput)
-		case schema.BlockTypePipelineStepEmail:
-			p := primitive.Email{}
-			output, primitiveError = p.Run(ctx, cmd.StepInput)
-		case schema.BlockTypePipelineStepQuery:
-			p := primitive.Query{}
-			output, primitiveError = p.Run(ctx, cmd.StepInput)
-		case schema.BlockTypePipelineStepSleep:
-			p := primitive.Sleep{}
-			output, primitiveError = p.Run(ctx, cmd.StepInput)
-		case schema.BlockTypePipelineStepTransform:
-			p := primitive.Transform{}
-			output, primitiveError = p.Run(ctx, cmd.StepInput)
-		case schema.BlockTypePipelineStepFunction:
-			p := primitive.Function{
-				ModPath: pipelineDefn.GetMod().ModPath,
-			}
-			output, primitiveError = p.Run(ctx, cmd.StepInput)
-		case schema.BlockTypePipelineStepContainer:
-			p := primitive.Container{FullyQualifiedStepName: stepDefn.GetFullyQualifiedName()}
-			output, primitiveError = p.Run(ctx, cmd.StepInput)
-		case schema.BlockTypePipelineStepInput:
-			if routerUrl, routed := primitive.GetInputRouter(); routed {
-				endStepFunc := func(stepExecution *execution.StepExecution, out *resources.Output) error {
-					return EndStepFromApi(ex, stepExecution, pipelineDefn, stepDefn, out, h.EventBus)
+		if replayed {
+			slog.Info("Replaying step output", "step", cmd.StepName, "pipeline_execution_id", cmd.PipelineExecutionID, "step_execution_id", cmd.StepExecutionID)
+		} else {
+			switch stepDefn.GetType() {
+			case schema.BlockTypePipelineStepHttp:
+				p := primitive.HTTPRequest{
+					ExecutionID:     cmd.Event.ExecutionID,
+					StepExecutionID: cmd.StepExecutionID,
 				}
-				p := primitive.NewRoutedInput(cmd.Event.ExecutionID, cmd.PipelineExecutionID, cmd.StepExecutionID, pipelineDefn.PipelineName, cmd.StepName, schema.BlockTypePipelineStepInput, routerUrl, endStepFunc)
-				cmd.StepInput["router_url"] = routerUrl
-				output, primitiveError = p.Run(ctx, cmd.StepInput)
-			} else {
-				p := primitive.NewInputPrimitive(cmd.Event.ExecutionID, cmd.PipelineExecutionID, cmd.StepExecutionID, pipelineDefn.PipelineName, cmd.StepName)
-				output, primitiveError = p.Run(ctx, cmd.StepInput)
-			}
-		case schema.BlockTypePipelineStepMessage:
-			if routerUrl, routed := primitive.GetInputRouter(); routed {
-				endStepFunc := func(stepExecution *execution.StepExecution, out *resources.Output) error {
-					return EndStepFromApi(ex, stepExecution, pipelineDefn, stepDefn, out, h.EventBus)
+				if mod := pipelineDefn.GetMod(); mod != nil {
+					p.ModPath = mod.ModPath
 				}
-				p := primitive.NewRoutedInput(cmd.Event.ExecutionID, cmd.PipelineExecutionID, cmd.StepExecutionID, pipelineDefn.PipelineName, cmd.StepName, schema.BlockTypePipelineStepMessage, routerUrl, endStepFunc)
-				cmd.StepInput["router_url"] = routerUrl
-				output, primitiveError = p.Run(ctx, cmd.StepInput)
-			} else {
-				p := primitive.NewMessagePrimitive(cmd.Event.ExecutionID, cmd.PipelineExecutionID, cmd.StepExecutionID, pipelineDefn.PipelineName, cmd.StepName)
-				output, primitiveError = p.Run(ctx, cmd.StepInput)
-			}
-		default:
-			slog.Error("Unknown step type", "type", stepDefn.GetType())
+				output, primitiveError = p.Run(stepCtx, cmd.StepInput)
+			case schema.BlockTypePipelineStepPipeline:
+				p := primitive.RunPipeline{}
+				output, primitiveError = p.Run(stepCtx, cmd.StepInput)
+			case schema.BlockTypePipelineStepEmail:
+				p := primitive.Email{}
+				output, primitiveError = p.Run(stepCtx, cmd.StepInput)
+			case schema.BlockTypePipelineStepQuery:
+				p := primitive.Query{}
+				output, primitiveError = p.Run(stepCtx, cmd.StepInput)
+			case schema.BlockTypePipelineStepSleep:
+				p := primitive.Sleep{}
+				output, primitiveError = p.Run(stepCtx, cmd.StepInput)
+			case schema.BlockTypePipelineStepTransform:
+				p := primitive.Transform{}
+				output, primitiveError = p.Run(stepCtx, cmd.StepInput)
+			case schema.BlockTypePipelineStepFunction:
+				p := primitive.Function{
+					ModPath: pipelineDefn.GetMod().ModPath,
+				}
+				output, primitiveError = p.Run(stepCtx, cmd.StepInput)
+			case schema.BlockTypePipelineStepContainer:
+				p := primitive.Container{FullyQualifiedStepName: stepDefn.GetFullyQualifiedName()}
+				output, primitiveError = p.Run(stepCtx, cmd.StepInput)
+			case schema.BlockTypePipelineStepInput:
+				if routerUrl, routed := primitive.GetInputRouter(); routed {
+					endStepFunc := func(stepExecution *execution.StepExecution, out *resources.Output) error {
+						return EndStepFromApi(ex, stepExecution, pipelineDefn, stepDefn, out, h.EventBus)
+					}
+					p := primitive.NewRoutedInput(cmd.Event.ExecutionID, cmd.PipelineExecutionID, cmd.StepExecutionID, pipelineDefn.PipelineName, cmd.StepName, schema.BlockTypePipelineStepInput, routerUrl, endStepFunc)
+					cmd.StepInput["router_url"] = routerUrl
+					output, primitiveError = p.Run(ctx, cmd.StepInput)
+				} else {
+					p := primitive.NewInputPrimitive(cmd.Event.ExecutionID, cmd.PipelineExecutionID, cmd.StepExecutionID, pipelineDefn.PipelineName, cmd.StepName)
+					output, primitiveError = p.Run(ctx, cmd.StepInput)
+				}
+			case schema.BlockTypePipelineStepMessage:
+				if routerUrl, routed := primitive.GetInputRouter(); routed {
+					endStepFunc := func(stepExecution *execution.StepExecution, out *resources.Output) error {
+						return EndStepFromApi(ex, stepExecution, pipelineDefn, stepDefn, out, h.EventBus)
+					}
+					p := primitive.NewRoutedInput(cmd.Event.ExecutionID, cmd.PipelineExecutionID, cmd.StepExecutionID, pipelineDefn.PipelineName, cmd.StepName, schema.BlockTypePipelineStepMessage, routerUrl, endStepFunc)
+					cmd.StepInput["router_url"] = routerUrl
+					output, primitiveError = p.Run(ctx, cmd.StepInput)
+				} else {
+					p := primitive.NewMessagePrimitive(cmd.Event.ExecutionID, cmd.PipelineExecutionID, cmd.StepExecutionID, pipelineDefn.PipelineName, cmd.StepName)
+					output, primitiveError = p.Run(ctx, cmd.StepInput)
+				}
+			default:
+				slog.Error("Unknown step type", "type", stepDefn.GetType())
 
-			plannerMutex = event.GetEventStoreMutex(cmd.Event.ExecutionID)
-			plannerMutex.Lock()
+				plannerMutex = event.GetEventStoreMutex(cmd.Event.ExecutionID)
+				plannerMutex.Lock()
 
-			err2 := h.EventBus.Publish(ctx, event.NewPipelineFailed(ctx, event.ForStepStartToPipelineFailed(cmd, err)))
-			if err2 != nil {
-				slog.Error("Error publishing event", "error", err2)
-			}
+				err2 := h.EventBus.Publish(ctx, event.NewPipelineFailed(ctx, event.ForStepStartToPipelineFailed(cmd, err)))
+				if err2 != nil {
+					slog.Error("Error publishing event", "error", err2)
+				}
 
-			return
+				return
+			}
 		}
 
 		plannerMutex = event.GetEventStoreMutex(cmd.Event.ExecutionID)
 		plannerMutex.Lock()
 
+		if stepCtx.Err() != nil {
+			slog.Info("Step execution canceled", "step", cmd.StepName, "pipeline_execution_id", cmd.PipelineExecutionID, "step_execution_id", cmd.StepExecutionID)
+			primitiveError = perr.ExecutionErrorWithMessage("Step execution canceled")
+		}
+
 		if primitiveError != nil {
 			slog.Error("primitive failed", "error", primitiveError)
 			if output == nil {
@@ -286,13 +312,13 @@ func (h StepStartHandler) Handle(ctx context.Context, c interface{}) error {
 			output.Status = constants.StateFinished
 		}
 
-		if output.Status == constants.StateFinished && stepDefn.GetType() == schema.BlockTypeInput && (o.IsServerMode || primitive.IsInputRouted()) {
+		if output.Status == constants.StateFinished && !replayed && stepDefn.GetType() == schema.BlockTypeInput && (o.IsServerMode || primitive.IsInputRouted()) {
 			slog.Info("input step started, waiting for external response", "step", cmd.StepName, "pipelineExecutionID", cmd.PipelineExecutionID, "executionID", cmd.Event.ExecutionID)
 			raisePipelinePlannedFromStepStart(stepDefn, cmd, h.EventBus)
 			return
 		}
 
-		if output.Status == constants.StateFinished && stepDefn.GetType() == schema.BlockTypePipelineStepMessage && primitive.IsInputRouted() {
+		if output.Status == constants.StateFinished && !replayed && stepDefn.GetType() == schema.BlockTypePipelineStepMessage && primitive.IsInputRouted() {
 			slog.Info("routed message step started, waiting for external confirmation/response", "step", cmd.StepName, "pipelineExecutionID", cmd.PipelineExecutionID, "executionID", cmd.Event.ExecutionID)
 			raisePipelinePlannedFromStepStart(stepDefn, cmd, h.EventBus)
 			return
@@ -13,6 +13,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	localcmdconfig "github.com/turbot/flowpipe/internal/cmdconfig"
+	"github.com/turbot/flowpipe/internal/es/event"
+	"github.com/turbot/flowpipe/internal/es/execution"
 	"github.com/turbot/flowpipe/internal/filepaths"
 	"github.com/turbot/flowpipe/internal/resources"
 	"github.com/turbot/flowpipe/internal/service/manager"
@@ -293,3 +295,96 @@ func TestModLongRunningTestingSuite(t *testing.T) {
 		FlowpipeTestSuite: &FlowpipeTestSuite{},
 	})
 }
+
+func (suite *ModLongRunningTestSuite) TestCancelPipelineWithChildPipeline() {
+	assert := assert.New(suite.T())
+	pipelineInput := resources.Input{}
+	_, pipelineCmd, err := runPipeline(suite.FlowpipeTestSuite, "test_suite_mod.pipeline.long_sleep_with_child", 500*time.Millisecond, pipelineInput)
+	if err != nil {
+		assert.Fail("Error creating execution", err)
+		return
+	}
+
+	cancelCmd, err := event.NewPipelineCancel(pipelineCmd.Event.ExecutionID, pipelineCmd.PipelineExecutionID, event.WithCancelReason("test"))
+	if err != nil {
+		assert.Fail("Error creating cancel command", err)
+		return
+	}
+
+	err = suite.esService.Send(cancelCmd)
+	if err != nil {
+		assert.Fail("Error sending cancel command", err)
+		return
+	}
+
+	// both sleep steps are 20s, the cancellation must interrupt them
+	ex, err := getExAndWait(suite.FlowpipeTestSuite, pipelineCmd.Event.ExecutionID, 100*time.Millisecond, 50, "canceled")
+	if err != nil {
+		assert.Fail("Error waiting for execution", err)
+		return
+	}
+
+	assert.Equal("canceled", ex.Status)
+	assert.Equal(2, len(ex.PipelineExecutions))
+	for _, p := range ex.PipelineExecutions {
+		assert.Equal("canceled", p.Status)
+	}
+}
+
+func (suite *ModLongRunningTestSuite) TestPauseAndResumePipeline() {
+	assert := assert.New(suite.T())
+	pipelineInput := resources.Input{}
+	_, pipelineCmd, err := runPipeline(suite.FlowpipeTestSuite, "test_suite_mod.pipeline.sequential_sleep", 500*time.Millisecond, pipelineInput)
+	if err != nil {
+		assert.Fail("Error creating execution", err)
+		return
+	}
+
+	pauseCmd, err := event.NewPipelinePause(pipelineCmd.Event.ExecutionID, pipelineCmd.PipelineExecutionID, event.WithPauseReason("test"))
+	if err != nil {
+		assert.Fail("Error creating pause command", err)
+		return
+	}
+
+	err = suite.esService.Send(pauseCmd)
+	if err != nil {
+		assert.Fail("Error sending pause command", err)
+		return
+	}
+
+	ex, err := getExAndWait(suite.FlowpipeTestSuite, pipelineCmd.Event.ExecutionID, 100*time.Millisecond, 50, "paused")
+	if err != nil {
+		assert.Fail("Error waiting for execution", err)
+		return
+	}
+	assert.Equal("paused", ex.Status)
+
+	// the in-flight step is left to finish but the next step must not be started
+	time.Sleep(3 * time.Second)
+
+	ex, err = execution.GetExecution(pipelineCmd.Event.ExecutionID)
+	if err != nil {
+		assert.Fail("Error getting execution", err)
+		return
+	}
+	pex := ex.PipelineExecutions[pipelineCmd.PipelineExecutionID]
+	assert.Equal("paused", pex.Status)
+	assert.Equal("finished", pex.StepStatus["sleep.first"]["0"].StepExecutions[0].Status)
+	assert.Nil(pex.StepStatus["sleep.second"])
+
+	resumeCmd := event.NewPipelineResume(pipelineCmd.Event.ExecutionID, pipelineCmd.PipelineExecutionID)
+	err = suite.esService.Send(resumeCmd)
+	if err != nil {
+		assert.Fail("Error sending resume command", err)
+		return
+	}
+
+	_, pex, err = getPipelineExAndWait(suite.FlowpipeTestSuite, pipelineCmd.Event, pipelineCmd.PipelineExecutionID, 100*time.Millisecond, 50, "finished")
+	if err != nil {
+		assert.Fail("Error waiting for execution", err)
+		return
+	}
+
+	assert.Equal("finished", pex.Status)
+	assert.Equal("done", pex.PipelineOutput["val"])
+}
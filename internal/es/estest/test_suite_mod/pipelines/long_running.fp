@@ -6,3 +6,28 @@ pipeline "long_sleep" {
         value = "done"
     }
 }
+
+pipeline "long_sleep_with_child" {
+    step "pipeline" "child" {
+        pipeline = pipeline.long_sleep
+    }
+
+    step "sleep" "long" {
+        duration = "20s"
+    }
+}
+
+pipeline "sequential_sleep" {
+    step "sleep" "first" {
+        duration = "2s"
+    }
+
+    step "sleep" "second" {
+        depends_on = [step.sleep.first]
+        duration   = "1s"
+    }
+
+    output "val" {
+        value = "done"
+    }
+}
@@ -3,7 +3,7 @@ package event
 var EndEvents = []string{
 	"finished",
 	"failed",
-	"cancelled",
+	"canceled",
 }
 
 const (
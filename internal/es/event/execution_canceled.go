@@ -0,0 +1,22 @@
+package event
+
+type ExecutionCanceled struct {
+	Event  *Event `json:"event"`
+	Type   string `json:"type"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (e *ExecutionCanceled) GetEvent() *Event {
+	return e.Event
+}
+
+func (e *ExecutionCanceled) HandlerName() string {
+	return HandlerExecutionCancelled
+}
+
+func ExecutionCanceledFromExecutionPlan(e *ExecutionPlan) *ExecutionCanceled {
+	return &ExecutionCanceled{
+		Event: NewFlowEvent(e.Event),
+		Type:  e.Type,
+	}
+}
@@ -24,12 +24,13 @@ func (e *PipelineCancel) HandlerName() string {
 type PipelineCancelOption func(*PipelineCancel) error
 
 // NewPipelineCancel creates a new PipelineCancel event.
-func NewPipelineCancel(pipelineExecutionID string, opts ...PipelineCancelOption) (*PipelineCancel, error) {
+func NewPipelineCancel(executionID, pipelineExecutionID string, opts ...PipelineCancelOption) (*PipelineCancel, error) {
 	// Defaults
-	e := NewEventForExecutionID(pipelineExecutionID)
+	e := NewEventForExecutionID(executionID)
 	// Defaults
 	evt := &PipelineCancel{
-		Event: e,
+		Event:               e,
+		PipelineExecutionID: pipelineExecutionID,
 	}
 	// Set options
 	for _, opt := range opts {
@@ -40,3 +41,20 @@ func NewPipelineCancel(pipelineExecutionID string, opts ...PipelineCancelOption)
 	}
 	return evt, nil
 }
+
+// WithCancelReason sets the reason recorded against the cancellation.
+func WithCancelReason(reason string) PipelineCancelOption {
+	return func(e *PipelineCancel) error {
+		e.Reason = reason
+		return nil
+	}
+}
+
+// PipelineCancelFromPipelineCanceled cascades the cancellation of a pipeline to one of its child pipelines.
+func PipelineCancelFromPipelineCanceled(e *PipelineCanceled, childPipelineExecutionID string) *PipelineCancel {
+	return &PipelineCancel{
+		Event:               NewFlowEvent(e.Event),
+		PipelineExecutionID: childPipelineExecutionID,
+		Reason:              e.Reason,
+	}
+}
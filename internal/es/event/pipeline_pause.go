@@ -40,6 +40,23 @@ func NewPipelinePause(executionId, pipelineExecutionId string, opts ...PipelineP
 	return cmd, nil
 }
 
+// WithPauseReason sets the reason recorded against the pause.
+func WithPauseReason(reason string) PipelinePauseOption {
+	return func(e *PipelinePause) error {
+		e.Reason = reason
+		return nil
+	}
+}
+
+// PipelinePauseFromPipelinePaused cascades the pause of a pipeline to one of its child pipelines.
+func PipelinePauseFromPipelinePaused(e *PipelinePaused, childPipelineExecutionID string) *PipelinePause {
+	return &PipelinePause{
+		Event:               NewFlowEvent(e.Event),
+		PipelineExecutionID: childPipelineExecutionID,
+		Reason:              e.Reason,
+	}
+}
+
 func PipelinePauseFromPipelinePlanned(e *PipelinePlanned) *PipelinePause {
 	cmd := &PipelinePause{
 		Event:               NewFlowEvent(e.Event),
@@ -773,6 +773,23 @@ func (ex *Execution) StepDefinition(pipelineExecutionID, stepExecutionID string)
 	return sd, nil
 }
 
+// stepFullyQualifiedName returns the name the step execution was queued with, i.e. the fully qualified name of its step
+// definition. The event log is replayed without looking up the step definitions, so a process can be shown after the
+// mod has changed or on a server without the mod (see process import).
+func (ex *Execution) stepFullyQualifiedName(pipelineExecutionID, stepExecutionID string) (string, error) {
+	pe, ok := ex.PipelineExecutions[pipelineExecutionID]
+	if !ok {
+		return "", perr.BadRequestWithMessage("pipeline execution not found: " + pipelineExecutionID)
+	}
+
+	se, ok := pe.StepExecutions[stepExecutionID]
+	if !ok {
+		return "", perr.BadRequestWithMessage("step execution not found: " + stepExecutionID)
+	}
+
+	return se.Name, nil
+}
+
 func (ex *Execution) PipelineData(pipelineExecutionID string) (map[string]interface{}, error) {
 
 	// Get the outputs from prior steps in the pipeline
@@ -952,6 +969,7 @@ var (
 	ExecutionFinishedEvent = event.ExecutionFinished{}
 	ExecutionFailedEvent   = event.ExecutionFailed{}
 	ExecutionPausedEvent   = event.ExecutionPaused{}
+	ExecutionCanceledEvent = event.ExecutionCanceled{}
 
 	TriggerQueuedEvent   = event.TriggerQueued{}
 	TriggerFailedEvent   = event.TriggerFailed{}
@@ -1023,6 +1041,9 @@ func (ex *Execution) appendEvent(entry interface{}) error {
 	case *event.ExecutionPaused:
 		ex.Status = "paused"
 
+	case *event.ExecutionCanceled:
+		ex.Status = constants.StateCanceled
+
 	case *event.TriggerQueue:
 		if ex.TriggerExecution != nil {
 			return perr.BadRequestWithMessage("trigger execution already exists")
@@ -1100,21 +1121,21 @@ func (ex *Execution) appendEvent(entry interface{}) error {
 			MaxConcurrency:      et.MaxConcurrency,
 		}
 
-		stepDefn, err := ex.StepDefinition(et.PipelineExecutionID, et.StepExecutionID)
+		stepName, err := ex.stepFullyQualifiedName(et.PipelineExecutionID, et.StepExecutionID)
 		if err != nil {
-			slog.Error("Failed to get step definition - 1", "execution", ex.ID, "stepExecutionID", et.StepExecutionID, "error", err)
+			slog.Error("Failed to get step name - 1", "execution", ex.ID, "stepExecutionID", et.StepExecutionID, "error", err)
 			return err
 		}
 		pe.StepExecutions[et.StepExecutionID].Input = et.StepInput
 		pe.StepExecutions[et.StepExecutionID].StepForEach = et.StepForEach
 		pe.StepExecutions[et.StepExecutionID].NextStepAction = et.NextStepAction
 
-		if pe.StepStatus[stepDefn.GetFullyQualifiedName()] == nil {
-			pe.StepStatus[stepDefn.GetFullyQualifiedName()] = map[string]*StepStatus{}
+		if pe.StepStatus[stepName] == nil {
+			pe.StepStatus[stepName] = map[string]*StepStatus{}
 		}
 
-		if pe.StepStatus[stepDefn.GetFullyQualifiedName()][et.StepForEach.Key] == nil {
-			pe.StepStatus[stepDefn.GetFullyQualifiedName()][et.StepForEach.Key] = &StepStatus{
+		if pe.StepStatus[stepName][et.StepForEach.Key] == nil {
+			pe.StepStatus[stepName][et.StepForEach.Key] = &StepStatus{
 				Queued:   map[string]bool{},
 				Started:  map[string]bool{},
 				Finished: map[string]bool{},
@@ -1122,7 +1143,7 @@ func (ex *Execution) appendEvent(entry interface{}) error {
 			}
 		}
 
-		pe.StepStatus[stepDefn.GetFullyQualifiedName()][et.StepForEach.Key].Queue(et.StepExecutionID)
+		pe.StepStatus[stepName][et.StepForEach.Key].Queue(et.StepExecutionID)
 
 	case *event.StepQueued:
 		pe := ex.PipelineExecutions[et.PipelineExecutionID]
@@ -1142,21 +1163,21 @@ func (ex *Execution) appendEvent(entry interface{}) error {
 
 		// Step the specific step execution status
 		pe.StepExecutions[et.StepExecutionID].Status = "started"
-		stepDefn, err := ex.StepDefinition(pe.ID, et.StepExecutionID)
+		stepName, err := ex.stepFullyQualifiedName(pe.ID, et.StepExecutionID)
 		if err != nil {
-			slog.Error("Failed to get step definition - 2", "stepExecutionID", et.StepExecutionID, "error", err)
+			slog.Error("Failed to get step name - 2", "stepExecutionID", et.StepExecutionID, "error", err)
 			return err
 		}
 
-		pe.StartStep(stepDefn.GetFullyQualifiedName(), et.Key, et.StepExecutionID)
+		pe.StartStep(stepName, et.Key, et.StepExecutionID)
 		pe.StepExecutions[et.StepExecutionID].StartTime = et.Event.CreatedAt
 
 	// this is the generic step finish event that is fired by the command.step_start command
 	case *event.StepFinished:
 		pe := ex.PipelineExecutions[et.PipelineExecutionID]
-		stepDefn, err := ex.StepDefinition(pe.ID, et.StepExecutionID)
+		stepName, err := ex.stepFullyQualifiedName(pe.ID, et.StepExecutionID)
 		if err != nil {
-			slog.Error("Failed to get step definition", "stepExecutionID", et.StepExecutionID, "error", err)
+			slog.Error("Failed to get step name", "stepExecutionID", et.StepExecutionID, "error", err)
 			return err
 		}
 
@@ -1198,25 +1219,25 @@ func (ex *Execution) appendEvent(entry interface{}) error {
 
 		// TODO: Fix creating duplicate data as we dereference before appending (moved EndTime above this so it is passed into StepStatus)
 		// append the Step Execution to the StepStatus (yes it's duplicate data, we may be able to refactor this later)
-		pe.StepStatus[stepDefn.GetFullyQualifiedName()][et.StepForEach.Key].StepExecutions = append(pe.StepStatus[stepDefn.GetFullyQualifiedName()][et.StepForEach.Key].StepExecutions,
+		pe.StepStatus[stepName][et.StepForEach.Key].StepExecutions = append(pe.StepStatus[stepName][et.StepForEach.Key].StepExecutions,
 			*pe.StepExecutions[et.StepExecutionID])
 
 		if et.Output.HasErrors() {
 			if et.Output.FailureMode == constants.FailureModeIgnored {
 				// Should we add the step errors to PipelineExecution.Errors if the error is ignored?
-				pe.FinishStep(stepDefn.GetFullyQualifiedName(), et.StepForEach.Key, et.StepExecutionID, loopHold, errorHold)
+				pe.FinishStep(stepName, et.StepForEach.Key, et.StepExecutionID, loopHold, errorHold)
 			} else {
-				pe.FailStep(stepDefn.GetFullyQualifiedName(), et.StepForEach.Key, et.StepExecutionID, loopHold, errorHold)
+				pe.FailStep(stepName, et.StepForEach.Key, et.StepExecutionID, loopHold, errorHold)
 
 				if !errorHold {
 					// if there's a retry config, don't add that failure to the pipeline failure until the final retry attempt
 					//
 					// retry completed is represented in the errorHold variable
-					pe.Fail(stepDefn.GetFullyQualifiedName(), et.Output.Errors...)
+					pe.Fail(stepName, et.Output.Errors...)
 				}
 			}
 		} else {
-			pe.FinishStep(stepDefn.GetFullyQualifiedName(), et.StepForEach.Key, et.StepExecutionID, loopHold, errorHold)
+			pe.FinishStep(stepName, et.StepForEach.Key, et.StepExecutionID, loopHold, errorHold)
 		}
 
 	case *event.StepForEachPlanned:
@@ -1255,7 +1276,7 @@ func (ex *Execution) appendEvent(entry interface{}) error {
 
 	case *event.PipelineCanceled:
 		pe := ex.PipelineExecutions[et.PipelineExecutionID]
-		pe.Status = "canceled"
+		pe.Status = constants.StateCanceled
 		pe.EndTime = et.Event.CreatedAt
 
 	case *event.PipelinePaused:
@@ -2,7 +2,6 @@ package execution
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -727,6 +726,16 @@ func (ex *ExecutionInMemory) AppendSerialisedEventLogEntry(logEntry event.EventL
 
 		return ex.appendEvent(&et)
 
+	case ExecutionCanceledEvent.HandlerName(): // "handler.execution_cancelled"
+		var et event.ExecutionCanceled
+		err := json.Unmarshal(jsonData, &et)
+		if err != nil {
+			slog.Error("Fail to unmarshall handler.execution_cancelled event", "execution", ex.ID, "error", err)
+			return perr.InternalWithMessage("Fail to unmarshall handler.execution_cancelled event")
+		}
+
+		return ex.appendEvent(&et)
+
 	case PipelineQueueCommand.HandlerName(): // "command.pipeline_queue"
 		var et event.PipelineQueue
 		err := json.Unmarshal(jsonData, &et)
@@ -946,6 +955,15 @@ func (ex *ExecutionInMemory) AppendEventLogEntry(logEntry event.EventLogImpl) er
 
 		return ex.appendEvent(et)
 
+	case ExecutionCanceledEvent.HandlerName(): // "handler.execution_cancelled"
+		et, ok := logEntry.GetDetail().(*event.ExecutionCanceled)
+		if !ok {
+			slog.Error("Fail to unmarshall handler.execution_cancelled event", "execution", ex.ID)
+			return perr.InternalWithMessage("Fail to unmarshall handler.execution_cancelled event")
+		}
+
+		return ex.appendEvent(et)
+
 	case PipelineQueueCommand.HandlerName(): // "command.pipeline_queue"
 		et, ok := logEntry.GetDetail().(*event.PipelineQueue)
 		if !ok {
@@ -1089,7 +1107,7 @@ func (ex *ExecutionInMemory) AppendEventLogEntry(logEntry event.EventLogImpl) er
 	return nil
 }
 
-func SaveEventToSQLite(db *sql.DB, executionID string, event event.EventLogImpl) error {
+func SaveEventToSQLite(db *store.DB, executionID string, event event.EventLogImpl) error {
 	retentionInSecond := viper.GetInt(constants.ArgProcessRetention)
 	if retentionInSecond == 0 {
 		return nil
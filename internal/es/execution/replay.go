@@ -0,0 +1,152 @@
+package execution
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/turbot/flowpipe/internal/constants"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+// Replay holds the step outputs recorded by a past execution. The steps of an execution replaying it are served the
+// recorded outputs instead of running their primitive, the steps with no recorded output run live.
+//
+// Child pipelines always run, so their steps are replayed too.
+type Replay struct {
+	SourceExecutionID string
+	FromStep          string
+
+	outputs map[string]*resources.Output
+}
+
+// NewReplay collects the outputs of the step executions of ex. If fromStep is set only the step executions started
+// before the first execution of fromStep are replayed, fromStep and the steps after it run live.
+func NewReplay(ex *Execution, fromStep string) (*Replay, error) {
+	r := &Replay{
+		SourceExecutionID: ex.ID,
+		FromStep:          fromStep,
+		outputs:           map[string]*resources.Output{},
+	}
+
+	var cutoff time.Time
+	if fromStep != "" {
+		for _, pex := range ex.PipelineExecutions {
+			for _, se := range pex.StepExecutions {
+				if se.Name == fromStep && (cutoff.IsZero() || se.StartTime.Before(cutoff)) {
+					cutoff = se.StartTime
+				}
+			}
+		}
+
+		if cutoff.IsZero() {
+			return nil, perr.BadRequestWithMessage("step " + fromStep + " was not run by execution " + ex.ID)
+		}
+	}
+
+	for _, pex := range ex.PipelineExecutions {
+		for _, se := range pex.StepExecutions {
+			if se.Output == nil || (se.Status != constants.StateFinished && se.Status != constants.StateFailed) {
+				continue
+			}
+
+			if !cutoff.IsZero() && !se.StartTime.Before(cutoff) {
+				continue
+			}
+
+			r.outputs[replayKey(replayPipelinePath(ex, pex), se.Name, se.StepForEach, se.StepLoop, se.StepRetry)] = se.Output
+		}
+	}
+
+	return r, nil
+}
+
+// StepOutput returns the recorded output of a step execution of the pipeline execution of ex, false if the step
+// execution has to run live
+func (r *Replay) StepOutput(ex *Execution, pipelineExecutionID, stepName string, stepForEach *resources.StepForEach, stepLoop *resources.StepLoop, stepRetry *resources.StepRetry) (*resources.Output, bool) {
+	pex, ok := ex.PipelineExecutions[pipelineExecutionID]
+	if !ok {
+		return nil, false
+	}
+
+	output, ok := r.outputs[replayKey(replayPipelinePath(ex, pex), stepName, stepForEach, stepLoop, stepRetry)]
+	if !ok {
+		return nil, false
+	}
+
+	// the step start decorates the output, don't change the recorded one
+	replayed := *output
+	replayed.Errors = append([]resources.StepError{}, output.Errors...)
+	return &replayed, true
+}
+
+// replayPipelinePath identifies the pipeline execution by its pipeline and the pipeline step executions that started it
+// from the root pipeline, the child pipelines started by different iterations of a step don't share their outputs
+func replayPipelinePath(ex *Execution, pex *PipelineExecution) string {
+	if pex.ParentStepExecutionID == "" {
+		return pex.Name
+	}
+
+	parentPex, ok := ex.PipelineExecutions[pex.ParentExecutionID]
+	if !ok {
+		return pex.Name
+	}
+
+	se, ok := parentPex.StepExecutions[pex.ParentStepExecutionID]
+	if !ok {
+		return pex.Name
+	}
+
+	return replayKey(replayPipelinePath(ex, parentPex), se.Name, se.StepForEach, se.StepLoop, se.StepRetry) + ">" + pex.Name
+}
+
+func replayKey(pipelinePath, stepName string, stepForEach *resources.StepForEach, stepLoop *resources.StepLoop, stepRetry *resources.StepRetry) string {
+	key := ""
+	if stepForEach != nil {
+		key = stepForEach.Key
+	}
+
+	loopIndex := 0
+	if stepLoop != nil {
+		loopIndex = stepLoop.Index
+	}
+
+	retryCount := 0
+	if stepRetry != nil {
+		retryCount = stepRetry.Count
+	}
+
+	return fmt.Sprintf("%s/%s/%s/%d/%d", pipelinePath, stepName, key, loopIndex, retryCount)
+}
+
+// The replays of the running executions, keyed by execution id. Replays are not persisted, an execution resumed
+// after a restart runs its remaining steps live.
+var (
+	replays     = map[string]*Replay{}
+	replaysLock sync.RWMutex
+)
+
+func SetReplay(executionID string, r *Replay) {
+	replaysLock.Lock()
+	defer replaysLock.Unlock()
+
+	slog.Info("Replaying execution", "execution_id", executionID, "source_execution_id", r.SourceExecutionID, "from_step", r.FromStep)
+	replays[executionID] = r
+}
+
+// GetReplay returns the replay of an execution, nil if the execution is not a replay
+func GetReplay(executionID string) *Replay {
+	replaysLock.RLock()
+	defer replaysLock.RUnlock()
+
+	return replays[executionID]
+}
+
+func ReleaseReplay(executionID string) {
+	replaysLock.Lock()
+	defer replaysLock.Unlock()
+
+	delete(replays, executionID)
+}
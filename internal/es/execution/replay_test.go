@@ -0,0 +1,208 @@
+package execution
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/flowpipe/internal/resources"
+)
+
+func TestReplay(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Date(2024, 8, 1, 3, 0, 0, 0, time.UTC)
+
+	ex := &Execution{
+		ID: "exec_replay",
+		PipelineExecutions: map[string]*PipelineExecution{
+			"pexec_1": {
+				ID:   "pexec_1",
+				Name: "mod.pipeline.replay",
+				StepExecutions: map[string]*StepExecution{
+					"sexec_1": {
+						Name:      "transform.first",
+						Status:    "finished",
+						StartTime: start,
+						Output:    &resources.Output{Status: "finished", Data: map[string]interface{}{"value": "one"}},
+					},
+					"sexec_2": {
+						Name:        "http.each",
+						Status:      "finished",
+						StartTime:   start.Add(time.Second),
+						StepForEach: &resources.StepForEach{Key: "a"},
+						Output:      &resources.Output{Status: "finished", Data: map[string]interface{}{"value": "a"}},
+					},
+					"sexec_3": {
+						Name:        "http.each",
+						Status:      "failed",
+						StartTime:   start.Add(time.Second),
+						StepForEach: &resources.StepForEach{Key: "b"},
+						StepRetry:   &resources.StepRetry{Count: 1},
+						Output:      &resources.Output{Status: "failed", Errors: []resources.StepError{{Step: "http.each"}}},
+					},
+					"sexec_4": {
+						Name:      "http.last",
+						Status:    "finished",
+						StartTime: start.Add(2 * time.Second),
+						Output:    &resources.Output{Status: "finished"},
+					},
+					"sexec_5": {
+						Name:      "transform.running",
+						Status:    "started",
+						StartTime: start.Add(time.Second),
+					},
+				},
+			},
+		},
+	}
+
+	replay, err := NewReplay(ex, "")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	output, ok := replay.StepOutput(ex, "pexec_1", "transform.first", nil, nil, nil)
+	assert.True(ok)
+	assert.Equal("one", output.Data["value"])
+
+	_, ok = replay.StepOutput(ex, "pexec_unknown", "transform.first", nil, nil, nil)
+	assert.False(ok)
+
+	output, ok = replay.StepOutput(ex, "pexec_1", "http.each", &resources.StepForEach{Key: "a"}, nil, nil)
+	assert.True(ok)
+	assert.Equal("a", output.Data["value"])
+
+	_, ok = replay.StepOutput(ex, "pexec_1", "http.each", &resources.StepForEach{Key: "b"}, nil, nil)
+	assert.False(ok, "the first attempt of b was not recorded")
+
+	output, ok = replay.StepOutput(ex, "pexec_1", "http.each", &resources.StepForEach{Key: "b"}, nil, &resources.StepRetry{Count: 1})
+	assert.True(ok)
+	assert.Equal(1, len(output.Errors))
+
+	// the replayed output is a copy, decorating it leaves the recorded output intact
+	output.Errors[0].Step = "changed"
+	output.Status = "changed"
+	output, _ = replay.StepOutput(ex, "pexec_1", "http.each", &resources.StepForEach{Key: "b"}, nil, &resources.StepRetry{Count: 1})
+	assert.Equal("http.each", output.Errors[0].Step)
+	assert.Equal("failed", output.Status)
+
+	_, ok = replay.StepOutput(ex, "pexec_1", "transform.running", nil, nil, nil)
+	assert.False(ok, "steps that did not complete run live")
+
+	_, ok = replay.StepOutput(ex, "pexec_1", "http.last", nil, nil, nil)
+	assert.True(ok)
+
+	// from http.each, only the steps started before it are replayed
+	replay, err = NewReplay(ex, "http.each")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	_, ok = replay.StepOutput(ex, "pexec_1", "transform.first", nil, nil, nil)
+	assert.True(ok)
+
+	_, ok = replay.StepOutput(ex, "pexec_1", "http.each", &resources.StepForEach{Key: "a"}, nil, nil)
+	assert.False(ok)
+
+	_, ok = replay.StepOutput(ex, "pexec_1", "http.last", nil, nil, nil)
+	assert.False(ok)
+
+	_, err = NewReplay(ex, "http.unknown")
+	assert.NotNil(err)
+}
+
+func TestReplayForEachChildPipeline(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Date(2024, 8, 1, 3, 0, 0, 0, time.UTC)
+
+	// the pipeline step runs the child pipeline for each of a and b, the child pipelines have the same steps
+	childPipeline := func(id, parentStepExecutionID, value string) *PipelineExecution {
+		return &PipelineExecution{
+			ID:                    id,
+			Name:                  "mod.pipeline.child",
+			ParentExecutionID:     "pexec_parent",
+			ParentStepExecutionID: parentStepExecutionID,
+			StepExecutions: map[string]*StepExecution{
+				"sexec_" + value: {
+					Name:      "transform.value",
+					Status:    "finished",
+					StartTime: start.Add(time.Second),
+					Output:    &resources.Output{Status: "finished", Data: map[string]interface{}{"value": value}},
+				},
+			},
+		}
+	}
+
+	ex := &Execution{
+		ID: "exec_replay",
+		PipelineExecutions: map[string]*PipelineExecution{
+			"pexec_parent": {
+				ID:   "pexec_parent",
+				Name: "mod.pipeline.parent",
+				StepExecutions: map[string]*StepExecution{
+					"sexec_each_a": {
+						Name:        "pipeline.each",
+						Status:      "finished",
+						StartTime:   start,
+						StepForEach: &resources.StepForEach{Key: "a"},
+						Output:      &resources.Output{Status: "finished"},
+					},
+					"sexec_each_b": {
+						Name:        "pipeline.each",
+						Status:      "finished",
+						StartTime:   start,
+						StepForEach: &resources.StepForEach{Key: "b"},
+						Output:      &resources.Output{Status: "finished"},
+					},
+				},
+			},
+			"pexec_child_a": childPipeline("pexec_child_a", "sexec_each_a", "a"),
+			"pexec_child_b": childPipeline("pexec_child_b", "sexec_each_b", "b"),
+		},
+	}
+
+	replay, err := NewReplay(ex, "")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	// the replaying execution has its own ids
+	live := &Execution{
+		ID: "exec_live",
+		PipelineExecutions: map[string]*PipelineExecution{
+			"pexec_live": {
+				ID:   "pexec_live",
+				Name: "mod.pipeline.parent",
+				StepExecutions: map[string]*StepExecution{
+					"sexec_live_b": {Name: "pipeline.each", StepForEach: &resources.StepForEach{Key: "b"}},
+					"sexec_live_a": {Name: "pipeline.each", StepForEach: &resources.StepForEach{Key: "a"}},
+				},
+			},
+			"pexec_live_a": {
+				ID:                    "pexec_live_a",
+				Name:                  "mod.pipeline.child",
+				ParentExecutionID:     "pexec_live",
+				ParentStepExecutionID: "sexec_live_a",
+			},
+			"pexec_live_b": {
+				ID:                    "pexec_live_b",
+				Name:                  "mod.pipeline.child",
+				ParentExecutionID:     "pexec_live",
+				ParentStepExecutionID: "sexec_live_b",
+			},
+		},
+	}
+
+	output, ok := replay.StepOutput(live, "pexec_live_a", "transform.value", nil, nil, nil)
+	assert.True(ok)
+	assert.Equal("a", output.Data["value"])
+
+	output, ok = replay.StepOutput(live, "pexec_live_b", "transform.value", nil, nil, nil)
+	assert.True(ok)
+	assert.Equal("b", output.Data["value"], "the child pipelines of the for_each keys don't share their outputs")
+
+	_, ok = replay.StepOutput(live, "pexec_live", "transform.value", nil, nil, nil)
+	assert.False(ok, "the outputs are per pipeline")
+}
@@ -0,0 +1,53 @@
+package execution
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Running step executions, keyed by pipeline execution id then step execution id. This allows an in-flight
+// primitive (container, function, http, etc.) to be stopped when its pipeline execution is canceled.
+var (
+	stepExecutionCancelFuncs     = map[string]map[string]context.CancelFunc{}
+	stepExecutionCancelFuncsLock sync.Mutex
+)
+
+// NewStepExecutionContext returns a context for running a step primitive that is detached from the
+// cancellation of the parent (Watermill cancels the message context as soon as the handler returns)
+// but can be canceled with CancelPipelineExecutionSteps.
+//
+// The returned function must be called when the step primitive completes.
+func NewStepExecutionContext(ctx context.Context, pipelineExecutionID, stepExecutionID string) (context.Context, func()) {
+	stepCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	stepExecutionCancelFuncsLock.Lock()
+	defer stepExecutionCancelFuncsLock.Unlock()
+
+	if stepExecutionCancelFuncs[pipelineExecutionID] == nil {
+		stepExecutionCancelFuncs[pipelineExecutionID] = map[string]context.CancelFunc{}
+	}
+	stepExecutionCancelFuncs[pipelineExecutionID][stepExecutionID] = cancel
+
+	return stepCtx, func() {
+		stepExecutionCancelFuncsLock.Lock()
+		defer stepExecutionCancelFuncsLock.Unlock()
+
+		cancel()
+		delete(stepExecutionCancelFuncs[pipelineExecutionID], stepExecutionID)
+		if len(stepExecutionCancelFuncs[pipelineExecutionID]) == 0 {
+			delete(stepExecutionCancelFuncs, pipelineExecutionID)
+		}
+	}
+}
+
+// CancelPipelineExecutionSteps cancels the context of every step currently running in the given pipeline execution.
+func CancelPipelineExecutionSteps(pipelineExecutionID string) {
+	stepExecutionCancelFuncsLock.Lock()
+	defer stepExecutionCancelFuncsLock.Unlock()
+
+	for stepExecutionID, cancel := range stepExecutionCancelFuncs[pipelineExecutionID] {
+		slog.Info("Canceling running step execution", "pipeline_execution_id", pipelineExecutionID, "step_execution_id", stepExecutionID)
+		cancel()
+	}
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/turbot/flowpipe/internal/es/event"
+	"github.com/turbot/flowpipe/internal/es/execution"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+type ExecutionCanceled EventHandler
+
+func (h ExecutionCanceled) HandlerName() string {
+	return execution.ExecutionCanceledEvent.HandlerName()
+}
+
+func (h ExecutionCanceled) NewEvent() interface{} {
+	return &event.ExecutionCanceled{}
+}
+
+func (h ExecutionCanceled) Handle(ctx context.Context, ei interface{}) error {
+
+	evt, ok := ei.(*event.ExecutionCanceled)
+
+	if !ok {
+		slog.Error("invalid event type", "expected", "*event.ExecutionCanceled", "actual", ei)
+		return perr.BadRequestWithMessage("invalid event type expected *event.ExecutionCanceled")
+	}
+
+	plannerMutex := event.GetEventStoreMutex(evt.Event.ExecutionID)
+	plannerMutex.Lock()
+	defer func() {
+		if plannerMutex != nil {
+			plannerMutex.Unlock()
+		}
+	}()
+
+	execution.ReleaseReplay(evt.Event.ExecutionID)
+
+	return nil
+}
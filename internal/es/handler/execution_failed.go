@@ -36,5 +36,7 @@ func (h ExecutionFailed) Handle(ctx context.Context, ei interface{}) error {
 		}
 	}()
 
+	execution.ReleaseReplay(evt.Event.ExecutionID)
+
 	return nil
 }
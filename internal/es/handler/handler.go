@@ -17,6 +17,8 @@ import (
 	"github.com/turbot/flowpipe/internal/store"
 	"github.com/turbot/pipe-fittings/cache"
 	"github.com/turbot/pipe-fittings/perr"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/zclconf/go-cty/cty"
 )
 
 type EventHandler struct {
@@ -62,19 +64,23 @@ func LogEventMessage(ctx context.Context, cmd interface{}, lock *sync.Mutex) err
 
 	db, err := store.OpenFlowpipeDB()
 	if err != nil {
-		return perr.InternalWithMessage("Error opening SQLite database " + err.Error())
+		return perr.InternalWithMessage("Error opening process store " + err.Error())
 	}
 	defer db.Close()
 
 	newExecution := false
 
-	var name string
+	var name, pipelineName, triggerName string
 	if executionQueueCmd, ok := commandEvent.(*event.ExecutionQueue); ok {
 		newExecution = true
 		if executionQueueCmd.TriggerQueue != nil {
 			name = executionQueueCmd.TriggerQueue.Name
+			triggerName = executionQueueCmd.TriggerQueue.Name
+			pipelineName = triggerPipelineName(triggerName)
 		} else if executionQueueCmd.PipelineQueue != nil {
 			name = executionQueueCmd.PipelineQueue.Name
+			pipelineName = executionQueueCmd.PipelineQueue.Name
+			triggerName = executionQueueCmd.PipelineQueue.Trigger
 		} else {
 			return perr.BadRequestWithMessage("Invalid ExecutionQueue command, no TriggerQueue or PipelineQueue")
 		}
@@ -100,7 +106,7 @@ func LogEventMessage(ctx context.Context, cmd interface{}, lock *sync.Mutex) err
 
 		metrics.RunMetricInstance.StartExecution(executionID, name)
 
-		err = store.StartPipeline(executionID, name)
+		err = store.StartPipeline(executionID, pipelineName, triggerName)
 		if err != nil {
 			slog.Error("Unable to save pipeline in the database", "error", err)
 			return err
@@ -142,3 +148,19 @@ func pipelineCompletionHandler(executionID, pipelineExecutionID string, pipeline
 		db.RemoveStepExecutionIDMap(se.ID)
 	}
 }
+
+// triggerPipelineName returns the name of the pipeline run by the trigger, empty if it can't be resolved (for example
+// HTTP triggers, which set the pipeline per method)
+func triggerPipelineName(triggerName string) string {
+	t, err := db.GetTrigger(triggerName)
+	if err != nil || t == nil || t.Pipeline == cty.NilVal || !t.Pipeline.Type().IsObjectType() || !t.Pipeline.Type().HasAttribute(schema.LabelName) {
+		return ""
+	}
+
+	pipelineName := t.Pipeline.GetAttr(schema.LabelName)
+	if pipelineName.IsNull() || !pipelineName.IsKnown() || pipelineName.Type() != cty.String {
+		return ""
+	}
+
+	return pipelineName.AsString()
+}
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"slices"
 
+	"github.com/turbot/flowpipe/internal/constants"
 	"github.com/turbot/flowpipe/internal/es/event"
 	"github.com/turbot/flowpipe/internal/es/execution"
 	"github.com/turbot/flowpipe/internal/store"
@@ -28,44 +29,63 @@ func (h PipelineCanceled) Handle(ctx context.Context, ei interface{}) error {
 		return perr.BadRequestWithMessage("invalid event type expected *event.PipelineCanceled")
 	}
 
-	err := store.UpdatePipelineState(evt.Event.ExecutionID, "cancelled")
-	if err != nil {
-		slog.Error("pipeline_cancelled: Error updating pipeline state", "error", err)
-	}
-
 	plannerMutex := event.GetEventStoreMutex(evt.Event.ExecutionID)
 	plannerMutex.Lock()
 	defer func() {
 		plannerMutex.Unlock()
 	}()
 
-	_, pipelineDefn, err := execution.GetPipelineDefnFromExecution(evt.Event.ExecutionID, evt.PipelineExecutionID)
+	ex, pipelineDefn, err := execution.GetPipelineDefnFromExecution(evt.Event.ExecutionID, evt.PipelineExecutionID)
 	if err != nil {
 		slog.Error("pipeline_cancelled: Error loading pipeline execution", "error", err)
 		return err
 	}
 
-	ex, err := execution.GetExecution(evt.Event.ExecutionID)
-	if err != nil {
-		slog.Error("pipeline_finished: Error loading pipeline execution", "error", err)
-		return err
+	// the pipeline_run state tracks the execution as a whole, child pipelines don't change it
+	if slices.Contains(ex.RootPipelines, evt.PipelineExecutionID) {
+		err = store.UpdatePipelineState(evt.Event.ExecutionID, constants.StateCanceled)
+		if err != nil {
+			slog.Error("pipeline_cancelled: Error updating pipeline state", "error", err)
+		}
 	}
 
-	if err != nil {
-		slog.Error("pipeline_finished: Error saving execution", "error", err)
-		// Should we raise pipeline fail here?
-		return nil
-	}
+	// cancel the child pipelines started by the pipeline steps of this pipeline
+	for _, childPex := range ex.PipelineExecutions {
+		if childPex.ParentExecutionID != evt.PipelineExecutionID || slices.Contains(event.EndEvents, childPex.Status) {
+			continue
+		}
 
-	pipelineCompletionHandler(evt.Event.ExecutionID, evt.PipelineExecutionID, pipelineDefn, ex.PipelineExecutions[evt.PipelineExecutionID].StepExecutions)
+		slog.Info("Canceling child pipeline execution", "execution_id", evt.Event.ExecutionID, "pipeline_execution_id", childPex.ID, "parent_execution_id", evt.PipelineExecutionID)
+		err = h.CommandBus.Send(ctx, event.PipelineCancelFromPipelineCanceled(evt, childPex.ID))
+		if err != nil {
+			slog.Error("Error publishing event", "error", err)
+		}
+	}
 
-	// raise execution plan command if this pipeline is in the root pipeline list
 	if slices.Contains(ex.RootPipelines, evt.PipelineExecutionID) {
-		cmd := event.ExecutionPlanFromPipelineCancelled(evt)
-		err = h.CommandBus.Send(ctx, cmd)
+		pipelineCompletionHandler(evt.Event.ExecutionID, evt.PipelineExecutionID, pipelineDefn, ex.PipelineExecutions[evt.PipelineExecutionID].StepExecutions)
+	} else {
+		// child pipelines only release their semaphores, the execution is completed by the root pipeline
+		execution.CompletePipelineExecutionStepSemaphore(evt.PipelineExecutionID)
+		err = execution.ReleasePipelineSemaphore(pipelineDefn)
 		if err != nil {
-			slog.Error("Error publishing event", "error", err)
+			slog.Error("Releasing pipeline semaphore", "error", err)
 		}
 	}
+
+	// raise execution plan command once every pipeline in the execution (including the child pipelines that we
+	// have just canceled) has stopped
+	for _, pex := range ex.PipelineExecutions {
+		if !slices.Contains(event.EndEvents, pex.Status) {
+			return nil
+		}
+	}
+
+	cmd := event.ExecutionPlanFromPipelineCancelled(evt)
+	err = h.CommandBus.Send(ctx, cmd)
+	if err != nil {
+		slog.Error("Error publishing event", "error", err)
+	}
+
 	return nil
 }
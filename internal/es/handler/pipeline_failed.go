@@ -30,11 +30,6 @@ func (h PipelineFailed) Handle(ctx context.Context, ei interface{}) error {
 
 	slog.Debug("pipeline_failed handler", "event", evt)
 
-	err := store.UpdatePipelineState(evt.Event.ExecutionID, "failed")
-	if err != nil {
-		slog.Error("pipeline_failed: Error updating pipeline state", "error", err)
-	}
-
 	plannerMutex := event.GetEventStoreMutex(evt.Event.ExecutionID)
 	plannerMutex.Lock()
 	defer func() {
@@ -47,6 +42,14 @@ func (h PipelineFailed) Handle(ctx context.Context, ei interface{}) error {
 		return err
 	}
 
+	// the pipeline_run state tracks the execution as a whole, child pipelines don't change it
+	if slices.Contains(ex.RootPipelines, evt.PipelineExecutionID) {
+		err = store.UpdatePipelineState(evt.Event.ExecutionID, "failed")
+		if err != nil {
+			slog.Error("pipeline_failed: Error updating pipeline state", "error", err)
+		}
+	}
+
 	parentStepExecution, err := ex.ParentStepExecution(evt.PipelineExecutionID)
 	if err != nil {
 		// We're already in a pipeline failed event handler
@@ -37,11 +37,6 @@ func (h PipelineFinished) Handle(ctx context.Context, ei interface{}) error {
 
 	slog.Debug("pipeline_finished event handler", "executionID", evt.Event.ExecutionID, "pipelineExecutionID", evt.PipelineExecutionID)
 
-	err := store.UpdatePipelineState(evt.Event.ExecutionID, "finished")
-	if err != nil {
-		slog.Error("pipeline_finished: Error updating pipeline state", "error", err)
-	}
-
 	plannerMutex := event.GetEventStoreMutex(evt.Event.ExecutionID)
 	plannerMutex.Lock()
 	defer func() {
@@ -58,6 +53,14 @@ func (h PipelineFinished) Handle(ctx context.Context, ei interface{}) error {
 		return nil
 	}
 
+	// the pipeline_run state tracks the execution as a whole, child pipelines don't change it
+	if slices.Contains(ex.RootPipelines, evt.PipelineExecutionID) {
+		err = store.UpdatePipelineState(evt.Event.ExecutionID, "finished")
+		if err != nil {
+			slog.Error("pipeline_finished: Error updating pipeline state", "error", err)
+		}
+	}
+
 	parentStepExecution, err := ex.ParentStepExecution(evt.PipelineExecutionID)
 	if err != nil {
 		err2 := h.CommandBus.Send(ctx, event.NewPipelineFail(event.ForPipelineFinishedToPipelineFail(evt, err)))
@@ -8,6 +8,7 @@ import (
 
 	"github.com/turbot/flowpipe/internal/es/event"
 	"github.com/turbot/flowpipe/internal/es/execution"
+	"github.com/turbot/flowpipe/internal/store"
 	"github.com/turbot/pipe-fittings/perr"
 )
 
@@ -46,8 +47,38 @@ func (h PipelinePaused) Handle(ctx context.Context, ei interface{}) error {
 		return nil
 	}
 
-	// raise execution plan command if this pipeline is in the root pipeline list
+	// the pipeline_run state tracks the execution as a whole, child pipelines don't change it
 	if slices.Contains(ex.RootPipelines, evt.PipelineExecutionID) {
+		err = store.UpdatePipelineState(evt.Event.ExecutionID, "paused")
+		if err != nil {
+			slog.Error("pipeline_paused: Error updating pipeline state", "error", err)
+		}
+	}
+
+	// pause the child pipelines started by the pipeline steps of this pipeline, steps that are already running are
+	// left to finish
+	for _, childPex := range ex.PipelineExecutions {
+		if childPex.ParentExecutionID != evt.PipelineExecutionID || (childPex.Status != "started" && childPex.Status != "queued") {
+			continue
+		}
+
+		slog.Info("Pausing child pipeline execution", "execution_id", evt.Event.ExecutionID, "pipeline_execution_id", childPex.ID, "parent_execution_id", evt.PipelineExecutionID)
+		err = h.CommandBus.Send(ctx, event.PipelinePauseFromPipelinePaused(evt, childPex.ID))
+		if err != nil {
+			slog.Error("Error publishing event", "error", err)
+		}
+	}
+
+	// raise execution plan command once every pipeline in the execution is paused (or has already completed)
+	allPaused := true
+	for _, p := range ex.PipelineExecutions {
+		if !p.IsPaused() && !slices.Contains(event.EndEvents, p.Status) {
+			allPaused = false
+			break
+		}
+	}
+
+	if allPaused {
 		cmd := event.ExecutionPlanFromPipelinePaused(evt)
 		err = h.CommandBus.Send(ctx, cmd)
 		if err != nil {
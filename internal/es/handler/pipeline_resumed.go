@@ -7,6 +7,7 @@ import (
 
 	"github.com/turbot/flowpipe/internal/es/event"
 	"github.com/turbot/flowpipe/internal/es/execution"
+	"github.com/turbot/flowpipe/internal/store"
 	"github.com/turbot/pipe-fittings/perr"
 )
 
@@ -27,6 +28,11 @@ func (h PipelineResumed) Handle(ctx context.Context, ei interface{}) error {
 		return perr.BadRequestWithMessage("invalid event type expected *event.PipelineResumed")
 	}
 
+	err := store.UpdatePipelineState(evt.Event.ExecutionID, "started")
+	if err != nil {
+		slog.Error("pipeline_resumed: Error updating pipeline state", "error", err)
+	}
+
 	plannerMutex := event.GetEventStoreMutex(evt.Event.ExecutionID)
 	plannerMutex.Lock()
 	defer func() {
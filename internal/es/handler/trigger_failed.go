@@ -4,8 +4,10 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/turbot/flowpipe/internal/constants"
 	"github.com/turbot/flowpipe/internal/es/event"
 	"github.com/turbot/flowpipe/internal/es/execution"
+	"github.com/turbot/flowpipe/internal/store"
 	"github.com/turbot/pipe-fittings/perr"
 )
 
@@ -37,8 +39,13 @@ func (h TriggerFailed) Handle(ctx context.Context, ei interface{}) error {
 
 	// There's only 1 trigger for each execution, it's a straight forward process to
 	// fail the execution
+	err := store.UpdatePipelineState(evt.Event.ExecutionID, constants.StateFailed)
+	if err != nil {
+		slog.Error("trigger_failed: Error updating pipeline state", "error", err)
+	}
+
 	cmd := event.ExecutionFailFromTriggerFailed(evt)
-	err := h.CommandBus.Send(ctx, cmd)
+	err = h.CommandBus.Send(ctx, cmd)
 	if err != nil {
 		slog.Error("Error publishing event", "error", err)
 		return nil
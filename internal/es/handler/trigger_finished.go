@@ -2,9 +2,12 @@ package handler
 
 import (
 	"context"
+	"log/slog"
 
+	"github.com/turbot/flowpipe/internal/constants"
 	"github.com/turbot/flowpipe/internal/es/event"
 	"github.com/turbot/flowpipe/internal/es/execution"
+	"github.com/turbot/flowpipe/internal/store"
 	"github.com/turbot/pipe-fittings/perr"
 )
 
@@ -34,8 +37,14 @@ func (h TriggerFinished) Handle(ctx context.Context, ei interface{}) error {
 		}
 	}()
 
+	// a trigger run that queued no pipeline only finishes here
+	err := store.UpdatePipelineState(evt.Event.ExecutionID, constants.StateFinished)
+	if err != nil {
+		slog.Error("trigger_finished: Error updating pipeline state", "error", err)
+	}
+
 	cmd := event.ExecutionFinishFromTriggerFinished(evt)
-	err := h.CommandBus.Send(ctx, cmd)
+	err = h.CommandBus.Send(ctx, cmd)
 	if err != nil {
 		return nil
 	}
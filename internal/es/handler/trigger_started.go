@@ -5,10 +5,12 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/turbot/flowpipe/internal/constants"
 	"github.com/turbot/flowpipe/internal/es/db"
 	"github.com/turbot/flowpipe/internal/es/event"
 	"github.com/turbot/flowpipe/internal/es/execution"
 	"github.com/turbot/flowpipe/internal/output"
+	"github.com/turbot/flowpipe/internal/store"
 	"github.com/turbot/flowpipe/internal/trigger"
 	"github.com/turbot/flowpipe/internal/types"
 	"github.com/turbot/pipe-fittings/perr"
@@ -100,6 +102,13 @@ func (h TriggerStarted) Handle(ctx context.Context, ei interface{}) error {
 
 			fperr := perr.InternalWithMessage("error sending pipeline command " + err.Error())
 			h.raiseError(ctx, evt, fperr)
+			continue
+		}
+
+		if notifier, ok := triggerRunner.(trigger.PipelineQueuedNotifier); ok {
+			if err := notifier.PipelineQueued(cmd); err != nil {
+				slog.Error("Error recording queued pipeline", "trigger", trg.Name(), "pipeline_execution_id", cmd.PipelineExecutionID, "error", err)
+			}
 		}
 	}
 
@@ -107,8 +116,13 @@ func (h TriggerStarted) Handle(ctx context.Context, ei interface{}) error {
 }
 
 func (h TriggerStarted) raiseError(ctx context.Context, evt *event.TriggerStarted, errToLog perr.ErrorModel) {
+	err := store.UpdatePipelineState(evt.Event.ExecutionID, constants.StateFailed)
+	if err != nil {
+		slog.Error("Error updating pipeline state", "error", err)
+	}
+
 	cmd := event.ExecutionFailFromTriggerStarted(evt, errToLog)
-	err := h.CommandBus.Send(ctx, cmd)
+	err = h.CommandBus.Send(ctx, cmd)
 	if err != nil {
 		slog.Error("Error publishing event", "error", err)
 	}
@@ -59,6 +59,12 @@ func FlowpipeDBFileName() string {
 	return dbPath
 }
 
+// EventBusDBFileName is the database used by the durable (sqlite) event bus to hold queued commands and events. It
+// lives next to flowpipe.db but is kept separate so the event bus writes don't contend with the process store.
+func EventBusDBFileName() string {
+	return filepath.Join(filepath.Dir(FlowpipeDBFileName()), "flowpipe-event-bus.db")
+}
+
 func GlobalInternalDir() string {
 	return path.Join(app_specific.InstallDir, "internal")
 }
@@ -66,3 +72,15 @@ func GlobalInternalDir() string {
 func EventStoreFilePath(executionId string) string {
 	return path.Join(EventStoreDir(), fmt.Sprintf("%s.jsonl", executionId))
 }
+
+// ProcessArchiveDir holds the event logs of the processes archived by the retention policies before being deleted
+// from the process store
+func ProcessArchiveDir() string {
+	return filepath.Join(filepath.Dir(FlowpipeDBFileName()), "archive")
+}
+
+// HttpResponseDir holds the response bodies the http steps of a process wrote to files, in a directory per step
+// execution
+func HttpResponseDir(executionId string) string {
+	return filepath.Join(filepath.Dir(FlowpipeDBFileName()), "http", executionId)
+}
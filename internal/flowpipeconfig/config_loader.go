@@ -9,6 +9,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/turbot/flowpipe/internal/constants"
 	fpparse "github.com/turbot/flowpipe/internal/parse"
 	"github.com/turbot/flowpipe/internal/resources"
 	filehelpers "github.com/turbot/go-kit/files"
@@ -416,6 +417,21 @@ func (f *FlowpipeConfig) loadFlowpipeConfigBlocks(configPath string, opts *loadC
 			}
 
 			f.ConnectionImports[connectionImport.GetUnqualifiedName()] = *connectionImport
+
+		case schema.BlockTypeOptions:
+			// the config paths are loaded in increasing precedence, the last retention options loaded win
+			if len(block.Labels) == 0 || block.Labels[0] != constants.OptionsTypeRetention {
+				continue
+			}
+
+			retention, moreDiags := decodeRetentionOptions(block)
+			if len(moreDiags) > 0 {
+				diags = append(diags, moreDiags...)
+				slog.Debug("failed to decode retention options block")
+				continue
+			}
+
+			f.Retention = retention
 		}
 	}
 
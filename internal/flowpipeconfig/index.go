@@ -3,6 +3,7 @@ package flowpipeconfig
 import (
 	"context"
 	"log/slog"
+	"reflect"
 	"sync"
 
 	"github.com/fsnotify/fsnotify"
@@ -26,6 +27,7 @@ type FlowpipeConfig struct {
 	Notifiers           map[string]resources.Notifier
 	ConnectionImports   map[string]modconfig.ConnectionImport
 	PipelingConnections map[string]connection.PipelingConnection
+	Retention           resources.RetentionPolicies
 
 	watcher                 *filewatcher.FileWatcher
 	fileWatcherErrorHandler func(context.Context, error)
@@ -47,6 +49,7 @@ func (f *FlowpipeConfig) updateResources(other *FlowpipeConfig) {
 	f.Notifiers = other.Notifiers
 	f.PipelingConnections = other.PipelingConnections
 	f.ConnectionImports = other.ConnectionImports
+	f.Retention = other.Retention
 
 }
 
@@ -133,7 +136,7 @@ func (f *FlowpipeConfig) Equals(other *FlowpipeConfig) bool {
 		}
 	}
 
-	return true
+	return reflect.DeepEqual(f.Retention, other.Retention)
 }
 
 func (f *FlowpipeConfig) SetupWatcher(ctx context.Context, errorHandler func(context.Context, error)) error {
@@ -0,0 +1,156 @@
+package flowpipeconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/turbot/flowpipe/internal/resources"
+)
+
+// retentionRule is a rule block of the retention options:
+//
+//	options "retention" {
+//	  max_age        = "7d"
+//	  failed_max_age = "30d"
+//	  orphan_max_age = "1d"
+//	  keep_last      = 10
+//	  archive        = true
+//
+//	  rule {
+//	    pipeline  = "mymod.pipeline.nightly_*"
+//	    keep_last = 100
+//	    max_age   = "90d"
+//	  }
+//	}
+type retentionRule struct {
+	Pipeline     *string `hcl:"pipeline,optional"`
+	Trigger      *string `hcl:"trigger,optional"`
+	KeepLast     *int    `hcl:"keep_last,optional"`
+	MaxAge       *string `hcl:"max_age,optional"`
+	FailedMaxAge *string `hcl:"failed_max_age,optional"`
+	OrphanMaxAge *string `hcl:"orphan_max_age,optional"`
+	Archive      *bool   `hcl:"archive,optional"`
+}
+
+type retentionOptions struct {
+	KeepLast     *int            `hcl:"keep_last,optional"`
+	MaxAge       *string         `hcl:"max_age,optional"`
+	FailedMaxAge *string         `hcl:"failed_max_age,optional"`
+	OrphanMaxAge *string         `hcl:"orphan_max_age,optional"`
+	Archive      *bool           `hcl:"archive,optional"`
+	Rules        []retentionRule `hcl:"rule,block"`
+}
+
+func decodeRetentionOptions(block *hcl.Block) (resources.RetentionPolicies, hcl.Diagnostics) {
+	var options retentionOptions
+	diags := gohcl.DecodeBody(block.Body, nil, &options)
+	if diags.HasErrors() {
+		return resources.RetentionPolicies{}, diags
+	}
+
+	policies := resources.RetentionPolicies{}
+	policies.Default, diags = retentionRule{
+		KeepLast:     options.KeepLast,
+		MaxAge:       options.MaxAge,
+		FailedMaxAge: options.FailedMaxAge,
+		OrphanMaxAge: options.OrphanMaxAge,
+		Archive:      options.Archive,
+	}.policy(block)
+	if diags.HasErrors() {
+		return resources.RetentionPolicies{}, diags
+	}
+
+	for _, rule := range options.Rules {
+		if rule.Pipeline == nil && rule.Trigger == nil {
+			return resources.RetentionPolicies{}, hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  "invalid retention rule",
+				Detail:   "a retention rule must set pipeline or trigger",
+				Subject:  &block.DefRange,
+			}}
+		}
+
+		policy, diags := rule.policy(block)
+		if diags.HasErrors() {
+			return resources.RetentionPolicies{}, diags
+		}
+		policies.Rules = append(policies.Rules, policy)
+	}
+
+	return policies, nil
+}
+
+func (r retentionRule) policy(block *hcl.Block) (resources.RetentionPolicy, hcl.Diagnostics) {
+	policy := resources.RetentionPolicy{
+		KeepLast: r.KeepLast,
+		Archive:  r.Archive,
+	}
+
+	if r.Pipeline != nil {
+		policy.Pipeline = *r.Pipeline
+	}
+	if r.Trigger != nil {
+		policy.Trigger = *r.Trigger
+	}
+
+	if r.KeepLast != nil && *r.KeepLast < 0 {
+		return policy, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "invalid retention keep_last",
+			Detail:   "keep_last must be positive",
+			Subject:  &block.DefRange,
+		}}
+	}
+
+	for _, d := range []struct {
+		name  string
+		value *string
+		dest  **time.Duration
+	}{
+		{"max_age", r.MaxAge, &policy.MaxAge},
+		{"failed_max_age", r.FailedMaxAge, &policy.FailedMaxAge},
+		{"orphan_max_age", r.OrphanMaxAge, &policy.OrphanMaxAge},
+	} {
+		if d.value == nil {
+			continue
+		}
+
+		duration, err := parseRetentionDuration(*d.value)
+		if err != nil {
+			return policy, hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  "invalid retention " + d.name,
+				Detail:   err.Error(),
+				Subject:  &block.DefRange,
+			}}
+		}
+		*d.dest = &duration
+	}
+
+	return policy, nil
+}
+
+// parseRetentionDuration parses a Go duration or a number of days (e.g. 30d), -1 keeps the runs forever
+func parseRetentionDuration(value string) (time.Duration, error) {
+	if value == "-1" {
+		return -1, nil
+	}
+
+	if days, found := strings.CutSuffix(value, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %s, expected a duration such as 24h or 7d", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid duration %s, expected a duration such as 24h or 7d", value)
+	}
+	return duration, nil
+}
@@ -447,8 +447,17 @@ func (fn *Function) StartIfNotStarted(imageName string) (string, error) {
 }
 
 func (fn *Function) Invoke(input []byte) (int, []byte, error) {
+	return fn.InvokeWithContext(fn.runCtx, input)
+}
+
+// InvokeWithContext invokes the function, abandoning the request if ctx is canceled before the function responds.
+func (fn *Function) InvokeWithContext(ctx context.Context, input []byte) (int, []byte, error) {
 	output := []byte{}
 
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	// Ensure the function has been started
 	_, err := fn.StartIfNotStarted(fn.CurrentVersionName)
 	if err != nil {
@@ -461,7 +470,14 @@ func (fn *Function) Invoke(input []byte) (int, []byte, error) {
 	slog.Debug("Executing Lambda function", "LambdaEndpoint", v.LambdaEndpoint(), "CurrentVersionName", fn.CurrentVersionName)
 
 	// Invoke the Lambda function
-	resp, err := http.Post(v.LambdaEndpoint(), "application/json", bytes.NewReader(input))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.LambdaEndpoint(), bytes.NewReader(input))
+	if err != nil {
+		slog.Error("Error creating Lambda function request", "error", err)
+		return 0, output, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		slog.Error("Error invoking Lambda function", "error", err)
 		return 0, output, err
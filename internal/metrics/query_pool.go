@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"time"
+)
+
+// QueryPool is the state of the connection pool of a database queried by the query steps and triggers
+type QueryPool struct {
+	// The connection string of the database, without the password
+	Database            string    `json:"database"`
+	MaxOpenConnections  int       `json:"max_open_connections"`
+	OpenConnections     int       `json:"open_connections"`
+	InUse               int       `json:"in_use"`
+	Idle                int       `json:"idle"`
+	WaitCount           int64     `json:"wait_count"`
+	WaitDurationMs      int64     `json:"wait_duration_ms"`
+	MaxIdleClosed       int64     `json:"max_idle_closed"`
+	MaxIdleTimeClosed   int64     `json:"max_idle_time_closed"`
+	HealthCheckFailures int64     `json:"health_check_failures"`
+	Queries             int64     `json:"queries"`
+	LastUsed            time.Time `json:"last_used"`
+}
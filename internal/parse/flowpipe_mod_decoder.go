@@ -874,6 +874,10 @@ func GetTriggerBlockSchema(triggerType string) *hcl.BodySchema {
 		return resources.TriggerQueryBlockSchema
 	case schema.TriggerTypeHttp:
 		return resources.TriggerHttpBlockSchema
+	case resources.TriggerTypeQueue:
+		return resources.TriggerQueueBlockSchema
+	case resources.TriggerTypeFile:
+		return resources.TriggerFileBlockSchema
 	default:
 		return nil
 	}
@@ -318,7 +318,7 @@ func (cp *Container) Run(ctx context.Context, input resources.Input) (*resources
 		Data: map[string]interface{}{},
 	}
 
-	containerID, exitCode, err := c.Run(cConfig)
+	containerID, exitCode, err := c.RunWithContext(ctx, cConfig)
 	if err != nil {
 		if e, ok := err.(perr.ErrorModel); !ok {
 			output.Errors = []resources.StepError{
@@ -152,7 +152,7 @@ func (e *Function) Run(ctx context.Context, input resources.Input) (*resources.O
 		body = string(jsonString)
 	}
 
-	statusCode, result, err := fn.Invoke([]byte(body))
+	statusCode, result, err := fn.InvokeWithContext(ctx, []byte(body))
 	if err != nil {
 		return nil, err
 	}
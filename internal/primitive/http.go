@@ -1,7 +1,6 @@
 package primitive
 
 import (
-	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
@@ -23,6 +22,12 @@ import (
 
 type HTTPRequest struct {
 	Input resources.Input
+
+	// The relative paths of the request files are resolved against the mod directory, and the response files are
+	// written to a directory of the step execution
+	ModPath         string
+	ExecutionID     string
+	StepExecutionID string
 }
 
 type HTTPInput struct {
@@ -33,6 +38,14 @@ type HTTPInput struct {
 	CaCertPem      string
 	Insecure       bool
 	Timeout        time.Duration
+	Pagination     *HTTPPagination
+	OAuth2         *HTTPOAuth2
+
+	RequestBodyFile string
+	FormFields      map[string]string
+	FormFiles       map[string]string
+	// The response body is written to a file in the directory if set
+	ResponseFileDir string
 }
 
 func (h *HTTPRequest) ValidateInput(ctx context.Context, i resources.Input) error {
@@ -70,6 +83,10 @@ func (h *HTTPRequest) ValidateInput(ctx context.Context, i resources.Input) erro
 			stepName := i[schema.AttributeTypeStepName].(string)
 			return perr.BadRequestWithMessage("step " + stepName + " should have either basic_auth or authorization header but not both")
 		}
+		if requestHeaders["Authorization"] != nil && i[resources.BlockTypeOAuth2] != nil {
+			stepName := i[schema.AttributeTypeStepName].(string)
+			return perr.BadRequestWithMessage("step " + stepName + " should have either oauth2 or authorization header but not both")
+		}
 	}
 
 	if i[schema.AttributeTypeTimeout] != nil {
@@ -111,6 +128,15 @@ func (h *HTTPRequest) Run(ctx context.Context, input resources.Input) (*resource
 		return nil, err
 	}
 
+	err = h.buildHTTPFileInput(input, httpInput)
+	if err != nil {
+		return nil, err
+	}
+
+	if httpInput.Pagination != nil {
+		return doPaginatedRequest(ctx, httpInput)
+	}
+
 	// Make the HTTP request
 	output, err := doRequest(ctx, httpInput)
 	if err != nil {
@@ -122,17 +148,7 @@ func (h *HTTPRequest) Run(ctx context.Context, input resources.Input) (*resource
 
 // doRequest performs the HTTP request based on the inputs provided and returns the output
 func doRequest(ctx context.Context, inputParams *HTTPInput) (*resources.Output, error) {
-	// Create the HTTP request
 	client := &http.Client{}
-	req, err := http.NewRequest(strings.ToUpper(inputParams.Method), inputParams.URL, bytes.NewBuffer([]byte(inputParams.RequestBody)))
-	if err != nil {
-		return nil, perr.BadRequestWithMessage("Error creating request: " + err.Error())
-	}
-
-	// Set the request headers
-	for k, v := range inputParams.RequestHeaders {
-		req.Header.Set(k, v.(string))
-	}
 
 	// Initialize the TLSClientConfig with default settings
 	tlsConfig := &tls.Config{} // #nosec G402
@@ -162,7 +178,12 @@ func doRequest(ctx context.Context, inputParams *HTTPInput) (*resources.Output,
 	}
 
 	start := time.Now().UTC()
-	resp, err := client.Do(req)
+	resp, token, err := sendRequest(ctx, client, inputParams, nil)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized && token != nil {
+		// the access token may have been revoked before it expired, retry once with a new token
+		resp.Body.Close()
+		resp, _, err = sendRequest(ctx, client, inputParams, token)
+	}
 	finish := time.Now().UTC()
 	if err != nil {
 		return nil, err
@@ -173,11 +194,6 @@ func doRequest(ctx context.Context, inputParams *HTTPInput) (*resources.Output,
 		}
 	}()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
 	// Golang Response.Header is a map[string][]string, which is accurate
 	// but complicated for users. We map it to a simpler key-value pair
 	// approach.
@@ -193,6 +209,28 @@ func doRequest(ctx context.Context, inputParams *HTTPInput) (*resources.Output,
 
 	output.Flowpipe = FlowpipeMetadataOutput(start, finish)
 
+	if resp.StatusCode >= 400 {
+		output.Errors = []resources.StepError{
+			{
+				Error: perr.FromHttpError(errors.New(resp.Status), resp.StatusCode),
+			},
+		}
+	}
+
+	if inputParams.ResponseFileDir != "" {
+		responseFile, err := writeResponseFile(resp, inputParams.ResponseFileDir)
+		if err != nil {
+			return nil, err
+		}
+		output.Data[AttributeTypeResponseFile] = responseFile
+		return &output, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
 	var bodyString string
 
 	if body != nil {
@@ -212,14 +250,6 @@ func doRequest(ctx context.Context, inputParams *HTTPInput) (*resources.Output,
 		output.Data[schema.AttributeTypeResponseBody] = bodyString
 	}
 
-	if resp.StatusCode >= 400 {
-		output.Errors = []resources.StepError{
-			{
-				Error: perr.FromHttpError(errors.New(resp.Status), resp.StatusCode),
-			},
-		}
-	}
-
 	return &output, nil
 }
 
@@ -308,6 +338,22 @@ func buildHTTPInput(input resources.Input) (*HTTPInput, error) {
 
 	inputParams.RequestHeaders = requestHeaders
 
+	if pagination, ok := input[resources.BlockTypePagination].(map[string]interface{}); ok {
+		var err error
+		inputParams.Pagination, err = buildHTTPPagination(pagination)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if oauth2, ok := input[resources.BlockTypeOAuth2].(map[string]interface{}); ok {
+		var err error
+		inputParams.OAuth2, err = buildHTTPOAuth2(oauth2)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if input[schema.AttributeTypeTimeout] != nil {
 		var timeout time.Duration
 		switch timeoutDuration := input[schema.AttributeTypeTimeout].(type) {
@@ -324,6 +370,45 @@ func buildHTTPInput(input resources.Input) (*HTTPInput, error) {
 	return inputParams, nil
 }
 
+// sendRequest creates and sends the HTTP request. With oauth2, the request is authenticated with the access token of
+// the credential, a new one if the rejected token was refused, and the token is returned.
+func sendRequest(ctx context.Context, client *http.Client, inputParams *HTTPInput, rejected *oauth2Token) (*http.Response, *oauth2Token, error) {
+	body, contentLength, contentType, err := requestBody(inputParams)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create the HTTP request
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(inputParams.Method), inputParams.URL, body)
+	if err != nil {
+		if closer, ok := body.(io.Closer); ok {
+			closer.Close()
+		}
+		return nil, nil, perr.BadRequestWithMessage("Error creating request: " + err.Error())
+	}
+	req.ContentLength = contentLength
+
+	// Set the request headers
+	for k, v := range inputParams.RequestHeaders {
+		req.Header.Set(k, v.(string))
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	var token *oauth2Token
+	if inputParams.OAuth2 != nil {
+		token, err = oauth2Tokens.token(ctx, client, inputParams.OAuth2, rejected)
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token.accessToken)
+	}
+
+	resp, err := client.Do(req)
+	return resp, token, err
+}
+
 // mapResponseHeaders maps the response headers to a simpler key-value pair
 func mapResponseHeaders(resp *http.Response) map[string]interface{} {
 	headers := map[string]interface{}{}
@@ -0,0 +1,238 @@
+package primitive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"maps"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/turbot/flowpipe/internal/filepaths"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/flowpipe/internal/util"
+	"github.com/turbot/pipe-fittings/perr"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+const (
+	// AttributeTypeResponseFile is the output of an http step with response_to_file, the path, size and checksum of the
+	// file the response body was written to
+	AttributeTypeResponseFile = "response_file"
+
+	AttributeTypePath   = "path"
+	AttributeTypeSize   = "size"
+	AttributeTypeSha256 = "sha256"
+
+	defaultResponseFileName = "response_body"
+)
+
+// responseFileDir is the directory of the response file of the step execution
+func (h *HTTPRequest) responseFileDir() string {
+	executionID, stepExecutionID := h.ExecutionID, h.StepExecutionID
+
+	// the primitive run outside of a pipeline gets a directory of its own
+	if executionID == "" {
+		executionID = util.NewExecutionId()
+	}
+	if stepExecutionID == "" {
+		stepExecutionID = util.NewStepExecutionId()
+	}
+	return filepath.Join(filepaths.HttpResponseDir(executionID), stepExecutionID)
+}
+
+// resolvePath resolves the relative paths of the request files against the mod directory
+func (h *HTTPRequest) resolvePath(p string) string {
+	if filepath.IsAbs(p) || h.ModPath == "" {
+		return p
+	}
+	return filepath.Join(h.ModPath, p)
+}
+
+// requestBody returns the body of the request, the body file or the multipart form are streamed rather than read in
+// memory. The content length is -1 if unknown, and the content type is set for the forms.
+func requestBody(inputParams *HTTPInput) (body io.Reader, contentLength int64, contentType string, err error) {
+	switch {
+	case inputParams.RequestBodyFile != "":
+		file, err := os.Open(inputParams.RequestBodyFile)
+		if err != nil {
+			return nil, 0, "", perr.BadRequestWithMessage("unable to open request_body_file: " + err.Error())
+		}
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, 0, "", perr.BadRequestWithMessage("unable to open request_body_file: " + err.Error())
+		}
+		return file, info.Size(), "", nil
+
+	case inputParams.FormFields != nil || inputParams.FormFiles != nil:
+		// check the files before the request is sent, the errors of the form writer can only abort the request
+		for _, fileName := range inputParams.FormFiles {
+			if _, err := os.Stat(fileName); err != nil {
+				return nil, 0, "", perr.BadRequestWithMessage("unable to open form file: " + err.Error())
+			}
+		}
+
+		reader, writer := io.Pipe()
+		form := multipart.NewWriter(writer)
+		go func() {
+			writer.CloseWithError(writeForm(form, inputParams.FormFields, inputParams.FormFiles))
+		}()
+		return reader, -1, form.FormDataContentType(), nil
+
+	default:
+		return strings.NewReader(inputParams.RequestBody), int64(len(inputParams.RequestBody)), "", nil
+	}
+}
+
+// writeForm writes the fields and the files of the multipart form, in the order of the field names
+func writeForm(form *multipart.Writer, fields, files map[string]string) error {
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		if err := form.WriteField(name, fields[name]); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		part, err := form.CreateFormFile(name, filepath.Base(files[name]))
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(files[name])
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(part, file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return form.Close()
+}
+
+var unsafeFileNameRegex = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// responseFileName is the last segment of the URL path, response_body if there's none
+func responseFileName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return defaultResponseFileName
+	}
+
+	name := unsafeFileNameRegex.ReplaceAllString(path.Base(u.Path), "_")
+	if strings.Trim(name, "._") == "" {
+		return defaultResponseFileName
+	}
+	return name
+}
+
+// writeResponseFile streams the response body to the file and returns its path, size and SHA-256 checksum
+func writeResponseFile(resp *http.Response, dir string) (map[string]any, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		slog.Error("error creating http response directory", "dir", dir, "error", err)
+		return nil, perr.InternalWithMessage("error creating http response directory")
+	}
+
+	filePath := filepath.Join(dir, responseFileName(resp.Request.URL.String()))
+	//nolint:gosec // the response is written under the data directory of the process
+	file, err := os.Create(filePath)
+	if err != nil {
+		slog.Error("error creating http response file", "path", filePath, "error", err)
+		return nil, perr.InternalWithMessage("error creating http response file")
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hash), resp.Body)
+	if err != nil {
+		return nil, perr.ExecutionErrorWithMessage("error reading http response body: " + err.Error())
+	}
+
+	err = file.Sync()
+	if err != nil {
+		slog.Error("error writing http response file", "path", filePath, "error", err)
+		return nil, perr.InternalWithMessage("error writing http response file")
+	}
+
+	return map[string]any{
+		AttributeTypePath:   filePath,
+		AttributeTypeSize:   size,
+		AttributeTypeSha256: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// buildHTTPFileInput sets the request and response files of the input
+func (h *HTTPRequest) buildHTTPFileInput(input resources.Input, inputParams *HTTPInput) error {
+	if responseToFile, ok := input[resources.AttributeTypeResponseToFile].(bool); ok && responseToFile {
+		if inputParams.Pagination != nil {
+			return perr.BadRequestWithMessage("the response can't be written to a file with pagination")
+		}
+		inputParams.ResponseFileDir = h.responseFileDir()
+	}
+
+	if requestBodyFile, ok := input[resources.AttributeTypeRequestBodyFile].(string); ok && requestBodyFile != "" {
+		inputParams.RequestBodyFile = h.resolvePath(requestBodyFile)
+	}
+
+	for name, target := range map[string]*map[string]string{
+		resources.AttributeTypeFormFields: &inputParams.FormFields,
+		resources.AttributeTypeFormFiles:  &inputParams.FormFiles,
+	} {
+		if input[name] == nil {
+			continue
+		}
+
+		form := map[string]string{}
+		switch values := input[name].(type) {
+		case map[string]string:
+			for k, v := range values {
+				form[k] = v
+			}
+		case map[string]any:
+			for k, v := range values {
+				s, ok := v.(string)
+				if !ok {
+					return perr.BadRequestWithMessage("the " + name + " values must be strings")
+				}
+				form[k] = s
+			}
+		default:
+			return perr.BadRequestWithMessage("the " + name + " attribute must be a map of strings")
+		}
+
+		if name == resources.AttributeTypeFormFiles {
+			for k, v := range form {
+				form[k] = h.resolvePath(v)
+			}
+		}
+		*target = form
+	}
+
+	bodies := 0
+	for _, set := range []bool{
+		input[schema.AttributeTypeRequestBody] != nil,
+		inputParams.RequestBodyFile != "",
+		inputParams.FormFields != nil || inputParams.FormFiles != nil,
+	} {
+		if set {
+			bodies++
+		}
+	}
+	if bodies > 1 {
+		return perr.BadRequestWithMessage("the http step should have only one of request_body, request_body_file or form_fields and form_files")
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package primitive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+func TestHTTPResponseToFile(t *testing.T) {
+	assert := assert.New(t)
+
+	dataDir := t.TempDir()
+	viper.Set(constants.ArgDataDir, dataDir)
+	defer viper.Set(constants.ArgDataDir, "")
+
+	content := strings.Repeat("0123456789", 100000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = io.WriteString(w, content)
+	}))
+	defer server.Close()
+
+	h := &HTTPRequest{ExecutionID: "exec_1", StepExecutionID: "sexec_1"}
+	output, err := h.Run(context.Background(), resources.Input{
+		schema.AttributeTypeUrl:               server.URL + "/downloads/archive.tar.gz",
+		resources.AttributeTypeResponseToFile: true,
+	})
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	assert.Nil(output.Get(schema.AttributeTypeResponseBody))
+	assert.Equal(200, output.Get(schema.AttributeTypeStatusCode))
+
+	responseFile := output.Get(AttributeTypeResponseFile).(map[string]any)
+	assert.Equal(filepath.Join(dataDir, "http", "exec_1", "sexec_1", "archive.tar.gz"), responseFile[AttributeTypePath])
+	assert.Equal(int64(len(content)), responseFile[AttributeTypeSize])
+
+	hash := sha256.Sum256([]byte(content))
+	assert.Equal(hex.EncodeToString(hash[:]), responseFile[AttributeTypeSha256])
+
+	written, err := os.ReadFile(responseFile[AttributeTypePath].(string))
+	assert.Nil(err)
+	assert.Equal(content, string(written))
+
+	assert.Equal("response_body", responseFileName(server.URL+"/"))
+	assert.Equal("a_b.json", responseFileName(server.URL+"/files/a%20b.json?version=2"))
+}
+
+func TestHTTPRequestBodyFile(t *testing.T) {
+	assert := assert.New(t)
+
+	var received string
+	var contentLength int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		contentLength = r.ContentLength
+	}))
+	defer server.Close()
+
+	modPath := t.TempDir()
+	content := strings.Repeat("flowpipe", 1000)
+	assert.Nil(os.WriteFile(filepath.Join(modPath, "upload.bin"), []byte(content), 0600))
+
+	// the relative path is resolved against the mod directory
+	h := &HTTPRequest{ModPath: modPath}
+	_, err := h.Run(context.Background(), resources.Input{
+		schema.AttributeTypeUrl:                server.URL,
+		schema.AttributeTypeMethod:             "put",
+		resources.AttributeTypeRequestBodyFile: "upload.bin",
+	})
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal(content, received)
+	assert.Equal(int64(len(content)), contentLength)
+
+	_, err = h.Run(context.Background(), resources.Input{
+		schema.AttributeTypeUrl:                server.URL,
+		resources.AttributeTypeRequestBodyFile: "missing.bin",
+	})
+	assert.NotNil(err)
+
+	_, err = h.Run(context.Background(), resources.Input{
+		schema.AttributeTypeUrl:                server.URL,
+		schema.AttributeTypeRequestBody:        "body",
+		resources.AttributeTypeRequestBodyFile: "upload.bin",
+	})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "only one of request_body, request_body_file or form_fields and form_files")
+}
+
+func TestHTTPMultipartForm(t *testing.T) {
+	assert := assert.New(t)
+
+	fields := map[string]string{}
+	files := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for name, values := range r.MultipartForm.Value {
+			fields[name] = values[0]
+		}
+		for name, headers := range r.MultipartForm.File {
+			file, _ := headers[0].Open()
+			content, _ := io.ReadAll(file)
+			file.Close()
+			files[name] = headers[0].Filename + ":" + string(content)
+		}
+	}))
+	defer server.Close()
+
+	modPath := t.TempDir()
+	assert.Nil(os.WriteFile(filepath.Join(modPath, "report.csv"), []byte("a,b\n1,2\n"), 0600))
+
+	h := &HTTPRequest{ModPath: modPath}
+	output, err := h.Run(context.Background(), resources.Input{
+		schema.AttributeTypeUrl:    server.URL,
+		schema.AttributeTypeMethod: "post",
+		resources.AttributeTypeFormFields: map[string]any{
+			"title": "Monthly report",
+		},
+		resources.AttributeTypeFormFiles: map[string]any{
+			"attachment": "report.csv",
+		},
+	})
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal(200, output.Get(schema.AttributeTypeStatusCode))
+	assert.Equal(map[string]string{"title": "Monthly report"}, fields)
+	assert.Equal(map[string]string{"attachment": "report.csv:a,b\n1,2\n"}, files)
+
+	_, err = h.Run(context.Background(), resources.Input{
+		schema.AttributeTypeUrl: server.URL,
+		resources.AttributeTypeFormFiles: map[string]any{
+			"attachment": "missing.csv",
+		},
+	})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "unable to open form file")
+}
@@ -0,0 +1,168 @@
+package primitive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+// oauth2ExpiryDelta refreshes the access tokens a bit before they expire, so they don't expire in flight
+const oauth2ExpiryDelta = 30 * time.Second
+
+// HTTPOAuth2 is the OAuth 2.0 client credentials of an http step, see resources.HttpOAuth2Config
+type HTTPOAuth2 struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+func buildHTTPOAuth2(input map[string]any) (*HTTPOAuth2, error) {
+	oauth2 := &HTTPOAuth2{}
+	oauth2.TokenURL, _ = input[resources.AttributeTypeTokenUrl].(string)
+	oauth2.ClientID, _ = input[resources.AttributeTypeClientId].(string)
+	oauth2.ClientSecret, _ = input[resources.AttributeTypeClientSecret].(string)
+
+	switch scopes := input[resources.AttributeTypeScopes].(type) {
+	case []string:
+		oauth2.Scopes = scopes
+	case []any:
+		for _, scope := range scopes {
+			if s, ok := scope.(string); ok {
+				oauth2.Scopes = append(oauth2.Scopes, s)
+			}
+		}
+	}
+
+	if oauth2.TokenURL == "" || oauth2.ClientID == "" || oauth2.ClientSecret == "" {
+		return nil, perr.BadRequestWithMessage("the oauth2 block must define the " + resources.AttributeTypeTokenUrl + ", " + resources.AttributeTypeClientId + " and " + resources.AttributeTypeClientSecret + " attributes")
+	}
+	if _, err := url.ParseRequestURI(oauth2.TokenURL); err != nil {
+		return nil, perr.BadRequestWithMessage("invalid oauth2 token url: " + oauth2.TokenURL)
+	}
+
+	return oauth2, nil
+}
+
+// cacheKey identifies the credential, the client secret is hashed so it isn't kept in the clear in the cache keys
+func (o *HTTPOAuth2) cacheKey() string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{o.TokenURL, o.ClientID, o.ClientSecret, strings.Join(o.Scopes, " ")}, "\n")))
+	return hex.EncodeToString(hash[:])
+}
+
+type oauth2Token struct {
+	accessToken string
+	expiry      time.Time
+}
+
+func (t *oauth2Token) valid() bool {
+	return t != nil && (t.expiry.IsZero() || time.Now().Add(oauth2ExpiryDelta).Before(t.expiry))
+}
+
+type oauth2TokenEntry struct {
+	mutex sync.Mutex
+	token *oauth2Token
+}
+
+// oauth2TokenCache keeps the access tokens of the http steps, per credential, until they expire
+type oauth2TokenCache struct {
+	mutex   sync.Mutex
+	entries map[string]*oauth2TokenEntry
+}
+
+var oauth2Tokens = &oauth2TokenCache{entries: map[string]*oauth2TokenEntry{}}
+
+// token returns the cached access token of the credential, requesting a new one if there's none, it has expired or it
+// is the rejected token. The rejected token is the one the API answered 401 to, nil otherwise.
+func (c *oauth2TokenCache) token(ctx context.Context, client *http.Client, credential *HTTPOAuth2, rejected *oauth2Token) (*oauth2Token, error) {
+	key := credential.cacheKey()
+
+	c.mutex.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &oauth2TokenEntry{}
+		c.entries[key] = entry
+	}
+	c.mutex.Unlock()
+
+	// the steps using the same credential wait for a single token request
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+
+	if entry.token.valid() && entry.token != rejected {
+		return entry.token, nil
+	}
+
+	token, err := requestOAuth2Token(ctx, client, credential)
+	if err != nil {
+		entry.token = nil
+		return nil, err
+	}
+
+	entry.token = token
+	return token, nil
+}
+
+// requestOAuth2Token requests an access token with the client credentials grant, the client authenticates with HTTP
+// basic authentication as per RFC 6749
+func requestOAuth2Token(ctx context.Context, client *http.Client, credential *HTTPOAuth2) (*oauth2Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(credential.Scopes) > 0 {
+		form.Set("scope", strings.Join(credential.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, credential.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, perr.BadRequestWithMessage("Error creating oauth2 token request: " + err.Error())
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(credential.ClientID), url.QueryEscape(credential.ClientSecret))
+
+	slog.Debug("Requesting oauth2 access token", "token_url", credential.TokenURL, "client_id", credential.ClientID)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, perr.ExecutionErrorWithMessage("oauth2 token request failed: " + err.Error())
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, perr.ExecutionErrorWithMessage("oauth2 token request failed: " + err.Error())
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, perr.UnauthorizedWithMessage("oauth2 token request to " + credential.TokenURL + " failed with status " + resp.Status + ": " + string(body))
+	}
+
+	var tokenResponse struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresIn   json.Number `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tokenResponse); err != nil || tokenResponse.AccessToken == "" {
+		return nil, perr.UnauthorizedWithMessage("oauth2 token response of " + credential.TokenURL + " has no access_token")
+	}
+
+	token := &oauth2Token{accessToken: tokenResponse.AccessToken}
+
+	// without expires_in, the token is refreshed when the API rejects it
+	if expiresIn, err := strconv.ParseInt(tokenResponse.ExpiresIn.String(), 10, 64); err == nil && expiresIn > 0 {
+		token.expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+
+	return token, nil
+}
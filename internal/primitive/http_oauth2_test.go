@@ -0,0 +1,183 @@
+package primitive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+// mockOAuth2Server is a token endpoint issuing the tokens token-1, token-2... and an API accepting the tokens that
+// haven't been revoked
+type mockOAuth2Server struct {
+	*httptest.Server
+
+	mutex         sync.Mutex
+	expiresIn     int
+	tokenRequests int
+	scopes        []string
+	valid         map[string]bool
+}
+
+func newMockOAuth2Server(expiresIn int) *mockOAuth2Server {
+	s := &mockOAuth2Server{expiresIn: expiresIn, valid: map[string]bool{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientSecret != "secret" || r.FormValue("grant_type") != "client_credentials" {
+			http.Error(w, `{"error": "invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+
+		s.tokenRequests++
+		s.scopes = append(s.scopes, r.FormValue("scope"))
+		token := fmt.Sprintf("%s-token-%d", clientID, s.tokenRequests)
+		s.valid[token] = true
+
+		response := map[string]any{"access_token": token, "token_type": "bearer"}
+		if s.expiresIn > 0 {
+			response["expires_in"] = s.expiresIn
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	})
+	mux.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		token := r.Header.Get("Authorization")[len("Bearer "):]
+		if !s.valid[token] {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(token))
+	})
+
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+func (s *mockOAuth2Server) revokeAll() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.valid = map[string]bool{}
+}
+
+func (s *mockOAuth2Server) run(clientID, clientSecret string) (*resources.Output, error) {
+	return (&HTTPRequest{}).Run(context.Background(), resources.Input{
+		schema.AttributeTypeUrl: s.URL + "/api",
+		resources.BlockTypeOAuth2: map[string]any{
+			resources.AttributeTypeTokenUrl:     s.URL + "/oauth/token",
+			resources.AttributeTypeClientId:     clientID,
+			resources.AttributeTypeClientSecret: clientSecret,
+			resources.AttributeTypeScopes:       []any{"read", "write"},
+		},
+	})
+}
+
+func TestHTTPOAuth2TokenCache(t *testing.T) {
+	assert := assert.New(t)
+
+	server := newMockOAuth2Server(3600)
+	defer server.Close()
+
+	for i := 0; i < 3; i++ {
+		output, err := server.run("cache", "secret")
+		if err != nil {
+			assert.FailNow(err.Error())
+		}
+		assert.Equal(200, output.Get(schema.AttributeTypeStatusCode))
+		assert.Equal("cache-token-1", output.Get(schema.AttributeTypeResponseBody))
+	}
+
+	// the token is cached per credential
+	output, err := server.run("other", "secret")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal("other-token-2", output.Get(schema.AttributeTypeResponseBody))
+	assert.Equal(2, server.tokenRequests)
+	assert.Equal([]string{"read write", "read write"}, server.scopes)
+}
+
+func TestHTTPOAuth2TokenRefresh(t *testing.T) {
+	assert := assert.New(t)
+
+	// the tokens expire within the expiry delta, so they are requested by each run
+	server := newMockOAuth2Server(10)
+	defer server.Close()
+
+	for i := 1; i <= 2; i++ {
+		output, err := server.run("expiring", "secret")
+		if err != nil {
+			assert.FailNow(err.Error())
+		}
+		assert.Equal(fmt.Sprintf("expiring-token-%d", i), output.Get(schema.AttributeTypeResponseBody))
+	}
+
+	// without expires_in, the token is refreshed on a 401
+	server = newMockOAuth2Server(0)
+	defer server.Close()
+
+	output, err := server.run("revoked", "secret")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal("revoked-token-1", output.Get(schema.AttributeTypeResponseBody))
+
+	server.revokeAll()
+
+	output, err = server.run("revoked", "secret")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal(200, output.Get(schema.AttributeTypeStatusCode))
+	assert.Equal("revoked-token-2", output.Get(schema.AttributeTypeResponseBody))
+	assert.Equal(2, server.tokenRequests)
+}
+
+func TestHTTPOAuth2Errors(t *testing.T) {
+	assert := assert.New(t)
+
+	server := newMockOAuth2Server(3600)
+	defer server.Close()
+
+	_, err := server.run("invalid", "wrong secret")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "oauth2 token request to "+server.URL+"/oauth/token failed with status 401")
+
+	_, err = (&HTTPRequest{}).Run(context.Background(), resources.Input{
+		schema.AttributeTypeUrl: server.URL + "/api",
+		resources.BlockTypeOAuth2: map[string]any{
+			resources.AttributeTypeTokenUrl: server.URL + "/oauth/token",
+			resources.AttributeTypeClientId: "missing",
+		},
+	})
+	assert.NotNil(err)
+
+	_, err = (&HTTPRequest{}).Run(context.Background(), resources.Input{
+		schema.AttributeTypeUrl:      server.URL + "/api",
+		schema.AttributeTypeStepName: "http.oauth2",
+		schema.AttributeTypeRequestHeaders: map[string]any{
+			"Authorization": "Bearer token",
+		},
+		resources.BlockTypeOAuth2: map[string]any{
+			resources.AttributeTypeTokenUrl:     server.URL + "/oauth/token",
+			resources.AttributeTypeClientId:     "both",
+			resources.AttributeTypeClientSecret: "secret",
+		},
+	})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "should have either oauth2 or authorization header but not both")
+}
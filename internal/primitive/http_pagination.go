@@ -0,0 +1,287 @@
+package primitive
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/pipe-fittings/perr"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+// AttributeTypePages is the output of a paginated http step with the url and status of each page
+const AttributeTypePages = "pages"
+
+// HTTPPagination is the pagination of an http step, see resources.HttpPaginationConfig
+type HTTPPagination struct {
+	Type        string
+	Cursor      string
+	CursorParam string
+	PageParam   string
+	LimitParam  string
+	PageSize    int
+	Items       string
+	MaxPages    int
+}
+
+func buildHTTPPagination(input map[string]any) (*HTTPPagination, error) {
+	pagination := &HTTPPagination{
+		CursorParam: "cursor",
+		MaxPages:    resources.DefaultHttpMaxPages,
+	}
+
+	stringValue := func(name string) string {
+		s, _ := input[name].(string)
+		return s
+	}
+
+	pagination.Type = stringValue(schema.AttributeTypeType)
+	pagination.Cursor = stringValue(resources.AttributeTypeCursor)
+	pagination.Items = stringValue(resources.AttributeTypeItems)
+	pagination.LimitParam = stringValue(resources.AttributeTypeLimitParam)
+	if s := stringValue(resources.AttributeTypeCursorParam); s != "" {
+		pagination.CursorParam = s
+	}
+
+	switch pagination.Type {
+	case resources.PaginationTypeLink:
+	case resources.PaginationTypeCursor:
+		if pagination.Cursor == "" {
+			return nil, perr.BadRequestWithMessage("the cursor pagination must define the " + resources.AttributeTypeCursor + " attribute")
+		}
+	case resources.PaginationTypeOffset, resources.PaginationTypePage:
+		pagination.PageParam = pagination.Type
+	default:
+		return nil, perr.BadRequestWithMessage("invalid pagination type: " + pagination.Type)
+	}
+
+	if s := stringValue(resources.AttributeTypePageParam); s != "" {
+		pagination.PageParam = s
+	}
+
+	for name, target := range map[string]*int{resources.AttributeTypePageSize: &pagination.PageSize, resources.AttributeTypeMaxPages: &pagination.MaxPages} {
+		if input[name] == nil {
+			continue
+		}
+
+		var i int
+		switch v := input[name].(type) {
+		case int:
+			i = v
+		case int64:
+			i = int(v)
+		case float64:
+			i = int(v)
+		}
+		if i < 1 {
+			return nil, perr.BadRequestWithMessage("the pagination " + name + " attribute must be a number greater than 0")
+		}
+		*target = i
+	}
+
+	if pagination.Type == resources.PaginationTypeOffset && pagination.PageSize == 0 {
+		return nil, perr.BadRequestWithMessage("the offset pagination must define the " + resources.AttributeTypePageSize + " attribute")
+	}
+
+	return pagination, nil
+}
+
+// doPaginatedRequest requests the pages in turn, until the last page, an error status or the max pages. The response
+// body of the output is the list of the page bodies, the other attributes are the ones of the last page, and the pages
+// attribute has the url and status of each page.
+func doPaginatedRequest(ctx context.Context, inputParams *HTTPInput) (*resources.Output, error) {
+	pagination := inputParams.Pagination
+	pageInput := *inputParams
+
+	if pagination.LimitParam != "" && pagination.PageSize > 0 {
+		pageURL, err := setQueryParam(pageInput.URL, pagination.LimitParam, strconv.Itoa(pagination.PageSize))
+		if err != nil {
+			return nil, err
+		}
+		pageInput.URL = pageURL
+	}
+
+	if pagination.Type == resources.PaginationTypePage {
+		pageURL, err := setQueryParam(pageInput.URL, pagination.PageParam, "1")
+		if err != nil {
+			return nil, err
+		}
+		pageInput.URL = pageURL
+	}
+
+	var output *resources.Output
+	bodies := []any{}
+	pages := []any{}
+	offset := 0
+
+	for page := 1; page <= pagination.MaxPages; page++ {
+		pageOutput, err := doRequest(ctx, &pageInput)
+		if err != nil {
+			return nil, err
+		}
+		output = pageOutput
+
+		body := pageOutput.Data[schema.AttributeTypeResponseBody]
+		bodies = append(bodies, body)
+		pages = append(pages, map[string]any{
+			schema.AttributeTypeUrl:        pageInput.URL,
+			schema.AttributeTypeStatus:     pageOutput.Data[schema.AttributeTypeStatus],
+			schema.AttributeTypeStatusCode: pageOutput.Data[schema.AttributeTypeStatusCode],
+		})
+
+		if len(pageOutput.Errors) > 0 {
+			break
+		}
+
+		var nextURL string
+		switch pagination.Type {
+		case resources.PaginationTypeLink:
+			headers, _ := pageOutput.Data[schema.AttributeTypeResponseHeaders].(map[string]any)
+			link, _ := headers["Link"].(string)
+			nextURL, err = nextLinkURL(pageInput.URL, link)
+
+		case resources.PaginationTypeCursor:
+			cursor, found, pathErr := jsonPathValue(body, pagination.Cursor)
+			if pathErr != nil {
+				return nil, pathErr
+			}
+			if found && cursor != nil && fmt.Sprint(cursor) != "" {
+				nextURL, err = setQueryParam(pageInput.URL, pagination.CursorParam, cursorString(cursor))
+			}
+
+		case resources.PaginationTypeOffset, resources.PaginationTypePage:
+			count, countErr := pageItemCount(body, pagination.Items)
+			if countErr != nil {
+				return nil, countErr
+			}
+			if count == 0 || (pagination.PageSize > 0 && count < pagination.PageSize) {
+				// the last page
+				break
+			}
+
+			offset += count
+			next := strconv.Itoa(offset)
+			if pagination.Type == resources.PaginationTypePage {
+				next = strconv.Itoa(page + 1)
+			}
+			nextURL, err = setQueryParam(pageInput.URL, pagination.PageParam, next)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+		if nextURL == "" {
+			break
+		}
+		pageInput.URL = nextURL
+	}
+
+	output.Data[schema.AttributeTypeResponseBody] = bodies
+	output.Data[AttributeTypePages] = pages
+
+	return output, nil
+}
+
+func setQueryParam(rawURL, name, value string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", perr.BadRequestWithMessage("invalid url: " + rawURL)
+	}
+
+	query := u.Query()
+	query.Set(name, value)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+var linkNextRegex = regexp.MustCompile(`<([^>]*)>\s*;[^,]*rel="?next"?`)
+
+// nextLinkURL returns the rel="next" URL of a Link header, resolved against the URL of the current page, empty on the
+// last page
+func nextLinkURL(currentURL, link string) (string, error) {
+	match := linkNextRegex.FindStringSubmatch(link)
+	if match == nil {
+		return "", nil
+	}
+
+	base, err := url.Parse(currentURL)
+	if err != nil {
+		return "", perr.BadRequestWithMessage("invalid url: " + currentURL)
+	}
+	next, err := base.Parse(match[1])
+	if err != nil {
+		return "", perr.BadRequestWithMessage("invalid next link: " + match[1])
+	}
+	return next.String(), nil
+}
+
+func cursorString(cursor any) string {
+	if f, ok := cursor.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(cursor)
+}
+
+// pageItemCount returns the number of items of a page, the length of the list at the items JSONPath, or of the body
+// if it's a list
+func pageItemCount(body any, itemsPath string) (int, error) {
+	items := body
+	if itemsPath != "" {
+		var err error
+		items, _, err = jsonPathValue(body, itemsPath)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	list, ok := items.([]any)
+	if !ok {
+		return 0, nil
+	}
+	return len(list), nil
+}
+
+var jsonPathSegmentRegex = regexp.MustCompile(`^(?:\.([A-Za-z_][A-Za-z0-9_-]*)|\[(\d+)\]|\['([^']*)'\]|\["([^"]*)"\])`)
+
+// jsonPathValue returns the value at the JSONPath in the decoded JSON data. The supported subset of JSONPath is the
+// member access, $.a.b or $['a'], and the array index, $.a[0]. Found is false if the path doesn't exist in the data.
+func jsonPathValue(data any, path string) (value any, found bool, err error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(path), "$")
+	if !ok {
+		return nil, false, perr.BadRequestWithMessage("invalid JSONPath, it must start with $: " + path)
+	}
+
+	value = data
+	for rest != "" {
+		match := jsonPathSegmentRegex.FindStringSubmatch(rest)
+		if match == nil {
+			return nil, false, perr.BadRequestWithMessage("invalid JSONPath: " + path)
+		}
+		rest = rest[len(match[0]):]
+
+		if match[2] != "" {
+			index, _ := strconv.Atoi(match[2])
+			list, ok := value.([]any)
+			if !ok || index >= len(list) {
+				return nil, false, nil
+			}
+			value = list[index]
+			continue
+		}
+
+		key := match[1] + match[3] + match[4]
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, false, nil
+		}
+		if value, ok = object[key]; !ok {
+			return nil, false, nil
+		}
+	}
+
+	return value, true, nil
+}
@@ -0,0 +1,231 @@
+package primitive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+// itemsServer serves the items 0 to total-1, the page is selected by the handler from the request
+func itemsServer(handler func(w http.ResponseWriter, r *http.Request) (items []int, extra map[string]any)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		items, extra := handler(w, r)
+		body := map[string]any{"items": items}
+		for k, v := range extra {
+			body[k] = v
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+}
+
+func pageOf(total, offset, size int) []int {
+	items := []int{}
+	for i := offset; i < total && i < offset+size; i++ {
+		items = append(items, i)
+	}
+	return items
+}
+
+func TestHTTPPaginationLink(t *testing.T) {
+	assert := assert.New(t)
+
+	server := itemsServer(func(w http.ResponseWriter, r *http.Request) ([]int, map[string]any) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("p"))
+		if page < 2 {
+			w.Header().Set("Link", fmt.Sprintf(`</items?p=%d>; rel="next", </items?p=0>; rel="first"`, page+1))
+		}
+		return pageOf(25, page*10, 10), nil
+	})
+	defer server.Close()
+
+	output, err := (&HTTPRequest{}).Run(context.Background(), resources.Input{
+		schema.AttributeTypeUrl: server.URL + "/items?p=0",
+		resources.BlockTypePagination: map[string]any{
+			schema.AttributeTypeType: resources.PaginationTypeLink,
+		},
+	})
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	bodies := output.Get(schema.AttributeTypeResponseBody).([]any)
+	assert.Equal(3, len(bodies))
+	assert.Equal(5, len(bodies[2].(map[string]any)["items"].([]any)))
+	assert.Equal(200, output.Get(schema.AttributeTypeStatusCode))
+
+	pages := output.Get(AttributeTypePages).([]any)
+	assert.Equal(3, len(pages))
+	assert.Equal(server.URL+"/items?p=2", pages[2].(map[string]any)[schema.AttributeTypeUrl])
+	assert.Equal(200, pages[2].(map[string]any)[schema.AttributeTypeStatusCode])
+}
+
+func TestHTTPPaginationCursor(t *testing.T) {
+	assert := assert.New(t)
+
+	server := itemsServer(func(w http.ResponseWriter, r *http.Request) ([]int, map[string]any) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("after"))
+		items := pageOf(25, offset, 10)
+		var next any
+		if offset+10 < 25 {
+			next = offset + 10
+		}
+		return items, map[string]any{"meta": map[string]any{"next": next}}
+	})
+	defer server.Close()
+
+	output, err := (&HTTPRequest{}).Run(context.Background(), resources.Input{
+		schema.AttributeTypeUrl: server.URL + "/items",
+		resources.BlockTypePagination: map[string]any{
+			schema.AttributeTypeType:           resources.PaginationTypeCursor,
+			resources.AttributeTypeCursor:      "$.meta.next",
+			resources.AttributeTypeCursorParam: "after",
+		},
+	})
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	bodies := output.Get(schema.AttributeTypeResponseBody).([]any)
+	assert.Equal(3, len(bodies))
+	assert.Equal(float64(20), bodies[2].(map[string]any)["items"].([]any)[0])
+	assert.Equal(server.URL+"/items?after=20", output.Get(AttributeTypePages).([]any)[2].(map[string]any)[schema.AttributeTypeUrl])
+}
+
+func TestHTTPPaginationOffsetAndPage(t *testing.T) {
+	assert := assert.New(t)
+
+	server := itemsServer(func(w http.ResponseWriter, r *http.Request) ([]int, map[string]any) {
+		size, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if page, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil {
+			return pageOf(20, (page-1)*size, size), nil
+		}
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		return pageOf(20, offset, size), nil
+	})
+	defer server.Close()
+
+	output, err := (&HTTPRequest{}).Run(context.Background(), resources.Input{
+		schema.AttributeTypeUrl: server.URL + "/items",
+		resources.BlockTypePagination: map[string]any{
+			schema.AttributeTypeType:          resources.PaginationTypeOffset,
+			resources.AttributeTypePageSize:   int64(10),
+			resources.AttributeTypeLimitParam: "limit",
+			resources.AttributeTypeItems:      "$.items",
+		},
+	})
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	// the second page is full, the third one is empty
+	assert.Equal(3, len(output.Get(schema.AttributeTypeResponseBody).([]any)))
+	assert.Equal(server.URL+"/items?limit=10&offset=20", output.Get(AttributeTypePages).([]any)[2].(map[string]any)[schema.AttributeTypeUrl])
+
+	output, err = (&HTTPRequest{}).Run(context.Background(), resources.Input{
+		schema.AttributeTypeUrl: server.URL + "/items?limit=8",
+		resources.BlockTypePagination: map[string]any{
+			schema.AttributeTypeType:        resources.PaginationTypePage,
+			resources.AttributeTypePageSize: 8,
+			resources.AttributeTypeItems:    "$['items']",
+		},
+	})
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	// 8, 8 and 4 items
+	bodies := output.Get(schema.AttributeTypeResponseBody).([]any)
+	assert.Equal(3, len(bodies))
+	assert.Equal(4, len(bodies[2].(map[string]any)["items"].([]any)))
+	assert.Equal(server.URL+"/items?limit=8&page=3", output.Get(AttributeTypePages).([]any)[2].(map[string]any)[schema.AttributeTypeUrl])
+}
+
+func TestHTTPPaginationMaxPagesAndErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	server := itemsServer(func(w http.ResponseWriter, r *http.Request) ([]int, map[string]any) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+		return pageOf(1000, (page-1)*10, 10), nil
+	})
+	defer server.Close()
+
+	output, err := (&HTTPRequest{}).Run(context.Background(), resources.Input{
+		schema.AttributeTypeUrl: server.URL + "/items",
+		resources.BlockTypePagination: map[string]any{
+			schema.AttributeTypeType:        resources.PaginationTypePage,
+			resources.AttributeTypeItems:    "$.items",
+			resources.AttributeTypeMaxPages: int64(2),
+		},
+	})
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal(2, len(output.Get(schema.AttributeTypeResponseBody).([]any)))
+	assert.Empty(output.Errors)
+
+	// the error page ends the pagination
+	output, err = (&HTTPRequest{}).Run(context.Background(), resources.Input{
+		schema.AttributeTypeUrl: server.URL + "/items",
+		resources.BlockTypePagination: map[string]any{
+			schema.AttributeTypeType:     resources.PaginationTypePage,
+			resources.AttributeTypeItems: "$.items",
+		},
+	})
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	pages := output.Get(AttributeTypePages).([]any)
+	assert.Equal(3, len(pages))
+	assert.Equal(429, pages[2].(map[string]any)[schema.AttributeTypeStatusCode])
+	assert.Equal(429, output.Get(schema.AttributeTypeStatusCode))
+	assert.Equal(1, len(output.Errors))
+
+	// invalid pagination
+	_, err = (&HTTPRequest{}).Run(context.Background(), resources.Input{
+		schema.AttributeTypeUrl: server.URL + "/items",
+		resources.BlockTypePagination: map[string]any{
+			schema.AttributeTypeType: resources.PaginationTypeOffset,
+		},
+	})
+	assert.NotNil(err)
+}
+
+func TestJSONPathValue(t *testing.T) {
+	assert := assert.New(t)
+
+	var data any
+	_ = json.Unmarshal([]byte(`{"meta": {"next-page": "abc", "pages": [{"n": 1}, {"n": 2}]}, "items": []}`), &data)
+
+	value, found, err := jsonPathValue(data, "$.meta['next-page']")
+	assert.Nil(err)
+	assert.True(found)
+	assert.Equal("abc", value)
+
+	value, found, err = jsonPathValue(data, `$.meta.pages[1]["n"]`)
+	assert.Nil(err)
+	assert.True(found)
+	assert.Equal(float64(2), value)
+
+	_, found, err = jsonPathValue(data, "$.meta.missing")
+	assert.Nil(err)
+	assert.False(found)
+
+	_, found, err = jsonPathValue(data, "$.meta.pages[5]")
+	assert.Nil(err)
+	assert.False(found)
+
+	_, _, err = jsonPathValue(data, "meta.next")
+	assert.NotNil(err)
+}
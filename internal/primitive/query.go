@@ -16,6 +16,10 @@ import (
 	"github.com/turbot/pipe-fittings/schema"
 )
 
+// AttributeTypeRowsAffected is the output of the query step with statements or a batch, the rows affected by each
+// statement
+const AttributeTypeRowsAffected = "rows_affected"
+
 const (
 	DriverPostgres   = "postgres"
 	DriverPostgresql = "postgresql"
@@ -35,10 +39,35 @@ func (e *Query) ValidateInput(ctx context.Context, i resources.Input) error {
 		return perr.BadRequestWithMessage("Query input must define database")
 	}
 
-	if i[schema.AttributeTypeSql] == nil {
+	if i[resources.AttributeTypeStatements] != nil {
+		if i[schema.AttributeTypeSql] != nil {
+			return perr.BadRequestWithMessage("Query input should have either sql or statements but not both")
+		}
+
+		statements, ok := i[resources.AttributeTypeStatements].([]interface{})
+		if !ok {
+			return perr.BadRequestWithMessage("Query statements must be a list")
+		}
+		if err := resources.ValidateQueryStatements(statements); err != nil {
+			return err
+		}
+	} else if i[schema.AttributeTypeSql] == nil {
 		return perr.BadRequestWithMessage("Query input must define sql")
 	}
 
+	if i[resources.AttributeTypeBatch] != nil {
+		batch, ok := i[resources.AttributeTypeBatch].([]interface{})
+		if !ok {
+			return perr.BadRequestWithMessage("Query batch must be a list")
+		}
+		if err := resources.ValidateQueryBatch(batch); err != nil {
+			return err
+		}
+		if i[schema.AttributeTypeSql] == nil {
+			return perr.BadRequestWithMessage("Query input with a batch must define sql")
+		}
+	}
+
 	// Validate the timeout attribute
 	if i[schema.AttributeTypeTimeout] != nil {
 		switch duration := i[schema.AttributeTypeTimeout].(type) {
@@ -69,7 +98,7 @@ func (e *Query) RunWithMetadata(ctx context.Context, input resources.Input) (*re
 	}
 
 	// Get the inputs
-	queryString := input[schema.AttributeTypeSql].(string)
+	queryString, _ := input[schema.AttributeTypeSql].(string)
 
 	var args []interface{}
 	if input[schema.AttributeTypeArgs] != nil {
@@ -88,13 +117,14 @@ func (e *Query) RunWithMetadata(ctx context.Context, input resources.Input) (*re
 		}
 	}
 
-	queryReader, err := NewQueryReader(input[schema.AttributeTypeDatabase].(string))
+	// the database is shared with the other query steps and triggers using the same connection string
+	queryReader, release, err := queryReaders.acquire(ctx, input[schema.AttributeTypeDatabase].(string))
 	if err != nil {
 		slog.Error("Error initializing the database", "error", err)
 		return nil, nil, perr.InternalWithMessage("Error initializing the database: " + err.Error())
 	}
 	e.QueryReader = queryReader
-	defer queryReader.Close()
+	defer release()
 
 	var results []map[string]interface{}
 	var md map[string]*sql.ColumnType
@@ -106,19 +136,34 @@ func (e *Query) RunWithMetadata(ctx context.Context, input resources.Input) (*re
 	// causes a context cancellation error for some test which don't have timeout set.
 	// So, for now we use 2 different methods to run the query, depending on whether the timeout is set or not.
 	// If set, we use the context with timeout, otherwise we use the sql.Query method.
+	queryContext := context.Background()
 	if timeout > 0 {
 		var cancel context.CancelFunc
 
 		// We can't use the watermill context to set the query timeout, since it will be cancelled by watermill.
 		// So, we create a new context with timeout and use it to run the query.
 		// Set the timeout
-		contextWithTimeout, cancel := context.WithTimeout(context.Background(), timeout)
+		queryContext, cancel = context.WithTimeout(context.Background(), timeout)
 		defer cancel()
-		results, md, err = queryReader.Query(contextWithTimeout, queryString, args...)
-	} else {
-		results, md, err = queryReader.Query(context.Background(), queryString, args...)
 	}
 
+	if statements := queryStatements(input); statements != nil {
+		transaction, _ := input[resources.AttributeTypeTransaction].(bool)
+		rowsAffected, err := queryReader.Exec(queryContext, statements, transaction)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		output := &resources.Output{
+			Data: map[string]interface{}{
+				AttributeTypeRowsAffected: rowsAffected,
+			},
+			Flowpipe: FlowpipeMetadataOutput(start, time.Now().UTC()),
+		}
+		return output, nil, nil
+	}
+
+	results, md, err = queryReader.Query(queryContext, queryString, args...)
 	if err != nil {
 		return nil, nil, perr.InternalWithMessage("Error executing query: " + err.Error())
 	}
@@ -135,6 +180,36 @@ func (e *Query) RunWithMetadata(ctx context.Context, input resources.Input) (*re
 	return output, md, nil
 }
 
+// queryStatements returns the statements of a multi-statement or batch query, run for their rows affected rather than
+// their rows, nil for a single query
+func queryStatements(input resources.Input) []QueryStatement {
+	if batch, ok := input[resources.AttributeTypeBatch].([]interface{}); ok {
+		sqlString := input[schema.AttributeTypeSql].(string)
+		statements := make([]QueryStatement, 0, len(batch))
+		for _, args := range batch {
+			statements = append(statements, QueryStatement{Sql: sqlString, Args: args.([]interface{})})
+		}
+		return statements
+	}
+
+	list, ok := input[resources.AttributeTypeStatements].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	statements := make([]QueryStatement, 0, len(list))
+	for _, item := range list {
+		switch s := item.(type) {
+		case string:
+			statements = append(statements, QueryStatement{Sql: s})
+		case map[string]interface{}:
+			args, _ := s[schema.AttributeTypeArgs].([]interface{})
+			statements = append(statements, QueryStatement{Sql: s[schema.AttributeTypeSql].(string), Args: args})
+		}
+	}
+	return statements
+}
+
 func (e *Query) Run(ctx context.Context, input resources.Input) (*resources.Output, error) {
 	output, _, err := e.RunWithMetadata(ctx, input)
 	return output, err
@@ -0,0 +1,230 @@
+package primitive
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	localconstants "github.com/turbot/flowpipe/internal/constants"
+	"github.com/turbot/flowpipe/internal/metrics"
+	"github.com/turbot/pipe-fittings/sanitize"
+)
+
+const (
+	// queryPoolHealthCheckInterval is how long a database can go without a health check, it is pinged by the next
+	// query after that
+	queryPoolHealthCheckInterval = 30 * time.Second
+	queryPoolHealthCheckTimeout  = 5 * time.Second
+)
+
+// QueryPoolSettings are the limits of the connection pool of each database
+type QueryPoolSettings struct {
+	MaxOpen     int
+	MaxIdle     int
+	MaxIdleTime time.Duration
+}
+
+// queryPoolSettingsFromConfig reads the settings from the config, falling back to the defaults when they're not set,
+// e.g. in the tests
+func queryPoolSettingsFromConfig() QueryPoolSettings {
+	settings := QueryPoolSettings{
+		MaxOpen:     viper.GetInt(localconstants.ArgQueryPoolMaxOpen),
+		MaxIdle:     viper.GetInt(localconstants.ArgQueryPoolMaxIdle),
+		MaxIdleTime: time.Duration(viper.GetInt(localconstants.ArgQueryPoolMaxIdleTime)) * time.Second,
+	}
+
+	if !viper.IsSet(localconstants.ArgQueryPoolMaxOpen) {
+		settings.MaxOpen = localconstants.DefaultQueryPoolMaxOpen
+	}
+	if !viper.IsSet(localconstants.ArgQueryPoolMaxIdle) {
+		settings.MaxIdle = localconstants.DefaultQueryPoolMaxIdle
+	}
+	if settings.MaxIdleTime <= 0 {
+		settings.MaxIdleTime = localconstants.DefaultQueryPoolMaxIdleTime * time.Second
+	}
+
+	return settings
+}
+
+type queryPoolEntry struct {
+	// the health check mutex, the other fields are guarded by the pool mutex
+	mutex sync.Mutex
+
+	reader              QueryReader
+	settings            QueryPoolSettings
+	users               int
+	queries             int64
+	lastUsed            time.Time
+	lastHealthCheck     time.Time
+	healthCheckFailures int64
+}
+
+// queryReaderPool shares the databases of the query steps and triggers, keyed by connection string, so the steps
+// reuse the connections rather than opening new ones on each run. The databases no longer queried are closed after
+// the max idle time. The SQLite and DuckDB files aren't pooled.
+type queryReaderPool struct {
+	mutex   sync.Mutex
+	entries map[string]*queryPoolEntry
+}
+
+var queryReaders = &queryReaderPool{entries: map[string]*queryPoolEntry{}}
+
+// isFileDatabase returns true for the SQLite and DuckDB databases, which are files opened by the process
+func isFileDatabase(connectionString string) bool {
+	driver, _, _ := strings.Cut(connectionString, ":")
+	return driver == DriverSQLite || driver == DriverSQLite3 || driver == DriverDuckDB
+}
+
+// acquire returns the reader of the database, to be released once the query is done
+func (p *queryReaderPool) acquire(ctx context.Context, connectionString string) (QueryReader, func(), error) {
+	// the file databases aren't pooled: the file can be replaced between two queries, which would keep reading the
+	// old one, and DuckDB locks the file for as long as it's open. They are opened by each query, as before.
+	if isFileDatabase(connectionString) {
+		reader, err := NewQueryReader(connectionString)
+		if err != nil {
+			if reader != nil {
+				reader.Close()
+			}
+			return nil, nil, err
+		}
+		return reader, reader.Close, nil
+	}
+
+	p.mutex.Lock()
+	p.closeIdle(time.Now())
+
+	entry, ok := p.entries[connectionString]
+	if !ok {
+		reader, err := NewQueryReader(connectionString)
+		if err != nil {
+			p.mutex.Unlock()
+			if reader != nil {
+				reader.Close()
+			}
+			return nil, nil, err
+		}
+
+		settings := queryPoolSettingsFromConfig()
+		reader.SetPoolLimits(settings.MaxOpen, settings.MaxIdle, settings.MaxIdleTime)
+
+		slog.Debug("Opened query pool", "database", sanitize.RedactDbConnectionPassword(connectionString), "max_open", settings.MaxOpen, "max_idle", settings.MaxIdle)
+
+		// the database has just been opened, there's nothing to check yet
+		entry = &queryPoolEntry{reader: reader, settings: settings, lastHealthCheck: time.Now()}
+		p.entries[connectionString] = entry
+	}
+
+	entry.users++
+	entry.queries++
+	entry.lastUsed = time.Now()
+	p.mutex.Unlock()
+
+	p.checkHealth(ctx, connectionString, entry)
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			entry.users--
+			entry.lastUsed = time.Now()
+		})
+	}
+
+	return entry.reader, release, nil
+}
+
+// checkHealth pings the database if it hasn't been checked for a while. The failures are only recorded: the
+// connections found broken are discarded by database/sql and the query reports the error of the database.
+func (p *queryReaderPool) checkHealth(ctx context.Context, connectionString string, entry *queryPoolEntry) {
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+
+	p.mutex.Lock()
+	due := time.Since(entry.lastHealthCheck) >= queryPoolHealthCheckInterval
+	p.mutex.Unlock()
+	if !due {
+		return
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, queryPoolHealthCheckTimeout)
+	defer cancel()
+	err := entry.reader.Ping(pingCtx)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if err != nil {
+		// checked again by the next query
+		entry.healthCheckFailures++
+		slog.Warn("Query pool health check failed", "database", sanitize.RedactDbConnectionPassword(connectionString), "error", err)
+		return
+	}
+	entry.lastHealthCheck = time.Now()
+}
+
+// closeIdle closes the databases that haven't been used for the max idle time, the pool mutex must be held
+func (p *queryReaderPool) closeIdle(now time.Time) {
+	for connectionString, entry := range p.entries {
+		if entry.users > 0 || now.Sub(entry.lastUsed) < entry.settings.MaxIdleTime {
+			continue
+		}
+
+		slog.Debug("Closing idle query pool", "database", sanitize.RedactDbConnectionPassword(connectionString))
+		entry.reader.Close()
+		delete(p.entries, connectionString)
+	}
+}
+
+// close closes all the databases, the connections in use are closed once their query is done
+func (p *queryReaderPool) close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for connectionString, entry := range p.entries {
+		entry.reader.Close()
+		delete(p.entries, connectionString)
+	}
+}
+
+func (p *queryReaderPool) metrics() []metrics.QueryPool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	pools := make([]metrics.QueryPool, 0, len(p.entries))
+	for connectionString, entry := range p.entries {
+		stats := entry.reader.Stats()
+		pools = append(pools, metrics.QueryPool{
+			Database:            sanitize.RedactDbConnectionPassword(connectionString),
+			MaxOpenConnections:  stats.MaxOpenConnections,
+			OpenConnections:     stats.OpenConnections,
+			InUse:               stats.InUse,
+			Idle:                stats.Idle,
+			WaitCount:           stats.WaitCount,
+			WaitDurationMs:      stats.WaitDuration.Milliseconds(),
+			MaxIdleClosed:       stats.MaxIdleClosed,
+			MaxIdleTimeClosed:   stats.MaxIdleTimeClosed,
+			HealthCheckFailures: entry.healthCheckFailures,
+			Queries:             entry.queries,
+			LastUsed:            entry.lastUsed,
+		})
+	}
+
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].Database < pools[j].Database
+	})
+	return pools
+}
+
+// QueryPoolMetrics returns the state of the connection pools of the databases queried by the query steps and triggers
+func QueryPoolMetrics() []metrics.QueryPool {
+	return queryReaders.metrics()
+}
+
+// CloseQueryPools closes the databases of the query steps and triggers
+func CloseQueryPools() {
+	queryReaders.close()
+}
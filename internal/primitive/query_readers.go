@@ -73,7 +73,11 @@ type QueryReader interface {
 	GetConnectionString() string
 	Initialize() error
 	Query(context.Context, string, ...interface{}) ([]map[string]interface{}, map[string]*sql.ColumnType, error)
+	Exec(ctx context.Context, statements []QueryStatement, transaction bool) ([]int64, error)
 	RowsToCty(rows []map[string]interface{}, columnTypes map[string]*sql.ColumnType) ([]cty.Value, error)
+	Ping(context.Context) error
+	SetPoolLimits(maxOpen, maxIdle int, maxIdleTime time.Duration)
+	Stats() sql.DBStats
 	Close()
 }
 
@@ -105,6 +109,22 @@ func (q *QueryReaderImpl) Close() {
 	}
 }
 
+func (q *QueryReaderImpl) Ping(ctx context.Context) error {
+	return q.db.PingContext(ctx)
+}
+
+// SetPoolLimits sets the limits of the connection pool of the database, a limit of 0 or less is unlimited except for
+// maxIdle, where it keeps no idle connection
+func (q *QueryReaderImpl) SetPoolLimits(maxOpen, maxIdle int, maxIdleTime time.Duration) {
+	q.db.SetMaxOpenConns(maxOpen)
+	q.db.SetMaxIdleConns(maxIdle)
+	q.db.SetConnMaxIdleTime(maxIdleTime)
+}
+
+func (q *QueryReaderImpl) Stats() sql.DBStats {
+	return q.db.Stats()
+}
+
 func (q *QueryReaderImpl) queryRows(rows *sql.Rows) ([]map[string]interface{}, map[string]*sql.ColumnType, error) {
 	columnsTypes, err := rows.ColumnTypes()
 	if err != nil {
@@ -135,6 +155,83 @@ func (q *QueryReaderImpl) Query(ctx context.Context, queryString string, args ..
 	return q.queryRows(rows)
 }
 
+// QueryStatement is a statement run by Exec with its args
+type QueryStatement struct {
+	Sql  string
+	Args []interface{}
+}
+
+// Exec runs the statements in turn and returns the rows affected by each one, -1 if the driver doesn't report it. The
+// statements with the same SQL, e.g. the ones of a batch, are prepared once. In a transaction, the statements are
+// rolled back if one of them fails.
+func (q *QueryReaderImpl) Exec(ctx context.Context, statements []QueryStatement, transaction bool) ([]int64, error) {
+	var preparer interface {
+		PrepareContext(context.Context, string) (*sql.Stmt, error)
+	} = q.db
+
+	var tx *sql.Tx
+	if transaction {
+		var err error
+		tx, err = q.db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, perr.InternalWithMessage("Error starting transaction: " + err.Error())
+		}
+		// no-op once committed
+		defer func() {
+			_ = tx.Rollback()
+		}()
+		preparer = tx
+	}
+
+	prepared := map[string]*sql.Stmt{}
+	defer func() {
+		for _, stmt := range prepared {
+			stmt.Close()
+		}
+	}()
+
+	rowsAffected := make([]int64, 0, len(statements))
+	for i, statement := range statements {
+		stmt, ok := prepared[statement.Sql]
+		if !ok {
+			var err error
+			stmt, err = preparer.PrepareContext(ctx, statement.Sql)
+			if err != nil {
+				return nil, statementError(ctx, fmt.Sprintf("Error preparing statement %d: ", i+1), err)
+			}
+			prepared[statement.Sql] = stmt
+		}
+
+		result, err := stmt.ExecContext(ctx, statement.Args...)
+		if err != nil {
+			return nil, statementError(ctx, fmt.Sprintf("Error executing statement %d: ", i+1), err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			slog.Debug("Rows affected not supported", "statement", i+1, "error", err)
+			affected = -1
+		}
+		rowsAffected = append(rowsAffected, affected)
+	}
+
+	if tx != nil {
+		err := tx.Commit()
+		if err != nil {
+			return nil, statementError(ctx, "Error committing transaction: ", err)
+		}
+	}
+
+	return rowsAffected, nil
+}
+
+func statementError(ctx context.Context, message string, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return perr.TimeoutWithMessage("Query execution exceeded timeout")
+	}
+	return perr.InternalWithMessage(message + err.Error())
+}
+
 func (q *QueryReaderImpl) RowsToCty(rows []map[string]interface{}, columnTypes map[string]*sql.ColumnType) ([]cty.Value, error) {
 	var rowsCty []cty.Value
 	for _, r := range rows {
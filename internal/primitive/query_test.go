@@ -1104,3 +1104,123 @@ func applyDatabaseScript(driverName, dbConnectionString, scriptPath string) erro
 
 	return nil
 }
+
+func TestQueryStatementsAndBatch(t *testing.T) {
+	ctx := context.Background()
+
+	for _, database := range []string{
+		"sqlite:" + t.TempDir() + "/statements.db",
+		"duckdb:" + t.TempDir() + "/statements.duckdb",
+	} {
+		t.Run(strings.SplitN(database, ":", 2)[0], func(t *testing.T) {
+			assert := assert.New(t)
+			hr := Query{}
+
+			output, err := hr.Run(ctx, resources.Input{
+				schema.AttributeTypeDatabase: database,
+				resources.AttributeTypeStatements: []interface{}{
+					"create table account (id integer primary key, name varchar, balance integer)",
+					map[string]interface{}{
+						schema.AttributeTypeSql:  "insert into account values (1, 'alice', 100), (2, 'bob', 50)",
+						schema.AttributeTypeArgs: []interface{}{},
+					},
+				},
+				resources.AttributeTypeTransaction: true,
+			})
+			if !assert.Nil(err) {
+				return
+			}
+			assert.Equal(int64(2), output.Get(AttributeTypeRowsAffected).([]int64)[1])
+
+			// the batch runs the statement once per arg tuple
+			output, err = hr.Run(ctx, resources.Input{
+				schema.AttributeTypeDatabase: database,
+				schema.AttributeTypeSql:      "insert into account values (?, ?, ?)",
+				resources.AttributeTypeBatch: []interface{}{
+					[]interface{}{3, "carol", 10},
+					[]interface{}{4, "dave", 20},
+					[]interface{}{5, "erin", 30},
+				},
+			})
+			if !assert.Nil(err) {
+				return
+			}
+			assert.Equal([]int64{1, 1, 1}, output.Get(AttributeTypeRowsAffected))
+
+			// the transaction is rolled back when a statement fails
+			_, err = hr.Run(ctx, resources.Input{
+				schema.AttributeTypeDatabase: database,
+				resources.AttributeTypeStatements: []interface{}{
+					map[string]interface{}{
+						schema.AttributeTypeSql:  "update account set balance = balance - ? where id = ?",
+						schema.AttributeTypeArgs: []interface{}{30, 1},
+					},
+					"insert into account values (1, 'duplicate', 0)",
+				},
+				resources.AttributeTypeTransaction: true,
+			})
+			assert.NotNil(err)
+			assert.Contains(err.Error(), "Error executing statement 2")
+
+			output, err = hr.Run(ctx, resources.Input{
+				schema.AttributeTypeDatabase: database,
+				schema.AttributeTypeSql:      "select count(*) as accounts, cast(sum(balance) as integer) as balance from account",
+			})
+			if !assert.Nil(err) {
+				return
+			}
+			rows := output.Get(schema.AttributeTypeRows).([]map[string]interface{})
+			assert.EqualValues(5, rows[0]["accounts"])
+			assert.EqualValues(210, rows[0]["balance"])
+
+			// without a transaction, the statements before the failure are kept
+			_, err = hr.Run(ctx, resources.Input{
+				schema.AttributeTypeDatabase: database,
+				resources.AttributeTypeStatements: []interface{}{
+					"update account set balance = 0 where id = 2",
+					"insert into account values (1, 'duplicate', 0)",
+				},
+			})
+			assert.NotNil(err)
+
+			output, err = hr.Run(ctx, resources.Input{
+				schema.AttributeTypeDatabase: database,
+				schema.AttributeTypeSql:      "select balance from account where id = 2",
+			})
+			if !assert.Nil(err) {
+				return
+			}
+			assert.EqualValues(0, output.Get(schema.AttributeTypeRows).([]map[string]interface{})[0]["balance"])
+		})
+	}
+}
+
+func TestQueryStatementsInvalidInput(t *testing.T) {
+	ctx := context.Background()
+
+	assert := assert.New(t)
+	hr := Query{}
+
+	_, err := hr.Run(ctx, resources.Input{
+		schema.AttributeTypeDatabase:      "sqlite:" + t.TempDir() + "/invalid.db",
+		schema.AttributeTypeSql:           "select 1",
+		resources.AttributeTypeStatements: []interface{}{"select 2"},
+	})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "either sql or statements but not both")
+
+	_, err = hr.Run(ctx, resources.Input{
+		schema.AttributeTypeDatabase:      "sqlite:" + t.TempDir() + "/invalid.db",
+		resources.AttributeTypeStatements: []interface{}{map[string]interface{}{"args": []interface{}{1}}},
+	})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "statement 1 must define the sql")
+
+	_, err = hr.Run(ctx, resources.Input{
+		schema.AttributeTypeDatabase: "sqlite:" + t.TempDir() + "/invalid.db",
+		schema.AttributeTypeSql:      "insert into account values (?)",
+		resources.AttributeTypeBatch: []interface{}{1, 2},
+	})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "the arg tuple 1 of the batch must be a list")
+}
@@ -55,7 +55,11 @@ func (e *Sleep) Run(ctx context.Context, input resources.Input) (*resources.Outp
 
 	slog.Debug("Sleeping for", "duration", duration)
 	start := time.Now().UTC()
-	time.Sleep(duration)
+	select {
+	case <-time.After(duration):
+	case <-ctx.Done():
+		return nil, perr.ExecutionErrorWithMessage("Sleep interrupted: " + ctx.Err().Error())
+	}
 	finish := time.Now().UTC()
 
 	output := &resources.Output{
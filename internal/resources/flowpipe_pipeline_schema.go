@@ -140,6 +140,21 @@ var TriggerScheduleBlockSchema = &hcl.BodySchema{
 		{
 			Name: schema.AttributeTypeEnabled,
 		},
+		{
+			Name: AttributeTypeMisfirePolicy,
+		},
+		{
+			Name: AttributeTypeTimezone,
+		},
+		{
+			Name: AttributeTypeExclude,
+		},
+		{
+			Name: AttributeTypeCalendar,
+		},
+		{
+			Name: AttributeTypeJitter,
+		},
 	},
 	Blocks: []hcl.BlockHeaderSchema{
 		{
@@ -213,6 +228,21 @@ var TriggerQueryBlockSchema = &hcl.BodySchema{
 		{
 			Name: schema.AttributeTypePrimaryKey,
 		},
+		{
+			Name: AttributeTypeWatermarkColumn,
+		},
+		{
+			Name: AttributeTypeTimezone,
+		},
+		{
+			Name: AttributeTypeExclude,
+		},
+		{
+			Name: AttributeTypeCalendar,
+		},
+		{
+			Name: AttributeTypeJitter,
+		},
 		{
 			Name:     schema.AttributeTypeDatabase,
 			Required: false,
@@ -233,6 +263,118 @@ var TriggerQueryBlockSchema = &hcl.BodySchema{
 	},
 }
 
+var TriggerQueueBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name:     schema.AttributeTypeDescription,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeTitle,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeDocumentation,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeTags,
+			Required: false,
+		},
+		{
+			Name:     AttributeTypeSource,
+			Required: true,
+		},
+		{
+			Name: AttributeTypePath,
+		},
+		{
+			Name: AttributeTypeUrl,
+		},
+		{
+			Name: AttributeTypeStream,
+		},
+		{
+			Name: AttributeTypeConsumer,
+		},
+		{
+			Name: AttributeTypeConcurrency,
+		},
+		{
+			Name: AttributeTypeMaxDeliveries,
+		},
+		{
+			Name: AttributeTypeDeadLetter,
+		},
+		{
+			Name:     schema.AttributeTypePipeline,
+			Required: true,
+		},
+		{
+			Name: schema.AttributeTypeArgs,
+		},
+		{
+			Name: schema.AttributeTypeEnabled,
+		},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type:       schema.BlockTypeParam,
+			LabelNames: []string{schema.LabelName},
+		},
+	},
+}
+
+var TriggerFileBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name:     schema.AttributeTypeDescription,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeTitle,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeDocumentation,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeTags,
+			Required: false,
+		},
+		{
+			Name:     AttributeTypePath,
+			Required: true,
+		},
+		{
+			Name: AttributeTypePattern,
+		},
+		{
+			Name: AttributeTypeEvents,
+		},
+		{
+			Name: AttributeTypeDebounce,
+		},
+		{
+			Name:     schema.AttributeTypePipeline,
+			Required: true,
+		},
+		{
+			Name: schema.AttributeTypeArgs,
+		},
+		{
+			Name: schema.AttributeTypeEnabled,
+		},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type:       schema.BlockTypeParam,
+			LabelNames: []string{schema.LabelName},
+		},
+	},
+}
+
 var TriggerHttpBlockSchema = &hcl.BodySchema{
 	Attributes: []hcl.AttributeSchema{
 		{
@@ -269,6 +411,9 @@ var TriggerHttpBlockSchema = &hcl.BodySchema{
 			Type:       schema.BlockTypeMethod,
 			LabelNames: []string{schema.LabelName},
 		},
+		{
+			Type: BlockTypeTriggerHttpAuth,
+		},
 		{
 			Type:       schema.BlockTypeParam,
 			LabelNames: []string{schema.LabelName},
@@ -396,6 +541,18 @@ var PipelineStepHttpBlockSchema = &hcl.BodySchema{
 		{
 			Name: schema.AttributeTypeRequestHeaders,
 		},
+		{
+			Name: AttributeTypeResponseToFile,
+		},
+		{
+			Name: AttributeTypeRequestBodyFile,
+		},
+		{
+			Name: AttributeTypeFormFields,
+		},
+		{
+			Name: AttributeTypeFormFiles,
+		},
 		{
 			Name: schema.AttributeTypeMaxConcurrency,
 		},
@@ -411,6 +568,12 @@ var PipelineStepHttpBlockSchema = &hcl.BodySchema{
 		{
 			Type: schema.BlockTypePipelineBasicAuth,
 		},
+		{
+			Type: BlockTypePagination,
+		},
+		{
+			Type: BlockTypeOAuth2,
+		},
 		{
 			Type: schema.BlockTypeLoop,
 		},
@@ -593,6 +756,15 @@ var PipelineStepQueryBlockSchema = &hcl.BodySchema{
 		{
 			Name: schema.AttributeTypeArgs,
 		},
+		{
+			Name: AttributeTypeStatements,
+		},
+		{
+			Name: AttributeTypeBatch,
+		},
+		{
+			Name: AttributeTypeTransaction,
+		},
 		{
 			Name: schema.AttributeTypeMaxConcurrency,
 		},
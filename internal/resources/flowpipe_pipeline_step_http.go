@@ -23,6 +23,13 @@ const (
 	HttpMethodPatch  = "patch"
 )
 
+const (
+	AttributeTypeResponseToFile  = "response_to_file"
+	AttributeTypeRequestBodyFile = "request_body_file"
+	AttributeTypeFormFields      = "form_fields"
+	AttributeTypeFormFiles       = "form_files"
+)
+
 var ValidHttpMethods = []string{
 	HttpMethodGet,
 	HttpMethodPost,
@@ -41,6 +48,16 @@ type PipelineStepHttp struct {
 	RequestBody     *string                `json:"request_body,omitempty"`
 	RequestHeaders  map[string]interface{} `json:"request_headers,omitempty"`
 	BasicAuthConfig *BasicAuthConfig       `json:"basic_auth,omitempty"`
+	Pagination      *HttpPaginationConfig  `json:"pagination,omitempty"`
+	OAuth2          *HttpOAuth2Config      `json:"oauth2,omitempty"`
+
+	// The response body is streamed to a file rather than returned in response_body
+	ResponseToFile *bool `json:"response_to_file,omitempty"`
+	// The request body is streamed from the file
+	RequestBodyFile *string `json:"request_body_file,omitempty"`
+	// The request body is a multipart/form-data form with the fields and the files, keyed by field name
+	FormFields map[string]string `json:"form_fields,omitempty"`
+	FormFiles  map[string]string `json:"form_files,omitempty"`
 }
 
 func (p *PipelineStepHttp) Equals(iOther PipelineStep) bool {
@@ -73,7 +90,13 @@ func (p *PipelineStepHttp) Equals(iOther PipelineStep) bool {
 		utils.PtrEqual(p.CaCertPem, other.CaCertPem) &&
 		utils.BoolPtrEqual(p.Insecure, other.Insecure) &&
 		utils.PtrEqual(p.RequestBody, other.RequestBody) &&
-		reflect.DeepEqual(p.RequestHeaders, other.RequestHeaders)
+		reflect.DeepEqual(p.RequestHeaders, other.RequestHeaders) &&
+		p.Pagination.Equals(other.Pagination) &&
+		p.OAuth2.Equals(other.OAuth2) &&
+		utils.BoolPtrEqual(p.ResponseToFile, other.ResponseToFile) &&
+		utils.PtrEqual(p.RequestBodyFile, other.RequestBodyFile) &&
+		reflect.DeepEqual(p.FormFields, other.FormFields) &&
+		reflect.DeepEqual(p.FormFiles, other.FormFiles)
 }
 
 func (p *PipelineStepHttp) GetInputs(evalContext *hcl.EvalContext) (map[string]interface{}, error) {
@@ -139,6 +162,23 @@ func (p *PipelineStepHttp) GetInputs2(evalContext *hcl.EvalContext) (map[string]
 	results[schema.AttributeTypeRequestHeaders] = requestHeadersValue
 	allConnectionDependencies = append(allConnectionDependencies, connectionDependencies...)
 
+	// response_to_file, request_body_file, form_fields and form_files
+	for name, field := range map[string]any{
+		AttributeTypeResponseToFile:  p.ResponseToFile,
+		AttributeTypeRequestBodyFile: p.RequestBodyFile,
+		AttributeTypeFormFields:      p.FormFields,
+		AttributeTypeFormFiles:       p.FormFiles,
+	} {
+		value, connectionDependencies, diags := decodeStepAttribute(p.UnresolvedAttributes, evalContext, p.Name, name, field)
+		if len(diags) > 0 {
+			return nil, nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+		}
+		if value != nil {
+			results[name] = value
+		}
+		allConnectionDependencies = append(allConnectionDependencies, connectionDependencies...)
+	}
+
 	if p.BasicAuthConfig != nil {
 		basicAuth, diags := p.BasicAuthConfig.GetInputs(evalContext, p.UnresolvedAttributes)
 		if diags.HasErrors() {
@@ -149,6 +189,24 @@ func (p *PipelineStepHttp) GetInputs2(evalContext *hcl.EvalContext) (map[string]
 		basicAuthMap["Password"] = basicAuth.Password
 		results[schema.BlockTypePipelineBasicAuth] = basicAuthMap
 	}
+
+	if p.Pagination != nil {
+		pagination, connectionDependencies, diags := p.Pagination.GetInputs(evalContext, p.UnresolvedAttributes, p.Name)
+		if len(diags) > 0 {
+			return nil, nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+		}
+		results[BlockTypePagination] = pagination
+		allConnectionDependencies = append(allConnectionDependencies, connectionDependencies...)
+	}
+
+	if p.OAuth2 != nil {
+		oauth2, connectionDependencies, diags := p.OAuth2.GetInputs(evalContext, p.UnresolvedAttributes, p.Name)
+		if len(diags) > 0 {
+			return nil, nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+		}
+		results[BlockTypeOAuth2] = oauth2
+		allConnectionDependencies = append(allConnectionDependencies, connectionDependencies...)
+	}
 	results[schema.AttributeTypeStepName] = p.Name
 
 	return results, allConnectionDependencies, nil
@@ -241,6 +299,46 @@ func (p *PipelineStepHttp) SetAttributes(hclAttributes hcl.Attributes, evalConte
 					continue
 				}
 			}
+
+		case AttributeTypeResponseToFile:
+			stepDiags := setBoolAttribute(attr, evalContext, p, "ResponseToFile", true)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+		case AttributeTypeRequestBodyFile:
+			stepDiags := setStringAttribute(attr, evalContext, p, "RequestBodyFile", true)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+		case AttributeTypeFormFields, AttributeTypeFormFiles:
+			val, stepDiags := dependsOnFromExpressions(attr, evalContext, p)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+			if val != cty.NilVal {
+				form, err := hclhelpers.CtyToGoMapString(val)
+				if err != nil {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Unable to parse " + name + " attribute to a map of strings",
+						Subject:  &attr.Range,
+					})
+					continue
+				}
+
+				if name == AttributeTypeFormFields {
+					p.FormFields = form
+				} else {
+					p.FormFiles = form
+				}
+			}
+
 		default:
 			if !p.IsBaseAttribute(name) {
 				diags = append(diags, &hcl.Diagnostic{
@@ -323,11 +421,74 @@ func (p *PipelineStepHttp) SetBlockConfig(blocks hcl.Blocks, evalContext *hcl.Ev
 		p.BasicAuthConfig = basicAuthConfig
 	}
 
+	if paginationBlocks := blocks.ByType()[BlockTypePagination]; len(paginationBlocks) > 0 {
+		if len(paginationBlocks) > 1 {
+			return hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Multiple pagination blocks found for step http",
+				Subject:  &paginationBlocks[1].DefRange,
+			}}
+		}
+
+		diags = append(diags, p.setPaginationConfig(paginationBlocks[0], evalContext)...)
+	}
+
+	if oauth2Blocks := blocks.ByType()[BlockTypeOAuth2]; len(oauth2Blocks) > 0 {
+		if len(oauth2Blocks) > 1 {
+			return hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Multiple oauth2 blocks found for step http",
+				Subject:  &oauth2Blocks[1].DefRange,
+			}}
+		}
+
+		if p.BasicAuthConfig != nil {
+			return hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Step http should have either basic_auth or oauth2 but not both",
+				Subject:  &oauth2Blocks[0].DefRange,
+			}}
+		}
+
+		diags = append(diags, p.setOAuth2Config(oauth2Blocks[0], evalContext)...)
+	}
+
 	return diags
 }
 
 func (p *PipelineStepHttp) Validate() hcl.Diagnostics {
 	diags := p.ValidateBaseAttributes()
+
+	// the request body is either the body, the body file or the form
+	isSet := func(name string, set bool) bool {
+		return set || p.UnresolvedAttributes[name] != nil
+	}
+	bodies := 0
+	for _, set := range []bool{
+		isSet(schema.AttributeTypeRequestBody, p.RequestBody != nil),
+		isSet(AttributeTypeRequestBodyFile, p.RequestBodyFile != nil),
+		isSet(AttributeTypeFormFields, p.FormFields != nil) || isSet(AttributeTypeFormFiles, p.FormFiles != nil),
+	} {
+		if set {
+			bodies++
+		}
+	}
+	if bodies > 1 {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Step http should have only one of request_body, request_body_file or form_fields and form_files",
+			Subject:  p.Range,
+		})
+	}
+
+	if p.Pagination != nil && isSet(AttributeTypeResponseToFile, utils.Deref(p.ResponseToFile, false)) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Step http can't write the response to a file with pagination",
+			Subject:  p.Range,
+		})
+	}
+
 	return diags
 }
 
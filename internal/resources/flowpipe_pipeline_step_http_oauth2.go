@@ -0,0 +1,153 @@
+package resources
+
+import (
+	"slices"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	BlockTypeOAuth2 = "oauth2"
+
+	AttributeTypeTokenUrl     = "token_url"
+	AttributeTypeClientId     = "client_id"
+	AttributeTypeClientSecret = "client_secret"
+	AttributeTypeScopes       = "scopes"
+)
+
+var oauth2Attributes = []string{AttributeTypeTokenUrl, AttributeTypeClientId, AttributeTypeClientSecret, AttributeTypeScopes}
+
+// HttpOAuth2Config authenticates the http step with an OAuth 2.0 access token, requested from the token URL with the
+// client credentials grant. The client id and secret are usually read from a credential, e.g.
+// credential.azure.default.client_id.
+//
+// The attributes that can't be resolved when the pipeline is loaded, which includes the credentials, are kept in the
+// unresolved attributes of the step, prefixed with "oauth2.".
+type HttpOAuth2Config struct {
+	TokenUrl     *string  `json:"token_url"`
+	ClientId     *string  `json:"client_id"`
+	ClientSecret *string  `json:"client_secret"`
+	Scopes       []string `json:"scopes,omitempty"`
+}
+
+func (c *HttpOAuth2Config) Equals(other *HttpOAuth2Config) bool {
+	if c == nil || other == nil {
+		return c == nil && other == nil
+	}
+
+	return utils.PtrEqual(c.TokenUrl, other.TokenUrl) &&
+		utils.PtrEqual(c.ClientId, other.ClientId) &&
+		utils.PtrEqual(c.ClientSecret, other.ClientSecret) &&
+		slices.Equal(c.Scopes, other.Scopes)
+}
+
+func (c *HttpOAuth2Config) fields() map[string]any {
+	return map[string]any{
+		AttributeTypeTokenUrl:     c.TokenUrl,
+		AttributeTypeClientId:     c.ClientId,
+		AttributeTypeClientSecret: c.ClientSecret,
+		AttributeTypeScopes:       c.Scopes,
+	}
+}
+
+// GetInputs returns the oauth2 input of the http primitive, keyed by attribute name
+func (c *HttpOAuth2Config) GetInputs(evalContext *hcl.EvalContext, unresolvedAttributes map[string]hcl.Expression, stepName string) (map[string]any, []ConnectionDependency, hcl.Diagnostics) {
+	results := map[string]any{}
+	var allConnectionDependencies []ConnectionDependency
+
+	for name, field := range c.fields() {
+		value, connectionDependencies, diags := decodeStepAttribute(unresolvedAttributes, evalContext, stepName, BlockTypeOAuth2+"."+name, field)
+		if len(diags) > 0 {
+			return nil, nil, diags
+		}
+		allConnectionDependencies = append(allConnectionDependencies, connectionDependencies...)
+
+		if value != nil {
+			results[name] = value
+		}
+	}
+
+	return results, allConnectionDependencies, nil
+}
+
+// setOAuth2Config reads the oauth2 block of the http step
+func (p *PipelineStepHttp) setOAuth2Config(block *hcl.Block, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	attributes, diags := block.Body.JustAttributes()
+	if len(diags) > 0 {
+		return diags
+	}
+
+	config := &HttpOAuth2Config{}
+
+	for name, attr := range attributes {
+		if !slices.Contains(oauth2Attributes, name) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unsupported attribute for oauth2: " + name,
+				Subject:  &attr.Range,
+			})
+			continue
+		}
+
+		// the attribute is resolved at runtime if it references a credential, a param or another step
+		prefixedAttr := *attr
+		prefixedAttr.Name = BlockTypeOAuth2 + "." + name
+		val, stepDiags := dependsOnFromExpressions(&prefixedAttr, evalContext, p)
+		if len(stepDiags) > 0 {
+			diags = append(diags, stepDiags...)
+			continue
+		}
+		if val == cty.NilVal {
+			continue
+		}
+
+		if name == AttributeTypeScopes {
+			scopes, err := hclhelpers.CtyToGoStringSlice(val, val.Type())
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unable to parse oauth2 " + name + " attribute to a list of strings",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+			config.Scopes = scopes
+			continue
+		}
+
+		s, err := hclhelpers.CtyToString(val)
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unable to parse oauth2 " + name + " attribute to string",
+				Subject:  &attr.Range,
+			})
+			continue
+		}
+
+		switch name {
+		case AttributeTypeTokenUrl:
+			config.TokenUrl = &s
+		case AttributeTypeClientId:
+			config.ClientId = &s
+		case AttributeTypeClientSecret:
+			config.ClientSecret = &s
+		}
+	}
+
+	for _, required := range []string{AttributeTypeTokenUrl, AttributeTypeClientId, AttributeTypeClientSecret} {
+		if attributes[required] == nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "The oauth2 block must define the " + required + " attribute",
+				Subject:  &block.DefRange,
+			})
+		}
+	}
+
+	p.OAuth2 = config
+	return diags
+}
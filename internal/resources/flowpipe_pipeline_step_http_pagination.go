@@ -0,0 +1,220 @@
+package resources
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	BlockTypePagination = "pagination"
+
+	AttributeTypeCursor      = "cursor"
+	AttributeTypeCursorParam = "cursor_param"
+	AttributeTypePageParam   = "page_param"
+	AttributeTypeLimitParam  = "limit_param"
+	AttributeTypePageSize    = "page_size"
+	AttributeTypeItems       = "items"
+	AttributeTypeMaxPages    = "max_pages"
+
+	// The next page is the rel="next" URL of the Link header
+	PaginationTypeLink = "link"
+	// The cursor of the next page is read from the response body and sent as a query parameter
+	PaginationTypeCursor = "cursor"
+	// The offset of the next page, in items, is sent as a query parameter
+	PaginationTypeOffset = "offset"
+	// The number of the next page, starting at 1, is sent as a query parameter
+	PaginationTypePage = "page"
+
+	DefaultHttpMaxPages = 100
+)
+
+var validPaginationTypes = []string{PaginationTypeLink, PaginationTypeCursor, PaginationTypeOffset, PaginationTypePage}
+
+var paginationStringAttributes = []string{schema.AttributeTypeType, AttributeTypeCursor, AttributeTypeCursorParam, AttributeTypePageParam, AttributeTypeLimitParam, AttributeTypeItems}
+
+var paginationNumberAttributes = []string{AttributeTypePageSize, AttributeTypeMaxPages}
+
+// HttpPaginationConfig makes the http step follow the pages of a paginated API. The cursor and items are JSONPath
+// expressions on the response body, e.g. $.meta.next_cursor. The offset and page styles stop at the first page with
+// fewer items than page_size, or no items: the items of a page are the list at the items path, or the body if it's a
+// list. At most max_pages pages are requested, DefaultHttpMaxPages if not set.
+//
+// The attributes that can't be resolved when the pipeline is loaded are kept in the unresolved attributes of the step,
+// prefixed with "pagination.".
+type HttpPaginationConfig struct {
+	Type        *string `json:"type"`
+	Cursor      *string `json:"cursor,omitempty"`
+	CursorParam *string `json:"cursor_param,omitempty"`
+	PageParam   *string `json:"page_param,omitempty"`
+	LimitParam  *string `json:"limit_param,omitempty"`
+	PageSize    *int64  `json:"page_size,omitempty"`
+	Items       *string `json:"items,omitempty"`
+	MaxPages    *int64  `json:"max_pages,omitempty"`
+}
+
+func (c *HttpPaginationConfig) Equals(other *HttpPaginationConfig) bool {
+	if c == nil || other == nil {
+		return c == nil && other == nil
+	}
+
+	return utils.PtrEqual(c.Type, other.Type) &&
+		utils.PtrEqual(c.Cursor, other.Cursor) &&
+		utils.PtrEqual(c.CursorParam, other.CursorParam) &&
+		utils.PtrEqual(c.PageParam, other.PageParam) &&
+		utils.PtrEqual(c.LimitParam, other.LimitParam) &&
+		utils.PtrEqual(c.PageSize, other.PageSize) &&
+		utils.PtrEqual(c.Items, other.Items) &&
+		utils.PtrEqual(c.MaxPages, other.MaxPages)
+}
+
+func (c *HttpPaginationConfig) fields() map[string]any {
+	return map[string]any{
+		schema.AttributeTypeType: c.Type,
+		AttributeTypeCursor:      c.Cursor,
+		AttributeTypeCursorParam: c.CursorParam,
+		AttributeTypePageParam:   c.PageParam,
+		AttributeTypeLimitParam:  c.LimitParam,
+		AttributeTypePageSize:    c.PageSize,
+		AttributeTypeItems:       c.Items,
+		AttributeTypeMaxPages:    c.MaxPages,
+	}
+}
+
+// GetInputs returns the pagination input of the http primitive, keyed by attribute name
+func (c *HttpPaginationConfig) GetInputs(evalContext *hcl.EvalContext, unresolvedAttributes map[string]hcl.Expression, stepName string) (map[string]any, []ConnectionDependency, hcl.Diagnostics) {
+	results := map[string]any{}
+	var allConnectionDependencies []ConnectionDependency
+
+	for name, field := range c.fields() {
+		value, connectionDependencies, diags := decodeStepAttribute(unresolvedAttributes, evalContext, stepName, BlockTypePagination+"."+name, field)
+		if len(diags) > 0 {
+			return nil, nil, diags
+		}
+		allConnectionDependencies = append(allConnectionDependencies, connectionDependencies...)
+
+		if value != nil {
+			results[name] = value
+		}
+	}
+
+	return results, allConnectionDependencies, nil
+}
+
+// setPaginationConfig reads the pagination block of the http step
+func (p *PipelineStepHttp) setPaginationConfig(block *hcl.Block, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	attributes, diags := block.Body.JustAttributes()
+	if len(diags) > 0 {
+		return diags
+	}
+
+	config := &HttpPaginationConfig{}
+
+	for name, attr := range attributes {
+		isString := slices.Contains(paginationStringAttributes, name)
+		if !isString && !slices.Contains(paginationNumberAttributes, name) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unsupported attribute for pagination: " + name,
+				Subject:  &attr.Range,
+			})
+			continue
+		}
+
+		// the attribute is resolved at runtime if it references a param or another step
+		prefixedAttr := *attr
+		prefixedAttr.Name = BlockTypePagination + "." + name
+		val, stepDiags := dependsOnFromExpressions(&prefixedAttr, evalContext, p)
+		if len(stepDiags) > 0 {
+			diags = append(diags, stepDiags...)
+			continue
+		}
+		if val == cty.NilVal {
+			continue
+		}
+
+		if isString {
+			s, err := hclhelpers.CtyToString(val)
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unable to parse pagination " + name + " attribute to string",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			switch name {
+			case schema.AttributeTypeType:
+				if !slices.Contains(validPaginationTypes, s) {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid pagination type: " + s + ". Specify one of " + strings.Join(validPaginationTypes, ", "),
+						Subject:  &attr.Range,
+					})
+					continue
+				}
+				config.Type = &s
+			case AttributeTypeCursor:
+				config.Cursor = &s
+			case AttributeTypeCursorParam:
+				config.CursorParam = &s
+			case AttributeTypePageParam:
+				config.PageParam = &s
+			case AttributeTypeLimitParam:
+				config.LimitParam = &s
+			case AttributeTypeItems:
+				config.Items = &s
+			}
+			continue
+		}
+
+		i, ctyDiags := hclhelpers.CtyToInt64(val)
+		if ctyDiags.HasErrors() || i == nil || *i < 1 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "The pagination " + name + " attribute must be a number greater than 0",
+				Subject:  &attr.Range,
+			})
+			continue
+		}
+
+		if name == AttributeTypePageSize {
+			config.PageSize = i
+		} else {
+			config.MaxPages = i
+		}
+	}
+
+	if _, ok := p.UnresolvedAttributes[BlockTypePagination+"."+schema.AttributeTypeType]; !ok && config.Type == nil && !diags.HasErrors() {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "The pagination block must define a type: " + strings.Join(validPaginationTypes, ", "),
+			Subject:  &block.DefRange,
+		})
+	}
+
+	if utils.Deref(config.Type, "") == PaginationTypeCursor && config.Cursor == nil && p.UnresolvedAttributes[BlockTypePagination+"."+AttributeTypeCursor] == nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "The cursor pagination must define the " + AttributeTypeCursor + " attribute, the JSONPath of the next cursor in the response body",
+			Subject:  &block.DefRange,
+		})
+	}
+
+	if utils.Deref(config.Type, "") == PaginationTypeOffset && config.PageSize == nil && p.UnresolvedAttributes[BlockTypePagination+"."+AttributeTypePageSize] == nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "The offset pagination must define the " + AttributeTypePageSize + " attribute",
+			Subject:  &block.DefRange,
+		})
+	}
+
+	p.Pagination = config
+	return diags
+}
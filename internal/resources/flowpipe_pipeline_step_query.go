@@ -2,6 +2,8 @@ package resources
 
 import (
 	"fmt"
+	"reflect"
+
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/turbot/go-kit/helpers"
@@ -16,12 +18,25 @@ import (
 	"log/slog"
 )
 
+const (
+	AttributeTypeTransaction = "transaction"
+	AttributeTypeStatements  = "statements"
+	AttributeTypeBatch       = "batch"
+)
+
 type PipelineStepQuery struct {
 	PipelineStepBase
 	Database         *string
 	ConnectionString *string       `json:"database"`
 	Sql              *string       `json:"sql"`
 	Args             []interface{} `json:"args"`
+
+	// The statements are run in turn, each one is either the SQL or an object with the sql and the args
+	Statements []interface{} `json:"statements,omitempty"`
+	// The sql is run once per arg tuple of the batch, e.g. for bulk inserts
+	Batch []interface{} `json:"batch,omitempty"`
+	// The statements or the batch are run in a single transaction, rolled back if one of them fails
+	Transaction *bool `json:"transaction,omitempty"`
 }
 
 func (p *PipelineStepQuery) Equals(iOther PipelineStep) bool {
@@ -53,7 +68,10 @@ func (p *PipelineStepQuery) Equals(iOther PipelineStep) bool {
 	}
 
 	return utils.PtrEqual(p.Database, other.Database) &&
-		utils.PtrEqual(p.Sql, other.Sql)
+		utils.PtrEqual(p.Sql, other.Sql) &&
+		reflect.DeepEqual(p.Statements, other.Statements) &&
+		reflect.DeepEqual(p.Batch, other.Batch) &&
+		utils.BoolPtrEqual(p.Transaction, other.Transaction)
 }
 
 func (p *PipelineStepQuery) GetInputs2(evalContext *hcl.EvalContext) (map[string]interface{}, []ConnectionDependency, error) {
@@ -126,6 +144,22 @@ func (p *PipelineStepQuery) GetInputs2(evalContext *hcl.EvalContext) (map[string
 	results[schema.AttributeTypeArgs] = argsValue
 	allConnnectionDependencies = append(allConnnectionDependencies, connectionDependencies...)
 
+	// statements, batch and transaction
+	for name, field := range map[string]any{
+		AttributeTypeStatements:  p.Statements,
+		AttributeTypeBatch:       p.Batch,
+		AttributeTypeTransaction: p.Transaction,
+	} {
+		value, connectionDependencies, diags := decodeStepAttribute(p.UnresolvedAttributes, evalContext, p.Name, name, field)
+		if len(diags) > 0 {
+			return nil, nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+		}
+		if value != nil {
+			results[name] = value
+		}
+		allConnnectionDependencies = append(allConnnectionDependencies, connectionDependencies...)
+	}
+
 	// if p.UnresolvedAttributes[schema.AttributeTypeArgs] != nil {
 	// 	var args cty.Value
 	// 	diags := gohcl.DecodeExpression(p.UnresolvedAttributes[schema.AttributeTypeArgs], evalContext, &args)
@@ -184,6 +218,51 @@ func (p *PipelineStepQuery) SetAttributes(hclAttributes hcl.Attributes, evalCont
 				p.Args = goVals
 			}
 
+		case AttributeTypeStatements, AttributeTypeBatch:
+			val, stepDiags := dependsOnFromExpressions(attr, evalContext, p)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+			if val != cty.NilVal {
+				// the elements are of different types, e.g. the statements mix strings and objects
+				goVal, err := hclhelpers.CtyToGo(val)
+				goVals, ok := goVal.([]interface{})
+				if err != nil || !ok {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Unable to parse '" + name + "' attribute to a list",
+						Subject:  &attr.Range,
+					})
+					continue
+				}
+
+				var err2 error
+				if name == AttributeTypeStatements {
+					err2 = ValidateQueryStatements(goVals)
+					p.Statements = goVals
+				} else {
+					err2 = ValidateQueryBatch(goVals)
+					p.Batch = goVals
+				}
+				if err2 != nil {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid '" + name + "' attribute: " + err2.Error(),
+						Subject:  &attr.Range,
+					})
+					continue
+				}
+			}
+
+		case AttributeTypeTransaction:
+			stepDiags := setBoolAttribute(attr, evalContext, p, "Transaction", true)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
 		default:
 			if !p.IsBaseAttribute(name) {
 				diags = append(diags, &hcl.Diagnostic{
@@ -201,5 +280,79 @@ func (p *PipelineStepQuery) SetAttributes(hclAttributes hcl.Attributes, evalCont
 func (p *PipelineStepQuery) Validate() hcl.Diagnostics {
 	// validate the base attributes
 	diags := p.ValidateBaseAttributes()
+
+	isSet := func(name string, set bool) bool {
+		return set || p.UnresolvedAttributes[name] != nil
+	}
+	hasSql := isSet(schema.AttributeTypeSql, p.Sql != nil)
+	hasStatements := isSet(AttributeTypeStatements, p.Statements != nil)
+	hasBatch := isSet(AttributeTypeBatch, p.Batch != nil)
+
+	var summary string
+	switch {
+	case hasSql && hasStatements:
+		summary = "Query step should have either sql or statements but not both"
+	case !hasSql && !hasStatements:
+		summary = "Query step must define sql or statements"
+	case hasBatch && !hasSql:
+		summary = "Query step with a batch must define the sql to run for each arg tuple"
+	case hasBatch && isSet(schema.AttributeTypeArgs, p.Args != nil):
+		summary = "Query step should have either args or batch but not both"
+	case hasStatements && isSet(schema.AttributeTypeArgs, p.Args != nil):
+		summary = "Query step with statements should set the args of each statement"
+	}
+	if summary != "" {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  summary,
+			Subject:  p.Range,
+		})
+	}
+
 	return diags
 }
+
+// ValidateQueryStatements checks the statements of a query, each statement is either the SQL or an object with the
+// sql and the args
+func ValidateQueryStatements(statements []interface{}) error {
+	if len(statements) == 0 {
+		return perr.BadRequestWithMessage("the statements must not be empty")
+	}
+
+	for i, statement := range statements {
+		switch s := statement.(type) {
+		case string:
+			continue
+		case map[string]interface{}:
+			if _, ok := s[schema.AttributeTypeSql].(string); !ok {
+				return perr.BadRequestWithMessage(fmt.Sprintf("statement %d must define the sql", i+1))
+			}
+			for k, v := range s {
+				switch k {
+				case schema.AttributeTypeSql:
+				case schema.AttributeTypeArgs:
+					if _, ok := v.([]interface{}); !ok {
+						return perr.BadRequestWithMessage(fmt.Sprintf("the args of statement %d must be a list", i+1))
+					}
+				default:
+					return perr.BadRequestWithMessage(fmt.Sprintf("unsupported attribute of statement %d: %s", i+1, k))
+				}
+			}
+		default:
+			return perr.BadRequestWithMessage(fmt.Sprintf("statement %d must be a string or an object with the sql and the args", i+1))
+		}
+	}
+
+	return nil
+}
+
+// ValidateQueryBatch checks the batch of a query is a list of arg tuples
+func ValidateQueryBatch(batch []interface{}) error {
+	for i, args := range batch {
+		if _, ok := args.([]interface{}); !ok {
+			return perr.BadRequestWithMessage(fmt.Sprintf("the arg tuple %d of the batch must be a list", i+1))
+		}
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
@@ -275,11 +276,28 @@ type TriggerConfig interface {
 }
 
 type TriggerSchedule struct {
-	Schedule             string                    `json:"schedule"`
+	Schedule      string `json:"schedule"`
+	MisfirePolicy string `json:"misfire_policy,omitempty"`
+
+	ScheduleOptions
+
 	UnresolvedAttributes map[string]hcl.Expression `json:"-"`
 	ConnectionDependsOn  []string                  `json:"connection_depends_on,omitempty"`
 }
 
+func (t *TriggerSchedule) GetSchedule() string {
+	return t.Schedule
+}
+
+// GetMisfirePolicy returns how the occurrences missed while the scheduler was not running are handled, skipped unless
+// the trigger says otherwise
+func (t *TriggerSchedule) GetMisfirePolicy() string {
+	if t.MisfirePolicy == "" {
+		return MisfirePolicySkip
+	}
+	return t.MisfirePolicy
+}
+
 func (t *TriggerSchedule) GetConfig(evalContext *hcl.EvalContext, mod *modconfig.Mod) (TriggerConfig, error) {
 	return t, nil
 }
@@ -352,7 +370,7 @@ func (t *TriggerSchedule) Equals(other TriggerConfig) bool {
 		}
 	}
 
-	return t.Schedule == otherTrigger.Schedule
+	return t.Schedule == otherTrigger.Schedule && t.MisfirePolicy == otherTrigger.MisfirePolicy && t.ScheduleOptions.Equals(&otherTrigger.ScheduleOptions)
 }
 
 func (t *TriggerSchedule) SetAttributes(mod *modconfig.Mod, trigger *Trigger, hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
@@ -397,7 +415,28 @@ func (t *TriggerSchedule) SetAttributes(mod *modconfig.Mod, trigger *Trigger, hc
 					Subject:  &attr.Range,
 				})
 			}
+		case AttributeTypeMisfirePolicy:
+			val, moreDiags := attr.Expr.Value(evalContext)
+			if moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			if val.Type() != cty.String || !slices.Contains(validMisfirePolicies, val.AsString()) {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "The " + AttributeTypeMisfirePolicy + " attribute must be one of: " + strings.Join(validMisfirePolicies, ", "),
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+			t.MisfirePolicy = val.AsString()
 		default:
+			if ok, moreDiags := t.setAttribute(mod, attr, evalContext); ok {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
 			if !trigger.IsBaseAttribute(name) {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
@@ -415,6 +454,18 @@ func (t *TriggerSchedule) SetBlocks(mod *modconfig.Mod, trigger *Trigger, hclBlo
 	return diags
 }
 
+const AttributeTypeMisfirePolicy = "misfire_policy"
+
+// The misfire policies of a schedule trigger: the occurrences missed while the scheduler was down are skipped, fired
+// once, or each fired in turn
+const (
+	MisfirePolicySkip     = "skip"
+	MisfirePolicyFireOnce = "fire_once"
+	MisfirePolicyFireAll  = "fire_all"
+)
+
+var validMisfirePolicies = []string{MisfirePolicySkip, MisfirePolicyFireOnce, MisfirePolicyFireAll}
+
 var validIntervals = []string{"hourly", "daily", "weekly", "5m", "10m", "15m", "30m", "60m", "1h", "2h", "4h", "6h", "12h", "24h"}
 
 type TriggerQuery struct {
@@ -424,6 +475,13 @@ type TriggerQuery struct {
 	PrimaryKey string                          `json:"primary_key"`
 	Captures   map[string]*TriggerQueryCapture `json:"captures"`
 
+	// WatermarkColumn switches the trigger to incremental mode: the greatest value of the column seen so far is bound as
+	// the only parameter of the sql, null on the first run, so the query only returns the new and changed rows. Deleted
+	// rows can't be detected in this mode.
+	WatermarkColumn string `json:"watermark_column,omitempty"`
+
+	ScheduleOptions
+
 	UnresolvedAttributes map[string]hcl.Expression `json:"-"`
 	ConnectionDependsOn  []string                  `json:"connection_depends_on,omitempty"`
 }
@@ -529,24 +587,36 @@ func (t *TriggerQuery) GetConfig(evalContext *hcl.EvalContext, mod *modconfig.Mo
 	}
 
 	newT := &TriggerQuery{
-		Sql:        sql,
-		Schedule:   schedule,
-		Database:   database,
-		PrimaryKey: primaryKey,
-		Captures:   make(map[string]*TriggerQueryCapture),
+		Sql:             sql,
+		Schedule:        schedule,
+		Database:        database,
+		PrimaryKey:      primaryKey,
+		WatermarkColumn: t.WatermarkColumn,
+		ScheduleOptions: t.ScheduleOptions,
+		Captures:        make(map[string]*TriggerQueryCapture),
 	}
 
 	for key, value := range t.Captures {
 		newT.Captures[key] = &TriggerQueryCapture{
-			Type:     value.Type,
-			Pipeline: value.Pipeline,
-			ArgsRaw:  value.ArgsRaw,
+			Type:          value.Type,
+			Pipeline:      value.Pipeline,
+			ArgsRaw:       value.ArgsRaw,
+			BatchSize:     value.BatchSize,
+			MaxRowsPerRun: value.MaxRowsPerRun,
 		}
 	}
 
 	return newT, nil
 }
 
+// GetSchedule returns the schedule of the query trigger, hourly if not set
+func (t *TriggerQuery) GetSchedule() string {
+	if t.Schedule == "" {
+		return "hourly"
+	}
+	return t.Schedule
+}
+
 func (t *TriggerQuery) Equals(other TriggerConfig) bool {
 	otherTrigger, ok := other.(*TriggerQuery)
 	if !ok {
@@ -569,6 +639,10 @@ func (t *TriggerQuery) Equals(other TriggerConfig) bool {
 		return false
 	}
 
+	if !t.ScheduleOptions.Equals(&otherTrigger.ScheduleOptions) {
+		return false
+	}
+
 	if t.Database != otherTrigger.Database {
 		return false
 	}
@@ -577,6 +651,10 @@ func (t *TriggerQuery) Equals(other TriggerConfig) bool {
 		return false
 	}
 
+	if t.WatermarkColumn != otherTrigger.WatermarkColumn {
+		return false
+	}
+
 	if len(t.Captures) != len(otherTrigger.Captures) {
 		return false
 	}
@@ -611,6 +689,17 @@ type TriggerQueryCapture struct {
 	Type     string
 	Pipeline cty.Value
 	ArgsRaw  hcl.Expression
+
+	// BatchSize splits the captured rows into pipeline runs of at most BatchSize rows, and MaxRowsPerRun defers the rows
+	// over the limit to the next trigger runs. The rows of a batched capture wait in the control database until their
+	// pipeline is queued.
+	BatchSize     int
+	MaxRowsPerRun int
+}
+
+// IsBatched returns true if the captured rows are delivered in batches rather than to a single pipeline run
+func (c *TriggerQueryCapture) IsBatched() bool {
+	return c.BatchSize > 0 || c.MaxRowsPerRun > 0
 }
 
 func (c *TriggerQueryCapture) Equals(other *TriggerQueryCapture) bool {
@@ -634,7 +723,7 @@ func (c *TriggerQueryCapture) Equals(other *TriggerQueryCapture) bool {
 		return false
 	}
 
-	return true
+	return c.BatchSize == other.BatchSize && c.MaxRowsPerRun == other.MaxRowsPerRun
 }
 
 func (t *TriggerQuery) SetAttributes(mod *modconfig.Mod, trigger *Trigger, hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
@@ -731,7 +820,29 @@ func (t *TriggerQuery) SetAttributes(mod *modconfig.Mod, trigger *Trigger, hclAt
 				t.PrimaryKey = primaryKey
 			}
 
+		case AttributeTypeWatermarkColumn:
+			val, moreDiags := attr.Expr.Value(evalContext)
+			if moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			if val.Type() != cty.String || val.AsString() == "" {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "The " + AttributeTypeWatermarkColumn + " attribute must be a column name",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+			t.WatermarkColumn = val.AsString()
+
 		default:
+			if ok, moreDiags := t.setAttribute(mod, attr, evalContext); ok {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
 			if !trigger.IsBaseAttribute(name) {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
@@ -745,6 +856,12 @@ func (t *TriggerQuery) SetAttributes(mod *modconfig.Mod, trigger *Trigger, hclAt
 	return diags
 }
 
+const (
+	AttributeTypeWatermarkColumn = "watermark_column"
+	AttributeTypeBatchSize       = "batch_size"
+	AttributeTypeMaxRowsPerRun   = "max_rows_per_run"
+)
+
 var validCaptureBlockTypes = []string{"insert", "update", "delete"}
 
 func (t *TriggerQuery) SetBlocks(mod *modconfig.Mod, trigger *Trigger, hclBlocks hcl.Blocks, evalContext *hcl.EvalContext) hcl.Diagnostics {
@@ -795,6 +912,16 @@ func (t *TriggerQuery) SetBlocks(mod *modconfig.Mod, trigger *Trigger, hclBlocks
 			continue
 		}
 
+		if captureBlockType == "delete" && t.WatermarkColumn != "" {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid capture block type",
+				Detail:   "The delete capture is not supported with " + AttributeTypeWatermarkColumn + ", the deleted rows are not returned by an incremental query",
+				Subject:  &captureBlock.DefRange,
+			})
+			continue
+		}
+
 		if t.Captures[captureBlockType] != nil {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
@@ -833,6 +960,35 @@ func (t *TriggerQuery) SetBlocks(mod *modconfig.Mod, trigger *Trigger, hclBlocks
 			}
 		}
 
+		for _, name := range []string{AttributeTypeBatchSize, AttributeTypeMaxRowsPerRun} {
+			attr, exists := hclAttributes[name]
+			if !exists {
+				continue
+			}
+
+			val, moreDiags := attr.Expr.Value(evalContext)
+			if moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			i, ctyDiags := hclhelpers.CtyToInt64(val)
+			if ctyDiags.HasErrors() || i == nil || *i < 1 {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "The " + name + " attribute must be a number greater than 0",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			if name == AttributeTypeBatchSize {
+				triggerCapture.BatchSize = int(*i)
+			} else {
+				triggerCapture.MaxRowsPerRun = int(*i)
+			}
+		}
+
 		t.Captures[captureBlockType] = triggerCapture
 	}
 
@@ -865,6 +1021,7 @@ type TriggerHttp struct {
 	Url           string                        `json:"url"`
 	ExecutionMode string                        `json:"execution_mode"`
 	Methods       map[string]*TriggerHTTPMethod `json:"methods"`
+	Auth          []*TriggerHttpAuth            `json:"auth,omitempty"`
 
 	UnresolvedAttributes map[string]hcl.Expression `json:"-"`
 	ConnectionDependsOn  []string                  `json:"connection_depends_on,omitempty"`
@@ -965,6 +1122,16 @@ func (t *TriggerHttp) Equals(other TriggerConfig) bool {
 		}
 	}
 
+	if len(t.Auth) != len(otherTrigger.Auth) {
+		return false
+	}
+
+	for i, auth := range t.Auth {
+		if !auth.Equals(otherTrigger.Auth[i]) {
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -973,6 +1140,11 @@ type TriggerHTTPMethod struct {
 	ExecutionMode string
 	Pipeline      cty.Value
 	ArgsRaw       hcl.Expression
+
+	// IdempotencyKeyRaw is evaluated against the request, the deliveries with the same key within IdempotencyTtl run the
+	// pipeline once
+	IdempotencyKeyRaw hcl.Expression
+	IdempotencyTtl    time.Duration
 }
 
 func (c *TriggerHTTPMethod) Equals(other *TriggerHTTPMethod) bool {
@@ -996,9 +1168,20 @@ func (c *TriggerHTTPMethod) Equals(other *TriggerHTTPMethod) bool {
 		return false
 	}
 
+	if !reflect.DeepEqual(c.IdempotencyKeyRaw, other.IdempotencyKeyRaw) || c.IdempotencyTtl != other.IdempotencyTtl {
+		return false
+	}
+
 	return true
 }
 
+const (
+	AttributeTypeIdempotencyKey = "idempotency_key"
+	AttributeTypeIdempotencyTtl = "idempotency_ttl"
+
+	DefaultIdempotencyTtl = 24 * time.Hour
+)
+
 var validExecutionMode = []string{"synchronous", "asynchronous"}
 var validMethodBlockTypes = []string{"post", "get"}
 
@@ -1059,9 +1242,25 @@ func (t *TriggerHttp) SetBlocks(mod *modconfig.Mod, trigger *Trigger, hclBlocks
 	diags := hcl.Diagnostics{}
 
 	t.Methods = make(map[string]*TriggerHTTPMethod)
+	t.Auth = nil
+
+	var methodBlocks hcl.Blocks
+	for _, block := range hclBlocks {
+		switch block.Type {
+		case schema.BlockTypeMethod:
+			methodBlocks = append(methodBlocks, block)
+		case BlockTypeTriggerHttpAuth:
+			auth, moreDiags := decodeTriggerHttpAuth(t, block, evalContext)
+			if moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+			t.Auth = append(t.Auth, auth)
+		}
+	}
 
 	// If no method blocks appear, only 'post' is supported, and the top-level `pipeline`, `args` and `execution_mode` will be applied
-	if len(hclBlocks) == 0 {
+	if len(methodBlocks) == 0 {
 		triggerMethod := &TriggerHTTPMethod{
 			Type: HttpMethodPost,
 		}
@@ -1095,7 +1294,7 @@ func (t *TriggerHttp) SetBlocks(mod *modconfig.Mod, trigger *Trigger, hclBlocks
 	}
 
 	// If the method blocks provided, we will consider the configuration provided in the method block
-	for _, methodBlock := range hclBlocks {
+	for _, methodBlock := range methodBlocks {
 
 		if len(methodBlock.Labels) != 1 {
 			diags = append(diags, &hcl.Diagnostic{
@@ -1201,6 +1400,50 @@ func (t *TriggerHttp) SetBlocks(mod *modconfig.Mod, trigger *Trigger, hclBlocks
 			}
 		}
 
+		if attr, exists := hclAttributes[AttributeTypeIdempotencyKey]; exists {
+			triggerMethod.IdempotencyKeyRaw = attr.Expr
+			triggerMethod.IdempotencyTtl = DefaultIdempotencyTtl
+		}
+
+		if attr, exists := hclAttributes[AttributeTypeIdempotencyTtl]; exists {
+			if triggerMethod.IdempotencyKeyRaw == nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  AttributeTypeIdempotencyTtl + " requires " + AttributeTypeIdempotencyKey,
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			val, moreDiags := attr.Expr.Value(evalContext)
+			if moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			ttl, ctyErr := hclhelpers.CtyToString(val)
+			if ctyErr != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unable to parse " + AttributeTypeIdempotencyTtl + " attribute to string",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			duration, err := time.ParseDuration(ttl)
+			if err != nil || duration <= 0 {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid " + AttributeTypeIdempotencyTtl,
+					Detail:   "The " + AttributeTypeIdempotencyTtl + " must be a positive duration, for example 24h",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+			triggerMethod.IdempotencyTtl = duration
+		}
+
 		t.Methods[methodBlockType] = triggerMethod
 	}
 
@@ -1229,6 +1472,32 @@ func (c *TriggerHTTPMethod) GetArgs(evalContext *hcl.EvalContext) (Input, hcl.Di
 	return retVal, diags
 }
 
+// GetIdempotencyKey evaluates the idempotency key of the method against the request, an empty key if the method has
+// no idempotency key
+func (c *TriggerHTTPMethod) GetIdempotencyKey(evalContext *hcl.EvalContext) (string, hcl.Diagnostics) {
+	if c.IdempotencyKeyRaw == nil {
+		return "", hcl.Diagnostics{}
+	}
+
+	value, diags := c.IdempotencyKeyRaw.Value(evalContext)
+	if diags.HasErrors() {
+		return "", diags
+	}
+
+	if value.IsNull() {
+		return "", diags
+	}
+
+	key, err := hclhelpers.CtyToString(value)
+	if err != nil {
+		return "", hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unable to parse " + AttributeTypeIdempotencyKey + " Trigger attribute to string",
+		}}
+	}
+	return key, diags
+}
+
 func NewTrigger(block *hcl.Block, mod *modconfig.Mod, triggerType, triggerName string) *Trigger {
 
 	triggerFullName := triggerType + "." + triggerName
@@ -1266,6 +1535,14 @@ func NewTrigger(block *hcl.Block, mod *modconfig.Mod, triggerType, triggerName s
 		trigger.Config = &TriggerHttp{
 			UnresolvedAttributes: make(map[string]hcl.Expression),
 		}
+	case TriggerTypeQueue:
+		trigger.Config = &TriggerQueue{
+			UnresolvedAttributes: make(map[string]hcl.Expression),
+		}
+	case TriggerTypeFile:
+		trigger.Config = &TriggerFile{
+			UnresolvedAttributes: make(map[string]hcl.Expression),
+		}
 	default:
 		return nil
 	}
@@ -1282,6 +1559,10 @@ func GetTriggerTypeFromTriggerConfig(config TriggerConfig) string {
 		return schema.TriggerTypeQuery
 	case *TriggerHttp:
 		return schema.TriggerTypeHttp
+	case *TriggerQueue:
+		return TriggerTypeQueue
+	case *TriggerFile:
+		return TriggerTypeFile
 	}
 
 	return ""
@@ -0,0 +1,252 @@
+package resources
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	TriggerTypeFile = "file"
+
+	FileEventCreate = "create"
+	FileEventModify = "modify"
+	FileEventDelete = "delete"
+
+	AttributeTypePattern  = "pattern"
+	AttributeTypeEvents   = "events"
+	AttributeTypeDebounce = "debounce"
+
+	DefaultFilePattern  = "*"
+	DefaultFileDebounce = time.Second
+)
+
+var validFileEvents = []string{FileEventCreate, FileEventModify, FileEventDelete}
+
+// TriggerFile watches a directory and runs the pipeline when a file matching the pattern is created, modified or
+// deleted, with the file available to the args as self.path, self.event, self.name, self.size and self.mod_time.
+//
+// The events of a file are debounced: the pipeline runs once the file has been left alone for the debounce window, so
+// a file being written in several chunks only triggers one run.
+type TriggerFile struct {
+	Path     string        `json:"path"`
+	Pattern  string        `json:"pattern"`
+	Events   []string      `json:"events"`
+	Debounce time.Duration `json:"debounce"`
+
+	UnresolvedAttributes map[string]hcl.Expression `json:"-"`
+	ConnectionDependsOn  []string                  `json:"connection_depends_on,omitempty"`
+}
+
+func (t *TriggerFile) GetConfig(evalContext *hcl.EvalContext, mod *modconfig.Mod) (TriggerConfig, error) {
+	return t, nil
+}
+
+func (t *TriggerFile) AppendDependsOn(...string) {
+}
+
+func (t *TriggerFile) AppendCredentialDependsOn(...string) {
+}
+
+func (t *TriggerFile) AppendConnectionDependsOn(connectionDependsOn ...string) {
+	existingDeps := make(map[string]struct{}, len(t.ConnectionDependsOn))
+	for _, dep := range t.ConnectionDependsOn {
+		existingDeps[dep] = struct{}{}
+	}
+
+	for _, dep := range connectionDependsOn {
+		if _, exists := existingDeps[dep]; !exists {
+			t.ConnectionDependsOn = append(t.ConnectionDependsOn, dep)
+			existingDeps[dep] = struct{}{}
+		}
+	}
+}
+
+func (t *TriggerFile) GetConnectionDependsOn() []string {
+	return t.ConnectionDependsOn
+}
+
+func (t *TriggerFile) AddUnresolvedAttribute(key string, value hcl.Expression) {
+	t.UnresolvedAttributes[key] = value
+}
+
+func (t *TriggerFile) GetUnresolvedAttributes() map[string]hcl.Expression {
+	return t.UnresolvedAttributes
+}
+
+func (t *TriggerFile) GetType() string {
+	return TriggerTypeFile
+}
+
+// HasEvent returns true if the trigger fires on the given event
+func (t *TriggerFile) HasEvent(event string) bool {
+	return slices.Contains(t.Events, event)
+}
+
+func (t *TriggerFile) Equals(other TriggerConfig) bool {
+	otherTrigger, ok := other.(*TriggerFile)
+	if !ok {
+		return false
+	}
+
+	if t == nil && !helpers.IsNil(otherTrigger) || t != nil && helpers.IsNil(otherTrigger) {
+		return false
+	}
+
+	if t == nil && helpers.IsNil(otherTrigger) {
+		return true
+	}
+
+	if len(t.UnresolvedAttributes) != len(other.GetUnresolvedAttributes()) {
+		return false
+	}
+
+	for key, expr := range t.UnresolvedAttributes {
+		otherExpr, ok := other.GetUnresolvedAttributes()[key]
+		if !ok || !hclhelpers.ExpressionsEqual(expr, otherExpr) {
+			return false
+		}
+	}
+
+	return t.Path == otherTrigger.Path &&
+		t.Pattern == otherTrigger.Pattern &&
+		slices.Equal(t.Events, otherTrigger.Events) &&
+		t.Debounce == otherTrigger.Debounce
+}
+
+func (t *TriggerFile) SetAttributes(mod *modconfig.Mod, trigger *Trigger, hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	diags := trigger.SetBaseAttributes(mod, hclAttributes, evalContext)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	t.Pattern = DefaultFilePattern
+	t.Events = validFileEvents
+	t.Debounce = DefaultFileDebounce
+
+	for name, attr := range hclAttributes {
+		switch name {
+		case AttributeTypePath, AttributeTypePattern, AttributeTypeDebounce:
+			val, moreDiags := attr.Expr.Value(evalContext)
+			if moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			s, err := hclhelpers.CtyToString(val)
+			if err != nil || val.Type() != cty.String {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unable to parse " + name + " attribute to string",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			switch name {
+			case AttributeTypePath:
+				t.Path = s
+			case AttributeTypePattern:
+				if _, err := filepath.Match(s, ""); err != nil {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid " + AttributeTypePattern,
+						Detail:   "The " + AttributeTypePattern + " must be a valid glob, for example *.csv",
+						Subject:  &attr.Range,
+					})
+					continue
+				}
+				t.Pattern = s
+			case AttributeTypeDebounce:
+				duration, err := time.ParseDuration(s)
+				if err != nil || duration < 0 {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid " + AttributeTypeDebounce,
+						Detail:   "The " + AttributeTypeDebounce + " must be a duration, for example 5s",
+						Subject:  &attr.Range,
+					})
+					continue
+				}
+				t.Debounce = duration
+			}
+
+		case AttributeTypeEvents:
+			val, moreDiags := attr.Expr.Value(evalContext)
+			if moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			events, err := hclhelpers.CtyToGoStringSlice(val, val.Type())
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unable to parse " + AttributeTypeEvents + " attribute to a list of strings",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			t.Events = nil
+			for _, event := range events {
+				if !slices.Contains(validFileEvents, event) {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid " + AttributeTypeEvents,
+						Detail:   "The file events must be one of: " + strings.Join(validFileEvents, ","),
+						Subject:  &attr.Range,
+					})
+					break
+				}
+				if !slices.Contains(t.Events, event) {
+					t.Events = append(t.Events, event)
+				}
+			}
+
+		default:
+			if !trigger.IsBaseAttribute(name) {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unsupported attribute for Trigger File: " + attr.Name,
+					Subject:  &attr.Range,
+				})
+			}
+		}
+	}
+
+	if diags.HasErrors() {
+		return diags
+	}
+
+	if t.Path == "" {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid file trigger",
+			Detail:   "The file trigger requires a path",
+			Subject:  &trigger.DeclRange,
+		})
+	}
+
+	if len(t.Events) == 0 {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid file trigger",
+			Detail:   "The file trigger requires at least one event",
+			Subject:  &trigger.DeclRange,
+		})
+	}
+
+	return diags
+}
+
+func (t *TriggerFile) SetBlocks(mod *modconfig.Mod, trigger *Trigger, hclBlocks hcl.Blocks, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	return hcl.Diagnostics{}
+}
@@ -0,0 +1,308 @@
+package resources
+
+import (
+	"net/netip"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	BlockTypeTriggerHttpAuth = "auth"
+
+	HttpAuthTypeHmac        = "hmac"
+	HttpAuthTypeBearer      = "bearer"
+	HttpAuthTypeIpAllowList = "ip_allow_list"
+
+	HttpAuthSchemeGithub  = "github"
+	HttpAuthSchemeStripe  = "stripe"
+	HttpAuthSchemeGeneric = "generic"
+)
+
+var validHttpAuthTypes = []string{HttpAuthTypeHmac, HttpAuthTypeBearer, HttpAuthTypeIpAllowList}
+var validHttpAuthSchemes = []string{HttpAuthSchemeGithub, HttpAuthSchemeStripe, HttpAuthSchemeGeneric}
+var validHttpAuthAlgorithms = []string{"sha1", "sha256", "sha512"}
+var validHttpAuthEncodings = []string{"hex", "base64"}
+
+// TriggerHttpAuth authenticates the requests of an HTTP trigger, on top of the hash in the trigger URL. A trigger may
+// have several auth blocks, a request must pass all of them:
+//
+//	auth {
+//	  type   = "hmac"
+//	  scheme = "github"
+//	  secret = var.webhook_secret
+//	}
+//
+//	auth {
+//	  type        = "ip_allow_list"
+//	  allowed_ips = ["192.30.252.0/22"]
+//	}
+type TriggerHttpAuth struct {
+	Type string `json:"type"`
+
+	// hmac: the signature header and its format are set by the scheme, the generic scheme sets them with the header,
+	// algorithm, prefix and encoding attributes
+	Scheme    string `json:"scheme,omitempty"`
+	Header    string `json:"header,omitempty"`
+	Algorithm string `json:"algorithm,omitempty"`
+	Prefix    string `json:"prefix,omitempty"`
+	Encoding  string `json:"encoding,omitempty"`
+
+	// stripe: the maximum age of the signature timestamp, in seconds
+	Tolerance int `json:"tolerance,omitempty"`
+
+	// ip_allow_list: IP addresses or CIDR ranges
+	AllowedIPs []string `json:"allowed_ips,omitempty"`
+
+	// The secret of the hmac signature and the bearer token are resolved on each request, they may refer to variables
+	// and connections
+	SecretRaw hcl.Expression `json:"-"`
+	TokenRaw  hcl.Expression `json:"-"`
+}
+
+func (a *TriggerHttpAuth) Equals(other *TriggerHttpAuth) bool {
+	if a == nil && other == nil {
+		return true
+	}
+
+	if a == nil && other != nil || a != nil && other == nil {
+		return false
+	}
+
+	if a.Type != other.Type || a.Scheme != other.Scheme || a.Header != other.Header || a.Algorithm != other.Algorithm ||
+		a.Prefix != other.Prefix || a.Encoding != other.Encoding || a.Tolerance != other.Tolerance {
+		return false
+	}
+
+	if !slices.Equal(a.AllowedIPs, other.AllowedIPs) {
+		return false
+	}
+
+	return reflect.DeepEqual(a.SecretRaw, other.SecretRaw) && reflect.DeepEqual(a.TokenRaw, other.TokenRaw)
+}
+
+// AllowedPrefixes returns the allowed IPs as prefixes, a single IP is a prefix of its full length
+func (a *TriggerHttpAuth) AllowedPrefixes() ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, allowed := range a.AllowedIPs {
+		if strings.Contains(allowed, "/") {
+			prefix, err := netip.ParsePrefix(allowed)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(allowed)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+func decodeTriggerHttpAuth(t *TriggerHttp, authBlock *hcl.Block, evalContext *hcl.EvalContext) (*TriggerHttpAuth, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	hclAttributes, moreDiags := authBlock.Body.JustAttributes()
+	if moreDiags.HasErrors() {
+		return nil, moreDiags
+	}
+
+	auth := &TriggerHttpAuth{}
+
+	for name, attr := range hclAttributes {
+		switch name {
+		case "secret":
+			auth.SecretRaw = attr.Expr
+			t.AppendConnectionDependsOn(connectionDependsOnFromExpression(attr.Expr)...)
+		case schema.AttributeTypeToken:
+			auth.TokenRaw = attr.Expr
+			t.AppendConnectionDependsOn(connectionDependsOnFromExpression(attr.Expr)...)
+		case schema.AttributeTypeType, "scheme", "header", "algorithm", "prefix", "encoding":
+			val, moreDiags := attr.Expr.Value(evalContext)
+			if moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			s, err := hclhelpers.CtyToString(val)
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unable to parse " + name + " attribute to string",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			switch name {
+			case schema.AttributeTypeType:
+				auth.Type = s
+			case "scheme":
+				auth.Scheme = s
+			case "header":
+				auth.Header = s
+			case "algorithm":
+				auth.Algorithm = s
+			case "prefix":
+				auth.Prefix = s
+			case "encoding":
+				auth.Encoding = s
+			}
+		case "tolerance":
+			val, moreDiags := attr.Expr.Value(evalContext)
+			if moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			tolerance, ctyDiags := hclhelpers.CtyToInt64(val)
+			if ctyDiags.HasErrors() || tolerance == nil || *tolerance < 0 {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "The tolerance must be a positive number of seconds",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+			auth.Tolerance = int(*tolerance)
+		case "allowed_ips":
+			val, moreDiags := attr.Expr.Value(evalContext)
+			if moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			if val.IsNull() || !(val.Type().IsListType() || val.Type().IsTupleType() || val.Type().IsSetType()) {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "The allowed_ips attribute must be a list of IP addresses or CIDR ranges",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			for it := val.ElementIterator(); it.Next(); {
+				_, v := it.Element()
+				if v.IsNull() || v.Type() != cty.String {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "The allowed_ips attribute must be a list of IP addresses or CIDR ranges",
+						Subject:  &attr.Range,
+					})
+					break
+				}
+				auth.AllowedIPs = append(auth.AllowedIPs, v.AsString())
+			}
+
+			if _, err := auth.AllowedPrefixes(); err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid IP address or CIDR range in allowed_ips",
+					Detail:   err.Error(),
+					Subject:  &attr.Range,
+				})
+			}
+		default:
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unsupported attribute for auth block: " + name,
+				Subject:  &attr.Range,
+			})
+		}
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return auth, auth.validate(authBlock)
+}
+
+func (a *TriggerHttpAuth) validate(authBlock *hcl.Block) hcl.Diagnostics {
+	invalid := func(summary, detail string) hcl.Diagnostics {
+		return hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  summary,
+			Detail:   detail,
+			Subject:  &authBlock.DefRange,
+		}}
+	}
+
+	if !slices.Contains(validHttpAuthTypes, a.Type) {
+		return invalid("Invalid auth type", "The auth type must be one of: "+strings.Join(validHttpAuthTypes, ","))
+	}
+
+	switch a.Type {
+	case HttpAuthTypeHmac:
+		if a.Scheme == "" {
+			a.Scheme = HttpAuthSchemeGithub
+		}
+		if !slices.Contains(validHttpAuthSchemes, a.Scheme) {
+			return invalid("Invalid auth scheme", "The hmac auth scheme must be one of: "+strings.Join(validHttpAuthSchemes, ","))
+		}
+		if a.SecretRaw == nil {
+			return invalid("Missing secret", "The hmac auth requires a secret")
+		}
+
+		if a.Scheme == HttpAuthSchemeGeneric {
+			if a.Header == "" {
+				return invalid("Missing header", "The generic hmac auth requires the signature header")
+			}
+			if a.Algorithm == "" {
+				a.Algorithm = "sha256"
+			}
+			if a.Encoding == "" {
+				a.Encoding = "hex"
+			}
+			if !slices.Contains(validHttpAuthAlgorithms, a.Algorithm) {
+				return invalid("Invalid algorithm", "The hmac algorithm must be one of: "+strings.Join(validHttpAuthAlgorithms, ","))
+			}
+			if !slices.Contains(validHttpAuthEncodings, a.Encoding) {
+				return invalid("Invalid encoding", "The hmac encoding must be one of: "+strings.Join(validHttpAuthEncodings, ","))
+			}
+		} else if a.Header != "" || a.Algorithm != "" || a.Prefix != "" || a.Encoding != "" {
+			return invalid("Invalid auth attribute", "header, algorithm, prefix and encoding are only supported by the generic hmac scheme")
+		}
+
+		if a.Scheme == HttpAuthSchemeStripe && a.Tolerance == 0 {
+			a.Tolerance = 300
+		}
+	case HttpAuthTypeBearer:
+		if a.TokenRaw == nil {
+			return invalid("Missing token", "The bearer auth requires a token")
+		}
+	case HttpAuthTypeIpAllowList:
+		if len(a.AllowedIPs) == 0 {
+			return invalid("Missing allowed_ips", "The ip_allow_list auth requires allowed_ips")
+		}
+	}
+
+	return nil
+}
+
+// connectionDependsOnFromExpression returns the connections referred to by an expression, as <type>.<name>
+func connectionDependsOnFromExpression(expr hcl.Expression) []string {
+	var dependsOn []string
+	for _, traversal := range expr.Variables() {
+		parts := hclhelpers.TraversalAsStringSlice(traversal)
+		if len(parts) < 2 || parts[0] != schema.BlockTypeConnection {
+			continue
+		}
+
+		if len(parts) == 2 {
+			dependsOn = append(dependsOn, parts[1]+".<dynamic>")
+		} else {
+			dependsOn = append(dependsOn, parts[1]+"."+parts[2])
+		}
+	}
+	return dependsOn
+}
@@ -0,0 +1,258 @@
+package resources
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	TriggerTypeQueue = "queue"
+
+	QueueSourceDirectory = "directory"
+	QueueSourceNats      = "nats"
+
+	AttributeTypeSource        = "source"
+	AttributeTypePath          = "path"
+	AttributeTypeUrl           = "url"
+	AttributeTypeStream        = "stream"
+	AttributeTypeConsumer      = "consumer"
+	AttributeTypeConcurrency   = "concurrency"
+	AttributeTypeMaxDeliveries = "max_deliveries"
+	AttributeTypeDeadLetter    = "dead_letter"
+
+	DefaultQueueConcurrency   = 1
+	DefaultQueueMaxDeliveries = 5
+)
+
+var validQueueSources = []string{QueueSourceDirectory, QueueSourceNats}
+
+// TriggerQueue consumes the messages of a queue and runs the pipeline once per message, with the message available to
+// the args as self.message. A message is acked once its pipeline finishes, and nacked if the pipeline fails. A message
+// that failed max_deliveries times is moved to the dead letter destination.
+//
+// The directory source reads the files of a local spool directory, the nats source pulls from a NATS JetStream
+// consumer.
+type TriggerQueue struct {
+	Source string `json:"source"`
+
+	// directory
+	Path string `json:"path,omitempty"`
+
+	// nats
+	Url      string `json:"url,omitempty"`
+	Stream   string `json:"stream,omitempty"`
+	Consumer string `json:"consumer,omitempty"`
+
+	Concurrency   int `json:"concurrency"`
+	MaxDeliveries int `json:"max_deliveries"`
+
+	// DeadLetter is a directory for the directory source, a subject for the nats source. The messages are discarded if
+	// it's not set.
+	DeadLetter string `json:"dead_letter,omitempty"`
+
+	UnresolvedAttributes map[string]hcl.Expression `json:"-"`
+	ConnectionDependsOn  []string                  `json:"connection_depends_on,omitempty"`
+}
+
+func (t *TriggerQueue) GetConfig(evalContext *hcl.EvalContext, mod *modconfig.Mod) (TriggerConfig, error) {
+	return t, nil
+}
+
+func (t *TriggerQueue) AppendDependsOn(...string) {
+}
+
+func (t *TriggerQueue) AppendCredentialDependsOn(...string) {
+}
+
+func (t *TriggerQueue) AppendConnectionDependsOn(connectionDependsOn ...string) {
+	existingDeps := make(map[string]struct{}, len(t.ConnectionDependsOn))
+	for _, dep := range t.ConnectionDependsOn {
+		existingDeps[dep] = struct{}{}
+	}
+
+	for _, dep := range connectionDependsOn {
+		if _, exists := existingDeps[dep]; !exists {
+			t.ConnectionDependsOn = append(t.ConnectionDependsOn, dep)
+			existingDeps[dep] = struct{}{}
+		}
+	}
+}
+
+func (t *TriggerQueue) GetConnectionDependsOn() []string {
+	return t.ConnectionDependsOn
+}
+
+func (t *TriggerQueue) AddUnresolvedAttribute(key string, value hcl.Expression) {
+	t.UnresolvedAttributes[key] = value
+}
+
+func (t *TriggerQueue) GetUnresolvedAttributes() map[string]hcl.Expression {
+	return t.UnresolvedAttributes
+}
+
+func (t *TriggerQueue) GetType() string {
+	return TriggerTypeQueue
+}
+
+func (t *TriggerQueue) Equals(other TriggerConfig) bool {
+	otherTrigger, ok := other.(*TriggerQueue)
+	if !ok {
+		return false
+	}
+
+	if t == nil && !helpers.IsNil(otherTrigger) || t != nil && helpers.IsNil(otherTrigger) {
+		return false
+	}
+
+	if t == nil && helpers.IsNil(otherTrigger) {
+		return true
+	}
+
+	if len(t.UnresolvedAttributes) != len(other.GetUnresolvedAttributes()) {
+		return false
+	}
+
+	for key, expr := range t.UnresolvedAttributes {
+		otherExpr, ok := other.GetUnresolvedAttributes()[key]
+		if !ok || !hclhelpers.ExpressionsEqual(expr, otherExpr) {
+			return false
+		}
+	}
+
+	return t.Source == otherTrigger.Source &&
+		t.Path == otherTrigger.Path &&
+		t.Url == otherTrigger.Url &&
+		t.Stream == otherTrigger.Stream &&
+		t.Consumer == otherTrigger.Consumer &&
+		t.Concurrency == otherTrigger.Concurrency &&
+		t.MaxDeliveries == otherTrigger.MaxDeliveries &&
+		t.DeadLetter == otherTrigger.DeadLetter
+}
+
+func (t *TriggerQueue) SetAttributes(mod *modconfig.Mod, trigger *Trigger, hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	diags := trigger.SetBaseAttributes(mod, hclAttributes, evalContext)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	t.Concurrency = DefaultQueueConcurrency
+	t.MaxDeliveries = DefaultQueueMaxDeliveries
+
+	for name, attr := range hclAttributes {
+		switch name {
+		case AttributeTypeSource, AttributeTypePath, AttributeTypeUrl, AttributeTypeStream, AttributeTypeConsumer, AttributeTypeDeadLetter:
+			val, moreDiags := attr.Expr.Value(evalContext)
+			if moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			s, err := hclhelpers.CtyToString(val)
+			if err != nil || val.Type() != cty.String {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unable to parse " + name + " attribute to string",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			switch name {
+			case AttributeTypeSource:
+				t.Source = s
+			case AttributeTypePath:
+				t.Path = s
+			case AttributeTypeUrl:
+				t.Url = s
+			case AttributeTypeStream:
+				t.Stream = s
+			case AttributeTypeConsumer:
+				t.Consumer = s
+			case AttributeTypeDeadLetter:
+				t.DeadLetter = s
+			}
+
+		case AttributeTypeConcurrency, AttributeTypeMaxDeliveries:
+			val, moreDiags := attr.Expr.Value(evalContext)
+			if moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			i, ctyDiags := hclhelpers.CtyToInt64(val)
+			if ctyDiags.HasErrors() || i == nil || *i < 1 {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "The " + name + " attribute must be a number greater than 0",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			if name == AttributeTypeConcurrency {
+				t.Concurrency = int(*i)
+			} else {
+				t.MaxDeliveries = int(*i)
+			}
+
+		default:
+			if !trigger.IsBaseAttribute(name) {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unsupported attribute for Trigger Queue: " + attr.Name,
+					Subject:  &attr.Range,
+				})
+			}
+		}
+	}
+
+	if diags.HasErrors() {
+		return diags
+	}
+
+	return append(diags, t.validate(trigger)...)
+}
+
+func (t *TriggerQueue) validate(trigger *Trigger) hcl.Diagnostics {
+	invalid := func(detail string) hcl.Diagnostics {
+		return hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid queue trigger",
+			Detail:   detail,
+			Subject:  &trigger.DeclRange,
+		}}
+	}
+
+	switch t.Source {
+	case QueueSourceDirectory:
+		if t.Path == "" {
+			return invalid("The directory source requires a path")
+		}
+		if t.Url != "" || t.Stream != "" || t.Consumer != "" {
+			return invalid("url, stream and consumer are only supported by the nats source")
+		}
+	case QueueSourceNats:
+		if t.Url == "" || t.Stream == "" || t.Consumer == "" {
+			return invalid("The nats source requires a url, a stream and a consumer")
+		}
+		if !strings.HasPrefix(t.Url, "nats://") && !strings.HasPrefix(t.Url, "tls://") && !strings.HasPrefix(t.Url, "tcp://") {
+			return invalid("The nats url must start with nats:// or tls://")
+		}
+		if t.Path != "" {
+			return invalid("path is only supported by the directory source")
+		}
+	default:
+		return invalid("The queue source must be one of: " + strings.Join(validQueueSources, ","))
+	}
+
+	return nil
+}
+
+func (t *TriggerQueue) SetBlocks(mod *modconfig.Mod, trigger *Trigger, hclBlocks hcl.Blocks, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	return hcl.Diagnostics{}
+}
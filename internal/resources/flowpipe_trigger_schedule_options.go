@@ -0,0 +1,197 @@
+package resources
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	// the time zones of the triggers don't depend on the zoneinfo of the host
+	_ "time/tzdata"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	AttributeTypeTimezone = "timezone"
+	AttributeTypeExclude  = "exclude"
+	AttributeTypeCalendar = "calendar"
+	AttributeTypeJitter   = "jitter"
+)
+
+var scheduleOptionAttributes = []string{AttributeTypeTimezone, AttributeTypeExclude, AttributeTypeCalendar, AttributeTypeJitter}
+
+// ScheduledTriggerConfig is the config of the triggers run by the scheduler, the schedule and query triggers
+type ScheduledTriggerConfig interface {
+	GetSchedule() string
+	GetScheduleOptions() *ScheduleOptions
+}
+
+// ScheduleOptions are the calendar options of a scheduled trigger. The schedule is evaluated in the time zone, UTC if
+// not set. The occurrences on the excluded dates, listed by the trigger or by the calendar file, are skipped, and each
+// firing is delayed by a random duration up to the jitter.
+//
+// The calendar file lists a date (YYYY-MM-DD) per line, optionally followed by the name of the holiday. Blank lines
+// and lines starting with # are ignored. The file is read when the mod is loaded.
+type ScheduleOptions struct {
+	Timezone string        `json:"timezone,omitempty"`
+	Exclude  []string      `json:"exclude,omitempty"`
+	Calendar string        `json:"calendar,omitempty"`
+	Jitter   time.Duration `json:"jitter,omitempty"`
+
+	calendarDates []string
+}
+
+func (o *ScheduleOptions) GetScheduleOptions() *ScheduleOptions {
+	return o
+}
+
+// Location returns the time zone the schedule is evaluated in
+func (o *ScheduleOptions) Location() *time.Location {
+	if o.Timezone == "" {
+		return time.UTC
+	}
+
+	location, err := time.LoadLocation(o.Timezone)
+	if err != nil {
+		// validated when the trigger is loaded
+		return time.UTC
+	}
+	return location
+}
+
+// IsExcluded returns true if the date of the given time, in the time zone of the schedule, is excluded
+func (o *ScheduleOptions) IsExcluded(at time.Time) bool {
+	date := at.In(o.Location()).Format(time.DateOnly)
+	return slices.Contains(o.Exclude, date) || slices.Contains(o.calendarDates, date)
+}
+
+func (o *ScheduleOptions) Equals(other *ScheduleOptions) bool {
+	return o.Timezone == other.Timezone &&
+		slices.Equal(o.Exclude, other.Exclude) &&
+		o.Calendar == other.Calendar &&
+		slices.Equal(o.calendarDates, other.calendarDates) &&
+		o.Jitter == other.Jitter
+}
+
+// setAttribute sets the schedule option, returns false if the attribute is not a schedule option
+func (o *ScheduleOptions) setAttribute(mod *modconfig.Mod, attr *hcl.Attribute, evalContext *hcl.EvalContext) (bool, hcl.Diagnostics) {
+	if !slices.Contains(scheduleOptionAttributes, attr.Name) {
+		return false, nil
+	}
+
+	val, diags := attr.Expr.Value(evalContext)
+	if diags.HasErrors() {
+		return true, diags
+	}
+
+	if attr.Name == AttributeTypeExclude {
+		dates, err := hclhelpers.CtyToGoStringSlice(val, val.Type())
+		if err != nil {
+			return true, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unable to parse " + AttributeTypeExclude + " attribute to a list of strings",
+				Subject:  &attr.Range,
+			}}
+		}
+
+		for _, date := range dates {
+			if _, err := time.Parse(time.DateOnly, date); err != nil {
+				return true, hcl.Diagnostics{&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid " + AttributeTypeExclude + " date: " + date,
+					Detail:   "The excluded dates must be formatted as YYYY-MM-DD",
+					Subject:  &attr.Range,
+				}}
+			}
+		}
+		o.Exclude = dates
+		return true, nil
+	}
+
+	if val.Type() != cty.String {
+		return true, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unable to parse " + attr.Name + " attribute to string",
+			Subject:  &attr.Range,
+		}}
+	}
+	s := val.AsString()
+
+	switch attr.Name {
+	case AttributeTypeTimezone:
+		if _, err := time.LoadLocation(s); err != nil || s == "" {
+			return true, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid " + AttributeTypeTimezone + ": " + s,
+				Detail:   "The " + AttributeTypeTimezone + " must be an IANA time zone, for example America/New_York",
+				Subject:  &attr.Range,
+			}}
+		}
+		o.Timezone = s
+
+	case AttributeTypeCalendar:
+		path := s
+		if !filepath.IsAbs(path) && mod != nil {
+			path = filepath.Join(mod.ModPath, path)
+		}
+
+		dates, err := readCalendarFile(path)
+		if err != nil {
+			return true, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid " + AttributeTypeCalendar + " file: " + s,
+				Detail:   err.Error(),
+				Subject:  &attr.Range,
+			}}
+		}
+		o.Calendar = s
+		o.calendarDates = dates
+
+	case AttributeTypeJitter:
+		duration, err := time.ParseDuration(s)
+		if err != nil || duration < 0 {
+			return true, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid " + AttributeTypeJitter,
+				Detail:   "The " + AttributeTypeJitter + " must be a duration, for example 5m",
+				Subject:  &attr.Range,
+			}}
+		}
+		o.Jitter = duration
+	}
+
+	return true, nil
+}
+
+func readCalendarFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var dates []string
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// the date may be followed by the name of the holiday
+		date, _, _ := strings.Cut(line, " ")
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return nil, fmt.Errorf("line %d is not a YYYY-MM-DD date: %s", lineNumber, line)
+		}
+		dates = append(dates, date)
+	}
+
+	return dates, scanner.Err()
+}
@@ -0,0 +1,33 @@
+package resources
+
+import "time"
+
+// RetentionPolicy defines how long the process history of the matching pipelines is kept. The unset fields inherit
+// from the default policy.
+type RetentionPolicy struct {
+	// Glob patterns on the pipeline and trigger names, empty matches everything
+	Pipeline string
+	Trigger  string
+
+	// Number of the most recent finished, failed or canceled runs that are kept whatever their age
+	KeepLast *int
+
+	// How long the finished and canceled runs are kept after their last update, negative to keep them forever
+	MaxAge *time.Duration
+
+	// How long the failed runs are kept, defaults to MaxAge
+	FailedMaxAge *time.Duration
+
+	// How long the runs that never reached a terminal state are kept after their last update, defaults to MaxAge
+	OrphanMaxAge *time.Duration
+
+	// Archive the events of the runs before deleting them
+	Archive *bool
+}
+
+type RetentionPolicies struct {
+	Default RetentionPolicy
+
+	// The first rule matching a run applies
+	Rules []RetentionPolicy
+}
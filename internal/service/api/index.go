@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"os"
 	"reflect"
 	"strings"
@@ -79,6 +80,11 @@ type APIService struct {
 	apiPrefixGroup *gin.RouterGroup
 	router         *gin.Engine
 	ModMetadata    RootModMetadata
+
+	triggerScheduler TriggerScheduler
+
+	// the reverse proxies whose X-Forwarded-For header gives the client IP of the HTTP trigger requests
+	trustedProxies []netip.Prefix
 }
 
 //go:embed all:assets
@@ -133,6 +139,22 @@ func WithHTTPPort(port int) APIServiceOption {
 	}
 }
 
+// WithTriggerScheduler sets the source of the fire times of the scheduled triggers
+func WithTriggerScheduler(triggerScheduler TriggerScheduler) APIServiceOption {
+	return func(api *APIService) error {
+		api.triggerScheduler = triggerScheduler
+		return nil
+	}
+}
+
+// WithTrustedProxies sets the reverse proxies whose X-Forwarded-For header is honoured by the HTTP triggers
+func WithTrustedProxies(trustedProxies []netip.Prefix) APIServiceOption {
+	return func(api *APIService) error {
+		api.trustedProxies = trustedProxies
+		return nil
+	}
+}
+
 // Start starts services managed by the Manager.
 func (api *APIService) Start() error {
 
@@ -146,6 +168,12 @@ func (api *APIService) Start() error {
 	// Initialize gin
 	router := gin.New()
 
+	// No proxy is trusted, so the client IP is the peer address rather than the X-Forwarded-For header sent by the client
+	err := router.SetTrustedProxies(nil)
+	if err != nil {
+		return err
+	}
+
 	// Add a ginslog middleware, which:
 	//   - Logs all requests, like a combined access and error log.
 	//   - Logs to stdout.
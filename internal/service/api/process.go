@@ -2,12 +2,17 @@ package api
 
 import (
 	"context"
+	"encoding/base64"
 	"log/slog"
 	"net/http"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
 	"github.com/turbot/flowpipe/internal/es/event"
 	"github.com/turbot/flowpipe/internal/es/execution"
 	"github.com/turbot/flowpipe/internal/metrics"
@@ -16,19 +21,24 @@ import (
 	"github.com/turbot/flowpipe/internal/store"
 	"github.com/turbot/flowpipe/internal/types"
 	"github.com/turbot/pipe-fittings/cache"
+	"github.com/turbot/pipe-fittings/constants"
 	"github.com/turbot/pipe-fittings/perr"
 )
 
 func (api *APIService) ProcessRegisterAPI(router *gin.RouterGroup) {
 	router.GET("/process", api.listProcess)
 	router.GET("/process/:process_id", api.getProcess)
+	router.POST("/process/prune", api.pruneProcess)
+	router.POST("/process/import", api.importProcess)
 	router.POST("/process/:process_id/command", api.cmdProcess)
 	router.GET("/process/:process_id/log/process.json", api.listProcessEventLog)
 	router.GET("/process/:process_id/execution", api.getProcessExecution)
+	router.GET("/process/:process_id/export", api.exportProcess)
+	router.POST("/process/:process_id/replay", api.replayProcess)
 }
 
 // @Summary List processs
-// @Description Lists processs
+// @Description Lists processs, newest first unless sorted otherwise
 // @ID   process_list
 // @Tags Process
 // @Accept json
@@ -36,6 +46,12 @@ func (api *APIService) ProcessRegisterAPI(router *gin.RouterGroup) {
 // / ...
 // @Param limit query int false "The max number of items to fetch per page of data, subject to a min and max of 1 and 100 respectively. If not specified will default to 25." default(25) minimum(1) maximum(100)
 // @Param next_token query string false "When list results are truncated, next_token will be returned, which is a cursor to fetch the next page of data. Pass next_token to the subsequent list request to fetch the next page of data."
+// @Param status query string false "Only the processes in this status" Enums(queued, started, paused, finished, failed, canceled)
+// @Param pipeline query string false "Only the processes of this pipeline"
+// @Param trigger query string false "Only the processes started by this trigger"
+// @Param since query string false "Only the processes started at or after this time, either a RFC 3339 timestamp or a duration before now (e.g. 24h, 7d)"
+// @Param until query string false "Only the processes started before this time, either a RFC 3339 timestamp or a duration before now (e.g. 24h, 7d)"
+// @Param sort query string false "Sort order on the start time" Enums(asc, desc) default(desc)
 // ...
 // @Success 200 {object} types.ListProcessResponse
 // @Failure 400 {object} perr.ErrorModel
@@ -52,9 +68,18 @@ func (api *APIService) listProcess(c *gin.Context) {
 		return
 	}
 
-	slog.Info("received list process request", "next_token", nextToken, "limit", limit)
+	var query types.ListProcessRequestQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+
+	limit = max(limit, viper.GetInt("api.list.limit.min"))
+	limit = min(limit, viper.GetInt("api.list.limit.max"))
+
+	slog.Info("received list process request", "next_token", nextToken, "limit", limit, "query", query)
 
-	result, err := ListProcesses()
+	result, err := ListProcesses(query, nextToken, limit)
 	if err != nil {
 		common.AbortWithError(c, err)
 		return
@@ -63,68 +88,172 @@ func (api *APIService) listProcess(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
-func ListProcesses() (*types.ListProcessResponse, error) {
-	var processList []types.Process
+// ListProcesses returns a page of the processes matching the query. The next token is the cursor returned by the
+// previous page (not base64 encoded), a limit of 0 returns all the processes.
+func ListProcesses(query types.ListProcessRequestQuery, nextToken string, limit int) (*types.ListProcessResponse, error) {
+	now := time.Now().UTC()
 
-	allExec := metrics.RunMetricInstance.RunningExecutions()
-	for _, exMetric := range allExec {
-		slog.Debug("allExec", "ex", exMetric)
-		ex, err := execution.GetExecution(exMetric.ExecutionID)
-		if err != nil {
-			slog.Error("Error loading execution", "error", err)
-			return nil, err
+	since, err := parseProcessListTime(query.Since, now)
+	if err != nil {
+		return nil, err
+	}
+
+	until, err := parseProcessListTime(query.Until, now)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := store.PipelineRunFilter{
+		Pipeline:  query.Pipeline,
+		Trigger:   query.Trigger,
+		Since:     since,
+		Until:     until,
+		Ascending: query.Sort == "asc",
+		Cursor:    nextToken,
+		Limit:     limit,
+	}
+
+	if query.Status != "" {
+		filter.States = []string{query.Status}
+	}
+
+	var runs []store.PipelineRun
+	var cursor string
+	if viper.GetInt(constants.ArgProcessRetention) == 0 {
+		// without process retention the executions aren't recorded in pipeline_run, they can only be listed in full
+		if len(filter.States) > 0 || filter.Pipeline != "" || filter.Trigger != "" || filter.Since != nil || filter.Until != nil || filter.Cursor != "" {
+			return nil, perr.BadRequestWithMessage("the process list can't be filtered or paged with a " + constants.ArgProcessRetention + " of 0, the processes are not recorded")
 		}
+		runs, err = listProcessesFromEventLog(filter.Ascending)
+	} else {
+		runs, cursor, err = store.ListPipelineRuns(filter)
+	}
+	if err != nil {
+		slog.Error("Error listing processes", "error", err)
+		return nil, err
+	}
 
+	processList := []types.Process{}
+	for _, run := range runs {
 		processList = append(processList, types.Process{
-			ID:        ex.ID,
-			Pipeline:  exMetric.Pipeline,
-			CreatedAt: exMetric.StartTimestamp,
-			Status:    "started", // We assume started as the finished pipeline shouldn't be in the Metrics instance``
+			ID:        run.ExecutionID,
+			Pipeline:  run.Pipeline,
+			Trigger:   run.Trigger,
+			Status:    run.State,
+			CreatedAt: run.StartedAt,
 		})
+	}
+
+	result := &types.ListProcessResponse{
+		Items: processList,
+	}
 
+	if cursor != "" {
+		token := base64.StdEncoding.EncodeToString([]byte(cursor))
+		result.NextToken = &token
+	}
+
+	return result, nil
+}
+
+// listProcessesFromEventLog lists all the executions running in this server and the ones found in the event log, for
+// when the executions aren't recorded in pipeline_run
+func listProcessesFromEventLog(ascending bool) ([]store.PipelineRun, error) {
+	var runs []store.PipelineRun
+	listed := map[string]bool{}
+
+	for _, exMetric := range metrics.RunMetricInstance.RunningExecutions() {
+		runs = append(runs, store.PipelineRun{
+			ExecutionID: exMetric.ExecutionID,
+			Pipeline:    exMetric.Pipeline,
+			State:       "started", // the finished executions aren't in the metrics
+			StartedAt:   exMetric.StartTimestamp,
+		})
+		listed[exMetric.ExecutionID] = true
 	}
 
-	// Extract the execution IDs from the log file names
 	executionIDs, err := store.ListExecutionIDs()
 	if err != nil {
 		slog.Error("Error listing execution IDs", "error", err)
 		return nil, perr.InternalWithMessage("Error listing execution IDs")
 	}
 
-	// Get the log entries using the execution ID and extract the pipeline name
-
-	for _, execID := range executionIDs {
-
-		evt := &event.Event{
-			ExecutionID: execID,
+	for _, executionID := range executionIDs {
+		if listed[executionID] {
+			continue
 		}
 
-		// TODO .. we need to skip if execution is for a different mod, but how do we know?
-		ex, err := execution.NewExecution(context.Background(), execution.WithEvent(evt))
+		ex, err := execution.NewExecution(context.Background(), execution.WithEvent(&event.Event{ExecutionID: executionID}))
 		if err != nil {
 			continue
 		}
 
-		// get outer pipeline (not child)
-		var outerPipeline execution.PipelineExecution
+		// the outer pipeline, not a child pipeline
 		for _, pipeline := range ex.PipelineExecutions {
 			if pipeline.ParentExecutionID == "" && pipeline.ParentStepExecutionID == "" {
-				outerPipeline = *pipeline
-				continue
+				runs = append(runs, store.PipelineRun{
+					ExecutionID: ex.ID,
+					Pipeline:    pipeline.Name,
+					State:       pipeline.Status,
+					StartedAt:   pipeline.StartTime,
+				})
+				break
 			}
 		}
+	}
 
-		processList = append(processList, types.Process{
-			ID:        ex.ID,
-			Pipeline:  outerPipeline.Name,
-			Status:    outerPipeline.Status,
-			CreatedAt: outerPipeline.StartTime,
-		})
+	sort.Slice(runs, func(i, j int) bool {
+		if ascending {
+			return runs[i].StartedAt.Before(runs[j].StartedAt)
+		}
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+
+	return runs, nil
+}
+
+// parseProcessListTime parses either a RFC 3339 timestamp or a duration before now (Go duration or a number of days,
+// e.g. 7d)
+func parseProcessListTime(value string, now time.Time) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
 	}
 
-	return &types.ListProcessResponse{
-		Items: processList,
-	}, nil
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	duration, err := parseTimeDuration(value)
+	if err != nil {
+		return nil, err
+	}
+
+	t := now.Add(-duration)
+	return &t, nil
+}
+
+// parseTimeDuration parses the duration of a time given relative to now, either a Go duration or a number of days
+func parseTimeDuration(value string) (time.Duration, error) {
+	var duration time.Duration
+	if days, found := strings.CutSuffix(value, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, perr.BadRequestWithMessage("invalid time: " + value)
+		}
+		duration = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		duration, err = time.ParseDuration(value)
+		if err != nil {
+			return 0, perr.BadRequestWithMessage("invalid time: " + value + ", expected a RFC 3339 timestamp or a duration such as 24h or 7d")
+		}
+	}
+
+	if duration < 0 {
+		return 0, perr.BadRequestWithMessage("invalid time: " + value + ", the duration must be positive")
+	}
+
+	return duration, nil
 }
 
 // @Summary Get process
@@ -340,7 +469,7 @@ func (api *APIService) getProcessExecution(c *gin.Context) {
 // @Produce json
 // / ...
 // @Param process_id path string true "The name of the process" format(^[a-z]{0,32}$)
-// @Param command body types.CmdProcess true "The command to execute"
+// @Param command body types.CmdProcess true "The command to execute: resume, pause or cancel"
 // ...
 // @Success 200 {object} types.Process
 // @Failure 400 {object} perr.ErrorModel
@@ -363,20 +492,49 @@ func (api *APIService) cmdProcess(c *gin.Context) {
 		return
 	}
 
-	if input.Command != "resume" {
-		common.AbortWithError(c, perr.BadRequestWithMessage("Invalid command"))
-	}
-
 	executionId := uri.ProcessId
 
-	// TODO: return result when the time come
-	_, _, err := ResumeProcess(executionId, api.EsService)
-	if err != nil {
-		common.AbortWithError(c, err)
-		return
-	}
+	switch input.Command {
+	case "resume":
+		// TODO: return result when the time come
+		_, _, err := ResumeProcess(executionId, api.EsService)
+		if err != nil {
+			common.AbortWithError(c, err)
+			return
+		}
 
-	c.JSON(http.StatusOK, nil)
+		c.JSON(http.StatusOK, nil)
+	case "pause":
+		err := PauseProcess(executionId, input.Reason, api.EsService)
+		if err != nil {
+			common.AbortWithError(c, err)
+			return
+		}
+
+		process, err := GetProcess(executionId)
+		if err != nil {
+			common.AbortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, process)
+	case "cancel":
+		err := CancelProcess(executionId, input.Reason, api.EsService)
+		if err != nil {
+			common.AbortWithError(c, err)
+			return
+		}
+
+		process, err := GetProcess(executionId)
+		if err != nil {
+			common.AbortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, process)
+	default:
+		common.AbortWithError(c, perr.BadRequestWithMessage("Invalid command"))
+	}
 }
 
 func reorderStepExecutions(pex map[string]*execution.StepExecution) []*execution.StepExecution {
@@ -559,3 +717,104 @@ func ResumeProcess(executionId string, esService *es.ESService) (pipelineExecuti
 
 	return pipelineExecutionId, pipelineName, nil
 }
+
+// PauseProcess pauses every running root pipeline of the given execution. No new steps are planned once the pipelines
+// are paused, steps that are already running are left to finish. The paused execution can be resumed with ResumeProcess.
+func PauseProcess(executionId, reason string, esService *es.ESService) error {
+
+	ex, err := execution.GetExecution(executionId)
+	if err != nil {
+		if perr.IsNotFound(err) {
+			return perr.BadRequestWithMessage("execution " + executionId + " is not running")
+		}
+		return err
+	}
+
+	paused := false
+	for _, pexId := range ex.RootPipelines {
+		pex := ex.PipelineExecutions[pexId]
+		if pex == nil || (pex.Status != "started" && pex.Status != "queued") {
+			continue
+		}
+
+		slog.Info("Pausing pipeline execution", "execution_id", executionId, "pipeline_execution_id", pexId)
+
+		cmd, err := event.NewPipelinePause(ex.ID, pexId, event.WithPauseReason(reason))
+		if err != nil {
+			return err
+		}
+
+		err = esService.Send(cmd)
+		if err != nil {
+			return err
+		}
+		paused = true
+	}
+
+	if !paused {
+		return perr.BadRequestWithMessage("execution " + executionId + " is not running, status: " + ex.Status)
+	}
+
+	return nil
+}
+
+// CancelProcess cancels every running root pipeline of the given execution. Child pipelines and in-flight steps
+// are stopped by the pipeline_cancel and pipeline_canceled handlers.
+func CancelProcess(executionId, reason string, esService *es.ESService) error {
+
+	ex, err := execution.GetExecution(executionId)
+	if err != nil && !perr.IsNotFound(err) {
+		return err
+	}
+
+	if ex == nil {
+		// Not in memory, i.e. paused or a process from a previous run. Load it from the process db so the
+		// cancel command handler can find it.
+		evt := &event.Event{
+			ExecutionID: executionId,
+		}
+
+		ex, err = execution.LoadExecutionFromProcessDB(evt)
+		if err != nil {
+			return err
+		}
+
+		if ex == nil {
+			return perr.NotFoundWithMessage("execution not found")
+		}
+
+		// Effectively forever
+		ok := cache.GetCache().SetWithTTL(executionId, ex, 10*365*24*time.Hour)
+		if !ok {
+			slog.Error("Error setting execution in cache", "execution_id", executionId)
+			return perr.InternalWithMessage("Error setting execution in cache")
+		}
+	}
+
+	canceled := false
+	for _, pexId := range ex.RootPipelines {
+		pex := ex.PipelineExecutions[pexId]
+		if pex == nil || slices.Contains(event.EndEvents, pex.Status) {
+			continue
+		}
+
+		slog.Info("Canceling pipeline execution", "execution_id", executionId, "pipeline_execution_id", pexId)
+
+		cmd, err := event.NewPipelineCancel(ex.ID, pexId, event.WithCancelReason(reason))
+		if err != nil {
+			return err
+		}
+
+		err = esService.Send(cmd)
+		if err != nil {
+			return err
+		}
+		canceled = true
+	}
+
+	if !canceled {
+		return perr.BadRequestWithMessage("execution " + executionId + " is not running, status: " + ex.Status)
+	}
+
+	return nil
+}
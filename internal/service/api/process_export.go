@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/turbot/flowpipe/internal/es/db"
+	"github.com/turbot/flowpipe/internal/es/event"
+	"github.com/turbot/flowpipe/internal/es/execution"
+	"github.com/turbot/flowpipe/internal/service/api/common"
+	"github.com/turbot/flowpipe/internal/store"
+	"github.com/turbot/flowpipe/internal/types"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/cache"
+	"github.com/turbot/pipe-fittings/perr"
+	"github.com/turbot/pipe-fittings/sanitize"
+)
+
+// @Summary Export process
+// @Description Export a process, its event log, pipeline definitions and outputs, to a bundle that can be imported into another server
+// @ID   process_export
+// @Tags Process
+// @Produce json
+// / ...
+// @Param process_id path string true "The id of the process" format(^[a-z]{0,32}$)
+// ...
+// @Success 200 {object} types.ProcessBundle
+// @Failure 400 {object} perr.ErrorModel
+// @Failure 401 {object} perr.ErrorModel
+// @Failure 403 {object} perr.ErrorModel
+// @Failure 404 {object} perr.ErrorModel
+// @Failure 429 {object} perr.ErrorModel
+// @Failure 500 {object} perr.ErrorModel
+// @Router /process/{process_id}/export [get]
+func (api *APIService) exportProcess(c *gin.Context) {
+	var uri types.ProcessRequestURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+
+	bundle, err := ExportProcess(uri.ProcessId)
+	if err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bundle)
+}
+
+// @Summary Import process
+// @Description Import a process exported from another server
+// @ID   process_import
+// @Tags Process
+// @Accept json
+// @Produce json
+// / ...
+// @Param bundle body types.ProcessBundle true "The exported process"
+// ...
+// @Success 200 {object} types.Process
+// @Failure 400 {object} perr.ErrorModel
+// @Failure 401 {object} perr.ErrorModel
+// @Failure 403 {object} perr.ErrorModel
+// @Failure 409 {object} perr.ErrorModel
+// @Failure 429 {object} perr.ErrorModel
+// @Failure 500 {object} perr.ErrorModel
+// @Router /process/import [post]
+func (api *APIService) importProcess(c *gin.Context) {
+	var bundle types.ProcessBundle
+	if err := c.ShouldBindJSON(&bundle); err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+
+	process, err := ImportProcess(&bundle)
+	if err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, process)
+}
+
+// ExportProcess bundles a process from the process store: its event log, the definitions of the pipelines it ran and
+// their outputs. The bundle is redacted.
+func ExportProcess(executionId string) (*types.ProcessBundle, error) {
+	events, err := store.ListEventRecords(executionId)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, perr.NotFoundWithMessage("process " + executionId + " not found")
+	}
+
+	process, err := GetProcess(executionId)
+	if err != nil {
+		return nil, err
+	}
+
+	// the trigger is only recorded in the pipeline run
+	run, err := store.GetPipelineRun(executionId)
+	if err != nil && !perr.IsNotFound(err) {
+		return nil, err
+	}
+	if run != nil {
+		process.Trigger = run.Trigger
+	}
+
+	evt := &event.Event{
+		ExecutionID: executionId,
+	}
+
+	ex, err := execution.NewExecution(context.Background(), execution.WithEvent(evt))
+	if err != nil {
+		return nil, err
+	}
+
+	rootMod := ""
+	if rootModNameCached, found := cache.GetCache().Get("#rootmod.name"); found {
+		rootMod, _ = rootModNameCached.(string)
+	}
+
+	bundle := &types.ProcessBundle{
+		Version:    types.ProcessBundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Process:    *process,
+		Pipelines:  map[string]json.RawMessage{},
+		Outputs:    map[string]map[string]any{},
+		Events:     events,
+	}
+
+	if app_specific.AppVersion != nil {
+		bundle.FlowpipeVersion = app_specific.AppVersion.String()
+	}
+
+	for pipelineExecutionID, pex := range ex.PipelineExecutions {
+		if pex.PipelineOutput != nil {
+			bundle.Outputs[pipelineExecutionID] = pex.PipelineOutput
+		}
+
+		if _, ok := bundle.Pipelines[pex.Name]; ok {
+			continue
+		}
+
+		// the mod that ran the process may no longer define the pipeline, the events are enough to show the process
+		pipeline, err := db.GetPipelineWithModFullVersion(pex.ModFullVersion, pex.Name)
+		if err != nil {
+			slog.Debug("Pipeline definition not found, not exported", "pipeline", pex.Name, "error", err)
+			continue
+		}
+
+		fpPipeline, err := types.FpPipelineFromModPipeline(pipeline, rootMod)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Pipelines[pex.Name], err = json.Marshal(fpPipeline)
+		if err != nil {
+			slog.Error("Error marshalling pipeline", "pipeline", pex.Name, "error", err)
+			return nil, perr.InternalWithMessage("Error marshalling pipeline")
+		}
+	}
+
+	return redactProcessBundle(bundle)
+}
+
+func redactProcessBundle(bundle *types.ProcessBundle) (*types.ProcessBundle, error) {
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		slog.Error("Error marshalling process bundle", "error", err)
+		return nil, perr.InternalWithMessage("Error marshalling process bundle")
+	}
+
+	redacted := &types.ProcessBundle{}
+	err = json.Unmarshal([]byte(sanitize.Instance.SanitizeString(string(data))), redacted)
+	if err != nil {
+		slog.Error("Error unmarshalling process bundle", "error", err)
+		return nil, perr.InternalWithMessage("Error unmarshalling process bundle")
+	}
+
+	return redacted, nil
+}
+
+// ImportProcess loads a process exported by ExportProcess into the process store
+func ImportProcess(bundle *types.ProcessBundle) (*types.Process, error) {
+	if bundle.Version != types.ProcessBundleVersion {
+		return nil, perr.BadRequestWithMessage("unsupported process bundle version: " + bundle.Version)
+	}
+
+	process := bundle.Process
+	if process.ID == "" {
+		return nil, perr.BadRequestWithMessage("process bundle has no execution id")
+	}
+	if len(bundle.Events) == 0 {
+		return nil, perr.BadRequestWithMessage("process bundle has no events")
+	}
+
+	for _, evt := range bundle.Events {
+		if evt.ProcessID != process.ID {
+			return nil, perr.BadRequestWithMessage("process bundle event " + evt.ID + " belongs to process " + evt.ProcessID)
+		}
+	}
+
+	run := store.PipelineRun{
+		ExecutionID: process.ID,
+		Pipeline:    process.Pipeline,
+		Trigger:     process.Trigger,
+		State:       process.Status,
+		StartedAt:   process.CreatedAt,
+	}
+
+	err := store.ImportProcess(run, bundle.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	return &process, nil
+}
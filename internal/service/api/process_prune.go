@@ -0,0 +1,80 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/turbot/flowpipe/internal/es/db"
+	"github.com/turbot/flowpipe/internal/service/api/common"
+	"github.com/turbot/flowpipe/internal/store"
+	"github.com/turbot/flowpipe/internal/types"
+)
+
+// @Summary Prune processes
+// @Description Delete the processes past the retention policies, a dry run only lists them
+// @ID   process_prune
+// @Tags Process
+// @Accept json
+// @Produce json
+// / ...
+// @Param command body types.CmdPruneProcess true "Whether to only list the processes that would be deleted"
+// ...
+// @Success 200 {object} types.PruneProcessResponse
+// @Failure 400 {object} perr.ErrorModel
+// @Failure 401 {object} perr.ErrorModel
+// @Failure 403 {object} perr.ErrorModel
+// @Failure 429 {object} perr.ErrorModel
+// @Failure 500 {object} perr.ErrorModel
+// @Router /process/prune [post]
+func (api *APIService) pruneProcess(c *gin.Context) {
+	var input types.CmdPruneProcess
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+
+	result, err := PruneProcesses(input.DryRun)
+	if err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// PruneProcesses applies the retention policies of the flowpipe config to the process history
+func PruneProcesses(dryRun bool) (*types.PruneProcessResponse, error) {
+	fpConfig, err := db.GetFlowpipeConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pruned, err := store.PruneProcesses(fpConfig.Retention, dryRun)
+	if err != nil {
+		slog.Error("Error pruning processes", "error", err)
+		return nil, err
+	}
+
+	result := &types.PruneProcessResponse{
+		DryRun: dryRun,
+		Items:  []types.PrunedProcess{},
+	}
+
+	for _, run := range pruned {
+		result.Items = append(result.Items, types.PrunedProcess{
+			Process: types.Process{
+				ID:        run.ExecutionID,
+				Pipeline:  run.Pipeline,
+				Trigger:   run.Trigger,
+				Status:    run.State,
+				CreatedAt: run.StartedAt,
+			},
+			UpdatedAt: run.UpdatedAt,
+			Reason:    run.Reason,
+			Archived:  run.Archived,
+		})
+	}
+
+	return result, nil
+}
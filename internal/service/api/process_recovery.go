@@ -0,0 +1,293 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/turbot/flowpipe/internal/constants"
+	"github.com/turbot/flowpipe/internal/es/command"
+	"github.com/turbot/flowpipe/internal/es/event"
+	"github.com/turbot/flowpipe/internal/es/execution"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/flowpipe/internal/service/es"
+	"github.com/turbot/flowpipe/internal/service/es/pubsub"
+	"github.com/turbot/flowpipe/internal/store"
+	"github.com/turbot/pipe-fittings/cache"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+// LoadInterruptedProcesses loads the executions that were still running when the server stopped (their pipeline_run is
+// either queued or started) and caches them, the commands and events that are replayed or requeued for these
+// executions expect to find them in the cache.
+//
+// Paused executions are not loaded, they are resumed on demand with ResumeProcess.
+func LoadInterruptedProcesses() ([]*execution.ExecutionInMemory, error) {
+	executionIDs, err := store.ListPipelineRunsWithState("queued", "started")
+	if err != nil {
+		return nil, err
+	}
+
+	var executions []*execution.ExecutionInMemory
+	for _, executionID := range executionIDs {
+		ex, err := loadInterruptedProcess(executionID)
+		if err != nil {
+			return nil, err
+		}
+		if ex != nil {
+			executions = append(executions, ex)
+		}
+	}
+
+	return executions, nil
+}
+
+// LoadPendingProcesses caches the executions of the commands and events left in the durable event bus database, they
+// are redelivered when the ES service starts whatever the recovery policy.
+func LoadPendingProcesses(eventBusDBPath string) error {
+	payloads, err := pubsub.PendingPayloads(eventBusDBPath)
+	if err != nil {
+		return err
+	}
+
+	var executionIDs []string
+	for _, payload := range payloads {
+		var p event.PayloadWithEvent
+		err := json.Unmarshal(payload, &p)
+		if err != nil || p.Event == nil || p.Event.ExecutionID == "" {
+			slog.Warn("Pending command or event without an execution", "error", err)
+			continue
+		}
+		if !slices.Contains(executionIDs, p.Event.ExecutionID) {
+			executionIDs = append(executionIDs, p.Event.ExecutionID)
+		}
+	}
+
+	for _, executionID := range executionIDs {
+		// this server carries on with the process, it's not recovered by the leader
+		err := store.ClaimPipelineRun(executionID)
+		if err != nil {
+			return err
+		}
+
+		_, err = loadInterruptedProcess(executionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadInterruptedProcess loads the execution and caches it, it returns nil if the execution can't be loaded or isn't
+// running
+func loadInterruptedProcess(executionID string) (*execution.ExecutionInMemory, error) {
+	ex, err := execution.LoadExecutionFromProcessDB(&event.Event{ExecutionID: executionID})
+	if err != nil {
+		// don't stop the server because of a single process that can't be loaded
+		slog.Error("Error loading interrupted process", "execution_id", executionID, "error", err)
+		return nil, nil
+	}
+
+	if ex == nil || ex.IsPaused() || slices.Contains(event.EndEvents, ex.Status) {
+		return nil, nil
+	}
+
+	// Effectively forever
+	ok := cache.GetCache().SetWithTTL(executionID, ex, 10*365*24*time.Hour)
+	if !ok {
+		slog.Error("Error setting execution in cache", "execution_id", executionID)
+		return nil, perr.InternalWithMessage("Error setting execution in cache")
+	}
+
+	return ex, nil
+}
+
+// RecoverProcesses applies the recovery policy to the processes interrupted by a server restart:
+//
+//   - fail (default): the pipelines and the execution are marked as failed
+//   - resume: the steps that were in-flight are requeued and the pipelines planned again. The steps run again, so it
+//     is only safe if their side effects can be repeated.
+//   - ignore: the processes are left as they are
+func RecoverProcesses(executions []*execution.ExecutionInMemory, policy string, esService *es.ESService) error {
+	return recoverProcesses(executions, policy, esService, esService.IsDurable())
+}
+
+// RecoverOrphanedProcess applies the recovery policy to a process whose server has stopped, claimed by this server. The
+// commands and events that were queued on the stopped server are not redelivered here.
+func RecoverOrphanedProcess(executionID, policy string, esService *es.ESService) error {
+	ex, err := loadInterruptedProcess(executionID)
+	if err != nil || ex == nil {
+		return err
+	}
+
+	return recoverProcesses([]*execution.ExecutionInMemory{ex}, policy, esService, false)
+}
+
+// recoverProcesses applies the recovery policy, redelivered is set if the pending commands and events of the
+// executions are redelivered by the event bus
+func recoverProcesses(executions []*execution.ExecutionInMemory, policy string, esService *es.ESService, redelivered bool) error {
+	for _, ex := range executions {
+		slog.Info("Recovering interrupted process", "execution_id", ex.ID, "status", ex.Status, "policy", policy)
+
+		var err error
+		switch policy {
+		case constants.RecoveryPolicyResume:
+			err = resumeInterruptedProcess(ex, esService, redelivered)
+		case constants.RecoveryPolicyFail:
+			err = failInterruptedProcess(ex)
+		case constants.RecoveryPolicyIgnore:
+			continue
+		default:
+			return perr.BadRequestWithMessage("invalid recovery policy: " + policy)
+		}
+
+		if err != nil {
+			slog.Error("Error recovering interrupted process", "execution_id", ex.ID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func isPipelineExecutionRunning(pex *execution.PipelineExecution) bool {
+	return !pex.IsPaused() && !slices.Contains(event.EndEvents, pex.Status)
+}
+
+func resumeInterruptedProcess(ex *execution.ExecutionInMemory, esService *es.ESService, redelivered bool) error {
+
+	// With a durable event bus the commands and events that were queued when the server stopped are redelivered.
+	// Only the steps that were executing are lost: the step start command is acked as soon as the step is started.
+	stepStatuses := []string{"queueing", "queued", "starting"}
+	if redelivered {
+		stepStatuses = []string{"starting"}
+	}
+
+	for _, pex := range ex.PipelineExecutions {
+		if !isPipelineExecutionRunning(pex) {
+			continue
+		}
+
+		pipelineDefn, err := ex.PipelineDefinition(pex.ID)
+		if err != nil {
+			return err
+		}
+
+		// The pipeline semaphore is released when the pipeline completes, replay the acquire that was done when the
+		// pipeline was first queued
+		err = execution.GetPipelineSemaphore(pipelineDefn)
+		if err != nil {
+			return err
+		}
+
+		for _, stepExecution := range reorderStepExecutions(pex.StepExecutions) {
+			stepDefn, err := ex.StepDefinition(pex.ID, stepExecution.ID)
+			if err != nil {
+				return err
+			}
+
+			if stepDefn == nil {
+				continue
+			}
+
+			// A pipeline step that has started its child pipeline is complete when the child pipeline is, the
+			// child pipeline is recovered on its own
+			if ex.FindPipelineExecutionByItsParentStepExecution(stepExecution.ID) != nil {
+				err := execution.GetPipelineExecutionStepSemaphoreMaxConcurrency(pex.ID, stepDefn, stepExecution.MaxConcurrency, true)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			if !slices.Contains(stepStatuses, stepExecution.Status) {
+				continue
+			}
+
+			slog.Info("Requeueing interrupted step", "execution_id", ex.ID, "pipeline_execution_id", pex.ID, "step_execution_id", stepExecution.ID, "step_name", stepExecution.Name, "status", stepExecution.Status)
+
+			cmd := &event.StepQueue{
+				Event: &event.Event{
+					ExecutionID: ex.ID,
+					CreatedAt:   time.Now().UTC(),
+				},
+				PipelineExecutionID: pex.ID,
+				StepExecutionID:     stepExecution.ID,
+				StepName:            stepExecution.Name,
+				StepInput:           stepExecution.Input,
+				StepForEach:         stepExecution.StepForEach,
+				StepLoop:            stepExecution.StepLoop,
+				StepRetry:           stepExecution.StepRetry,
+				NextStepAction:      stepExecution.NextStepAction,
+				MaxConcurrency:      stepExecution.MaxConcurrency,
+			}
+			err = esService.Send(cmd)
+			if err != nil {
+				return err
+			}
+		}
+
+		if redelivered {
+			continue
+		}
+
+		// The pending planning of the pipeline is lost, plan it again
+		err = esService.Send(&event.PipelinePlan{
+			Event:               event.NewEventForExecutionID(ex.ID),
+			PipelineExecutionID: pex.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func failInterruptedProcess(ex *execution.ExecutionInMemory) error {
+	ctx := context.Background()
+
+	errorModel := perr.InternalWithMessage("Process interrupted by a server restart")
+
+	// The events are logged directly rather than published: the handlers would try to continue the execution (release
+	// semaphores, plan the parent pipelines, run the error handling of the parent steps) which is not what we want
+	for _, pex := range ex.PipelineExecutions {
+		if !isPipelineExecutionRunning(pex) {
+			continue
+		}
+
+		evt := &event.PipelineFailed{
+			Event:               event.NewEventForExecutionID(ex.ID),
+			PipelineExecutionID: pex.ID,
+			Errors: []resources.StepError{
+				{
+					PipelineExecutionID: pex.ID,
+					Pipeline:            pex.Name,
+					Error:               errorModel,
+				},
+			},
+		}
+
+		err := command.LogEventMessage(ctx, evt, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := command.LogEventMessage(ctx, &event.ExecutionFailed{
+		Event: event.NewEventForExecutionID(ex.ID),
+		Error: errorModel,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	err = store.UpdatePipelineState(ex.ID, constants.StateFailed)
+	if err != nil {
+		return err
+	}
+
+	return ex.EndExecution()
+}
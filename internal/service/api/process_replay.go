@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/turbot/flowpipe/internal/es/event"
+	"github.com/turbot/flowpipe/internal/es/execution"
+	"github.com/turbot/flowpipe/internal/service/api/common"
+	"github.com/turbot/flowpipe/internal/service/es"
+	"github.com/turbot/flowpipe/internal/types"
+	"github.com/turbot/flowpipe/internal/util"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+// @Summary Replay process
+// @Description Run the pipeline of a past process again with its original args against the current mod. The steps are served their recorded output up to the given step, and run live from there.
+// @ID   process_replay
+// @Tags Process
+// @Accept json
+// @Produce json
+// / ...
+// @Param process_id path string true "The id of the process to replay" format(^[a-z]{0,32}$)
+// @Param command body types.CmdReplayProcess true "The step to continue live from"
+// ...
+// @Success 200 {object} types.PipelineExecutionResponse
+// @Failure 400 {object} perr.ErrorModel
+// @Failure 401 {object} perr.ErrorModel
+// @Failure 403 {object} perr.ErrorModel
+// @Failure 404 {object} perr.ErrorModel
+// @Failure 429 {object} perr.ErrorModel
+// @Failure 500 {object} perr.ErrorModel
+// @Router /process/{process_id}/replay [post]
+func (api *APIService) replayProcess(c *gin.Context) {
+	var uri types.ProcessRequestURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+
+	var input types.CmdReplayProcess
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+
+	response, err := ReplayProcess(uri.ProcessId, input.FromStep, api.EsService)
+	if err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ReplayProcess starts a new execution of the pipeline run by a past process, with the same args. The steps of the
+// new execution are served the outputs recorded by the past process (see execution.Replay).
+func ReplayProcess(sourceExecutionId, fromStep string, esService *es.ESService) (types.PipelineExecutionResponse, error) {
+	evt := &event.Event{
+		ExecutionID: sourceExecutionId,
+	}
+
+	source, err := execution.NewExecution(context.Background(), execution.WithEvent(evt))
+	if err != nil {
+		return types.PipelineExecutionResponse{}, err
+	}
+
+	var rootPipeline *execution.PipelineExecution
+	for _, pex := range source.PipelineExecutions {
+		if pex.ParentExecutionID == "" && pex.ParentStepExecutionID == "" {
+			rootPipeline = pex
+			break
+		}
+	}
+
+	if rootPipeline == nil {
+		return types.PipelineExecutionResponse{}, perr.NotFoundWithMessage("No pipeline found for process " + sourceExecutionId)
+	}
+
+	replay, err := execution.NewReplay(source, fromStep)
+	if err != nil {
+		return types.PipelineExecutionResponse{}, err
+	}
+
+	input := types.CmdPipeline{
+		Command: "run",
+		Args:    rootPipeline.Args,
+	}
+
+	// the replay must be in place before the first step of the new execution starts
+	executionId := util.NewExecutionId()
+	execution.SetReplay(executionId, replay)
+
+	response, _, err := ExecutePipeline(input, executionId, rootPipeline.Name, esService)
+	if err != nil {
+		execution.ReleaseReplay(executionId)
+		return types.PipelineExecutionResponse{}, err
+	}
+
+	return response, nil
+}
@@ -2,10 +2,13 @@ package service
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/turbot/flowpipe/internal/metrics"
+	"github.com/turbot/flowpipe/internal/primitive"
 )
 
 func RegisterPublicAPI(router *gin.RouterGroup) {
 	router.GET("/service", serviceGet)
+	router.GET("/service/metrics", serviceMetricsGet)
 }
 
 func serviceGet(c *gin.Context) {
@@ -13,3 +16,10 @@ func serviceGet(c *gin.Context) {
 		"status": "UP",
 	})
 }
+
+func serviceMetricsGet(c *gin.Context) {
+	c.JSON(200, gin.H{
+		"running_executions": metrics.RunMetricInstance.RunningExecutions(),
+		"query_pools":        primitive.QueryPoolMetrics(),
+	})
+}
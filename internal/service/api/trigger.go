@@ -2,11 +2,13 @@ package api
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	localconstants "github.com/turbot/flowpipe/internal/constants"
@@ -16,6 +18,8 @@ import (
 	"github.com/turbot/flowpipe/internal/resources"
 	"github.com/turbot/flowpipe/internal/service/api/common"
 	"github.com/turbot/flowpipe/internal/service/es"
+	"github.com/turbot/flowpipe/internal/store"
+	"github.com/turbot/flowpipe/internal/trigger"
 	"github.com/turbot/flowpipe/internal/types"
 	"github.com/turbot/pipe-fittings/cache"
 	"github.com/turbot/pipe-fittings/perr"
@@ -25,6 +29,7 @@ import (
 func (api *APIService) TriggerRegisterAPI(router *gin.RouterGroup) {
 	router.GET("/trigger", api.listTriggers)
 	router.GET("/trigger/:trigger_name", api.getTrigger)
+	router.GET("/trigger/:trigger_name/runs", api.listTriggerRuns)
 	router.POST("/trigger/:trigger_name/command", api.cmdTrigger)
 	// router.GET("/trigger/:trigger_name/key", api.listTriggerKeys)
 }
@@ -71,6 +76,11 @@ func ListTriggers(rootMod string) (*types.ListTriggerResponse, error) {
 		return nil, err
 	}
 
+	overrides, err := store.ListTriggerOverrides()
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert the list of triggers to FpTrigger type
 	var fpTriggers []types.FpTrigger
 
@@ -79,6 +89,7 @@ func ListTriggers(rootMod string) (*types.ListTriggerResponse, error) {
 		if err != nil {
 			return nil, err
 		}
+		applyTriggerOverride(fpTrigger, &trigger, overrides[trigger.Name()])
 
 		fpTriggers = append(fpTriggers, *fpTrigger)
 	}
@@ -101,13 +112,14 @@ func ListTriggers(rootMod string) (*types.ListTriggerResponse, error) {
 }
 
 // @Summary Get trigger
-// @Description Get trigger
+// @Description Get trigger, with the next fire times of a scheduled trigger
 // @ID   trigger_get
 // @Tags Trigger
 // @Accept json
 // @Produce json
 // / ...
 // @Param trigger_name path string true "The name of the trigger" format(^[a-z]{0,32}$)
+// @Param fire_times query int false "The number of next fire times of a scheduled trigger to return. If not specified will default to 5." default(5) minimum(0) maximum(100)
 // ...
 // @Success 200 {object} types.FpTrigger
 // @Failure 400 {object} perr.ErrorModel
@@ -126,7 +138,13 @@ func (api *APIService) getTrigger(c *gin.Context) {
 	}
 	triggerName := uri.TriggerName
 
-	fpTrigger, err := GetTrigger(triggerName, api.EsService.RootMod.Name())
+	var query types.TriggerRequestQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+
+	fpTrigger, err := GetTrigger(triggerName, api.EsService.RootMod.Name(), query.GetFireTimes())
 	if err != nil {
 		common.AbortWithError(c, err)
 		return
@@ -135,7 +153,8 @@ func (api *APIService) getTrigger(c *gin.Context) {
 	c.JSON(http.StatusOK, fpTrigger)
 }
 
-func GetTrigger(triggerName string, rootMod string) (*types.FpTrigger, error) {
+// GetTrigger returns the trigger with its runtime state and, for an enabled scheduled trigger, its next fire times
+func GetTrigger(triggerName string, rootMod string, fireTimes int) (*types.FpTrigger, error) {
 	// If we run the API server with a mod foo, in order get the trigger, the API needs the fully-qualified name of the trigger.
 	// For example: foo.trigger.trigger_type.bar
 	// However, since foo is the top level mod, we should be able to just get the trigger bar
@@ -168,15 +187,209 @@ func GetTrigger(triggerName string, rootMod string) (*types.FpTrigger, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	override, err := store.GetTriggerOverride(trigger.Name())
+	if err != nil {
+		return nil, err
+	}
+	applyTriggerOverride(fpTrigger, trigger, override)
+
+	err = setNextFireTimes(fpTrigger, trigger, fireTimes)
+	if err != nil {
+		return nil, err
+	}
+
+	return fpTrigger, nil
+}
+
+// setNextFireTimes sets the next fire times of an enabled scheduled trigger
+func setNextFireTimes(fpTrigger *types.FpTrigger, t *resources.Trigger, fireTimes int) error {
+	if _, ok := t.Config.(resources.ScheduledTriggerConfig); !ok || !fpTrigger.Enabled || fireTimes <= 0 {
+		return nil
+	}
+
+	var err error
+	fpTrigger.NextFireTimes, err = trigger.NextFireTimes(t, time.Now(), fireTimes)
+	return err
+}
+
+// applyTriggerOverride sets the runtime state of the trigger, its override may be nil
+func applyTriggerOverride(fpTrigger *types.FpTrigger, t *resources.Trigger, override *store.TriggerOverride) {
+	fpTrigger.Enabled = trigger.IsEnabled(t, override)
+	fpTrigger.Paused = fpTrigger.Enabled && trigger.IsPaused(override)
+	if override != nil {
+		fpTrigger.Until = override.Until
+	}
+}
+
+// triggerCommandStates are the runtime states set by the trigger commands
+var triggerCommandStates = map[string]string{
+	"enable":  localconstants.TriggerOverrideEnabled,
+	"disable": localconstants.TriggerOverrideDisabled,
+	"pause":   localconstants.TriggerOverridePaused,
+}
+
+// SetTriggerState enables, disables or pauses the trigger, until the given time (a RFC 3339 timestamp or a duration)
+// if not empty. The scheduler, if not nil, applies the new state straight away, otherwise the running servers apply
+// it within a minute.
+func SetTriggerState(triggerName, command, until, rootMod string, triggerScheduler TriggerScheduler) (*types.FpTrigger, error) {
+	state, ok := triggerCommandStates[command]
+	if !ok {
+		return nil, perr.BadRequestWithMessage("invalid trigger command: " + command)
+	}
+
+	trg, err := db.GetTrigger(triggerName)
+	if err != nil {
+		if perr.IsNotFound(err) {
+			return nil, perr.NotFoundWithMessage("trigger " + triggerName + " not found")
+		}
+		return nil, err
+	}
+
+	var untilTime *time.Time
+	if until != "" {
+		now := time.Now().UTC()
+		if t, err := time.Parse(time.RFC3339, until); err == nil {
+			untilTime = &t
+		} else {
+			duration, err := parseTimeDuration(until)
+			if err != nil {
+				return nil, err
+			}
+			t := now.Add(duration)
+			untilTime = &t
+		}
+	}
+
+	err = trigger.SetOverride(trg, state, untilTime)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Trigger state changed", "trigger", trg.Name(), "command", command, "until", until)
+
+	if triggerScheduler != nil {
+		err = triggerScheduler.RescheduleTriggers()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fpTrigger, err := types.FpTriggerFromModTrigger(*trg, rootMod)
+	if err != nil {
+		return nil, err
+	}
+
+	override, err := store.GetTriggerOverride(trg.Name())
+	if err != nil {
+		return nil, err
+	}
+	applyTriggerOverride(fpTrigger, trg, override)
+
 	return fpTrigger, nil
 }
 
+// TriggerScheduler gives the fire times of the scheduled triggers and applies their runtime states
+type TriggerScheduler interface {
+	TriggerFireTimes(triggerName string) (previous, next *time.Time)
+
+	// RescheduleTriggers applies the runtime states of the triggers
+	RescheduleTriggers() error
+}
+
+// @Summary List trigger runs
+// @Description Lists the runs of a trigger, newest first, with the previous and next fire time of a scheduled trigger
+// @ID   trigger_list_runs
+// @Tags Trigger
+// @Accept json
+// @Produce json
+// / ...
+// @Param trigger_name path string true "The name of the trigger" format(^[a-z_]{0,32}$)
+// @Param limit query int false "The max number of items to fetch per page of data, subject to a min and max of 1 and 100 respectively. If not specified will default to 25." default(25) minimum(1) maximum(100)
+// @Param next_token query string false "When list results are truncated, next_token will be returned, which is a cursor to fetch the next page of data. Pass next_token to the subsequent list request to fetch the next page of data."
+// ...
+// @Success 200 {object} types.ListTriggerRunResponse
+// @Failure 400 {object} perr.ErrorModel
+// @Failure 401 {object} perr.ErrorModel
+// @Failure 403 {object} perr.ErrorModel
+// @Failure 404 {object} perr.ErrorModel
+// @Failure 429 {object} perr.ErrorModel
+// @Failure 500 {object} perr.ErrorModel
+// @Router /trigger/{trigger_name}/runs [get]
+func (api *APIService) listTriggerRuns(c *gin.Context) {
+	var uri types.TriggerRequestURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+
+	nextToken, limit, err := common.ListPagingRequest(c)
+	if err != nil {
+		common.AbortWithError(c, perr.BadRequestWithMessage(err.Error()))
+		return
+	}
+
+	result, err := ListTriggerRuns(ConstructTriggerFullyQualifiedName(uri.TriggerName), nextToken, limit, api.triggerScheduler)
+	if err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListTriggerRuns returns a page of the run history of a trigger. The fire times are only set if the trigger is
+// scheduled by the given scheduler, which may be nil.
+func ListTriggerRuns(triggerName, nextToken string, limit int, triggerScheduler TriggerScheduler) (*types.ListTriggerRunResponse, error) {
+	trg, err := db.GetTrigger(triggerName)
+	if err != nil {
+		if perr.IsNotFound(err) {
+			return nil, perr.NotFoundWithMessage("trigger " + triggerName + " not found")
+		}
+		return nil, err
+	}
+
+	runs, cursor, err := store.ListTriggerRuns(trg.Name(), nextToken, limit)
+	if err != nil {
+		slog.Error("Error listing trigger runs", "trigger", trg.Name(), "error", err)
+		return nil, err
+	}
+
+	result := &types.ListTriggerRunResponse{
+		Trigger: trg.Name(),
+		Items:   []types.TriggerRun{},
+	}
+
+	for _, run := range runs {
+		result.Items = append(result.Items, types.TriggerRun{
+			ExecutionID:   run.ExecutionID,
+			Source:        run.Source,
+			FiredAt:       run.FiredAt,
+			Status:        run.State,
+			InsertedCount: run.InsertedCount,
+			UpdatedCount:  run.UpdatedCount,
+			DeletedCount:  run.DeletedCount,
+		})
+	}
+
+	if cursor != "" {
+		token := base64.StdEncoding.EncodeToString([]byte(cursor))
+		result.NextToken = &token
+	}
+
+	if triggerScheduler != nil {
+		result.PreviousFireTime, result.NextFireTime = triggerScheduler.TriggerFireTimes(trg.FullName)
+	}
+
+	return result, nil
+}
+
 func ConstructTriggerFullyQualifiedName(triggerName string) string {
 	return ConstructFullyQualifiedName("trigger", 2, triggerName)
 }
 
 func ExecuteTrigger(ctx context.Context, input types.CmdTrigger, executionId, triggerName string, esService *es.ESService) (string, error) {
-	_, err := db.GetTrigger(triggerName)
+	trg, err := db.GetTrigger(triggerName)
 	if err != nil {
 		if perr.IsNotFound(err) {
 			newErr := perr.NotFoundWithMessage("unable to find trigger " + triggerName)
@@ -188,6 +401,12 @@ func ExecuteTrigger(ctx context.Context, input types.CmdTrigger, executionId, tr
 	}
 
 	executionCmd := event.NewExecutionQueueForTrigger(executionId, triggerName)
+
+	err = store.StartTriggerRun(executionCmd.Event.ExecutionID, trg.Name(), localconstants.TriggerSourceManual)
+	if err != nil {
+		return "", err
+	}
+
 	err = esService.CommandBus.Send(ctx, executionCmd)
 	if err != nil {
 		return "", err
@@ -230,6 +449,17 @@ func (api *APIService) cmdTrigger(c *gin.Context) {
 
 	triggerName := ConstructTriggerFullyQualifiedName(uri.TriggerName)
 
+	if _, ok := triggerCommandStates[input.Command]; ok {
+		fpTrigger, err := SetTriggerState(triggerName, input.Command, input.Until, api.EsService.RootMod.Name(), api.triggerScheduler)
+		if err != nil {
+			common.AbortWithError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, fpTrigger)
+		return
+	}
+
 	executionMode := input.GetExecutionMode()
 	waitRetry := input.GetWaitRetry()
 
@@ -12,12 +12,15 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/spf13/viper"
+	localconstants "github.com/turbot/flowpipe/internal/constants"
 	"github.com/turbot/flowpipe/internal/es/db"
 	"github.com/turbot/flowpipe/internal/es/event"
 	"github.com/turbot/flowpipe/internal/es/execution"
 	"github.com/turbot/flowpipe/internal/output"
 	"github.com/turbot/flowpipe/internal/resources"
 	"github.com/turbot/flowpipe/internal/service/api/common"
+	"github.com/turbot/flowpipe/internal/store"
+	"github.com/turbot/flowpipe/internal/trigger"
 	"github.com/turbot/flowpipe/internal/types"
 	"github.com/turbot/flowpipe/internal/util"
 	"github.com/turbot/pipe-fittings/cache"
@@ -75,9 +78,15 @@ func (api *APIService) runTriggerHook(c *gin.Context) {
 		return
 	}
 
-	// Check if the HTTP trigger is enabled
+	override, err := store.GetTriggerOverride(t.Name())
+	if err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+
+	// Check if the HTTP trigger is enabled, and not paused at runtime
 	// If not enabled, return a 404 error with a custom error type
-	if t.Enabled != nil && !*t.Enabled {
+	if !trigger.IsFiring(t, override) {
 		common.AbortWithError(c, perr.NotFoundWithMessageAndType(perr.ErrorCodeTriggerDisabled, "Trigger Disabled"))
 		return
 	}
@@ -113,18 +122,28 @@ func (api *APIService) runTriggerHook(c *gin.Context) {
 		return
 	}
 
-	body := ""
+	var bodyBytes []byte
 	if c.Request.Body != nil {
-		bodyBytes, err := io.ReadAll(c.Request.Body)
+		bodyBytes, err = io.ReadAll(c.Request.Body)
 		if err != nil {
 			common.AbortWithError(c, err)
 			return
 		}
-		body = string(bodyBytes)
 	}
+
+	// the hash only proves the URL is known, the auth blocks of the trigger authenticate the sender
+	clientIP := trigger.RequestClientIP(c.Request, api.trustedProxies)
+	err = trigger.VerifyHttpAuth(mod, t, c.Request.Header, clientIP, bodyBytes)
+	if err != nil {
+		slog.Warn("HTTP trigger request rejected", "trigger", t.Name(), "client_ip", clientIP, "error", err)
+		output.RenderServerOutput(c, types.NewServerOutputTriggerRejected(time.Now(), t.Name(), clientIP, err))
+		common.AbortWithError(c, err)
+		return
+	}
+
 	data := map[string]interface{}{}
 
-	data["request_body"] = body
+	data["request_body"] = string(bodyBytes)
 	data["request_headers"] = map[string]string{}
 	for k, v := range c.Request.Header {
 		data["request_headers"].(map[string]string)[k] = v[0]
@@ -180,6 +199,12 @@ func (api *APIService) runTriggerHook(c *gin.Context) {
 		common.AbortWithError(c, error_helpers.HclDiagsToError("trigger", diags))
 	}
 
+	idempotencyKey, diags := triggerMethod.GetIdempotencyKey(evalContext)
+	if diags.HasErrors() {
+		common.AbortWithError(c, error_helpers.HclDiagsToError("trigger", diags))
+		return
+	}
+
 	pipeline := triggerMethod.Pipeline
 	pipelineName := pipeline.AsValueMap()["name"].AsString()
 
@@ -189,10 +214,27 @@ func (api *APIService) runTriggerHook(c *gin.Context) {
 		Event:               event.NewFlowEvent(executionCmd.Event),
 		PipelineExecutionID: util.NewPipelineExecutionId(),
 		Name:                pipelineName,
+		Trigger:             t.Name(),
 	}
 
 	pipelineCmd.Args = pipelineArgs
 
+	// providers retry the deliveries they don't see acknowledged, a retried delivery gets the execution started by the
+	// first one
+	if idempotencyKey != "" {
+		existing, claimed, err := store.ClaimIdempotencyKey(t.Name(), idempotencyKey, pipelineCmd.Event.ExecutionID, pipelineCmd.PipelineExecutionID, triggerMethod.IdempotencyTtl)
+		if err != nil {
+			common.AbortWithError(c, err)
+			return
+		}
+
+		if !claimed {
+			slog.Info("Duplicate HTTP trigger delivery, returning the original execution", "trigger", t.Name(), "idempotency_key", idempotencyKey, "execution_id", existing.ExecutionID)
+			api.respondWithExistingExecution(c, triggerMethod, existing, pipelineName, waitRetry)
+			return
+		}
+	}
+
 	if output.IsServerMode {
 		output.RenderServerOutput(c, types.NewServerOutputTriggerExecution(time.Now(), pipelineCmd.Event.ExecutionID, t.Name(), pipelineName))
 	}
@@ -200,10 +242,22 @@ func (api *APIService) runTriggerHook(c *gin.Context) {
 	executionCmd.PipelineQueue = &pipelineCmd
 
 	if err := api.EsService.Send(executionCmd); err != nil {
+		if idempotencyKey != "" {
+			// the execution never started, let the provider's retry start it
+			releaseErr := store.ReleaseIdempotencyKey(t.Name(), idempotencyKey, pipelineCmd.Event.ExecutionID)
+			if releaseErr != nil {
+				slog.Error("Error releasing idempotency key", "trigger", t.Name(), "error", releaseErr)
+			}
+		}
 		common.AbortWithError(c, err)
 		return
 	}
 
+	err = store.StartTriggerRun(pipelineCmd.Event.ExecutionID, t.Name(), localconstants.TriggerSourceWebhook)
+	if err != nil {
+		slog.Error("Error recording trigger run", "trigger", t.Name(), "error", err)
+	}
+
 	if triggerMethod.ExecutionMode == "synchronous" {
 		pipelineExecutionResponse, err := api.waitForPipeline(pipelineCmd, waitRetry)
 		api.processSinglePipelineResult(c, &pipelineExecutionResponse, &pipelineCmd, err)
@@ -222,6 +276,37 @@ func (api *APIService) runTriggerHook(c *gin.Context) {
 	c.JSON(http.StatusOK, pipelineExecutionResponse)
 }
 
+// respondWithExistingExecution responds to a duplicate delivery with the execution started by the first delivery, the
+// same way the first delivery was responded to
+func (api *APIService) respondWithExistingExecution(c *gin.Context, triggerMethod *resources.TriggerHTTPMethod, existing *store.IdempotencyKey, pipelineName string, waitRetry int) {
+	pipelineCmd := event.PipelineQueue{
+		Event: &event.Event{
+			ExecutionID: existing.ExecutionID,
+		},
+		PipelineExecutionID: existing.PipelineExecutionID,
+		Name:                pipelineName,
+	}
+
+	c.Header("flowpipe-idempotent-replayed", "true")
+
+	if triggerMethod.ExecutionMode == "synchronous" {
+		pipelineExecutionResponse, err := api.waitForPipeline(pipelineCmd, waitRetry)
+		api.processSinglePipelineResult(c, &pipelineExecutionResponse, &pipelineCmd, err)
+		return
+	}
+
+	pipelineExecutionResponse := types.PipelineExecutionResponse{
+		Flowpipe: types.FlowpipeResponseMetadata{
+			ExecutionID:         existing.ExecutionID,
+			PipelineExecutionID: existing.PipelineExecutionID,
+		},
+	}
+
+	c.Header("flowpipe-execution-id", existing.ExecutionID)
+	c.Header("flowpipe-pipeline-execution-id", existing.PipelineExecutionID)
+	c.JSON(http.StatusOK, pipelineExecutionResponse)
+}
+
 func (api *APIService) waitForPipeline(pipelineCmd event.PipelineQueue, waitRetry int) (types.PipelineExecutionResponse, error) {
 	if waitRetry == 0 {
 		waitRetry = 60
@@ -249,7 +334,7 @@ func (api *APIService) waitForPipeline(pipelineCmd event.PipelineQueue, waitRetr
 		}
 
 		// Wait for the execution to finish
-		if ex.Status == expectedState || ex.Status == "failed" || ex.Status == "finished" || ex.Status == "paused" {
+		if ex.Status == expectedState || ex.Status == "failed" || ex.Status == "finished" || ex.Status == "paused" || ex.Status == localconstants.StateCanceled {
 			break
 		}
 	}
@@ -313,7 +398,7 @@ func WaitForTrigger(triggerName, executionId string, waitRetry int) (types.Trigg
 				lastStatus = event.HandlerExecutionFinished
 			} else if ex.Status == "paused" {
 				lastStatus = event.HandlerExecutionPaused
-			} else if ex.Status == "cancelled" {
+			} else if ex.Status == localconstants.StateCanceled {
 				lastStatus = event.HandlerExecutionCancelled
 			}
 			break
@@ -6,16 +6,20 @@ import (
 	"os"
 	"time"
 
+	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
 	slogwatermill "github.com/denisss025/slog-watermill"
 	_ "github.com/garsue/watermillzap"
+	"github.com/turbot/flowpipe/internal/constants"
 	"github.com/turbot/flowpipe/internal/es/command"
 	"github.com/turbot/flowpipe/internal/es/execution"
 	"github.com/turbot/flowpipe/internal/es/handler"
+	"github.com/turbot/flowpipe/internal/filepaths"
 	"github.com/turbot/flowpipe/internal/log"
 	"github.com/turbot/flowpipe/internal/service/es/middleware"
+	"github.com/turbot/flowpipe/internal/service/es/pubsub"
 	"github.com/turbot/flowpipe/internal/util"
 	"github.com/turbot/pipe-fittings/modconfig"
 	"github.com/turbot/pipe-fittings/perr"
@@ -28,17 +32,36 @@ type ESService struct {
 	EventBus   command.FpEventBus
 	router     *message.Router
 
+	// The watermill Pub/Sub backend used for the command and event buses, see constants.EventBusMemory and
+	// constants.EventBusSQLite
+	eventBus string
+
 	RootMod   *modconfig.Mod
 	Status    string     `json:"status"`
 	StartedAt *time.Time `json:"started_at,omitempty"`
 	StoppedAt *time.Time `json:"stopped_at,omitempty"`
 }
 
-func NewESService(ctx context.Context) (*ESService, error) {
+// ESServiceOption defines a type of function to configures the ESService.
+type ESServiceOption func(*ESService)
+
+// WithEventBus sets the Pub/Sub backend used for the command and event buses.
+func WithEventBus(eventBus string) ESServiceOption {
+	return func(es *ESService) {
+		es.eventBus = eventBus
+	}
+}
+
+func NewESService(ctx context.Context, opts ...ESServiceOption) (*ESService, error) {
 	// Defaults
 	es := &ESService{
-		ctx:    ctx,
-		Status: "initialized",
+		ctx:      ctx,
+		eventBus: constants.DefaultEventBus,
+		Status:   "initialized",
+	}
+	// Set options
+	for _, opt := range opts {
+		opt(es)
 	}
 	return es, nil
 }
@@ -61,6 +84,11 @@ func (es *ESService) IsRunning() bool {
 	return es.router.IsRunning()
 }
 
+// IsDurable returns true if the commands and events queued on the event bus survive a restart
+func (es *ESService) IsDurable() bool {
+	return es.eventBus == constants.EventBusSQLite
+}
+
 func (es *ESService) Start() error {
 	slog.Debug("ES starting")
 	defer slog.Debug("ES started")
@@ -69,14 +97,11 @@ func (es *ESService) Start() error {
 
 	cqrsMarshaler := cqrs.JSONMarshaler{}
 
-	goChannelConfig := gochannel.Config{
-		//TODO - I really don't understand this and I'm not sure it's necessary.
-		// OutputChannelBuffer: 10000,
-		// Persistent:          true,
-	}
 	wLogger := slogwatermill.New(log.FlowpipeLogger())
-	commandsPubSub := gochannel.NewGoChannel(goChannelConfig, wLogger)
-	eventsPubSub := gochannel.NewGoChannel(goChannelConfig, wLogger)
+	commandsPubSub, eventsPubSub, err := es.newPubSubs(wLogger)
+	if err != nil {
+		return err
+	}
 
 	// CQRS is built on messages router. Detailed documentation: https://watermill.io/docs/messages-router/
 	router, err := message.NewRouter(message.RouterConfig{
@@ -170,6 +195,7 @@ func (es *ESService) Start() error {
 				handler.ExecutionPlanned{CommandBus: &handler.FpCommandBusImpl{Cb: cb}},
 				handler.ExecutionFinished{CommandBus: &handler.FpCommandBusImpl{Cb: cb}},
 				handler.ExecutionFailed{CommandBus: &handler.FpCommandBusImpl{Cb: cb}},
+				handler.ExecutionCanceled{CommandBus: &handler.FpCommandBusImpl{Cb: cb}},
 				handler.ExecutionPaused{CommandBus: &handler.FpCommandBusImpl{Cb: cb}},
 				handler.TriggerQueued{CommandBus: &handler.FpCommandBusImpl{Cb: cb}},
 				handler.TriggerStarted{CommandBus: &handler.FpCommandBusImpl{Cb: cb}},
@@ -214,6 +240,39 @@ func (es *ESService) Start() error {
 	return nil
 }
 
+type pubSub interface {
+	message.Publisher
+	message.Subscriber
+}
+
+// newPubSubs returns the Pub/Subs for the command and event buses
+func (es *ESService) newPubSubs(wLogger watermill.LoggerAdapter) (commandsPubSub, eventsPubSub pubSub, err error) {
+	switch es.eventBus {
+	case constants.EventBusMemory:
+		goChannelConfig := gochannel.Config{
+			//TODO - I really don't understand this and I'm not sure it's necessary.
+			// OutputChannelBuffer: 10000,
+			// Persistent:          true,
+		}
+		commandsPubSub = gochannel.NewGoChannel(goChannelConfig, wLogger)
+		eventsPubSub = gochannel.NewGoChannel(goChannelConfig, wLogger)
+		return commandsPubSub, eventsPubSub, nil
+
+	case constants.EventBusSQLite:
+		// commands and events are published to different topics, so they can share the same database
+		sqlitePubSub, err := pubsub.NewSQLite(pubsub.SQLiteConfig{
+			DBPath: filepaths.EventBusDBFileName(),
+		}, wLogger)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sqlitePubSub, sqlitePubSub, nil
+
+	default:
+		return nil, nil, perr.BadRequestWithMessage("invalid event bus: " + es.eventBus + ", valid values are " + constants.EventBusMemory + " or " + constants.EventBusSQLite)
+	}
+}
+
 func (es *ESService) Stop() error {
 
 	slog.Debug("ES stopping")
@@ -0,0 +1,327 @@
+package pubsub
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/turbot/pipe-fittings/perr"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type SQLiteConfig struct {
+	// Path to the SQLite database file holding the queued messages
+	DBPath string
+
+	// How often the subscribers check the database for messages that were not signalled in process (for example
+	// messages left over from a previous run). Defaults to 1 second.
+	PollInterval time.Duration
+}
+
+// SQLite is a durable watermill Pub/Sub. Published messages are written to a SQLite database and only removed once the
+// subscriber has acked them, so messages that were queued (or in-flight) when the process died are redelivered when
+// the topic is subscribed to again.
+//
+// Like the gochannel Pub/Sub, a subscriber receives the messages of a topic one at a time: the next message is only
+// sent once the previous one is acked. Nacked messages are resent. Unlike gochannel, a topic can only have a single
+// subscriber, which is how the CQRS facade wires the command and event handlers (one topic per handler).
+type SQLite struct {
+	config SQLiteConfig
+	logger watermill.LoggerAdapter
+	db     *sql.DB
+
+	subscribersLock sync.Mutex
+	subscribers     map[string]chan struct{}
+
+	closing     chan struct{}
+	closed      bool
+	closeLock   sync.Mutex
+	subscribeWg sync.WaitGroup
+}
+
+func NewSQLite(config SQLiteConfig, logger watermill.LoggerAdapter) (*SQLite, error) {
+	if config.DBPath == "" {
+		return nil, perr.BadRequestWithMessage("SQLite pub/sub requires a database path")
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if logger == nil {
+		logger = watermill.NopLogger{}
+	}
+
+	db, err := sql.Open("sqlite3", config.DBPath+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, perr.InternalWithMessage("error opening pub/sub database: " + err.Error())
+	}
+
+	// SQLite only supports a single writer
+	db.SetMaxOpenConns(1)
+
+	createTableSQL := `
+	create table if not exists message (
+		offset integer primary key autoincrement,
+		topic text not null,
+		uuid text not null,
+		payload blob,
+		metadata text,
+		created_at datetime not null
+	)`
+
+	_, err = db.Exec(createTableSQL)
+	if err != nil {
+		db.Close()
+		return nil, perr.InternalWithMessage("error creating message table: " + err.Error())
+	}
+
+	_, err = db.Exec(`create index if not exists idx_message_topic on message (topic, offset)`)
+	if err != nil {
+		db.Close()
+		return nil, perr.InternalWithMessage("error creating message index: " + err.Error())
+	}
+
+	return &SQLite{
+		config:      config,
+		logger:      logger,
+		db:          db,
+		subscribers: map[string]chan struct{}{},
+		closing:     make(chan struct{}),
+	}, nil
+}
+
+// Publish persists the messages before signalling the subscriber of the topic, the message is therefore not lost if
+// the process dies before it is handled.
+func (s *SQLite) Publish(topic string, messages ...*message.Message) error {
+	if s.isClosed() {
+		return perr.InternalWithMessage("pub/sub is closed")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range messages {
+		metadata, err := json.Marshal(msg.Metadata)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+
+		_, err = tx.Exec("insert into message (topic, uuid, payload, metadata, created_at) values (?, ?, ?, ?, ?)",
+			topic, msg.UUID, []byte(msg.Payload), string(metadata), time.Now().UTC())
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	s.subscribersLock.Lock()
+	notify := s.subscribers[topic]
+	s.subscribersLock.Unlock()
+
+	if notify != nil {
+		select {
+		case notify <- struct{}{}:
+		default:
+			// the subscriber is already due to check for new messages
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLite) Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error) {
+	if s.isClosed() {
+		return nil, perr.InternalWithMessage("pub/sub is closed")
+	}
+
+	s.subscribersLock.Lock()
+	defer s.subscribersLock.Unlock()
+
+	if s.subscribers[topic] != nil {
+		return nil, perr.BadRequestWithMessage("topic " + topic + " already has a subscriber")
+	}
+
+	notify := make(chan struct{}, 1)
+	s.subscribers[topic] = notify
+
+	output := make(chan *message.Message)
+
+	s.subscribeWg.Add(1)
+	go func() {
+		defer s.subscribeWg.Done()
+		defer close(output)
+		defer func() {
+			s.subscribersLock.Lock()
+			delete(s.subscribers, topic)
+			s.subscribersLock.Unlock()
+		}()
+
+		s.consume(ctx, topic, notify, output)
+	}()
+
+	return output, nil
+}
+
+func (s *SQLite) consume(ctx context.Context, topic string, notify <-chan struct{}, output chan<- *message.Message) {
+	logFields := watermill.LogFields{"topic": topic}
+
+	for {
+		offset, msg, err := s.next(topic)
+		if err != nil {
+			s.logger.Error("Error reading next message", err, logFields)
+		}
+
+		if msg == nil {
+			select {
+			case <-notify:
+			case <-time.After(s.config.PollInterval):
+			case <-ctx.Done():
+				return
+			case <-s.closing:
+				return
+			}
+			continue
+		}
+
+		if !s.send(ctx, msg, output, logFields) {
+			return
+		}
+
+		_, err = s.db.Exec("delete from message where offset = ?", offset)
+		if err != nil {
+			s.logger.Error("Error removing acked message", err, logFields)
+		}
+	}
+}
+
+// send delivers the message until it is acked. Returns false if the subscription is closed before the message is acked.
+func (s *SQLite) send(ctx context.Context, msg *message.Message, output chan<- *message.Message, logFields watermill.LogFields) bool {
+	msgCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for {
+		// send a fresh copy so a resend after a nack is not already nacked
+		msgToSend := msg.Copy()
+		msgToSend.SetContext(msgCtx)
+
+		select {
+		case output <- msgToSend:
+		case <-ctx.Done():
+			return false
+		case <-s.closing:
+			return false
+		}
+
+		select {
+		case <-msgToSend.Acked():
+			return true
+		case <-msgToSend.Nacked():
+			s.logger.Trace("Nack received, resending message", logFields)
+			continue
+		case <-ctx.Done():
+			return false
+		case <-s.closing:
+			return false
+		}
+	}
+}
+
+func (s *SQLite) next(topic string) (int64, *message.Message, error) {
+	row := s.db.QueryRow("select offset, uuid, payload, metadata from message where topic = ? order by offset limit 1", topic)
+
+	var offset int64
+	var uuid, metadata string
+	var payload []byte
+
+	err := row.Scan(&offset, &uuid, &payload, &metadata)
+	if err == sql.ErrNoRows {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	msg := message.NewMessage(uuid, payload)
+	if metadata != "" {
+		err = json.Unmarshal([]byte(metadata), &msg.Metadata)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return offset, msg, nil
+}
+
+// PendingPayloads returns the payloads of the messages in the database that haven't been acked yet, in the order they
+// were published. They are redelivered once the topics are subscribed to again. There are none if the database
+// doesn't exist.
+func PendingPayloads(dbPath string) ([][]byte, error) {
+	_, err := os.Stat(dbPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	db, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, perr.InternalWithMessage("error opening pub/sub database: " + err.Error())
+	}
+	defer db.Close()
+
+	rows, err := db.Query("select payload from message order by offset")
+	if err != nil {
+		return nil, perr.InternalWithMessage("error listing pending messages: " + err.Error())
+	}
+	defer rows.Close()
+
+	var payloads [][]byte
+	for rows.Next() {
+		var payload []byte
+		err = rows.Scan(&payload)
+		if err != nil {
+			return nil, perr.InternalWithMessage("error reading pending message: " + err.Error())
+		}
+		payloads = append(payloads, payload)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, perr.InternalWithMessage("error listing pending messages: " + err.Error())
+	}
+
+	return payloads, nil
+}
+
+func (s *SQLite) isClosed() bool {
+	s.closeLock.Lock()
+	defer s.closeLock.Unlock()
+
+	return s.closed
+}
+
+// Close stops the subscribers and closes the database. Messages that have not been acked are kept and will be
+// redelivered on the next run. Close can be called more than once (the router closes every handler's subscriber).
+func (s *SQLite) Close() error {
+	s.closeLock.Lock()
+	if s.closed {
+		s.closeLock.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.closing)
+	s.closeLock.Unlock()
+
+	s.subscribeWg.Wait()
+
+	return s.db.Close()
+}
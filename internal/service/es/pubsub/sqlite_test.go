@@ -0,0 +1,129 @@
+package pubsub
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func receive(t *testing.T, messages <-chan *message.Message) *message.Message {
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for message")
+		return nil
+	}
+}
+
+func TestSQLitePublishSubscribe(t *testing.T) {
+	assert := assert.New(t)
+
+	ps, err := NewSQLite(SQLiteConfig{DBPath: filepath.Join(t.TempDir(), "bus.db")}, nil)
+	if err != nil {
+		assert.Fail("error creating pub/sub", err)
+		return
+	}
+	defer ps.Close()
+
+	messages, err := ps.Subscribe(context.Background(), "command.step_start")
+	if err != nil {
+		assert.Fail("error subscribing", err)
+		return
+	}
+
+	_, err = ps.Subscribe(context.Background(), "command.step_start")
+	assert.NotNil(err, "a topic can only have a single subscriber")
+
+	msg := message.NewMessage(watermill.NewUUID(), []byte(`{"foo":"bar"}`))
+	msg.Metadata.Set("name", "StepStart")
+	err = ps.Publish("command.step_start", msg)
+	assert.Nil(err)
+
+	received := receive(t, messages)
+	assert.Equal(msg.UUID, received.UUID)
+	assert.Equal(`{"foo":"bar"}`, string(received.Payload))
+	assert.Equal("StepStart", received.Metadata.Get("name"))
+
+	// nacked messages are resent
+	received.Nack()
+	received = receive(t, messages)
+	assert.Equal(msg.UUID, received.UUID)
+	received.Ack()
+}
+
+func TestSQLiteRedeliverAfterRestart(t *testing.T) {
+	assert := assert.New(t)
+
+	dbPath := filepath.Join(t.TempDir(), "bus.db")
+
+	ps, err := NewSQLite(SQLiteConfig{DBPath: dbPath}, nil)
+	if err != nil {
+		assert.Fail("error creating pub/sub", err)
+		return
+	}
+
+	first := message.NewMessage(watermill.NewUUID(), []byte("first"))
+	second := message.NewMessage(watermill.NewUUID(), []byte("second"))
+	err = ps.Publish("command.pipeline_plan", first, second)
+	assert.Nil(err)
+
+	messages, err := ps.Subscribe(context.Background(), "command.pipeline_plan")
+	if err != nil {
+		assert.Fail("error subscribing", err)
+		return
+	}
+
+	// ack the first message, the second one is in-flight when the process "dies"
+	received := receive(t, messages)
+	assert.Equal(first.UUID, received.UUID)
+	received.Ack()
+
+	received = receive(t, messages)
+	assert.Equal(second.UUID, received.UUID)
+
+	err = ps.Close()
+	assert.Nil(err)
+
+	// the in-flight message is still pending
+	payloads, err := PendingPayloads(dbPath)
+	assert.Nil(err)
+	assert.Equal([][]byte{[]byte("second")}, payloads)
+
+	ps, err = NewSQLite(SQLiteConfig{DBPath: dbPath}, nil)
+	if err != nil {
+		assert.Fail("error re-opening pub/sub", err)
+		return
+	}
+	defer ps.Close()
+
+	messages, err = ps.Subscribe(context.Background(), "command.pipeline_plan")
+	if err != nil {
+		assert.Fail("error subscribing", err)
+		return
+	}
+
+	received = receive(t, messages)
+	assert.Equal(second.UUID, received.UUID)
+	assert.Equal("second", string(received.Payload))
+	received.Ack()
+
+	select {
+	case msg := <-messages:
+		assert.Fail("unexpected message redelivered", msg.UUID)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSQLitePendingPayloadsWithoutDatabase(t *testing.T) {
+	assert := assert.New(t)
+
+	payloads, err := PendingPayloads(filepath.Join(t.TempDir(), "missing.db"))
+	assert.Nil(err)
+	assert.Nil(payloads)
+}
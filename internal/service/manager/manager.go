@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/netip"
 	"os"
 	"os/signal"
 	"path"
@@ -17,16 +18,19 @@ import (
 	fpconstants "github.com/turbot/flowpipe/internal/constants"
 	"github.com/turbot/flowpipe/internal/docker"
 	"github.com/turbot/flowpipe/internal/es/db"
+	"github.com/turbot/flowpipe/internal/es/execution"
 	"github.com/turbot/flowpipe/internal/filepaths"
 	"github.com/turbot/flowpipe/internal/flowpipeconfig"
 	"github.com/turbot/flowpipe/internal/fperr"
 	"github.com/turbot/flowpipe/internal/output"
 	fparse "github.com/turbot/flowpipe/internal/parse"
+	"github.com/turbot/flowpipe/internal/primitive"
 	"github.com/turbot/flowpipe/internal/resources"
 	"github.com/turbot/flowpipe/internal/service/api"
 	"github.com/turbot/flowpipe/internal/service/es"
 	"github.com/turbot/flowpipe/internal/service/scheduler"
 	"github.com/turbot/flowpipe/internal/store"
+	"github.com/turbot/flowpipe/internal/trigger"
 	"github.com/turbot/flowpipe/internal/types"
 	"github.com/turbot/flowpipe/internal/util"
 	"github.com/turbot/go-kit/files"
@@ -71,6 +75,13 @@ type Manager struct {
 	HTTPAddress string
 	HTTPPort    int
 
+	eventBus       string
+	recoveryPolicy string
+	leaderElection bool
+	nodeID         string
+	trustedProxies []netip.Prefix
+	skipCleanup    bool
+
 	startup StartupFlag
 
 	Status    string
@@ -113,7 +124,56 @@ func (m *Manager) Start() (*Manager, error) {
 		return nil, err
 	}
 
+	// Force cleanup if it hasn't run for 1 day, the retention policies are in the flowpipe config loaded with the
+	// resources
+	if !m.skipCleanup {
+		fpConfig, err := db.GetFlowpipeConfig()
+		if err != nil {
+			return nil, err
+		}
+		store.ForceCleanup(fpConfig.Retention)
+	}
+
+	if m.leaderElection {
+		// the runs started by this server are recovered by the leader once this server stops renewing its node lease,
+		// the scheduler renews it from now on
+		nodeID, err := nodeID()
+		if err != nil {
+			return nil, err
+		}
+		m.nodeID = nodeID
+		store.SetProcessOwner(nodeID)
+
+		_, err = store.AcquireLease(store.NodeLeaseName(nodeID), nodeID, scheduler.DefaultLeaseTTL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if m.shouldStartES() {
+		// The interrupted processes must be in the cache before the ES service starts, a durable event bus redelivers
+		// their pending commands and events as soon as it is started
+		var interrupted []*execution.ExecutionInMemory
+		if m.eventBus == fpconstants.EventBusSQLite {
+			// the pending commands and events are redelivered whatever the recovery policy
+			err := api.LoadPendingProcesses(filepaths.EventBusDBFileName())
+			if err != nil {
+				return nil, err
+			}
+		}
+		switch {
+		case m.recoveryPolicy == "":
+		case m.leaderElection:
+			// the servers share the process store, the queued and started runs may be running on another server. The
+			// leader recovers the runs of the servers that have stopped, see startSchedulerService
+		default:
+			var err error
+			interrupted, err = api.LoadInterruptedProcesses()
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		err := m.startESService()
 		if err != nil {
 			return nil, err
@@ -128,6 +188,13 @@ func (m *Manager) Start() (*Manager, error) {
 		}
 
 		slog.Info("Flowpipe service started ...")
+
+		if len(interrupted) > 0 {
+			err = api.RecoverProcesses(interrupted, m.recoveryPolicy, m.ESService)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if m.shouldStartAPI() {
@@ -187,9 +254,6 @@ func (m *Manager) initializeModDirectory() error {
 		}
 	}
 
-	// Force cleanup if it hasn't run for 1 day
-	store.ForceCleanup()
-
 	return nil
 }
 
@@ -288,7 +352,12 @@ func (m *Manager) initializeResources() error {
 
 func (m *Manager) startESService() error {
 	// start event sourcing service
-	esService, err := es.NewESService(m.ctx)
+	var esOpts []es.ESServiceOption
+	if m.eventBus != "" {
+		esOpts = append(esOpts, es.WithEventBus(m.eventBus))
+	}
+
+	esService, err := es.NewESService(m.ctx, esOpts...)
 	if err != nil {
 		return err
 	}
@@ -308,7 +377,9 @@ func (m *Manager) startAPIService() error {
 	// Define the API service
 	apiService, err := api.NewAPIService(m.ctx, m.ESService,
 		api.WithHTTPAddress(m.HTTPAddress),
-		api.WithHTTPPort(m.HTTPPort))
+		api.WithHTTPPort(m.HTTPPort),
+		api.WithTriggerScheduler(m),
+		api.WithTrustedProxies(m.trustedProxies))
 
 	if err != nil {
 		return err
@@ -320,14 +391,20 @@ func (m *Manager) startAPIService() error {
 }
 
 func (m *Manager) startSchedulerService() error {
-	s := scheduler.NewSchedulerService(m.ctx, m.ESService, m.triggers)
-	if err := s.Start(); err != nil {
-		slog.Error("error starting scheduler service", "error", err)
-		return err
+	var opts []scheduler.SchedulerServiceOption
+	if m.leaderElection {
+		opts = append(opts, scheduler.WithLeaderElection(m.nodeID))
+
+		if m.recoveryPolicy != "" && m.recoveryPolicy != fpconstants.RecoveryPolicyIgnore {
+			opts = append(opts, scheduler.WithProcessRecovery(func(executionID string) error {
+				return api.RecoverOrphanedProcess(executionID, m.recoveryPolicy, m.ESService)
+			}))
+		}
 	}
 
-	err := s.ScheduleCoreServices()
-	if err != nil {
+	s := scheduler.NewSchedulerService(m.ctx, m.ESService, m.triggers, opts...)
+	if err := s.Start(); err != nil {
+		slog.Error("error starting scheduler service", "error", err)
 		return err
 	}
 
@@ -335,6 +412,22 @@ func (m *Manager) startSchedulerService() error {
 	return nil
 }
 
+// TriggerFireTimes returns the fire times of a scheduled trigger, nil if the scheduler is not running
+func (m *Manager) TriggerFireTimes(triggerName string) (previous, next *time.Time) {
+	if m.schedulerService == nil {
+		return nil, nil
+	}
+	return m.schedulerService.TriggerFireTimes(triggerName)
+}
+
+// RescheduleTriggers applies the runtime states of the triggers, if the scheduler is running
+func (m *Manager) RescheduleTriggers() error {
+	if m.schedulerService == nil {
+		return nil
+	}
+	return m.schedulerService.RescheduleTriggers()
+}
+
 // Stop stops services managed by the Manager.
 func (m *Manager) Stop() error {
 	slog.Debug("manager stopping")
@@ -353,6 +446,13 @@ func (m *Manager) Stop() error {
 		}
 	}
 
+	if m.schedulerService != nil {
+		if err := m.schedulerService.Stop(); err != nil {
+			// Log and continue stopping other services
+			slog.Error("error stopping scheduler service", "error", err)
+		}
+	}
+
 	if m.ESService != nil {
 		if err := m.ESService.Stop(); err != nil {
 			// Log and continue stopping other services
@@ -360,6 +460,9 @@ func (m *Manager) Stop() error {
 		}
 	}
 
+	// Close the databases of the query steps and triggers
+	primitive.CloseQueryPools()
+
 	// Cleanup docker artifacts
 	// TODO - Can we remove this since we cleanup per function etc?
 	if docker.GlobalDockerClient != nil {
@@ -610,10 +713,19 @@ func (m *Manager) renderServerShutdownOutput() {
 func renderServerTriggers(triggers map[string]*resources.Trigger) []sanitize.SanitizedStringer {
 	var outputs []sanitize.SanitizedStringer
 
+	overrides, err := store.ListTriggerOverrides()
+	if err != nil {
+		slog.Error("Error listing trigger overrides", "error", err)
+	}
+
 	for key, t := range triggers {
 		tt := resources.GetTriggerTypeFromTriggerConfig(t.Config)
 		prefix := types.NewServerOutputPrefix(time.Now(), "trigger")
-		o := types.NewServerOutputTrigger(prefix, key, tt, t.Enabled)
+		enabled := t.Enabled
+		if override := overrides[t.Name()]; override != nil {
+			enabled = utils.ToPointer(trigger.IsEnabled(t, override))
+		}
+		o := types.NewServerOutputTrigger(prefix, key, tt, enabled)
 		switch tt {
 		case schema.TriggerTypeHttp:
 			if tc, ok := t.Config.(*resources.TriggerHttp); ok {
@@ -634,8 +746,30 @@ func renderServerTriggers(triggers map[string]*resources.Trigger) []sanitize.San
 				o.Sql = &tc.Sql
 				outputs = append(outputs, o)
 			}
+		case resources.TriggerTypeQueue:
+			if tc, ok := t.Config.(*resources.TriggerQueue); ok {
+				source := tc.Source
+				o.Source = &source
+				outputs = append(outputs, o)
+			}
+		case resources.TriggerTypeFile:
+			if tc, ok := t.Config.(*resources.TriggerFile); ok {
+				path := filepath.Join(tc.Path, tc.Pattern)
+				o.Path = &path
+				outputs = append(outputs, o)
+			}
 		}
 	}
 
 	return outputs
 }
+
+// nodeID identifies this server when competing for the scheduler lease and as the owner of its pipeline runs
+func nodeID() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", perr.InternalWithMessage("unable to get hostname: " + err.Error())
+	}
+
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid()), nil
+}
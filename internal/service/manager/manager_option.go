@@ -1,5 +1,7 @@
 package manager
 
+import "net/netip"
+
 // ManagerOption defines a type of function to configures the Manager.
 type ManagerOption func(*Manager)
 
@@ -30,3 +32,41 @@ func WithServerConfig(addr string, port int) ManagerOption {
 		m.startup |= startES | startAPI | startScheduler
 	}
 }
+
+// WithEventBus sets the Pub/Sub backend used by the event sourcing service, either "memory" (default) or "sqlite".
+func WithEventBus(eventBus string) ManagerOption {
+	return func(m *Manager) {
+		m.eventBus = eventBus
+	}
+}
+
+// WithRecoveryPolicy enables the recovery of the processes interrupted by a server restart, the policy is either
+// "resume", "fail" or "ignore".
+func WithRecoveryPolicy(policy string) ManagerOption {
+	return func(m *Manager) {
+		m.recoveryPolicy = policy
+	}
+}
+
+// WithLeaderElection runs the scheduler in active/passive mode, only the server holding the scheduler lease runs the
+// scheduled triggers. Every server serves the API and webhooks.
+func WithLeaderElection(enabled bool) ManagerOption {
+	return func(m *Manager) {
+		m.leaderElection = enabled
+	}
+}
+
+// WithTrustedProxies sets the reverse proxies whose X-Forwarded-For header gives the client IP of the HTTP trigger
+// requests
+func WithTrustedProxies(trustedProxies []netip.Prefix) ManagerOption {
+	return func(m *Manager) {
+		m.trustedProxies = trustedProxies
+	}
+}
+
+// WithoutCleanup skips the forced cleanup of the process store on start, e.g. to preview the cleanup
+func WithoutCleanup() ManagerOption {
+	return func(m *Manager) {
+		m.skipCleanup = true
+	}
+}
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/turbot/flowpipe/internal/constants"
+	"github.com/turbot/flowpipe/internal/es/handler"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/flowpipe/internal/store"
+	"github.com/turbot/flowpipe/internal/trigger"
+)
+
+const fileWatchRetryInterval = queueReconnectBackoff
+
+// fileListener runs the pipeline of a file trigger for each change of its watched files. The directory is watched
+// again if it can't be watched yet, e.g. a share that isn't mounted.
+type fileListener struct {
+	trigger    *resources.Trigger
+	config     *resources.TriggerFile
+	commandBus handler.FpCommandBus
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newFileListener(t *resources.Trigger, config *resources.TriggerFile, commandBus handler.FpCommandBus) *fileListener {
+	return &fileListener{
+		trigger:    t,
+		config:     config,
+		commandBus: commandBus,
+	}
+}
+
+func (l *fileListener) triggerConfig() resources.TriggerConfig {
+	return l.config
+}
+
+func (l *fileListener) start(ctx context.Context) {
+	ctx, l.cancel = context.WithCancel(ctx)
+	l.done = make(chan struct{})
+
+	slog.Info("Starting file watcher", "trigger", l.trigger.Name(), "path", l.config.Path, "pattern", l.config.Pattern, "events", l.config.Events)
+	go l.run(ctx)
+}
+
+func (l *fileListener) stop() {
+	if l.cancel == nil {
+		return
+	}
+
+	slog.Info("Stopping file watcher", "trigger", l.trigger.Name())
+	l.cancel()
+	<-l.done
+	l.cancel = nil
+}
+
+func (l *fileListener) run(ctx context.Context) {
+	defer close(l.done)
+
+	for {
+		watcher, err := trigger.NewFileWatcher(l.config, func(ev trigger.FileEvent) {
+			l.fire(ctx, ev)
+		})
+		if err == nil {
+			<-ctx.Done()
+			watcher.Close()
+			return
+		}
+
+		slog.Error("Unable to watch files", "trigger", l.trigger.Name(), "path", l.config.Path, "error", err)
+		if !sleepContext(ctx, fileWatchRetryInterval) {
+			return
+		}
+	}
+}
+
+func (l *fileListener) fire(ctx context.Context, ev trigger.FileEvent) {
+	executionCmd, err := trigger.NewFileEventExecution(ctx, l.trigger.Name(), ev)
+	if err != nil {
+		slog.Error("Unable to run file trigger", "trigger", l.trigger.Name(), "path", ev.Path, "event", ev.Event, "error", err)
+		return
+	}
+
+	err = l.commandBus.Send(ctx, executionCmd)
+	if err != nil {
+		slog.Error("Unable to run file trigger", "trigger", l.trigger.Name(), "path", ev.Path, "event", ev.Event, "error", err)
+		return
+	}
+
+	err = store.StartTriggerRun(executionCmd.Event.ExecutionID, l.trigger.Name(), constants.TriggerSourceFile)
+	if err != nil {
+		slog.Error("Error recording trigger run", "trigger", l.trigger.Name(), "error", err)
+	}
+}
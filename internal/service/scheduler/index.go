@@ -6,11 +6,13 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-co-op/gocron"
+	"github.com/turbot/flowpipe/internal/constants"
+	"github.com/turbot/flowpipe/internal/es/db"
 	"github.com/turbot/flowpipe/internal/resources"
-	"github.com/turbot/flowpipe/internal/schedule"
 	"github.com/turbot/flowpipe/internal/service/es"
 	"github.com/turbot/flowpipe/internal/store"
 	"github.com/turbot/flowpipe/internal/trigger"
@@ -24,38 +26,76 @@ type SchedulerService struct {
 	Triggers      map[string]*resources.Trigger
 	esService     *es.ESService
 	cronScheduler *gocron.Scheduler
+	cronLock      sync.Mutex
+
+	// the scheduled firings delayed by the trigger jitter
+	jitter jitterTimers
+
+	// the triggers fired by external events, e.g. queue consumers, run alongside the scheduled triggers, keyed by
+	// trigger name
+	listeners map[string]triggerListener
+
+	// leader election, only the leader runs the scheduled triggers
+	leaderElection bool
+	nodeID         string
+	leaseTTL       time.Duration
+	isLeader       bool
+	leaseRenewedAt time.Time
+	stopElection   chan struct{}
+	electionWg     sync.WaitGroup
+
+	// recovers the runs of the nodes that have stopped, called by the leader
+	recoverProcess func(executionID string) error
 }
 
-func NewSchedulerService(ctx context.Context, esService *es.ESService, triggers map[string]*resources.Trigger) *SchedulerService {
-	return &SchedulerService{
+type SchedulerServiceOption func(*SchedulerService)
+
+func NewSchedulerService(ctx context.Context, esService *es.ESService, triggers map[string]*resources.Trigger, opts ...SchedulerServiceOption) *SchedulerService {
+	s := &SchedulerService{
 		ctx:       ctx,
 		esService: esService,
 		Triggers:  triggers,
+		leaseTTL:  DefaultLeaseTTL,
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
 }
 
 func (s *SchedulerService) RescheduleTriggers() error {
+	s.cronLock.Lock()
+	defer s.cronLock.Unlock()
+
+	return s.rescheduleTriggers()
+}
+
+// rescheduleTriggers is called with the cron lock held
+func (s *SchedulerService) rescheduleTriggers() error {
 	if s.cronScheduler == nil {
 		return nil
 	}
 
+	overrides, err := store.ListTriggerOverrides()
+	if err != nil {
+		return err
+	}
+
 	validJobsNames := []string{}
 
 	for _, t := range s.Triggers {
-		var scheduleString string
-		switch config := t.Config.(type) {
-		case *resources.TriggerSchedule:
-			scheduleString = config.Schedule
-		case *resources.TriggerQuery:
-			scheduleString = config.Schedule
-			if scheduleString == "" {
-				scheduleString = "hourly"
-			}
-		case *resources.TriggerHttp:
+		if _, ok := t.Config.(resources.ScheduledTriggerConfig); !ok {
 			continue
 		}
 
-		if t.Enabled != nil && !*t.Enabled {
+		cronExpression, err := trigger.CronExpression(t)
+		if err != nil {
+			return err
+		}
+
+		if !trigger.IsEnabled(t, overrides[t.Name()]) {
 			// if trigger is disabled, skip the scheduling logic, do not add to the validJobNames list
 			// it will be removed below
 			continue
@@ -66,11 +106,13 @@ func (s *SchedulerService) RescheduleTriggers() error {
 		// Find the job in the scheduler
 		jobs, err := s.cronScheduler.FindJobsByTag("id:" + t.FullName)
 		if err != nil && err == gocron.ErrJobNotFoundWithTag {
-			// Job not found in the scheduler, schedule it
-			err := s.scheduleTrigger(t)
+			// Job not found in the scheduler, schedule it. The trigger is new or re-enabled, the occurrences while it
+			// wasn't scheduled are not missed.
+			err := s.scheduleTrigger(t, overrides[t.Name()])
 			if err != nil {
 				return err
 			}
+			s.setTriggerLastFired(t, time.Now())
 			continue
 		} else if err != nil {
 			// Real error, return the error
@@ -82,23 +124,24 @@ func (s *SchedulerService) RescheduleTriggers() error {
 		}
 
 		if len(jobs) == 0 {
-			err := s.scheduleTrigger(t)
+			err := s.scheduleTrigger(t, overrides[t.Name()])
 			if err != nil {
 				return err
 			}
+			s.setTriggerLastFired(t, time.Now())
 			continue
 		}
 
 		job := jobs[0]
 		jobTags := job.Tags()
 
-		// Detect changes, only changes in the schedule should result in a re-schedule. Changes in the trigger config itself,
-		// i.e. pipeline changes don't need a re-schedule. We trigger config is not stored in the scheduler, when mod is updated
-		// the cache is updated and the definition is retrieved again when we run the trigger.
-		if jobTags[1] != "schedule:"+scheduleString {
-			slog.Info("Rescheduling trigger", "name", t.Name(), "schedule", scheduleString)
+		// Detect changes, only changes in the schedule (or its time zone) should result in a re-schedule. Changes in the
+		// trigger config itself, i.e. pipeline changes don't need a re-schedule. We trigger config is not stored in the
+		// scheduler, when mod is updated the cache is updated and the definition is retrieved again when we run the trigger.
+		if jobTags[1] != "schedule:"+cronExpression {
+			slog.Info("Rescheduling trigger", "name", t.Name(), "schedule", cronExpression)
 			s.cronScheduler.RemoveByReference(job)
-			err := s.scheduleTrigger(t)
+			err := s.scheduleTrigger(t, overrides[t.Name()])
 			if err != nil {
 				return err
 			}
@@ -120,34 +163,59 @@ func (s *SchedulerService) RescheduleTriggers() error {
 		}
 	}
 
+	s.reconcileTriggerListeners(overrides)
+
 	return nil
 }
 
-func (s *SchedulerService) scheduleTrigger(t *resources.Trigger) error {
+// TriggerFireTimes returns the time a scheduled trigger last fired and the time it fires next, nil if the trigger is
+// not scheduled on this node (e.g. the node is not the leader) or has not fired since it was scheduled
+func (s *SchedulerService) TriggerFireTimes(triggerName string) (previous, next *time.Time) {
+	s.cronLock.Lock()
+	defer s.cronLock.Unlock()
 
-	scheduleString := ""
+	if s.cronScheduler == nil {
+		return nil, nil
+	}
 
-	switch config := t.Config.(type) {
-	case *resources.TriggerSchedule:
-		scheduleString = config.Schedule
-	case *resources.TriggerQuery:
-		scheduleString = config.Schedule
-		if scheduleString == "" {
-			scheduleString = "hourly"
-		}
-	default:
-		// can't schedule HTTP Trigger
+	jobs, err := s.cronScheduler.FindJobsByTag("id:" + triggerName)
+	if err != nil || len(jobs) == 0 {
+		return nil, nil
+	}
+	job := jobs[0]
+
+	// the last run is set to the scheduling time until the job actually runs
+	if job.RunCount() > 0 {
+		lastRun := job.LastRun()
+		previous = &lastRun
+	}
+
+	if nextRun := job.NextRun(); !nextRun.IsZero() {
+		next = &nextRun
+	}
+
+	return previous, next
+}
+
+func (s *SchedulerService) scheduleTrigger(t *resources.Trigger, override *store.TriggerOverride) error {
+	if _, ok := t.Config.(resources.ScheduledTriggerConfig); !ok {
+		// the triggers fired by events, e.g. HTTP triggers, are not scheduled
 		return nil
 	}
 
-	if t.Enabled != nil && !*t.Enabled {
+	if !trigger.IsEnabled(t, override) {
 		slog.Debug("Trigger is disabled", "name", t.Name())
 		return nil
 	}
 
+	cronExpression, err := trigger.CronExpression(t)
+	if err != nil {
+		return err
+	}
+
 	tags := []string{
 		"id:" + t.FullName,
-		"schedule:" + scheduleString,
+		"schedule:" + cronExpression,
 	}
 
 	pipelineName := ""
@@ -173,39 +241,155 @@ func (s *SchedulerService) scheduleTrigger(t *resources.Trigger) error {
 	scheduledTriggerRunner := TriggerScheduleRunner{
 		TriggerRunner: triggerRunner,
 		CommandBus:    s.esService.CommandBus,
+		jitter:        &s.jitter,
 	}
 
-	_, err := s.cronScheduler.Cron(scheduleString).Tag(tags...).Do(scheduledTriggerRunner.Run)
+	slog.Info("Scheduling trigger", "name", t.Name(), "tags", tags, "cronExpression", cronExpression)
+	_, err = s.cronScheduler.Cron(cronExpression).Tag(tags...).Do(scheduledTriggerRunner.Run)
 	if err != nil {
-		cronExpression, err := schedule.IntervalToCronExpression(t.FullName, scheduleString)
+		return err
+	}
+	return nil
+}
+
+// Start schedules the triggers and the core services. With leader election enabled the scheduling only starts once this
+// node is elected leader.
+func (s *SchedulerService) Start() error {
+	if s.leaderElection {
+		s.stopElection = make(chan struct{})
+		s.electionWg.Add(1)
+		go s.runLeaderElection()
+		return nil
+	}
+
+	return s.startScheduling()
+}
+
+// Stop stops the scheduler and gives up the leadership so another node can take over straight away.
+func (s *SchedulerService) Stop() error {
+	if s.leaderElection && s.stopElection != nil {
+		close(s.stopElection)
+		s.electionWg.Wait()
+		s.stopElection = nil
+
+		err := store.ReleaseLease(store.NodeLeaseName(s.nodeID), s.nodeID)
 		if err != nil {
-			return err
+			slog.Error("Error releasing node lease", "node_id", s.nodeID, "error", err)
 		}
 
-		slog.Info("Scheduling trigger", "name", t.Name(), "schedule", scheduleString, "tags", tags, "cronExpression", cronExpression)
-		_, err = s.cronScheduler.Cron(cronExpression).Tag(tags...).Do(scheduledTriggerRunner.Run)
-		if err != nil {
-			return err
+		if s.isLeader {
+			s.stepDown()
 		}
+		return nil
 	}
+
+	s.stopScheduling()
 	return nil
 }
 
-func (s *SchedulerService) Start() error {
-	s.cronScheduler = gocron.NewScheduler(time.UTC)
+func (s *SchedulerService) startScheduling() error {
+	s.cronLock.Lock()
+	defer s.cronLock.Unlock()
+
+	overrides, err := store.ListTriggerOverrides()
+	if err != nil {
+		return err
+	}
+
+	cronScheduler := gocron.NewScheduler(time.UTC)
+	s.cronScheduler = cronScheduler
 
 	for _, t := range s.Triggers {
-		err := s.scheduleTrigger(t)
+		err := s.scheduleTrigger(t, overrides[t.Name()])
 		if err != nil {
+			s.cronScheduler = nil
 			return err
 		}
 	}
 
-	s.cronScheduler.StartAsync()
+	// catch up with the occurrences missed while the scheduler was down, or while another node was the leader and
+	// failed to fire them
+	for _, t := range s.Triggers {
+		s.fireMissedOccurrences(t, overrides[t.Name()])
+	}
+
+	err = s.ScheduleCoreServices()
+	if err != nil {
+		s.cronScheduler = nil
+		return err
+	}
+
+	cronScheduler.StartAsync()
+	s.reconcileTriggerListeners(overrides)
 	return nil
 }
 
-func (s SchedulerService) ScheduleCoreServices() error {
+// fireMissedOccurrences fires the occurrences of the schedule trigger missed since it last fired, according to its
+// misfire policy, then records now as its last firing. A trigger that never fired has nothing to catch up.
+func (s *SchedulerService) fireMissedOccurrences(t *resources.Trigger, override *store.TriggerOverride) {
+	config, ok := t.Config.(*resources.TriggerSchedule)
+	if !ok || !trigger.IsEnabled(t, override) {
+		return
+	}
+
+	now := time.Now()
+	lastFired, err := store.GetTriggerLastFired(t.Name())
+	if err != nil {
+		slog.Error("Error reading trigger last fired time", "trigger", t.Name(), "error", err)
+		return
+	}
+
+	if lastFired != nil && !trigger.IsPaused(override) {
+		missed, count, err := trigger.MissedOccurrences(t, *lastFired, now)
+		if err != nil {
+			slog.Error("Error finding missed trigger occurrences", "trigger", t.Name(), "error", err)
+			return
+		}
+
+		firings := trigger.MisfireFirings(config.GetMisfirePolicy(), missed)
+		if count > 0 {
+			slog.Info("Trigger missed occurrences", "trigger", t.Name(), "last_fired", *lastFired, "missed", count, "misfire_policy", config.GetMisfirePolicy(), "firing", len(firings))
+		}
+
+		scheduledTriggerRunner := TriggerScheduleRunner{
+			TriggerRunner: trigger.NewTriggerRunner(t, "", ""),
+			CommandBus:    s.esService.CommandBus,
+		}
+		for range firings {
+			scheduledTriggerRunner.fire(constants.TriggerSourceCatchUp)
+		}
+	}
+
+	s.setTriggerLastFired(t, now)
+}
+
+// setTriggerLastFired records the last firing of a schedule trigger, the point the missed occurrences are found from
+func (s *SchedulerService) setTriggerLastFired(t *resources.Trigger, firedAt time.Time) {
+	if _, ok := t.Config.(*resources.TriggerSchedule); !ok {
+		return
+	}
+
+	err := store.SetTriggerLastFired(t.Name(), firedAt)
+	if err != nil {
+		slog.Error("Error recording trigger last fired time", "trigger", t.Name(), "error", err)
+	}
+}
+
+func (s *SchedulerService) stopScheduling() {
+	s.cronLock.Lock()
+	defer s.cronLock.Unlock()
+
+	if s.cronScheduler == nil {
+		return
+	}
+
+	s.cronScheduler.Stop()
+	s.cronScheduler = nil
+	s.jitter.stop()
+	s.stopTriggerListeners()
+}
+
+func (s *SchedulerService) ScheduleCoreServices() error {
 
 	currentTime := time.Now()
 
@@ -222,11 +406,44 @@ func (s SchedulerService) ScheduleCoreServices() error {
 
 	slog.Info("Scheduling flowpipe db cleanup", "schedule", scheduleString, "tags", tags)
 
-	_, err := s.cronScheduler.Cron(scheduleString).Tag(tags...).Do(store.CleanupRunner)
+	_, err := s.cronScheduler.Cron(scheduleString).Tag(tags...).Do(cleanupRunner)
 	if err != nil {
 		slog.Error("Error scheduling flowpipe db cleanup", "error", err)
 		return perr.InternalWithMessage("error scheduling flowpipe db cleanup")
 	}
 
+	// the trigger overrides set by the CLI or another node, and the ones that ended, are applied within a minute
+	_, err = s.cronScheduler.Every(1).Minute().Tag("core-services", "trigger-overrides").Do(s.applyTriggerOverrides)
+	if err != nil {
+		slog.Error("Error scheduling trigger overrides", "error", err)
+		return perr.InternalWithMessage("error scheduling trigger overrides")
+	}
+
 	return nil
 }
+
+// applyTriggerOverrides reschedules the triggers with their current overrides. Skipped if the scheduler is busy, e.g.
+// stopping, which waits for this job to finish.
+func (s *SchedulerService) applyTriggerOverrides() {
+	if !s.cronLock.TryLock() {
+		return
+	}
+	defer s.cronLock.Unlock()
+
+	err := s.rescheduleTriggers()
+	if err != nil {
+		slog.Error("Error applying trigger overrides", "error", err)
+	}
+}
+
+// cleanupRunner runs the flowpipe db cleanup with the retention policies of the current flowpipe config, the config
+// may have been reloaded since the cleanup was scheduled
+func cleanupRunner() {
+	fpConfig, err := db.GetFlowpipeConfig()
+	if err != nil {
+		slog.Error("Error getting flowpipe config", "error", err)
+		return
+	}
+
+	store.CleanupRunner(fpConfig.Retention)
+}
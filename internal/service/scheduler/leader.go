@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/turbot/flowpipe/internal/store"
+)
+
+const (
+	// DefaultLeaseTTL is how long the leader holds the scheduler lease without renewing it. A standby node takes over
+	// at most this long after the leader has died.
+	DefaultLeaseTTL = 15 * time.Second
+
+	schedulerLeaseName = "scheduler"
+)
+
+// WithLeaderElection runs the scheduler in active/passive mode: the nodes sharing the process store compete for a lease
+// and only the node holding it runs the scheduled triggers. The API and webhooks are served by every
+// node.
+func WithLeaderElection(nodeID string) SchedulerServiceOption {
+	return func(s *SchedulerService) {
+		s.leaderElection = true
+		s.nodeID = nodeID
+	}
+}
+
+// WithLeaseTTL sets how long the scheduler lease is held without being renewed, the leader renews it every third of the
+// TTL.
+func WithLeaseTTL(ttl time.Duration) SchedulerServiceOption {
+	return func(s *SchedulerService) {
+		s.leaseTTL = ttl
+	}
+}
+
+// WithProcessRecovery recovers the queued and started runs of the nodes that have stopped: every node renews a node
+// lease, the leader claims the runs whose owner's node lease has expired and recovers them with recoverProcess.
+func WithProcessRecovery(recoverProcess func(executionID string) error) SchedulerServiceOption {
+	return func(s *SchedulerService) {
+		s.recoverProcess = recoverProcess
+	}
+}
+
+// IsLeader returns true if this node runs the scheduled triggers.
+func (s *SchedulerService) IsLeader() bool {
+	if !s.leaderElection {
+		return true
+	}
+
+	s.cronLock.Lock()
+	defer s.cronLock.Unlock()
+
+	return s.isLeader
+}
+
+func (s *SchedulerService) runLeaderElection() {
+	defer s.electionWg.Done()
+
+	ticker := time.NewTicker(s.leaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		s.campaign()
+
+		select {
+		case <-ticker.C:
+		case <-s.stopElection:
+			return
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// campaign renews the node lease, takes (or renews) the scheduler lease and starts or stops the scheduling when the
+// leadership changes. The leader then recovers the runs of the nodes that have stopped.
+func (s *SchedulerService) campaign() {
+	// the runs of this node are recovered by the leader once its node lease has expired
+	_, err := store.AcquireLease(store.NodeLeaseName(s.nodeID), s.nodeID, s.leaseTTL)
+	if err != nil {
+		slog.Warn("Unable to renew node lease", "node_id", s.nodeID, "error", err)
+	}
+
+	acquired, err := store.AcquireLease(schedulerLeaseName, s.nodeID, s.leaseTTL)
+	if err != nil {
+		slog.Warn("Unable to acquire scheduler lease", "node_id", s.nodeID, "error", err)
+
+		// keep scheduling until the lease we hold expires, another node can't take it before that
+		if s.IsLeader() && time.Since(s.leaseRenewedAt) >= s.leaseTTL {
+			s.stepDown()
+		}
+		return
+	}
+
+	if !acquired {
+		if s.IsLeader() {
+			slog.Warn("Scheduler lease lost", "node_id", s.nodeID)
+			s.stepDown()
+		}
+		return
+	}
+
+	s.leaseRenewedAt = time.Now()
+	if s.IsLeader() {
+		s.recoverOrphanedProcesses()
+		return
+	}
+
+	slog.Info("Elected scheduler leader", "node_id", s.nodeID)
+	err = s.startScheduling()
+	if err != nil {
+		slog.Error("Error starting scheduler", "node_id", s.nodeID, "error", err)
+
+		// let another node try
+		err = store.ReleaseLease(schedulerLeaseName, s.nodeID)
+		if err != nil {
+			slog.Error("Error releasing scheduler lease", "node_id", s.nodeID, "error", err)
+		}
+		return
+	}
+
+	s.cronLock.Lock()
+	s.isLeader = true
+	s.cronLock.Unlock()
+
+	s.recoverOrphanedProcesses()
+}
+
+// recoverOrphanedProcesses recovers the runs whose owner has stopped renewing its node lease. A run is claimed first so
+// it's only recovered once, even if the leadership changes meanwhile.
+func (s *SchedulerService) recoverOrphanedProcesses() {
+	if s.recoverProcess == nil {
+		return
+	}
+
+	executionIDs, err := store.ListOrphanedPipelineRuns()
+	if err != nil {
+		slog.Warn("Unable to list orphaned processes", "node_id", s.nodeID, "error", err)
+		return
+	}
+
+	for _, executionID := range executionIDs {
+		claimed, err := store.ClaimOrphanedPipelineRun(executionID)
+		if err != nil {
+			slog.Warn("Unable to claim orphaned process", "execution_id", executionID, "node_id", s.nodeID, "error", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		slog.Info("Recovering orphaned process", "execution_id", executionID, "node_id", s.nodeID)
+		err = s.recoverProcess(executionID)
+		if err != nil {
+			slog.Error("Error recovering orphaned process", "execution_id", executionID, "node_id", s.nodeID, "error", err)
+		}
+	}
+}
+
+func (s *SchedulerService) stepDown() {
+	slog.Info("Stepping down as scheduler leader", "node_id", s.nodeID)
+
+	s.stopScheduling()
+
+	s.cronLock.Lock()
+	s.isLeader = false
+	s.cronLock.Unlock()
+
+	err := store.ReleaseLease(schedulerLeaseName, s.nodeID)
+	if err != nil {
+		slog.Error("Error releasing scheduler lease", "node_id", s.nodeID, "error", err)
+	}
+}
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/turbot/flowpipe/internal/es/handler"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/flowpipe/internal/store"
+	"github.com/turbot/flowpipe/internal/trigger"
+)
+
+// triggerListener runs a trigger fired by external events rather than by the cron scheduler, e.g. the consumer of a
+// queue trigger. Listeners only run on the leader, like the scheduled triggers.
+type triggerListener interface {
+	start(ctx context.Context)
+	stop()
+
+	// triggerConfig is the configuration the listener was started with
+	triggerConfig() resources.TriggerConfig
+}
+
+// newTriggerListener returns the listener of the trigger, nil if the trigger isn't fired by external events
+func newTriggerListener(t *resources.Trigger, commandBus handler.FpCommandBus) triggerListener {
+	switch config := t.Config.(type) {
+	case *resources.TriggerQueue:
+		return newQueueConsumer(t, config, commandBus)
+	case *resources.TriggerFile:
+		return newFileListener(t, config, commandBus)
+	}
+	return nil
+}
+
+// reconcileTriggerListeners starts a listener for each enabled trigger fired by external events, restarting the
+// listeners whose trigger configuration changed and stopping the ones whose trigger was removed, disabled or paused
+// (a paused queue trigger leaves its messages in the queue). Called with the cron lock held.
+func (s *SchedulerService) reconcileTriggerListeners(overrides map[string]*store.TriggerOverride) {
+	if s.listeners == nil {
+		s.listeners = map[string]triggerListener{}
+	}
+
+	for name, listener := range s.listeners {
+		t, ok := s.Triggers[name]
+		if ok && trigger.IsFiring(t, overrides[t.Name()]) && listener.triggerConfig().Equals(t.Config) {
+			continue
+		}
+
+		listener.stop()
+		delete(s.listeners, name)
+	}
+
+	for name, t := range s.Triggers {
+		if !trigger.IsFiring(t, overrides[t.Name()]) {
+			continue
+		}
+
+		if _, running := s.listeners[name]; running {
+			continue
+		}
+
+		listener := newTriggerListener(t, s.esService.CommandBus)
+		if listener == nil {
+			continue
+		}
+
+		listener.start(s.ctx)
+		s.listeners[name] = listener
+	}
+}
+
+// stopTriggerListeners stops every trigger listener. Called with the cron lock held.
+func (s *SchedulerService) stopTriggerListeners() {
+	for name, listener := range s.listeners {
+		listener.stop()
+		delete(s.listeners, name)
+	}
+}
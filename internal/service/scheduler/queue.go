@@ -0,0 +1,250 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/turbot/flowpipe/internal/constants"
+	"github.com/turbot/flowpipe/internal/es/execution"
+	"github.com/turbot/flowpipe/internal/es/handler"
+	"github.com/turbot/flowpipe/internal/output"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/flowpipe/internal/store"
+	"github.com/turbot/flowpipe/internal/trigger"
+	"github.com/turbot/flowpipe/internal/types"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+const (
+	queueFetchWait          = 30 * time.Second
+	queueReconnectBackoff   = 5 * time.Second
+	queuePollInterval       = time.Second
+	queueInProgressInterval = 10 * time.Second
+
+	// how long an execution has to show up once sent before the message is considered failed
+	queueExecutionStartTimeout = time.Minute
+)
+
+// queueConsumer runs the pipeline of a queue trigger for each message of its queue, at most concurrency messages at a
+// time. A message is settled once its execution is over: acked if it finished, nacked otherwise until it has been
+// delivered max_deliveries times, then dead lettered.
+//
+// The messages being processed when the consumer stops are left unsettled, the source delivers them again.
+type queueConsumer struct {
+	trigger    *resources.Trigger
+	config     *resources.TriggerQueue
+	commandBus handler.FpCommandBus
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newQueueConsumer(t *resources.Trigger, config *resources.TriggerQueue, commandBus handler.FpCommandBus) *queueConsumer {
+	return &queueConsumer{
+		trigger:    t,
+		config:     config,
+		commandBus: commandBus,
+	}
+}
+
+func (c *queueConsumer) triggerConfig() resources.TriggerConfig {
+	return c.config
+}
+
+func (c *queueConsumer) start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.done = make(chan struct{})
+
+	slog.Info("Starting queue consumer", "trigger", c.trigger.Name(), "source", c.config.Source, "concurrency", c.config.Concurrency)
+	go c.run(ctx)
+}
+
+func (c *queueConsumer) stop() {
+	if c.cancel == nil {
+		return
+	}
+
+	slog.Info("Stopping queue consumer", "trigger", c.trigger.Name())
+	c.cancel()
+	<-c.done
+	c.cancel = nil
+}
+
+func (c *queueConsumer) run(ctx context.Context) {
+	defer close(c.done)
+
+	var wg sync.WaitGroup
+	var source trigger.QueueSource
+
+	defer func() {
+		wg.Wait()
+		if source != nil {
+			source.Close()
+		}
+	}()
+
+	slots := make(chan struct{}, max(c.config.Concurrency, 1))
+
+	for {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		if source == nil {
+			var err error
+			source, err = trigger.NewQueueSource(ctx, c.config)
+			if err != nil {
+				slog.Error("Unable to connect to the queue", "trigger", c.trigger.Name(), "source", c.config.Source, "error", err)
+				<-slots
+				if !sleepContext(ctx, queueReconnectBackoff) {
+					return
+				}
+				continue
+			}
+		}
+
+		msg, err := source.Fetch(ctx, queueFetchWait)
+		if err != nil {
+			slog.Error("Unable to fetch from the queue", "trigger", c.trigger.Name(), "source", c.config.Source, "error", err)
+			<-slots
+
+			// wait for the messages being processed, they are settled through the source
+			wg.Wait()
+			source.Close()
+			source = nil
+
+			if !sleepContext(ctx, queueReconnectBackoff) {
+				return
+			}
+			continue
+		}
+
+		if msg == nil {
+			<-slots
+			continue
+		}
+
+		wg.Add(1)
+		go func(source trigger.QueueSource, msg *trigger.QueueMessage) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			c.process(ctx, source, msg)
+		}(source, msg)
+	}
+}
+
+func (c *queueConsumer) process(ctx context.Context, source trigger.QueueSource, msg *trigger.QueueMessage) {
+	executionCmd, err := trigger.NewQueueMessageExecution(ctx, c.trigger.Name(), msg)
+	if err != nil {
+		c.fail(ctx, source, msg, err.Error())
+		return
+	}
+
+	err = c.commandBus.Send(ctx, executionCmd)
+	if err != nil {
+		c.fail(ctx, source, msg, err.Error())
+		return
+	}
+
+	err = store.StartTriggerRun(executionCmd.Event.ExecutionID, c.trigger.Name(), constants.TriggerSourceQueue)
+	if err != nil {
+		slog.Error("Error recording trigger run", "trigger", c.trigger.Name(), "error", err)
+	}
+
+	status, err := c.waitForExecution(ctx, source, msg, executionCmd.Event.ExecutionID)
+	if err != nil {
+		c.fail(ctx, source, msg, err.Error())
+		return
+	}
+
+	switch status {
+	case constants.StateFinished:
+		err := source.Ack(msg)
+		if err != nil {
+			slog.Error("Unable to ack message", "trigger", c.trigger.Name(), "message", msg.ID, "error", err)
+		}
+	case "":
+		// the consumer is stopping, leave the message to be delivered again
+		return
+	default:
+		c.fail(ctx, source, msg, "execution "+executionCmd.Event.ExecutionID+" "+status)
+	}
+}
+
+// waitForExecution waits for the execution to be over and returns its status, an empty status if the consumer is
+// stopping. The source is told the message is in progress while waiting.
+func (c *queueConsumer) waitForExecution(ctx context.Context, source trigger.QueueSource, msg *trigger.QueueMessage, executionID string) (string, error) {
+	startedAt := time.Now()
+	lastInProgress := time.Now()
+
+	ticker := time.NewTicker(queuePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", nil
+		case <-ticker.C:
+		}
+
+		if time.Since(lastInProgress) >= queueInProgressInterval {
+			err := source.InProgress(msg)
+			if err != nil {
+				slog.Warn("Unable to extend the message ack deadline", "trigger", c.trigger.Name(), "message", msg.ID, "error", err)
+			}
+			lastInProgress = time.Now()
+		}
+
+		ex, err := execution.GetExecution(executionID)
+		if err != nil {
+			if perr.IsNotFound(err) && time.Since(startedAt) < queueExecutionStartTimeout {
+				continue
+			}
+			return "", err
+		}
+
+		switch ex.Status {
+		case constants.StateFinished, constants.StateFailed, constants.StateCanceled:
+			return ex.Status, nil
+		}
+	}
+}
+
+// fail returns the message to the queue, or dead letters it once it has been delivered max_deliveries times
+func (c *queueConsumer) fail(ctx context.Context, source trigger.QueueSource, msg *trigger.QueueMessage, reason string) {
+	if msg.Deliveries < c.config.MaxDeliveries {
+		slog.Warn("Queue message failed, returning it to the queue", "trigger", c.trigger.Name(), "message", msg.ID, "deliveries", msg.Deliveries, "reason", reason)
+
+		err := source.Nack(msg)
+		if err != nil {
+			slog.Error("Unable to nack message", "trigger", c.trigger.Name(), "message", msg.ID, "error", err)
+		}
+		return
+	}
+
+	slog.Warn("Queue message failed too many times, moving it to the dead letter destination", "trigger", c.trigger.Name(), "message", msg.ID, "deliveries", msg.Deliveries, "dead_letter", c.config.DeadLetter, "reason", reason)
+
+	if output.IsServerMode {
+		output.RenderServerOutput(ctx, types.NewServerOutputError(types.NewServerOutputPrefix(time.Now(), "trigger"), "dead lettering message "+msg.ID+" of "+c.trigger.Name(), perr.ExecutionErrorWithMessage(reason)))
+	}
+
+	err := source.DeadLetter(msg, reason)
+	if err != nil {
+		slog.Error("Unable to dead letter message", "trigger", c.trigger.Name(), "message", msg.ID, "error", err)
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
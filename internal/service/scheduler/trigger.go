@@ -3,25 +3,134 @@ package scheduler
 import (
 	"context"
 	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
 
+	"github.com/turbot/flowpipe/internal/constants"
+	"github.com/turbot/flowpipe/internal/es/db"
 	"github.com/turbot/flowpipe/internal/es/event"
 	"github.com/turbot/flowpipe/internal/es/handler"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/flowpipe/internal/store"
 	"github.com/turbot/flowpipe/internal/trigger"
 )
 
 type TriggerScheduleRunner struct {
 	TriggerRunner trigger.TriggerRunner
 	CommandBus    handler.FpCommandBus
+
+	// the firings delayed by the jitter, stopped with the scheduling
+	jitter *jitterTimers
 }
 
 func (s *TriggerScheduleRunner) Run() {
 	triggerName := s.TriggerRunner.GetTrigger().Name()
 
+	// a paused trigger stays scheduled, the firings are skipped until it's resumed
+	override, err := store.GetTriggerOverride(triggerName)
+	if err != nil {
+		slog.Error("Error reading trigger override", "trigger", triggerName, "error", err)
+		return
+	}
+	if trigger.IsPaused(override) {
+		slog.Info("Trigger paused, skipping", "trigger", triggerName)
+		s.setLastFired()
+		return
+	}
+
+	// the excluded dates and the jitter may change without a re-schedule, use the current definition of the trigger
+	t, err := db.GetTrigger(triggerName)
+	if err != nil {
+		t = s.TriggerRunner.GetTrigger()
+	}
+
+	if trigger.IsExcluded(t, time.Now()) {
+		slog.Info("Trigger excluded today, skipping", "trigger", triggerName)
+		s.setLastFired()
+		return
+	}
+
+	if config, ok := t.Config.(resources.ScheduledTriggerConfig); ok && config.GetScheduleOptions().Jitter > 0 && s.jitter != nil {
+		delay := rand.N(config.GetScheduleOptions().Jitter)
+		slog.Debug("Delaying trigger by its jitter", "trigger", triggerName, "delay", delay)
+
+		// a firing stopped with the scheduling isn't recorded, it's caught up when the scheduling starts again
+		s.jitter.afterFunc(delay, func() {
+			s.setLastFired()
+			s.fire(constants.TriggerSourceSchedule)
+		})
+		return
+	}
+
+	s.setLastFired()
+	s.fire(constants.TriggerSourceSchedule)
+}
+
+// setLastFired records the occurrence as handled, fired or skipped by a pause, it's not caught up after a restart
+func (s *TriggerScheduleRunner) setLastFired() {
+	triggerName := s.TriggerRunner.GetTrigger().Name()
+
+	err := store.SetTriggerLastFired(triggerName, time.Now())
+	if err != nil {
+		slog.Error("Error recording trigger last fired time", "trigger", triggerName, "error", err)
+	}
+}
+
+func (s *TriggerScheduleRunner) fire(source string) {
+	triggerName := s.TriggerRunner.GetTrigger().Name()
+
 	executionCmd := event.NewExecutionQueueForTrigger("", triggerName)
 
+	err := store.StartTriggerRun(executionCmd.Event.ExecutionID, triggerName, source)
+	if err != nil {
+		slog.Error("Error recording trigger run", "trigger", triggerName, "error", err)
+	}
+
 	// Send the trigger command
-	err := s.CommandBus.Send(context.TODO(), executionCmd)
+	err = s.CommandBus.Send(context.TODO(), executionCmd)
 	if err != nil {
 		slog.Error("Error sending trigger command", "trigger", triggerName, "error", err)
 	}
 }
+
+// jitterTimers are the firings of the scheduled triggers delayed by their jitter
+type jitterTimers struct {
+	lock   sync.Mutex
+	timers map[*time.Timer]struct{}
+}
+
+// afterFunc calls f after the delay unless the timers are stopped first
+func (j *jitterTimers) afterFunc(delay time.Duration, f func()) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if j.timers == nil {
+		j.timers = map[*time.Timer]struct{}{}
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		// the timer may have expired while it was being stopped
+		j.lock.Lock()
+		_, pending := j.timers[timer]
+		delete(j.timers, timer)
+		j.lock.Unlock()
+
+		if pending {
+			f()
+		}
+	})
+	j.timers[timer] = struct{}{}
+}
+
+// stop cancels the pending firings
+func (j *jitterTimers) stop() {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	for timer := range j.timers {
+		timer.Stop()
+	}
+	j.timers = nil
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/flowpipe/internal/constants"
+)
+
+const (
+	BackendSQLite   = "sqlite"
+	BackendPostgres = "postgres"
+)
+
+// Backend is the database holding the process store: the pipeline runs, their events, the query trigger control
+// tables and the internal housekeeping values.
+//
+// The queries are written once using ? placeholders, the backend rebinds them to its own placeholder syntax.
+type Backend interface {
+	Name() string
+
+	// Open returns a connection to the database, creating or migrating its schema as required.
+	Open() (*sql.DB, error)
+
+	// Shared returns true if the connection returned by Open is a pool shared by every caller, it must then not be
+	// closed by the caller.
+	Shared() bool
+
+	Rebind(query string) string
+	IsUniqueViolation(err error) bool
+}
+
+var (
+	backendLock sync.Mutex
+	backends    = map[string]Backend{}
+)
+
+// CurrentBackend returns the backend selected by the process-store connection string, SQLite (flowpipe.db in the mod
+// location) if the connection string is not set.
+func CurrentBackend() Backend {
+	connectionString := viper.GetString(constants.ArgProcessStore)
+
+	backendLock.Lock()
+	defer backendLock.Unlock()
+
+	if b, ok := backends[connectionString]; ok {
+		return b
+	}
+
+	var b Backend
+	if strings.HasPrefix(connectionString, "postgres://") || strings.HasPrefix(connectionString, "postgresql://") {
+		b = newPostgresBackend(connectionString)
+	} else {
+		b = &sqliteBackend{}
+	}
+
+	backends[connectionString] = b
+	return b
+}
+
+// IsValidProcessStore returns true if the connection string selects a supported backend
+func IsValidProcessStore(connectionString string) bool {
+	return connectionString == "" || strings.HasPrefix(connectionString, "postgres://") || strings.HasPrefix(connectionString, "postgresql://")
+}
+
+// rebindDollar replaces the ? placeholders outside quoted literals with the numbered $n placeholders used by Postgres
+func rebindDollar(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(query) + 10)
+
+	n := 0
+	inQuote := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			inQuote = !inQuote
+			sb.WriteRune(r)
+		case r == '?' && !inQuote:
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
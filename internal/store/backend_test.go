@@ -0,0 +1,30 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebindDollar(t *testing.T) {
+
+	assert := assert.New(t)
+
+	assert.Equal("select * from event", rebindDollar("select * from event"))
+	assert.Equal("update pipeline_run set state = $1, updated_at = $2 where execution_id = $3",
+		rebindDollar("update pipeline_run set state = ?, updated_at = ? where execution_id = ?"))
+
+	// placeholders in quoted literals are left alone
+	assert.Equal("select 'what?' from internal where name = $1 and value = 'a''?'",
+		rebindDollar("select 'what?' from internal where name = ? and value = 'a''?'"))
+}
+
+func TestIsValidProcessStore(t *testing.T) {
+
+	assert := assert.New(t)
+
+	assert.True(IsValidProcessStore(""))
+	assert.True(IsValidProcessStore("postgres://flowpipe@localhost:5432/flowpipe"))
+	assert.True(IsValidProcessStore("postgresql://flowpipe@localhost:5432/flowpipe?sslmode=disable"))
+	assert.False(IsValidProcessStore("mysql://flowpipe@localhost:3306/flowpipe"))
+}
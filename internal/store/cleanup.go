@@ -9,48 +9,50 @@ import (
 
 	"github.com/spf13/viper"
 	"github.com/turbot/flowpipe/internal/filepaths"
+	"github.com/turbot/flowpipe/internal/resources"
 	"github.com/turbot/pipe-fittings/constants"
 	"github.com/turbot/pipe-fittings/perr"
 	putils "github.com/turbot/pipe-fittings/utils"
 )
 
-func cleanupFlowpipeDB(currentTime time.Time, offset time.Duration) (int, error) {
-	slog.Debug("Cleaning up flowpipe db")
-	db, err := OpenFlowpipeDB()
+// cleanupFlowpipeDB deletes the runs, and their events, that are past their retention. The runs not matched by a
+// retention policy with a max age are kept for defaultMaxAge (negative to keep them forever).
+func cleanupFlowpipeDB(currentTime time.Time, policies resources.RetentionPolicies, defaultMaxAge time.Duration, dryRun bool) ([]PrunedRun, error) {
+	slog.Debug("Cleaning up flowpipe db", "dryRun", dryRun)
+
+	pruned, err := planPrune(currentTime, policies, defaultMaxAge)
 	if err != nil {
-		slog.Error("error opening flowpipe db", "error", err)
-		return -1, perr.InternalWithMessage("error opening flowpipe db")
+		slog.Error("error planning flowpipe db cleanup", "error", err)
+		return nil, err
 	}
-	defer db.Close()
 
-	timeLimit := currentTime.Add(offset)
-
-	// TODO: how do we cleanup orphaned pipeline runs? We can filter this to just 'finished', 'cancelled' and 'failed' states
-	// but then the orphan pipeline will never be cleaned. Should we have a hard limit?
-	cleanupQuery := `delete from pipeline_run where updated_at < ?;`
-
-	timeAsString := timeLimit.Format(putils.RFC3339WithMS)
+	if dryRun {
+		return pruned, nil
+	}
 
-	result, err := db.Exec(cleanupQuery, timeAsString)
+	db, err := OpenFlowpipeDB()
 	if err != nil {
-		slog.Error("error cleaning up flowpipe db", "error", err)
-		return -1, perr.InternalWithMessage("error cleaning up flowpipe db")
+		slog.Error("error opening flowpipe db", "error", err)
+		return nil, perr.InternalWithMessage("error opening flowpipe db")
 	}
+	defer db.Close()
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		slog.Error("error cleaning up flowpipe db", "error", err)
-		return -1, perr.InternalWithMessage("error cleaning up flowpipe db")
+	for _, run := range pruned {
+		err := pruneRun(db, run)
+		if err != nil {
+			slog.Error("error cleaning up flowpipe db", "execution_id", run.ExecutionID, "error", err)
+			return nil, err
+		}
 	}
 
-	slog.Debug("Cleaned up flowpipe db", "rowsAffected", rowsAffected)
+	slog.Debug("Cleaned up flowpipe db", "rowsAffected", len(pruned))
 
 	sql := `select value from internal where name = 'last_cleanup'`
 
 	rows, err := db.Query(sql)
 	if err != nil {
 		slog.Error("error getting last cleanup time", "error", err)
-		return -1, perr.InternalWithMessage("error getting last cleanup time")
+		return nil, perr.InternalWithMessage("error getting last cleanup time")
 	}
 
 	var lastCleanupTime string
@@ -58,7 +60,7 @@ func cleanupFlowpipeDB(currentTime time.Time, offset time.Duration) (int, error)
 		err = rows.Scan(&lastCleanupTime)
 		if err != nil {
 			slog.Error("error getting last cleanup time", "error", err)
-			return -1, perr.InternalWithMessage("error getting last cleanup time")
+			return nil, perr.InternalWithMessage("error getting last cleanup time")
 		}
 	}
 	defer rows.Close()
@@ -70,53 +72,71 @@ func cleanupFlowpipeDB(currentTime time.Time, offset time.Duration) (int, error)
 		_, err = db.Exec(sql, currentTimeStringFormat, currentTimeStringFormat)
 		if err != nil {
 			slog.Error("error updating last cleanup time", "error", err)
-			return -1, perr.InternalWithMessage("error updating last cleanup time")
+			return nil, perr.InternalWithMessage("error updating last cleanup time")
 		}
 	} else {
 		sql = `insert into internal (name, value, created_at) values ('last_cleanup', ?, ?)`
 		_, err = db.Exec(sql, currentTimeStringFormat, currentTimeStringFormat)
 		if err != nil {
 			slog.Error("error inserting last cleanup time", "error", err)
-			return -1, perr.InternalWithMessage("error inserting last cleanup time")
+			return nil, perr.InternalWithMessage("error inserting last cleanup time")
 		}
 	}
 
-	return int(rowsAffected), nil
+	return pruned, nil
 }
 
-func CleanupRunner() {
-
-	currentTime := time.Now().UTC()
+// PruneProcesses applies the retention policies to the process history, a dry run only lists the runs that would be
+// deleted
+func PruneProcesses(policies resources.RetentionPolicies, dryRun bool) ([]PrunedRun, error) {
+	return cleanupFlowpipeDB(time.Now().UTC(), policies, defaultRetentionMaxAge(), dryRun)
+}
 
+// defaultRetentionMaxAge is the process retention setting, -1 keeps the processes forever
+func defaultRetentionMaxAge() time.Duration {
 	retentionInSecond := viper.GetInt(constants.ArgProcessRetention)
 	if retentionInSecond == -1 {
-		slog.Debug("Skipping cleanup as retention is set to -1")
-		return
+		return -1
 	}
+	return time.Duration(retentionInSecond) * time.Second
+}
 
-	offset := time.Duration(-1*retentionInSecond) * time.Second
-
-	rowsAffected, err := cleanupFlowpipeDB(currentTime, offset)
+func CleanupRunner(policies resources.RetentionPolicies) {
+	// a retention of -1 keeps the processes forever, unless the retention policies say otherwise
+	pruned, err := PruneProcesses(policies, false)
 	if err != nil {
 		slog.Error("error cleaning up flowpipe db", "error", err)
 		return
 	}
 
-	slog.Info("Cleaned up flowpipe db", "rowsAffected", rowsAffected)
+	slog.Info("Cleaned up flowpipe db", "rowsAffected", len(pruned))
 
-	deleteOldJsonlFiles(filepaths.EventStoreDir(), retentionInSecond)
+	expiredKeys, err := DeleteExpiredIdempotencyKeys(time.Now().UTC())
+	if err != nil {
+		slog.Error("error deleting expired idempotency keys", "error", err)
+	} else if expiredKeys > 0 {
+		slog.Info("Deleted expired idempotency keys", "rowsAffected", expiredKeys)
+	}
+
+	// the jsonl event logs of the pruned runs are deleted with them, without a process retention the runs are not
+	// recorded and the event logs are deleted as they are
+	if viper.GetInt(constants.ArgProcessRetention) == 0 {
+		deleteOldJsonlFiles(filepaths.EventStoreDir(), 0)
+	}
 }
 
 // Force cleanup run if we haven't run it more than 1 day
-func ForceCleanup() {
+func ForceCleanup(policies resources.RetentionPolicies) {
 	// can only clean up if flowpipe.db exist
-	dbPath := filepaths.FlowpipeDBFileName()
+	if CurrentBackend().Name() == BackendSQLite {
+		dbPath := filepaths.FlowpipeDBFileName()
 
-	_, err := os.Stat(dbPath)
+		_, err := os.Stat(dbPath)
 
-	if os.IsNotExist(err) {
-		slog.Debug("Skipping force cleanup as flowpipe.db does not exist")
-		return
+		if os.IsNotExist(err) {
+			slog.Debug("Skipping force cleanup as flowpipe.db does not exist")
+			return
+		}
 	}
 
 	slog.Debug("Checking if cleanup must be run")
@@ -168,7 +188,7 @@ func ForceCleanup() {
 
 	slog.Debug("Running force cleanup")
 
-	CleanupRunner()
+	CleanupRunner(policies)
 }
 
 // This function should be removed eventually. SQLite store is out in v0.3.
@@ -1,10 +1,15 @@
 package store
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/turbot/flowpipe/internal/filepaths"
+	"github.com/turbot/flowpipe/internal/resources"
 	putils "github.com/turbot/pipe-fittings/utils"
 )
 
@@ -17,8 +22,7 @@ func TestCleanupDB(t *testing.T) {
 		assert.FailNow(err.Error())
 	}
 
-	// offset := time.Duration(-24*30) * time.Hour
-	offset := -1 * time.Hour
+	maxAge := time.Hour
 
 	layout := putils.RFC3339WithMS
 	str := "2024-02-02T01:59:00.000Z"
@@ -30,10 +34,112 @@ func TestCleanupDB(t *testing.T) {
 
 	currentTime := anchorTime.Add(1 * time.Hour)
 
-	rowsAffected, err := cleanupFlowpipeDB(currentTime, offset)
+	pruned, err := cleanupFlowpipeDB(currentTime, resources.RetentionPolicies{}, maxAge, false)
 	if err != nil {
 		assert.FailNow(err.Error())
 	}
 
-	assert.Equal(2, rowsAffected, "rowsAffected should be 2")
+	assert.Equal(2, len(pruned), "rowsAffected should be 2")
+}
+
+func TestCleanupDBRetentionPolicies(t *testing.T) {
+
+	assert := assert.New(t)
+
+	err := copyNewFlowpipeDbCleanFile("./clean_test_files/flowpipe_clean_2.db")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	defer os.RemoveAll(filepaths.ProcessArchiveDir())
+
+	keepLast := 2
+	forever := time.Duration(-1)
+	archived := true
+
+	policies := resources.RetentionPolicies{
+		Default: resources.RetentionPolicy{
+			Archive: &archived,
+		},
+		Rules: []resources.RetentionPolicy{
+			{
+				Pipeline: "*.pipeline.lots_of_sleep",
+				KeepLast: &keepLast,
+			},
+			{
+				Pipeline:     "*.pipeline.lots_of_sleep_bound",
+				OrphanMaxAge: &forever,
+			},
+		},
+	}
+
+	currentTime, err := time.Parse(putils.RFC3339WithMS, "2024-08-02T00:00:00.000Z")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	expected := []string{
+		"exec_cqlg0mc204vtcsgqlut0", // past the default max age
+		"exec_cmu4op272ijiakh9u63g", // past the last 2 runs of lots_of_sleep
+		"exec_cmu41da72ijuoi3q9gj0",
+		"exec_cmu410272ijuoi3q9gd0", // finished, the rule only keeps the orphans of lots_of_sleep_bound forever
+	}
+
+	pruned, err := cleanupFlowpipeDB(currentTime, policies, time.Hour, true)
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	var executionIDs []string
+	for _, run := range pruned {
+		executionIDs = append(executionIDs, run.ExecutionID)
+		assert.True(run.Archived)
+	}
+	assert.Equal(expected, executionIDs)
+
+	// a dry run doesn't delete anything
+	runs, _, err := ListPipelineRuns(PipelineRunFilter{})
+	assert.Nil(err)
+	assert.Equal(7, len(runs))
+
+	// the jsonl event logs of the pruned runs are deleted with them
+	for _, executionID := range []string{"exec_cmu4op272ijiakh9u63g", "exec_cmu5cli72ijjh42rbl1g"} {
+		err = os.WriteFile(filepaths.EventStoreFilePath(executionID), []byte("{}\n"), 0600)
+		if err != nil {
+			assert.FailNow(err.Error())
+		}
+		defer os.Remove(filepaths.EventStoreFilePath(executionID))
+	}
+
+	pruned, err = cleanupFlowpipeDB(currentTime, policies, time.Hour, false)
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal(4, len(pruned))
+
+	_, err = os.Stat(filepaths.EventStoreFilePath("exec_cmu4op272ijiakh9u63g"))
+	assert.True(os.IsNotExist(err), "the event log of the pruned run should be deleted")
+	_, err = os.Stat(filepaths.EventStoreFilePath("exec_cmu5cli72ijjh42rbl1g"))
+	assert.Nil(err, "the event log of the kept run should be kept")
+
+	runs, _, err = ListPipelineRuns(PipelineRunFilter{})
+	assert.Nil(err)
+	assert.Equal(3, len(runs))
+
+	// the events are archived then deleted
+	archive, err := os.ReadFile(filepath.Join(filepaths.ProcessArchiveDir(), "exec_cmu410272ijuoi3q9gd0.jsonl"))
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal(80, strings.Count(string(archive), "\n"))
+
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	defer db.Close()
+
+	var count int
+	err = db.QueryRow("select count(*) from event where process_id = ?", "exec_cmu410272ijuoi3q9gd0").Scan(&count)
+	assert.Nil(err)
+	assert.Equal(0, count)
 }
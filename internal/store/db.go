@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// DB is a connection to the process store. Queries use ? placeholders whatever the backend.
+type DB struct {
+	*sql.DB
+	backend Backend
+}
+
+func (db *DB) Backend() Backend {
+	return db.backend
+}
+
+func (db *DB) Exec(query string, args ...any) (sql.Result, error) {
+	return db.DB.Exec(db.backend.Rebind(query), args...)
+}
+
+func (db *DB) Query(query string, args ...any) (*sql.Rows, error) {
+	return db.DB.Query(db.backend.Rebind(query), args...)
+}
+
+func (db *DB) QueryRow(query string, args ...any) *sql.Row {
+	return db.DB.QueryRow(db.backend.Rebind(query), args...)
+}
+
+func (db *DB) Prepare(query string) (*sql.Stmt, error) {
+	return db.DB.Prepare(db.backend.Rebind(query))
+}
+
+func (db *DB) Begin() (*Tx, error) {
+	return db.BeginTx(context.Background(), nil)
+}
+
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, backend: db.backend}, nil
+}
+
+// Close releases the connection. Connections from a shared pool are left open.
+func (db *DB) Close() error {
+	if db.backend.Shared() {
+		return nil
+	}
+	return db.DB.Close()
+}
+
+// Tx is a transaction on the process store. Queries use ? placeholders whatever the backend.
+type Tx struct {
+	*sql.Tx
+	backend Backend
+}
+
+func (tx *Tx) Exec(query string, args ...any) (sql.Result, error) {
+	return tx.Tx.Exec(tx.backend.Rebind(query), args...)
+}
+
+func (tx *Tx) Query(query string, args ...any) (*sql.Rows, error) {
+	return tx.Tx.Query(tx.backend.Rebind(query), args...)
+}
+
+func (tx *Tx) QueryRow(query string, args ...any) *sql.Row {
+	return tx.Tx.QueryRow(tx.backend.Rebind(query), args...)
+}
+
+func (tx *Tx) Prepare(query string) (*sql.Stmt, error) {
+	return tx.Tx.Prepare(tx.backend.Rebind(query))
+}
@@ -0,0 +1,129 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/turbot/pipe-fittings/perr"
+	putils "github.com/turbot/pipe-fittings/utils"
+)
+
+// IdempotencyKey records the execution started by the first delivery of a webhook, so the deliveries retried by the
+// provider return that execution instead of starting a new one
+type IdempotencyKey struct {
+	TriggerName         string    `json:"trigger_name"`
+	Key                 string    `json:"key"`
+	ExecutionID         string    `json:"execution_id"`
+	PipelineExecutionID string    `json:"pipeline_execution_id"`
+	ExpiresAt           time.Time `json:"expires_at"`
+}
+
+// triggerIdempotencyKeyTableSQL creates the table of the idempotency keys of the HTTP triggers, created by the store migrations
+const triggerIdempotencyKeyTableSQL = `
+	create table if not exists trigger_idempotency_key (
+		trigger_name text not null,
+		idempotency_key text not null,
+		execution_id text not null,
+		pipeline_execution_id text not null,
+		expires_at bigint not null,
+		created_at text,
+		primary key (trigger_name, idempotency_key)
+	)
+	`
+
+// ClaimIdempotencyKey records the execution for the idempotency key of the trigger, for the next ttl. If the key is
+// already recorded and has not expired, the recorded execution is returned and claimed is false.
+//
+// The claim is atomic, of concurrent deliveries with the same key only one claims it.
+func ClaimIdempotencyKey(triggerName, key, executionID, pipelineExecutionID string, ttl time.Duration) (*IdempotencyKey, bool, error) {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return nil, false, err
+	}
+	defer db.Close()
+
+	now := time.Now().UTC()
+
+	upsertSQL := `insert into trigger_idempotency_key (trigger_name, idempotency_key, execution_id, pipeline_execution_id, expires_at, created_at) values (?, ?, ?, ?, ?, ?)
+		on conflict (trigger_name, idempotency_key) do update set execution_id = excluded.execution_id, pipeline_execution_id = excluded.pipeline_execution_id,
+		expires_at = excluded.expires_at, created_at = excluded.created_at
+		where trigger_idempotency_key.expires_at < ?`
+
+	result, err := db.Exec(upsertSQL, triggerName, key, executionID, pipelineExecutionID, now.Add(ttl).UnixMilli(), now.Format(putils.RFC3339WithMS), now.UnixMilli())
+	if err != nil {
+		slog.Error("error claiming idempotency key", "trigger", triggerName, "error", err)
+		return nil, false, perr.InternalWithMessage("error claiming idempotency key " + err.Error())
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, false, perr.InternalWithMessage("error claiming idempotency key " + err.Error())
+	}
+
+	if rowsAffected == 1 {
+		return &IdempotencyKey{
+			TriggerName:         triggerName,
+			Key:                 key,
+			ExecutionID:         executionID,
+			PipelineExecutionID: pipelineExecutionID,
+			ExpiresAt:           now.Add(ttl),
+		}, true, nil
+	}
+
+	existing := &IdempotencyKey{
+		TriggerName: triggerName,
+		Key:         key,
+	}
+
+	var expiresAt int64
+	row := db.QueryRow("select execution_id, pipeline_execution_id, expires_at from trigger_idempotency_key where trigger_name = ? and idempotency_key = ?", triggerName, key)
+	err = row.Scan(&existing.ExecutionID, &existing.PipelineExecutionID, &expiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// deleted by the cleanup between the two statements, unlikely as it had not expired
+			return nil, false, perr.ConflictWithMessage("idempotency key " + key + " was released, retry the request")
+		}
+		slog.Error("error getting idempotency key", "trigger", triggerName, "error", err)
+		return nil, false, perr.InternalWithMessage("error getting idempotency key " + err.Error())
+	}
+	existing.ExpiresAt = time.UnixMilli(expiresAt).UTC()
+
+	return existing, false, nil
+}
+
+// ReleaseIdempotencyKey forgets the idempotency key recorded for the execution, so a retried delivery starts a new
+// execution. Used when the execution recorded for the key could not be started.
+func ReleaseIdempotencyKey(triggerName, key, executionID string) error {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec("delete from trigger_idempotency_key where trigger_name = ? and idempotency_key = ? and execution_id = ?", triggerName, key, executionID)
+	if err != nil {
+		slog.Error("error releasing idempotency key", "trigger", triggerName, "error", err)
+		return perr.InternalWithMessage("error releasing idempotency key " + err.Error())
+	}
+
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys deletes the idempotency keys past their ttl, returns the number of keys deleted
+func DeleteExpiredIdempotencyKeys(currentTime time.Time) (int64, error) {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	result, err := db.Exec("delete from trigger_idempotency_key where expires_at < ?", currentTime.UnixMilli())
+	if err != nil {
+		slog.Error("error deleting expired idempotency keys", "error", err)
+		return 0, perr.InternalWithMessage("error deleting expired idempotency keys " + err.Error())
+	}
+
+	return result.RowsAffected()
+}
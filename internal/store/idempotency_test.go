@@ -0,0 +1,66 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimIdempotencyKey(t *testing.T) {
+
+	assert := assert.New(t)
+
+	err := copyNewFlowpipeDbCleanFile("./clean_test_files/flowpipe_clean.db")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	key, claimed, err := ClaimIdempotencyKey("mod.trigger.http.github", "delivery-1", "exec_a", "pexec_a", time.Minute)
+	assert.Nil(err)
+	assert.True(claimed, "the first delivery should claim the key")
+	assert.Equal("exec_a", key.ExecutionID)
+
+	key, claimed, err = ClaimIdempotencyKey("mod.trigger.http.github", "delivery-1", "exec_b", "pexec_b", time.Minute)
+	assert.Nil(err)
+	assert.False(claimed, "a retried delivery should not claim the key")
+	assert.Equal("exec_a", key.ExecutionID)
+	assert.Equal("pexec_a", key.PipelineExecutionID)
+
+	// keys are per trigger
+	_, claimed, err = ClaimIdempotencyKey("mod.trigger.http.stripe", "delivery-1", "exec_c", "pexec_c", time.Minute)
+	assert.Nil(err)
+	assert.True(claimed)
+
+	// releasing the key of another execution does nothing
+	err = ReleaseIdempotencyKey("mod.trigger.http.github", "delivery-1", "exec_b")
+	assert.Nil(err)
+
+	key, claimed, err = ClaimIdempotencyKey("mod.trigger.http.github", "delivery-1", "exec_d", "pexec_d", time.Minute)
+	assert.Nil(err)
+	assert.False(claimed)
+	assert.Equal("exec_a", key.ExecutionID)
+
+	err = ReleaseIdempotencyKey("mod.trigger.http.github", "delivery-1", "exec_a")
+	assert.Nil(err)
+
+	_, claimed, err = ClaimIdempotencyKey("mod.trigger.http.github", "delivery-1", "exec_e", "pexec_e", time.Millisecond)
+	assert.Nil(err)
+	assert.True(claimed, "a released key should be claimed again")
+
+	time.Sleep(10 * time.Millisecond)
+
+	key, claimed, err = ClaimIdempotencyKey("mod.trigger.http.github", "delivery-1", "exec_f", "pexec_f", time.Minute)
+	assert.Nil(err)
+	assert.True(claimed, "an expired key should be claimed again")
+	assert.Equal("exec_f", key.ExecutionID)
+
+	_, _, err = ClaimIdempotencyKey("mod.trigger.http.github", "delivery-2", "exec_g", "pexec_g", time.Millisecond)
+	assert.Nil(err)
+
+	time.Sleep(10 * time.Millisecond)
+
+	deleted, err := DeleteExpiredIdempotencyKeys(time.Now().UTC())
+	assert.Nil(err)
+	assert.Equal(int64(1), deleted)
+}
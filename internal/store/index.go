@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log/slog"
 	"os"
 	"time"
@@ -12,7 +13,7 @@ import (
 	"github.com/turbot/pipe-fittings/perr"
 	"github.com/turbot/pipe-fittings/utils"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
 func moveFlowpipeDbFromModDirToFlowpipeModDir() error {
@@ -245,6 +246,149 @@ func UpgradeFlowpipeDB2() error {
 	return nil
 }
 
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// createPipelineRunListIndexes creates the indexes used to filter and page through the process list
+func createPipelineRunListIndexes(db execer) error {
+	indexes := []string{
+		`create index if not exists idx_pipeline_run_started_at on pipeline_run (started_at, execution_id)`,
+		`create index if not exists idx_pipeline_run_state_started_at on pipeline_run (state, started_at)`,
+		`create index if not exists idx_pipeline_run_pipeline_started_at on pipeline_run (pipeline, started_at)`,
+		`create index if not exists idx_pipeline_run_trigger_name_started_at on pipeline_run (trigger_name, started_at)`,
+	}
+
+	for _, indexSql := range indexes {
+		_, err := db.Exec(indexSql)
+		if err != nil {
+			slog.Error("error creating pipeline_run index", "error", err)
+			return perr.InternalWithMessage("error creating pipeline_run index")
+		}
+	}
+
+	return nil
+}
+
+// upgradePipelineRunTable adds the trigger_name column and the list indexes to a flowpipe.db created before they were
+// introduced, the databases created since have them already
+func upgradePipelineRunTable(tx *sql.Tx) error {
+	var count int
+	err := tx.QueryRow(`select count(*) from pragma_table_info('pipeline_run') where name = 'trigger_name'`).Scan(&count)
+	if err != nil {
+		slog.Error("error checking pipeline_run columns", "error", err)
+		return perr.InternalWithMessage("error checking pipeline_run columns")
+	}
+
+	if count == 0 {
+		_, err = tx.Exec(`alter table pipeline_run add column trigger_name text`)
+		if err != nil {
+			slog.Error("error adding trigger_name column to pipeline_run", "error", err)
+			return perr.InternalWithMessage("error adding trigger_name column to pipeline_run")
+		}
+	}
+
+	return createPipelineRunListIndexes(tx)
+}
+
+// sqliteMigration is a change to the flowpipe.db schema, applied in the migration transaction
+type sqliteMigration func(tx *sql.Tx) error
+
+// sqliteStatement is a migration running a single statement
+func sqliteStatement(statement string) sqliteMigration {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(statement)
+		return err
+	}
+}
+
+// sqliteMigrations are the changes made to flowpipe.db after version 2.0, applied in order and tracked by the sqlite
+// user_version. A migration is never changed once released: add a new one instead.
+var sqliteMigrations = []sqliteMigration{
+	// 1: scheduler lease
+	sqliteStatement(leaseTableSQL),
+
+	// 2: http trigger idempotency keys
+	sqliteStatement(triggerIdempotencyKeyTableSQL),
+
+	// 3: query trigger watermarks
+	sqliteStatement(queryTriggerWatermarkTableSQL),
+
+	// 4: query trigger pending rows
+	sqliteStatement(queryTriggerPendingRowTableSQL),
+
+	// 5: trigger run history
+	sqliteStatement(triggerRunTableSQL),
+
+	// 6: trigger overrides
+	sqliteStatement(triggerOverrideTableSQL),
+
+	// 7: trigger schedule
+	sqliteStatement(triggerScheduleTableSQL),
+
+	// 8: process list filters and pagination
+	upgradePipelineRunTable,
+
+	// 9: pipeline run owner
+	sqliteStatement(`alter table pipeline_run add column owner text`),
+}
+
+// migrateSQLite applies the migrations that flowpipe.db doesn't have yet
+func migrateSQLite(db *sql.DB) error {
+	var currentVersion int
+	err := db.QueryRow("pragma user_version").Scan(&currentVersion)
+	if err != nil {
+		slog.Error("error getting flowpipe.db user_version", "error", err)
+		return perr.InternalWithMessage("error getting flowpipe.db user_version")
+	}
+
+	if currentVersion >= len(sqliteMigrations) {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		slog.Error("error starting transaction", "error", err)
+		return perr.InternalWithMessage("error starting transaction")
+	}
+
+	commited := false
+	defer func() {
+		if !commited {
+			err := tx.Rollback()
+			if err != nil {
+				slog.Error("error rolling back transaction", "error", err)
+			}
+		}
+	}()
+
+	for i := currentVersion; i < len(sqliteMigrations); i++ {
+		slog.Debug("Migrating flowpipe.db", "version", i+1)
+
+		err = sqliteMigrations[i](tx)
+		if err != nil {
+			slog.Error("error migrating flowpipe.db", "version", i+1, "error", err)
+			return perr.InternalWithMessage("error migrating flowpipe.db " + err.Error())
+		}
+	}
+
+	// pragma doesn't take parameters
+	_, err = tx.Exec(fmt.Sprintf("pragma user_version = %d", len(sqliteMigrations)))
+	if err != nil {
+		slog.Error("error setting flowpipe.db user_version", "error", err)
+		return perr.InternalWithMessage("error setting flowpipe.db user_version")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		slog.Error("error committing transaction", "error", err)
+		return perr.InternalWithMessage("error committing transaction")
+	}
+	commited = true
+
+	return nil
+}
+
 func InitializeFlowpipeDB() error {
 
 	err := moveFlowpipeDbFromModDirToFlowpipeModDir()
@@ -290,7 +434,8 @@ func InitializeFlowpipeDB() error {
 		pipeline text,
 		state text,
 		started_at datetime,
-		updated_at datetime
+		updated_at datetime,
+		trigger_name text
 	)`
 
 	_, err = tx.Exec(createTableSQL)
@@ -306,6 +451,11 @@ func InitializeFlowpipeDB() error {
 		return perr.InternalWithMessage("error creating pipeline_run index")
 	}
 
+	err = createPipelineRunListIndexes(tx)
+	if err != nil {
+		return err
+	}
+
 	err = createEventTable(tx)
 	if err != nil {
 		slog.Error("error creating event table", "error", err)
@@ -374,7 +524,41 @@ func InitializeFlowpipeDB() error {
 	return nil
 }
 
-func OpenFlowpipeDB() (*sql.DB, error) {
+// OpenFlowpipeDB opens the process store, flowpipe.db unless a Postgres process store is configured. The caller is
+// responsible for closing the connection.
+func OpenFlowpipeDB() (*DB, error) {
+	b := CurrentBackend()
+
+	db, err := b.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DB{DB: db, backend: b}, nil
+}
+
+// sqliteBackend stores the processes in flowpipe.db in the mod location
+type sqliteBackend struct{}
+
+func (b *sqliteBackend) Name() string {
+	return BackendSQLite
+}
+
+func (b *sqliteBackend) Shared() bool {
+	return false
+}
+
+func (b *sqliteBackend) Rebind(query string) string {
+	return query
+}
+
+func (b *sqliteBackend) IsUniqueViolation(err error) bool {
+	sqliteErr, ok := err.(sqlite3.Error)
+	return ok && sqliteErr.Code == sqlite3.ErrConstraint &&
+		(sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
+}
+
+func (b *sqliteBackend) Open() (*sql.DB, error) {
 
 	dbPath := filepaths.FlowpipeDBFileName()
 
@@ -400,6 +584,12 @@ func OpenFlowpipeDB() (*sql.DB, error) {
 		return nil, perr.InternalWithMessage("Error opening SQLite database " + err.Error())
 	}
 
+	err = migrateSQLite(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	// Enable foreign key constraints
 	_, err = db.Exec("PRAGMA foreign_keys=ON")
 	if err != nil {
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/turbot/pipe-fittings/perr"
+	putils "github.com/turbot/pipe-fittings/utils"
+)
+
+// leaseTableSQL creates the table of the leases of the servers sharing the process store, created by the store migrations
+const leaseTableSQL = `
+	create table if not exists lease (
+		name text primary key,
+		holder text not null,
+		expires_at bigint not null,
+		updated_at text
+	)
+	`
+
+// AcquireLease takes the named lease for the holder, or renews it if the holder already has it. The lease can only be
+// taken from another holder once it has expired. Returns true if the holder has the lease for the next ttl.
+//
+// Leases are stored in the process store, so they are shared by every server using the same store.
+func AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	now := time.Now().UTC()
+
+	upsertSQL := `insert into lease (name, holder, expires_at, updated_at) values (?, ?, ?, ?)
+		on conflict (name) do update set holder = excluded.holder, expires_at = excluded.expires_at, updated_at = excluded.updated_at
+		where lease.holder = excluded.holder or lease.expires_at < ?`
+
+	result, err := db.Exec(upsertSQL, name, holder, now.Add(ttl).UnixMilli(), now.Format(putils.RFC3339WithMS), now.UnixMilli())
+	if err != nil {
+		slog.Error("error acquiring lease", "name", name, "holder", holder, "error", err)
+		return false, perr.InternalWithMessage("error acquiring lease " + err.Error())
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, perr.InternalWithMessage("error acquiring lease " + err.Error())
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// ReleaseLease gives up the named lease so another holder can take it without waiting for it to expire. Does nothing if
+// the lease is held by someone else.
+func ReleaseLease(name, holder string) error {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec("delete from lease where name = ? and holder = ?", name, holder)
+	if err != nil {
+		slog.Error("error releasing lease", "name", name, "holder", holder, "error", err)
+		return perr.InternalWithMessage("error releasing lease " + err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquireLease(t *testing.T) {
+
+	assert := assert.New(t)
+
+	err := copyNewFlowpipeDbCleanFile("./clean_test_files/flowpipe_clean.db")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	acquired, err := AcquireLease("scheduler", "node-a", time.Minute)
+	assert.Nil(err)
+	assert.True(acquired, "node-a should take the free lease")
+
+	acquired, err = AcquireLease("scheduler", "node-b", time.Minute)
+	assert.Nil(err)
+	assert.False(acquired, "node-b should not take the lease held by node-a")
+
+	acquired, err = AcquireLease("scheduler", "node-a", time.Minute)
+	assert.Nil(err)
+	assert.True(acquired, "node-a should renew its lease")
+
+	// releasing a lease held by someone else does nothing
+	err = ReleaseLease("scheduler", "node-b")
+	assert.Nil(err)
+
+	acquired, err = AcquireLease("scheduler", "node-b", time.Minute)
+	assert.Nil(err)
+	assert.False(acquired)
+
+	err = ReleaseLease("scheduler", "node-a")
+	assert.Nil(err)
+
+	acquired, err = AcquireLease("scheduler", "node-b", time.Millisecond)
+	assert.Nil(err)
+	assert.True(acquired, "node-b should take the released lease")
+
+	time.Sleep(10 * time.Millisecond)
+
+	acquired, err = AcquireLease("scheduler", "node-a", time.Minute)
+	assert.Nil(err)
+	assert.True(acquired, "node-a should take the expired lease")
+}
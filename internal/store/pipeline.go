@@ -1,17 +1,43 @@
 package store
 
 import (
+	"database/sql"
 	"log/slog"
+	"strings"
 	"time"
 
-	"github.com/mattn/go-sqlite3"
 	"github.com/spf13/viper"
 	"github.com/turbot/pipe-fittings/constants"
 	"github.com/turbot/pipe-fittings/perr"
 	putils "github.com/turbot/pipe-fittings/utils"
 )
 
-func StartPipeline(executionId, pipelineName string) error {
+// processOwner is recorded as the owner of the pipeline runs started by this server
+var processOwner string
+
+// SetProcessOwner sets the node recorded as the owner of the pipeline runs started from now on. With leader election the
+// servers sharing the process store renew their node lease (NodeLeaseName) while they are running, the queued and
+// started runs of a server whose node lease has expired are recovered by the leader.
+func SetProcessOwner(owner string) {
+	processOwner = owner
+}
+
+// NodeLeaseName is the lease renewed by a server while it is running its pipeline runs
+func NodeLeaseName(nodeID string) string {
+	return nodeLeasePrefix + nodeID
+}
+
+const nodeLeasePrefix = "node:"
+
+// orphanedPipelineRunSQL matches the queued and started runs whose owner isn't renewing its node lease, the runs
+// without an owner included
+const orphanedPipelineRunSQL = `state in ('queued', 'started') and not exists (
+		select 1 from lease where lease.name = '` + nodeLeasePrefix + `' || coalesce(pipeline_run.owner, '') and lease.expires_at >= ?
+	)`
+
+// StartPipeline records a new execution in pipeline_run. The trigger name is empty if the execution was not started by a
+// trigger.
+func StartPipeline(executionId, pipelineName, triggerName string) error {
 	retentionInSecond := viper.GetInt(constants.ArgProcessRetention)
 	if retentionInSecond == 0 {
 		return nil
@@ -24,7 +50,7 @@ func StartPipeline(executionId, pipelineName string) error {
 	defer db.Close()
 
 	// Prepare the insert statement
-	stmt, err := db.Prepare("insert into pipeline_run(execution_id, pipeline, state, started_at, updated_at) values(?, ?, ?, ?, ?)")
+	stmt, err := db.Prepare("insert into pipeline_run(execution_id, pipeline, trigger_name, state, started_at, updated_at, owner) values(?, ?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		slog.Error("error preparing statement", "error", err)
 		return perr.InternalWithMessage("error preparing statement " + err.Error())
@@ -34,10 +60,9 @@ func StartPipeline(executionId, pipelineName string) error {
 	// Execute the statement
 	currentTime := time.Now().UTC()
 	currentTimeString := currentTime.Format(putils.RFC3339WithMS)
-	_, err = stmt.Exec(executionId, pipelineName, "queued", currentTimeString, currentTimeString)
+	_, err = stmt.Exec(executionId, pipelineName, triggerName, "queued", currentTimeString, currentTimeString, sql.NullString{String: processOwner, Valid: processOwner != ""})
 	if err != nil {
-		sqlIteErr, ok := err.(sqlite3.Error)
-		if ok && sqlIteErr.Code == sqlite3.ErrConstraint && sqlIteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		if db.Backend().IsUniqueViolation(err) {
 			slog.Error("pipeline execution already exists", "executionID", executionId)
 			return perr.BadRequestWithMessage("pipeline execution '" + executionId + "' already exists")
 		}
@@ -80,3 +105,308 @@ func UpdatePipelineState(executionID, newState string) error {
 
 	return nil
 }
+
+// ListPipelineRunsWithState returns the id of the executions whose pipeline_run is in one of the given states
+func ListPipelineRunsWithState(states ...string) ([]string, error) {
+	if len(states) == 0 {
+		return nil, nil
+	}
+
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	args := make([]any, len(states))
+	placeholders := make([]string, len(states))
+	for i, state := range states {
+		args[i] = state
+		placeholders[i] = "?"
+	}
+
+	rows, err := db.Query("select execution_id from pipeline_run where state in ("+strings.Join(placeholders, ", ")+") order by started_at", args...)
+	if err != nil {
+		slog.Error("error querying pipeline_run", "error", err)
+		return nil, perr.InternalWithMessage("error querying pipeline_run")
+	}
+	defer rows.Close()
+
+	var executionIDs []string
+	for rows.Next() {
+		var executionID string
+		err = rows.Scan(&executionID)
+		if err != nil {
+			slog.Error("error scanning pipeline_run", "error", err)
+			return nil, perr.InternalWithMessage("error scanning pipeline_run")
+		}
+		executionIDs = append(executionIDs, executionID)
+	}
+
+	return executionIDs, nil
+}
+
+// ListOrphanedPipelineRuns returns the id of the queued and started executions whose owner has stopped renewing its node
+// lease, the server running them has stopped
+func ListOrphanedPipelineRuns() ([]string, error) {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("select execution_id from pipeline_run where "+orphanedPipelineRunSQL+" order by started_at", time.Now().UTC().UnixMilli())
+	if err != nil {
+		slog.Error("error querying orphaned pipeline_run", "error", err)
+		return nil, perr.InternalWithMessage("error querying pipeline_run")
+	}
+	defer rows.Close()
+
+	var executionIDs []string
+	for rows.Next() {
+		var executionID string
+		err = rows.Scan(&executionID)
+		if err != nil {
+			slog.Error("error scanning pipeline_run", "error", err)
+			return nil, perr.InternalWithMessage("error scanning pipeline_run")
+		}
+		executionIDs = append(executionIDs, executionID)
+	}
+
+	return executionIDs, nil
+}
+
+// ClaimOrphanedPipelineRun records this server as the owner of the run if it's still orphaned. Returns false if the run
+// has been claimed by another server or isn't running anymore.
+func ClaimOrphanedPipelineRun(executionID string) (bool, error) {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	now := time.Now().UTC()
+	result, err := db.Exec("update pipeline_run set owner = ?, updated_at = ? where execution_id = ? and "+orphanedPipelineRunSQL,
+		sql.NullString{String: processOwner, Valid: processOwner != ""}, now.Format(putils.RFC3339WithMS), executionID, now.UnixMilli())
+	if err != nil {
+		slog.Error("error claiming pipeline_run", "execution_id", executionID, "error", err)
+		return false, perr.InternalWithMessage("error claiming pipeline_run " + err.Error())
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, perr.InternalWithMessage("error claiming pipeline_run " + err.Error())
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// ClaimPipelineRun records this server as the owner of the run whoever owned it, e.g. the run of a command redelivered
+// by this server. Does nothing without a process owner.
+func ClaimPipelineRun(executionID string) error {
+	if processOwner == "" {
+		return nil
+	}
+
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec("update pipeline_run set owner = ?, updated_at = ? where execution_id = ?", processOwner, time.Now().UTC().Format(putils.RFC3339WithMS), executionID)
+	if err != nil {
+		slog.Error("error claiming pipeline_run", "execution_id", executionID, "error", err)
+		return perr.InternalWithMessage("error claiming pipeline_run " + err.Error())
+	}
+
+	return nil
+}
+
+// PipelineRun is a row of pipeline_run, one per execution
+type PipelineRun struct {
+	ExecutionID string
+	Pipeline    string
+	Trigger     string
+	State       string
+	StartedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type PipelineRunFilter struct {
+	States   []string
+	Pipeline string
+	Trigger  string
+
+	// Only the executions started in [Since, Until)
+	Since *time.Time
+	Until *time.Time
+
+	// Oldest first, the default is newest first
+	Ascending bool
+
+	// Cursor returned by the previous page, empty for the first page
+	Cursor string
+	Limit  int
+}
+
+// ListPipelineRuns returns a page of the executions matching the filter, and the cursor of the next page (empty on the
+// last page). The pages are keyed on the start time and the execution id, so executions added while paging through
+// the list don't shift the pages.
+func ListPipelineRuns(filter PipelineRunFilter) ([]PipelineRun, string, error) {
+	var conditions []string
+	var args []any
+
+	if len(filter.States) > 0 {
+		placeholders := make([]string, len(filter.States))
+		for i, state := range filter.States {
+			placeholders[i] = "?"
+			args = append(args, state)
+		}
+		conditions = append(conditions, "state in ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	if filter.Pipeline != "" {
+		conditions = append(conditions, "pipeline = ?")
+		args = append(args, filter.Pipeline)
+	}
+
+	if filter.Trigger != "" {
+		conditions = append(conditions, "trigger_name = ?")
+		args = append(args, filter.Trigger)
+	}
+
+	if filter.Since != nil {
+		conditions = append(conditions, "started_at >= ?")
+		args = append(args, filter.Since.UTC().Format(putils.RFC3339WithMS))
+	}
+
+	if filter.Until != nil {
+		conditions = append(conditions, "started_at < ?")
+		args = append(args, filter.Until.UTC().Format(putils.RFC3339WithMS))
+	}
+
+	order := "desc"
+	comparison := "<"
+	if filter.Ascending {
+		order = "asc"
+		comparison = ">"
+	}
+
+	if filter.Cursor != "" {
+		cursorStartedAt, cursorExecutionID, found := strings.Cut(filter.Cursor, "|")
+		if !found {
+			return nil, "", perr.BadRequestWithMessage("invalid cursor")
+		}
+		conditions = append(conditions, "(started_at "+comparison+" ? or (started_at = ? and execution_id "+comparison+" ?))")
+		args = append(args, cursorStartedAt, cursorStartedAt, cursorExecutionID)
+	}
+
+	query := "select execution_id, pipeline, trigger_name, state, started_at, updated_at from pipeline_run"
+	if len(conditions) > 0 {
+		query += " where " + strings.Join(conditions, " and ")
+	}
+	query += " order by started_at " + order + ", execution_id " + order
+
+	if filter.Limit > 0 {
+		// fetch one more to know if there is a next page
+		query += " limit ?"
+		args = append(args, filter.Limit+1)
+	}
+
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return nil, "", err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		slog.Error("error querying pipeline_run", "error", err)
+		return nil, "", perr.InternalWithMessage("error querying pipeline_run")
+	}
+	defer rows.Close()
+
+	var runs []PipelineRun
+	var startedAts []string
+	for rows.Next() {
+		var run PipelineRun
+		var pipeline, trigger sql.NullString
+		var startedAt, updatedAt any
+
+		err = rows.Scan(&run.ExecutionID, &pipeline, &trigger, &run.State, &startedAt, &updatedAt)
+		if err != nil {
+			slog.Error("error scanning pipeline_run", "error", err)
+			return nil, "", perr.InternalWithMessage("error scanning pipeline_run")
+		}
+
+		run.Pipeline = pipeline.String
+		run.Trigger = trigger.String
+		run.StartedAt = timestampValue(startedAt)
+		run.UpdatedAt = timestampValue(updatedAt)
+
+		runs = append(runs, run)
+		startedAts = append(startedAts, run.StartedAt.Format(putils.RFC3339WithMS))
+	}
+
+	cursor := ""
+	if filter.Limit > 0 && len(runs) > filter.Limit {
+		runs = runs[:filter.Limit]
+		cursor = startedAts[filter.Limit-1] + "|" + runs[filter.Limit-1].ExecutionID
+	}
+
+	return runs, cursor, nil
+}
+
+// GetPipelineRun returns the pipeline_run row of an execution
+func GetPipelineRun(executionID string) (*PipelineRun, error) {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	run := PipelineRun{}
+	var pipeline, trigger sql.NullString
+	var startedAt, updatedAt any
+
+	row := db.QueryRow("select execution_id, pipeline, trigger_name, state, started_at, updated_at from pipeline_run where execution_id = ?", executionID)
+	err = row.Scan(&run.ExecutionID, &pipeline, &trigger, &run.State, &startedAt, &updatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, perr.NotFoundWithMessage("process '" + executionID + "' not found")
+		}
+
+		slog.Error("error querying pipeline_run", "error", err)
+		return nil, perr.InternalWithMessage("error querying pipeline_run")
+	}
+
+	run.Pipeline = pipeline.String
+	run.Trigger = trigger.String
+	run.StartedAt = timestampValue(startedAt)
+	run.UpdatedAt = timestampValue(updatedAt)
+
+	return &run, nil
+}
+
+// timestampValue converts a pipeline_run timestamp, stored as a RFC3339WithMS string, to a time. The SQLite driver
+// already returns a time for the datetime columns.
+func timestampValue(v any) time.Time {
+	var str string
+	switch t := v.(type) {
+	case time.Time:
+		return t.UTC()
+	case string:
+		str = t
+	case []byte:
+		str = string(t)
+	default:
+		return time.Time{}
+	}
+
+	parsed, err := time.Parse(putils.RFC3339WithMS, str)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
@@ -0,0 +1,157 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListPipelineRunsWithState(t *testing.T) {
+
+	assert := assert.New(t)
+
+	err := copyNewFlowpipeDbCleanFile("./clean_test_files/flowpipe_clean_2.db")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	executionIDs, err := ListPipelineRunsWithState("queued", "started")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	assert.Equal([]string{"exec_cmu5cli72ijjh42rbl1g"}, executionIDs)
+
+	executionIDs, err = ListPipelineRunsWithState("finished")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	assert.Equal(6, len(executionIDs))
+	assert.Equal("exec_cmu410272ijuoi3q9gd0", executionIDs[0], "executions should be ordered by start time")
+
+	executionIDs, err = ListPipelineRunsWithState()
+	assert.Nil(err)
+	assert.Equal(0, len(executionIDs))
+}
+
+func TestListPipelineRuns(t *testing.T) {
+
+	assert := assert.New(t)
+
+	err := copyNewFlowpipeDbCleanFile("./clean_test_files/flowpipe_clean_2.db")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	runs, cursor, err := ListPipelineRuns(PipelineRunFilter{})
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	assert.Equal(7, len(runs))
+	assert.Equal("", cursor)
+	for i := 1; i < len(runs); i++ {
+		assert.False(runs[i].StartedAt.After(runs[i-1].StartedAt), "executions should be ordered newest first")
+	}
+
+	runs, _, err = ListPipelineRuns(PipelineRunFilter{States: []string{"started"}})
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	assert.Equal(1, len(runs))
+	assert.Equal("exec_cmu5cli72ijjh42rbl1g", runs[0].ExecutionID)
+
+	// page through the finished executions, oldest first
+	var executionIDs []string
+	filter := PipelineRunFilter{States: []string{"finished"}, Ascending: true, Limit: 4}
+	for {
+		runs, cursor, err = ListPipelineRuns(filter)
+		if err != nil {
+			assert.FailNow(err.Error())
+		}
+		for _, run := range runs {
+			executionIDs = append(executionIDs, run.ExecutionID)
+		}
+		if cursor == "" {
+			break
+		}
+		filter.Cursor = cursor
+	}
+
+	expected, err := ListPipelineRunsWithState("finished")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal(expected, executionIDs)
+
+	since := runs[len(runs)-1].StartedAt
+	runs, _, err = ListPipelineRuns(PipelineRunFilter{States: []string{"finished"}, Since: &since})
+	assert.Nil(err)
+	assert.Equal(1, len(runs))
+
+	_, _, err = ListPipelineRuns(PipelineRunFilter{Cursor: "invalid"})
+	assert.NotNil(err)
+}
+
+func TestOrphanedPipelineRuns(t *testing.T) {
+
+	assert := assert.New(t)
+
+	err := copyNewFlowpipeDbCleanFile("./clean_test_files/flowpipe_clean_2.db")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	SetProcessOwner("node-a")
+	defer SetProcessOwner("")
+
+	// the run doesn't have an owner
+	executionIDs, err := ListOrphanedPipelineRuns()
+	assert.Nil(err)
+	assert.Equal([]string{"exec_cmu5cli72ijjh42rbl1g"}, executionIDs)
+
+	acquired, err := AcquireLease(NodeLeaseName("node-a"), "node-a", time.Minute)
+	assert.Nil(err)
+	assert.True(acquired)
+
+	claimed, err := ClaimOrphanedPipelineRun("exec_cmu5cli72ijjh42rbl1g")
+	assert.Nil(err)
+	assert.True(claimed)
+
+	// node-a is renewing its lease
+	executionIDs, err = ListOrphanedPipelineRuns()
+	assert.Nil(err)
+	assert.Equal(0, len(executionIDs))
+
+	claimed, err = ClaimOrphanedPipelineRun("exec_cmu5cli72ijjh42rbl1g")
+	assert.Nil(err)
+	assert.False(claimed, "the run is owned by a running node")
+
+	// finished runs are never orphaned
+	claimed, err = ClaimOrphanedPipelineRun("exec_cmu410272ijuoi3q9gd0")
+	assert.Nil(err)
+	assert.False(claimed)
+
+	err = ReleaseLease(NodeLeaseName("node-a"), "node-a")
+	assert.Nil(err)
+
+	executionIDs, err = ListOrphanedPipelineRuns()
+	assert.Nil(err)
+	assert.Equal([]string{"exec_cmu5cli72ijjh42rbl1g"}, executionIDs)
+
+	// node-b redelivers the run's commands
+	SetProcessOwner("node-b")
+	err = ClaimPipelineRun("exec_cmu5cli72ijjh42rbl1g")
+	assert.Nil(err)
+
+	acquired, err = AcquireLease(NodeLeaseName("node-b"), "node-b", time.Minute)
+	assert.Nil(err)
+	assert.True(acquired)
+
+	executionIDs, err = ListOrphanedPipelineRuns()
+	assert.Nil(err)
+	assert.Equal(0, len(executionIDs))
+}
@@ -0,0 +1,227 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/lib/pq"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+// postgresMigrationLockID is the advisory lock held while migrating, so servers starting together against the same
+// database don't apply the migrations twice
+const postgresMigrationLockID = 7103
+
+// postgresMigrations are applied in order, a migration is never changed once released: add a new one instead.
+//
+// The timestamps written as strings by the process store (pipeline_run, internal and query_trigger_captured_row) are
+// kept as text, like in flowpipe.db.
+var postgresMigrations = []string{
+	// 1: initial schema, equivalent to flowpipe.db version 2.0
+	`
+	create table if not exists pipeline_run (
+		id bigserial primary key,
+		execution_id text unique,
+		pipeline text,
+		state text,
+		started_at text,
+		updated_at text
+	);
+
+	create table if not exists event (
+		id text primary key,
+		struct_version text,
+		process_id text,
+		message text,
+		level text,
+		created_at timestamptz,
+		detail text,
+		constraint fk_event_execution_id foreign key (process_id) references pipeline_run(execution_id) on delete cascade
+	);
+
+	create index if not exists idx_event_process_id on event (process_id);
+	create index if not exists idx_event_created_at on event (created_at);
+
+	create table if not exists query_trigger_captured_row (
+		trigger_name text,
+		primary_key text,
+		row_hash text,
+		created_at text,
+		updated_at text,
+		primary key (trigger_name, primary_key)
+	);
+
+	create table if not exists internal (
+		id bigserial primary key,
+		name text unique,
+		created_at text,
+		updated_at text,
+		value text
+	);
+	`,
+
+	// 2: process list filters and pagination
+	`
+	alter table pipeline_run add column if not exists trigger_name text;
+
+	create index if not exists idx_pipeline_run_started_at on pipeline_run (started_at, execution_id);
+	create index if not exists idx_pipeline_run_state_started_at on pipeline_run (state, started_at);
+	create index if not exists idx_pipeline_run_pipeline_started_at on pipeline_run (pipeline, started_at);
+	create index if not exists idx_pipeline_run_trigger_name_started_at on pipeline_run (trigger_name, started_at);
+	`,
+
+	// 3: scheduler lease
+	leaseTableSQL,
+
+	// 4: http trigger idempotency keys
+	triggerIdempotencyKeyTableSQL,
+
+	// 5: query trigger watermarks
+	queryTriggerWatermarkTableSQL,
+
+	// 6: query trigger pending rows
+	queryTriggerPendingRowTableSQL,
+
+	// 7: trigger run history
+	triggerRunTableSQL,
+
+	// 8: trigger overrides
+	triggerOverrideTableSQL,
+
+	// 9: trigger schedule
+	triggerScheduleTableSQL,
+
+	// 10: pipeline run owner
+	`alter table pipeline_run add column if not exists owner text`,
+}
+
+// postgresBackend stores the processes in a Postgres database. Unlike flowpipe.db the database can be shared by several
+// servers and lives on managed storage.
+type postgresBackend struct {
+	connectionString string
+
+	lock sync.Mutex
+	db   *sql.DB
+}
+
+func newPostgresBackend(connectionString string) *postgresBackend {
+	return &postgresBackend{
+		connectionString: connectionString,
+	}
+}
+
+func (b *postgresBackend) Name() string {
+	return BackendPostgres
+}
+
+func (b *postgresBackend) Shared() bool {
+	return true
+}
+
+func (b *postgresBackend) Rebind(query string) string {
+	return rebindDollar(query)
+}
+
+func (b *postgresBackend) IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// Open returns the connection pool, the schema is migrated the first time the database is opened.
+func (b *postgresBackend) Open() (*sql.DB, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.db != nil {
+		return b.db, nil
+	}
+
+	db, err := sql.Open("postgres", b.connectionString)
+	if err != nil {
+		return nil, perr.InternalWithMessage("Error opening Postgres process store " + err.Error())
+	}
+
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		slog.Error("error connecting to Postgres process store", "error", err)
+		return nil, perr.InternalWithMessage("Error connecting to Postgres process store " + err.Error())
+	}
+
+	err = migratePostgres(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	b.db = db
+	return b.db, nil
+}
+
+func migratePostgres(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		slog.Error("error starting transaction", "error", err)
+		return perr.InternalWithMessage("error starting transaction")
+	}
+
+	commited := false
+	defer func() {
+		if !commited {
+			err := tx.Rollback()
+			if err != nil {
+				slog.Error("error rolling back transaction", "error", err)
+			}
+		}
+	}()
+
+	_, err = tx.Exec("select pg_advisory_xact_lock($1)", postgresMigrationLockID)
+	if err != nil {
+		slog.Error("error acquiring migration lock", "error", err)
+		return perr.InternalWithMessage("error acquiring migration lock")
+	}
+
+	_, err = tx.Exec(`create table if not exists schema_migration (
+		version integer primary key,
+		applied_at timestamptz not null default now()
+	)`)
+	if err != nil {
+		slog.Error("error creating schema_migration table", "error", err)
+		return perr.InternalWithMessage("error creating schema_migration table")
+	}
+
+	var currentVersion int
+	err = tx.QueryRow("select coalesce(max(version), 0) from schema_migration").Scan(&currentVersion)
+	if err != nil {
+		slog.Error("error getting current schema version", "error", err)
+		return perr.InternalWithMessage("error getting current schema version")
+	}
+
+	for i := currentVersion; i < len(postgresMigrations); i++ {
+		version := i + 1
+		slog.Info("Migrating Postgres process store", "version", version)
+
+		_, err = tx.Exec(postgresMigrations[i])
+		if err != nil {
+			slog.Error("error migrating Postgres process store", "version", version, "error", err)
+			return perr.InternalWithMessage("error migrating Postgres process store " + err.Error())
+		}
+
+		_, err = tx.Exec("insert into schema_migration (version) values ($1)", version)
+		if err != nil {
+			slog.Error("error recording schema version", "version", version, "error", err)
+			return perr.InternalWithMessage("error recording schema version")
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		slog.Error("error committing transaction", "error", err)
+		return perr.InternalWithMessage("error committing transaction")
+	}
+	commited = true
+
+	return nil
+}
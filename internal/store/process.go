@@ -1,11 +1,25 @@
 package store
 
 import (
+	"encoding/json"
 	"log/slog"
+	"time"
 
 	"github.com/turbot/pipe-fittings/perr"
+	putils "github.com/turbot/pipe-fittings/utils"
 )
 
+// EventRecord is a row of the event table, it has the JSON layout of the process event log
+type EventRecord struct {
+	StructVersion string          `json:"struct_version"`
+	ID            string          `json:"id"`
+	ProcessID     string          `json:"process_id"`
+	Message       string          `json:"message"`
+	Level         string          `json:"level"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Detail        json.RawMessage `json:"detail"`
+}
+
 func ListExecutionIDs() ([]string, error) {
 	db, err := OpenFlowpipeDB()
 	if err != nil {
@@ -13,7 +27,7 @@ func ListExecutionIDs() ([]string, error) {
 	}
 	defer db.Close()
 
-	rows, err := db.Query("select distinct process_id from event order by id desc")
+	rows, err := db.Query("select process_id from event group by process_id order by max(id) desc")
 	if err != nil {
 		slog.Error("error querying process", "error", err)
 		return nil, perr.InternalWithMessage("error querying process")
@@ -33,3 +47,102 @@ func ListExecutionIDs() ([]string, error) {
 
 	return executionIDs, nil
 }
+
+// ListEventRecords returns the events of a process, oldest first
+func ListEventRecords(executionID string) ([]EventRecord, error) {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return listEventRecords(db, executionID)
+}
+
+func listEventRecords(db *DB, executionID string) ([]EventRecord, error) {
+	rows, err := db.Query(`select id, struct_version, process_id, message, level, created_at, detail from event where process_id = ? order by created_at asc`, executionID)
+	if err != nil {
+		slog.Error("error querying event table", "error", err)
+		return nil, perr.InternalWithMessage("error querying event table")
+	}
+	defer rows.Close()
+
+	var events []EventRecord
+	for rows.Next() {
+		var evt EventRecord
+		var createdAt any
+		var detail string
+
+		err = rows.Scan(&evt.ID, &evt.StructVersion, &evt.ProcessID, &evt.Message, &evt.Level, &createdAt, &detail)
+		if err != nil {
+			slog.Error("error scanning event table", "error", err)
+			return nil, perr.InternalWithMessage("error scanning event table")
+		}
+
+		evt.CreatedAt = timestampValue(createdAt)
+		evt.Detail = json.RawMessage(detail)
+		if detail == "" {
+			evt.Detail = json.RawMessage("null")
+		}
+
+		events = append(events, evt)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("error querying event table", "error", err)
+		return nil, perr.InternalWithMessage("error querying event table")
+	}
+
+	return events, nil
+}
+
+// ImportProcess adds a process exported from another store, its pipeline run and its events. The pipeline run is
+// recorded as updated now so the retention of the imported process starts from the import.
+func ImportProcess(run PipelineRun, events []EventRecord) error {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		slog.Error("error starting transaction", "error", err)
+		return perr.InternalWithMessage("error starting transaction")
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.Exec("insert into pipeline_run(execution_id, pipeline, trigger_name, state, started_at, updated_at) values(?, ?, ?, ?, ?, ?)",
+		run.ExecutionID, run.Pipeline, run.Trigger, run.State, run.StartedAt.UTC().Format(putils.RFC3339WithMS), time.Now().UTC().Format(putils.RFC3339WithMS))
+	if err != nil {
+		if db.Backend().IsUniqueViolation(err) {
+			return perr.ConflictWithMessage("process '" + run.ExecutionID + "' already exists")
+		}
+
+		slog.Error("error inserting pipeline run", "error", err)
+		return perr.InternalWithMessage("error inserting pipeline run")
+	}
+
+	for _, evt := range events {
+		_, err = tx.Exec(`insert into event (id, struct_version, process_id, created_at, message, level, detail) values (?, ?, ?, ?, ?, ?, ?)`,
+			evt.ID, evt.StructVersion, run.ExecutionID, evt.CreatedAt, evt.Message, evt.Level, string(evt.Detail))
+		if err != nil {
+			if db.Backend().IsUniqueViolation(err) {
+				return perr.ConflictWithMessage("event '" + evt.ID + "' already exists")
+			}
+
+			slog.Error("error inserting event", "error", err)
+			return perr.InternalWithMessage("error inserting event")
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		slog.Error("error committing transaction", "error", err)
+		return perr.InternalWithMessage("error committing transaction")
+	}
+
+	return nil
+}
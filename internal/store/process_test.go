@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/perr"
 )
 
 func copyNewFlowpipeDbCleanFile(cleanSource string) error {
@@ -52,3 +53,69 @@ func TestLoadExecutionFromDB(t *testing.T) {
 	assert.Equal("exec_cqlecrk204vm4kl8io10", excutionIDs[1])
 	assert.Equal("exec_cqled8k204vm0pm8h5dg", excutionIDs[0])
 }
+
+func TestImportProcess(t *testing.T) {
+	assert := assert.New(t)
+
+	err := copyNewFlowpipeDbCleanFile("./clean_test_files/flowpipe_clean_2.db")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	executionID := "exec_cqlg0mc204vtcsgqlut0"
+
+	events, err := ListEventRecords(executionID)
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal(16, len(events))
+	assert.True(events[0].CreatedAt.Before(events[15].CreatedAt), "events should be ordered by creation time")
+
+	run, err := GetPipelineRun(executionID)
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal("finished", run.State)
+
+	_, err = GetPipelineRun("exec_does_not_exist")
+	assert.True(perr.IsNotFound(err))
+
+	// the process is already in the store
+	err = ImportProcess(*run, events)
+	assert.True(perr.IsConflict(err))
+
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	err = pruneRun(db, PrunedRun{PipelineRun: *run})
+	db.Close()
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	err = ImportProcess(*run, events)
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	imported, err := ListEventRecords(executionID)
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal(len(events), len(imported))
+	for i := range events {
+		assert.Equal(events[i].ID, imported[i].ID)
+		assert.Equal(events[i].Message, imported[i].Message)
+		assert.True(events[i].CreatedAt.Equal(imported[i].CreatedAt))
+		assert.JSONEq(string(events[i].Detail), string(imported[i].Detail))
+	}
+
+	importedRun, err := GetPipelineRun(executionID)
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal(run.Pipeline, importedRun.Pipeline)
+	assert.Equal(run.State, importedRun.State)
+	assert.True(run.StartedAt.Equal(importedRun.StartedAt))
+}
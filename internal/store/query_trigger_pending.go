@@ -0,0 +1,151 @@
+package store
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/perr"
+	putils "github.com/turbot/pipe-fittings/utils"
+)
+
+// QueryTriggerPendingRow is a row captured by a batched query trigger capture that has not been delivered to a
+// pipeline yet. Row is the JSON encoded row, or primary key for the delete capture.
+type QueryTriggerPendingRow struct {
+	PrimaryKey string
+	Row        string
+}
+
+// queryTriggerPendingRowTableSQL creates the table of the rows captured by the query triggers and not yet run, created by the store migrations
+const queryTriggerPendingRowTableSQL = `
+	create table if not exists query_trigger_pending_row (
+		trigger_name text not null,
+		capture_type text not null,
+		primary_key text not null,
+		row_data text not null,
+		created_at text not null,
+		primary key (trigger_name, capture_type, primary_key)
+	)
+	`
+
+// AddQueryTriggerPendingRows queues the captured rows for delivery. A row already pending keeps its place in the queue
+// and is delivered with its latest data.
+func AddQueryTriggerPendingRows(triggerName, captureType string, rows []QueryTriggerPendingRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return perr.InternalWithMessage("error starting transaction " + err.Error())
+	}
+
+	upsertSQL := `insert into query_trigger_pending_row (trigger_name, capture_type, primary_key, row_data, created_at) values (?, ?, ?, ?, ?)
+		on conflict (trigger_name, capture_type, primary_key) do update set row_data = excluded.row_data`
+
+	stmt, err := tx.Prepare(upsertSQL)
+	if err != nil {
+		_ = tx.Rollback()
+		return perr.InternalWithMessage("error preparing pending row insert " + err.Error())
+	}
+	defer stmt.Close()
+
+	createdAt := time.Now().UTC().Format(putils.RFC3339WithMS)
+	for _, row := range rows {
+		_, err = stmt.Exec(triggerName, captureType, row.PrimaryKey, row.Row, createdAt)
+		if err != nil {
+			_ = tx.Rollback()
+			slog.Error("error adding query trigger pending row", "trigger", triggerName, "capture", captureType, "error", err)
+			return perr.InternalWithMessage("error adding query trigger pending row " + err.Error())
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return perr.InternalWithMessage("error committing pending rows " + err.Error())
+	}
+
+	return nil
+}
+
+// ListQueryTriggerPendingRows returns the oldest pending rows of the capture, all of them if limit is 0, and the total
+// number of pending rows of the capture
+func ListQueryTriggerPendingRows(triggerName, captureType string, limit int) ([]QueryTriggerPendingRow, int, error) {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer db.Close()
+
+	var total int
+	err = db.QueryRow(`select count(*) from query_trigger_pending_row where trigger_name = ? and capture_type = ?`, triggerName, captureType).Scan(&total)
+	if err != nil {
+		return nil, 0, perr.InternalWithMessage("error counting query trigger pending rows " + err.Error())
+	}
+
+	listSQL := `select primary_key, row_data from query_trigger_pending_row where trigger_name = ? and capture_type = ?
+		order by created_at, primary_key`
+	args := []any{triggerName, captureType}
+	if limit > 0 {
+		listSQL += ` limit ?`
+		args = append(args, limit)
+	}
+
+	rows, err := db.Query(listSQL, args...)
+	if err != nil {
+		return nil, 0, perr.InternalWithMessage("error listing query trigger pending rows " + err.Error())
+	}
+	defer rows.Close()
+
+	var pending []QueryTriggerPendingRow
+	for rows.Next() {
+		var row QueryTriggerPendingRow
+		err := rows.Scan(&row.PrimaryKey, &row.Row)
+		if err != nil {
+			return nil, 0, perr.InternalWithMessage("error reading query trigger pending row " + err.Error())
+		}
+		pending = append(pending, row)
+	}
+
+	return pending, total, rows.Err()
+}
+
+// DeleteQueryTriggerPendingRows removes the delivered rows from the pending rows of the capture
+func DeleteQueryTriggerPendingRows(triggerName, captureType string, primaryKeys []string) error {
+	if len(primaryKeys) == 0 {
+		return nil
+	}
+
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// chunked to stay under the bind variable limit of the databases
+	const chunkSize = 500
+	for start := 0; start < len(primaryKeys); start += chunkSize {
+		chunk := primaryKeys[start:min(start+chunkSize, len(primaryKeys))]
+
+		args := []any{triggerName, captureType}
+		for _, pk := range chunk {
+			args = append(args, pk)
+		}
+
+		//nolint:gosec // only placeholders are concatenated
+		deleteSQL := `delete from query_trigger_pending_row where trigger_name = ? and capture_type = ? and primary_key in (?` + strings.Repeat(", ?", len(chunk)-1) + `)`
+		_, err = db.Exec(deleteSQL, args...)
+		if err != nil {
+			slog.Error("error deleting query trigger pending rows", "trigger", triggerName, "capture", captureType, "error", err)
+			return perr.InternalWithMessage("error deleting query trigger pending rows " + err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/turbot/pipe-fittings/perr"
+	putils "github.com/turbot/pipe-fittings/utils"
+)
+
+// queryTriggerWatermarkTableSQL creates the table of the watermarks of the query triggers, created by the store migrations
+const queryTriggerWatermarkTableSQL = `
+	create table if not exists query_trigger_watermark (
+		trigger_name text primary key,
+		watermark text not null,
+		updated_at text
+	)
+	`
+
+// GetQueryTriggerWatermark returns the encoded watermark of the query trigger, found is false if the trigger has not
+// recorded a watermark yet
+func GetQueryTriggerWatermark(triggerName string) (watermark string, found bool, err error) {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return "", false, err
+	}
+	defer db.Close()
+
+	err = db.QueryRow(`select watermark from query_trigger_watermark where trigger_name = ?`, triggerName).Scan(&watermark)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		slog.Error("error reading query trigger watermark", "trigger", triggerName, "error", err)
+		return "", false, perr.InternalWithMessage("error reading query trigger watermark " + err.Error())
+	}
+
+	return watermark, true, nil
+}
+
+// SaveQueryTriggerWatermark records the encoded watermark of the query trigger
+func SaveQueryTriggerWatermark(triggerName, watermark string) error {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	upsertSQL := `insert into query_trigger_watermark (trigger_name, watermark, updated_at) values (?, ?, ?)
+		on conflict (trigger_name) do update set watermark = excluded.watermark, updated_at = excluded.updated_at`
+
+	_, err = db.Exec(upsertSQL, triggerName, watermark, time.Now().UTC().Format(putils.RFC3339WithMS))
+	if err != nil {
+		slog.Error("error saving query trigger watermark", "trigger", triggerName, "error", err)
+		return perr.InternalWithMessage("error saving query trigger watermark " + err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,253 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/turbot/flowpipe/internal/constants"
+	"github.com/turbot/flowpipe/internal/filepaths"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+// resolvedRetention is the retention of a run, the policies merged and the defaults applied
+type resolvedRetention struct {
+	// the runs of a group share the keep last count
+	group string
+
+	keepLast     int
+	maxAge       time.Duration
+	failedMaxAge time.Duration
+	orphanMaxAge time.Duration
+	archive      bool
+}
+
+func resolveRetention(p resources.RetentionPolicies, pipeline, trigger string, defaultMaxAge time.Duration) resolvedRetention {
+	policy := p.Default
+	group := "pipeline/" + pipeline
+
+	for i, rule := range p.Rules {
+		if !matchRetentionPattern(rule.Pipeline, pipeline) || !matchRetentionPattern(rule.Trigger, trigger) {
+			continue
+		}
+
+		policy = inheritRetention(rule, p.Default)
+
+		// a rule on triggers keeps the last runs of each trigger rather than of each pipeline
+		if rule.Trigger != "" {
+			group = fmt.Sprintf("%d/trigger/%s", i, trigger)
+		} else {
+			group = fmt.Sprintf("%d/pipeline/%s", i, pipeline)
+		}
+		break
+	}
+
+	r := resolvedRetention{
+		group:  group,
+		maxAge: defaultMaxAge,
+	}
+
+	if policy.KeepLast != nil {
+		r.keepLast = *policy.KeepLast
+	}
+	if policy.MaxAge != nil {
+		r.maxAge = *policy.MaxAge
+	}
+
+	r.failedMaxAge = r.maxAge
+	if policy.FailedMaxAge != nil {
+		r.failedMaxAge = *policy.FailedMaxAge
+	}
+
+	r.orphanMaxAge = r.maxAge
+	if policy.OrphanMaxAge != nil {
+		r.orphanMaxAge = *policy.OrphanMaxAge
+	}
+
+	if policy.Archive != nil {
+		r.archive = *policy.Archive
+	}
+
+	return r
+}
+
+func inheritRetention(p, defaults resources.RetentionPolicy) resources.RetentionPolicy {
+	if p.KeepLast == nil {
+		p.KeepLast = defaults.KeepLast
+	}
+	if p.MaxAge == nil {
+		p.MaxAge = defaults.MaxAge
+	}
+	if p.FailedMaxAge == nil {
+		p.FailedMaxAge = defaults.FailedMaxAge
+	}
+	if p.OrphanMaxAge == nil {
+		p.OrphanMaxAge = defaults.OrphanMaxAge
+	}
+	if p.Archive == nil {
+		p.Archive = defaults.Archive
+	}
+	return p
+}
+
+func matchRetentionPattern(pattern, name string) bool {
+	if pattern == "" || pattern == name {
+		return true
+	}
+
+	match, err := filepath.Match(pattern, name)
+	if err != nil {
+		slog.Warn("invalid retention pattern", "pattern", pattern, "error", err)
+		return false
+	}
+	return match
+}
+
+// PrunedRun is a run deleted, or that would be deleted in a dry run, by the retention policies
+type PrunedRun struct {
+	PipelineRun
+	Reason   string
+	Archived bool
+}
+
+// planPrune lists the runs to delete, the runs are visited newest first so the keep last counts keep the most recent
+// runs
+func planPrune(currentTime time.Time, policies resources.RetentionPolicies, defaultMaxAge time.Duration) ([]PrunedRun, error) {
+	runs, _, err := ListPipelineRuns(PipelineRunFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	kept := map[string]int{}
+	var pruned []PrunedRun
+	for _, run := range runs {
+		retention := resolveRetention(policies, run.Pipeline, run.Trigger, defaultMaxAge)
+
+		var maxAge time.Duration
+		var reason string
+		switch run.State {
+		case constants.StateFinished, constants.StateCanceled, constants.StateFailed:
+			kept[retention.group]++
+			if kept[retention.group] <= retention.keepLast {
+				continue
+			}
+
+			maxAge = retention.maxAge
+			if run.State == constants.StateFailed {
+				maxAge = retention.failedMaxAge
+			}
+			reason = fmt.Sprintf("%s for more than %s", run.State, maxAge)
+		default:
+			// the run never reached a terminal state, its process was lost (i.e. the server was stopped)
+			maxAge = retention.orphanMaxAge
+			reason = fmt.Sprintf("orphaned, %s with no update for more than %s", run.State, maxAge)
+		}
+
+		if maxAge < 0 || !run.UpdatedAt.Before(currentTime.Add(-maxAge)) {
+			continue
+		}
+
+		pruned = append(pruned, PrunedRun{
+			PipelineRun: run,
+			Reason:      reason,
+			Archived:    retention.archive,
+		})
+	}
+
+	return pruned, nil
+}
+
+// pruneRun deletes a run and its events, archiving the events first if required
+func pruneRun(db *DB, run PrunedRun) error {
+	if run.Archived {
+		err := archiveProcessEvents(db, run.ExecutionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		slog.Error("error starting transaction", "error", err)
+		return perr.InternalWithMessage("error starting transaction")
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.Exec(`delete from event where process_id = ?`, run.ExecutionID)
+	if err != nil {
+		slog.Error("error deleting process events", "execution_id", run.ExecutionID, "error", err)
+		return perr.InternalWithMessage("error deleting process events")
+	}
+
+	_, err = tx.Exec(`delete from pipeline_run where execution_id = ?`, run.ExecutionID)
+	if err != nil {
+		slog.Error("error deleting pipeline run", "execution_id", run.ExecutionID, "error", err)
+		return perr.InternalWithMessage("error deleting pipeline run")
+	}
+
+	_, err = tx.Exec(`delete from trigger_run where execution_id = ?`, run.ExecutionID)
+	if err != nil {
+		slog.Error("error deleting trigger run", "execution_id", run.ExecutionID, "error", err)
+		return perr.InternalWithMessage("error deleting trigger run")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		slog.Error("error committing transaction", "error", err)
+		return perr.InternalWithMessage("error committing transaction")
+	}
+
+	// the response files of the http steps and the jsonl event log go with the run
+	err = os.RemoveAll(filepaths.HttpResponseDir(run.ExecutionID))
+	if err != nil {
+		slog.Warn("error deleting http response files", "execution_id", run.ExecutionID, "error", err)
+	}
+
+	err = os.Remove(filepaths.EventStoreFilePath(run.ExecutionID))
+	if err != nil && !os.IsNotExist(err) {
+		slog.Warn("error deleting jsonl event log", "execution_id", run.ExecutionID, "error", err)
+	}
+
+	return nil
+}
+
+// archiveProcessEvents writes the events of a process to <archive dir>/<execution id>.jsonl, one event per line
+func archiveProcessEvents(db *DB, executionID string) error {
+	archiveDir := filepaths.ProcessArchiveDir()
+	err := os.MkdirAll(archiveDir, 0755)
+	if err != nil {
+		slog.Error("error creating archive directory", "dir", archiveDir, "error", err)
+		return perr.InternalWithMessage("error creating archive directory")
+	}
+
+	events, err := listEventRecords(db, executionID)
+	if err != nil {
+		return err
+	}
+
+	archivePath := filepath.Join(archiveDir, executionID+".jsonl")
+	//nolint:gosec // the archive is not more sensitive than the process store
+	file, err := os.Create(archivePath)
+	if err != nil {
+		slog.Error("error creating archive file", "path", archivePath, "error", err)
+		return perr.InternalWithMessage("error creating archive file")
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, evt := range events {
+		err = encoder.Encode(evt)
+		if err != nil {
+			slog.Error("error writing archive file", "path", archivePath, "error", err)
+			return perr.InternalWithMessage("error writing archive file")
+		}
+	}
+
+	return file.Sync()
+}
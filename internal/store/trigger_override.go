@@ -0,0 +1,151 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/turbot/pipe-fittings/perr"
+	putils "github.com/turbot/pipe-fittings/utils"
+)
+
+// TriggerOverride is the runtime state of a trigger set by the trigger enable, disable and pause commands, overriding
+// the enabled attribute of the trigger definition. The override ends at Until, if set.
+type TriggerOverride struct {
+	TriggerName string
+	State       string
+	Until       *time.Time
+	UpdatedAt   time.Time
+}
+
+// IsActive returns false once the override has ended
+func (o *TriggerOverride) IsActive(now time.Time) bool {
+	return o != nil && (o.Until == nil || now.Before(*o.Until))
+}
+
+// triggerOverrideTableSQL creates the table of the runtime overrides of the triggers, created by the store migrations
+const triggerOverrideTableSQL = `
+	create table if not exists trigger_override (
+		trigger_name text primary key,
+		state text not null,
+		until_time text,
+		updated_at text not null
+	)
+	`
+
+// SetTriggerOverride records the runtime state of the trigger, replacing its previous override
+func SetTriggerOverride(triggerName, state string, until *time.Time) error {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var untilTime any
+	if until != nil {
+		untilTime = until.UTC().Format(putils.RFC3339WithMS)
+	}
+
+	upsertSQL := `insert into trigger_override (trigger_name, state, until_time, updated_at) values (?, ?, ?, ?)
+		on conflict (trigger_name) do update set state = excluded.state, until_time = excluded.until_time, updated_at = excluded.updated_at`
+
+	_, err = db.Exec(upsertSQL, triggerName, state, untilTime, time.Now().UTC().Format(putils.RFC3339WithMS))
+	if err != nil {
+		slog.Error("error saving trigger override", "trigger", triggerName, "error", err)
+		return perr.InternalWithMessage("error saving trigger override " + err.Error())
+	}
+
+	return nil
+}
+
+// DeleteTriggerOverride removes the runtime state of the trigger, the trigger definition applies again
+func DeleteTriggerOverride(triggerName string) error {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`delete from trigger_override where trigger_name = ?`, triggerName)
+	if err != nil {
+		slog.Error("error deleting trigger override", "trigger", triggerName, "error", err)
+		return perr.InternalWithMessage("error deleting trigger override " + err.Error())
+	}
+
+	return nil
+}
+
+// GetTriggerOverride returns the active override of the trigger, nil if the trigger has none
+func GetTriggerOverride(triggerName string) (*TriggerOverride, error) {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	row := db.QueryRow(`select trigger_name, state, until_time, updated_at from trigger_override where trigger_name = ?`, triggerName)
+	override, err := scanTriggerOverride(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		slog.Error("error reading trigger override", "trigger", triggerName, "error", err)
+		return nil, perr.InternalWithMessage("error reading trigger override " + err.Error())
+	}
+
+	if !override.IsActive(time.Now()) {
+		return nil, nil
+	}
+	return override, nil
+}
+
+// ListTriggerOverrides returns the active overrides, by trigger name
+func ListTriggerOverrides() (map[string]*TriggerOverride, error) {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`select trigger_name, state, until_time, updated_at from trigger_override`)
+	if err != nil {
+		slog.Error("error listing trigger overrides", "error", err)
+		return nil, perr.InternalWithMessage("error listing trigger overrides " + err.Error())
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	overrides := map[string]*TriggerOverride{}
+	for rows.Next() {
+		override, err := scanTriggerOverride(rows)
+		if err != nil {
+			return nil, perr.InternalWithMessage("error reading trigger override " + err.Error())
+		}
+
+		if override.IsActive(now) {
+			overrides[override.TriggerName] = override
+		}
+	}
+
+	return overrides, rows.Err()
+}
+
+func scanTriggerOverride(row interface{ Scan(...any) error }) (*TriggerOverride, error) {
+	var override TriggerOverride
+	var until sql.NullString
+	var updatedAt string
+
+	err := row.Scan(&override.TriggerName, &override.State, &until, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if until.Valid {
+		untilTime := timestampValue(until.String)
+		override.Until = &untilTime
+	}
+	override.UpdatedAt = timestampValue(updatedAt)
+
+	return &override, nil
+}
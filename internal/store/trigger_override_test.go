@@ -0,0 +1,67 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTriggerOverrides(t *testing.T) {
+
+	assert := assert.New(t)
+
+	err := copyNewFlowpipeDbCleanFile("./clean_test_files/flowpipe_clean_2.db")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	override, err := GetTriggerOverride("local.trigger.schedule.nightly")
+	assert.Nil(err)
+	assert.Nil(override, "no override until a command is run")
+
+	until := time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond)
+	assert.Nil(SetTriggerOverride("local.trigger.schedule.nightly", "paused", &until))
+	assert.Nil(SetTriggerOverride("local.trigger.http.hook", "disabled", nil))
+
+	// ended a minute ago
+	ended := time.Now().Add(-time.Minute)
+	assert.Nil(SetTriggerOverride("local.trigger.query.changes", "disabled", &ended))
+
+	override, err = GetTriggerOverride("local.trigger.schedule.nightly")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	if assert.NotNil(override) {
+		assert.Equal("paused", override.State)
+		if assert.NotNil(override.Until) {
+			assert.True(until.Equal(*override.Until))
+		}
+	}
+
+	override, err = GetTriggerOverride("local.trigger.query.changes")
+	assert.Nil(err)
+	assert.Nil(override, "an ended override is not active")
+
+	overrides, err := ListTriggerOverrides()
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal(2, len(overrides))
+	assert.Equal("disabled", overrides["local.trigger.http.hook"].State)
+	assert.Nil(overrides["local.trigger.http.hook"].Until)
+
+	// a new command replaces the override
+	assert.Nil(SetTriggerOverride("local.trigger.schedule.nightly", "enabled", nil))
+	override, err = GetTriggerOverride("local.trigger.schedule.nightly")
+	assert.Nil(err)
+	if assert.NotNil(override) {
+		assert.Equal("enabled", override.State)
+		assert.Nil(override.Until)
+	}
+
+	assert.Nil(DeleteTriggerOverride("local.trigger.schedule.nightly"))
+	override, err = GetTriggerOverride("local.trigger.schedule.nightly")
+	assert.Nil(err)
+	assert.Nil(override)
+}
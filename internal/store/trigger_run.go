@@ -0,0 +1,172 @@
+package store
+
+import (
+	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/perr"
+	putils "github.com/turbot/pipe-fittings/utils"
+)
+
+// TriggerRun is a firing of a trigger. State is the state of the execution it started, empty if the execution has
+// been pruned. The capture counts are only set for query triggers.
+type TriggerRun struct {
+	ExecutionID string
+	Trigger     string
+	Source      string
+	FiredAt     time.Time
+	State       string
+
+	InsertedCount *int
+	UpdatedCount  *int
+	DeletedCount  *int
+}
+
+// triggerRunTableSQL creates the table of the run history of the triggers, created by the store migrations
+const triggerRunTableSQL = `
+	create table if not exists trigger_run (
+		execution_id text primary key,
+		trigger_name text not null,
+		source text not null,
+		fired_at text not null,
+		inserted_count integer,
+		updated_count integer,
+		deleted_count integer
+	);
+
+	create index if not exists idx_trigger_run_trigger_name_fired_at on trigger_run (trigger_name, fired_at)
+	`
+
+// StartTriggerRun records the firing of a trigger and the execution it started. Like pipeline_run, nothing is
+// recorded when the processes are not retained.
+func StartTriggerRun(executionID, triggerName, source string) error {
+	retentionInSecond := viper.GetInt(constants.ArgProcessRetention)
+	if retentionInSecond == 0 {
+		return nil
+	}
+
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`insert into trigger_run (execution_id, trigger_name, source, fired_at) values (?, ?, ?, ?)`,
+		executionID, triggerName, source, time.Now().UTC().Format(putils.RFC3339WithMS))
+	if err != nil {
+		if db.Backend().IsUniqueViolation(err) {
+			return perr.BadRequestWithMessage("trigger run '" + executionID + "' already exists")
+		}
+
+		slog.Error("error recording trigger run", "trigger", triggerName, "execution_id", executionID, "error", err)
+		return perr.InternalWithMessage("error recording trigger run " + err.Error())
+	}
+
+	return nil
+}
+
+// SetTriggerRunCaptureCounts records the number of rows inserted, updated and deleted seen by a query trigger run
+func SetTriggerRunCaptureCounts(executionID string, inserted, updated, deleted int) error {
+	retentionInSecond := viper.GetInt(constants.ArgProcessRetention)
+	if retentionInSecond == 0 {
+		return nil
+	}
+
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`update trigger_run set inserted_count = ?, updated_count = ?, deleted_count = ? where execution_id = ?`,
+		inserted, updated, deleted, executionID)
+	if err != nil {
+		slog.Error("error recording trigger run capture counts", "execution_id", executionID, "error", err)
+		return perr.InternalWithMessage("error recording trigger run capture counts " + err.Error())
+	}
+
+	return nil
+}
+
+// ListTriggerRuns returns a page of the runs of the trigger, newest first, and the cursor of the next page (empty on
+// the last page)
+func ListTriggerRuns(triggerName, cursor string, limit int) ([]TriggerRun, string, error) {
+	query := `select r.execution_id, r.trigger_name, r.source, r.fired_at, p.state, r.inserted_count, r.updated_count, r.deleted_count
+		from trigger_run r left join pipeline_run p on p.execution_id = r.execution_id
+		where r.trigger_name = ?`
+	args := []any{triggerName}
+
+	if cursor != "" {
+		cursorFiredAt, cursorExecutionID, found := strings.Cut(cursor, "|")
+		if !found {
+			return nil, "", perr.BadRequestWithMessage("invalid cursor")
+		}
+		query += ` and (r.fired_at < ? or (r.fired_at = ? and r.execution_id < ?))`
+		args = append(args, cursorFiredAt, cursorFiredAt, cursorExecutionID)
+	}
+
+	query += ` order by r.fired_at desc, r.execution_id desc`
+
+	if limit > 0 {
+		// fetch one more to know if there is a next page
+		query += ` limit ?`
+		args = append(args, limit+1)
+	}
+
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return nil, "", err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		slog.Error("error querying trigger_run", "error", err)
+		return nil, "", perr.InternalWithMessage("error querying trigger_run")
+	}
+	defer rows.Close()
+
+	var runs []TriggerRun
+	var firedAts []string
+	for rows.Next() {
+		var run TriggerRun
+		var firedAt string
+		var state sql.NullString
+		var inserted, updated, deleted sql.NullInt64
+
+		err = rows.Scan(&run.ExecutionID, &run.Trigger, &run.Source, &firedAt, &state, &inserted, &updated, &deleted)
+		if err != nil {
+			slog.Error("error scanning trigger_run", "error", err)
+			return nil, "", perr.InternalWithMessage("error scanning trigger_run")
+		}
+
+		run.FiredAt = timestampValue(firedAt)
+		run.State = state.String
+		run.InsertedCount = nullIntPtr(inserted)
+		run.UpdatedCount = nullIntPtr(updated)
+		run.DeletedCount = nullIntPtr(deleted)
+
+		runs = append(runs, run)
+		firedAts = append(firedAts, firedAt)
+	}
+
+	nextCursor := ""
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+		nextCursor = firedAts[limit-1] + "|" + runs[limit-1].ExecutionID
+	}
+
+	return runs, nextCursor, nil
+}
+
+func nullIntPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	i := int(v.Int64)
+	return &i
+}
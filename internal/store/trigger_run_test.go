@@ -0,0 +1,85 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+func TestTriggerRuns(t *testing.T) {
+
+	assert := assert.New(t)
+
+	err := copyNewFlowpipeDbCleanFile("./clean_test_files/flowpipe_clean_2.db")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	viper.Set(constants.ArgProcessRetention, 3600)
+	defer viper.Set(constants.ArgProcessRetention, nil)
+
+	triggerName := "local.trigger.query.changes"
+
+	assert.Nil(StartTriggerRun("exec_cmu410272ijuoi3q9gd0", triggerName, "schedule"))
+	assert.Nil(StartTriggerRun("exec_cmu5cli72ijjh42rbl1g", triggerName, "manual"))
+	// the execution of this run has been pruned
+	assert.Nil(StartTriggerRun("exec_pruned", triggerName, "schedule"))
+	assert.Nil(StartTriggerRun("exec_other", "local.trigger.schedule.other", "schedule"))
+
+	err = StartTriggerRun("exec_pruned", triggerName, "schedule")
+	assert.True(perr.IsBadRequest(err), "a run is recorded once per execution")
+
+	assert.Nil(SetTriggerRunCaptureCounts("exec_cmu410272ijuoi3q9gd0", 3, 2, 1))
+
+	runs, cursor, err := ListTriggerRuns(triggerName, "", 0)
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal("", cursor)
+	assert.Equal(3, len(runs))
+
+	byExecution := map[string]TriggerRun{}
+	for _, run := range runs {
+		assert.Equal(triggerName, run.Trigger)
+		assert.False(run.FiredAt.IsZero())
+		byExecution[run.ExecutionID] = run
+	}
+
+	run := byExecution["exec_cmu410272ijuoi3q9gd0"]
+	assert.Equal("schedule", run.Source)
+	assert.Equal("finished", run.State)
+	if assert.NotNil(run.InsertedCount) && assert.NotNil(run.UpdatedCount) && assert.NotNil(run.DeletedCount) {
+		assert.Equal(3, *run.InsertedCount)
+		assert.Equal(2, *run.UpdatedCount)
+		assert.Equal(1, *run.DeletedCount)
+	}
+
+	run = byExecution["exec_cmu5cli72ijjh42rbl1g"]
+	assert.Equal("manual", run.Source)
+	assert.NotEqual("", run.State)
+	assert.Nil(run.InsertedCount)
+
+	assert.Equal("", byExecution["exec_pruned"].State)
+
+	// paging
+	firstPage, cursor, err := ListTriggerRuns(triggerName, "", 2)
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal(2, len(firstPage))
+	assert.NotEqual("", cursor)
+
+	secondPage, cursor, err := ListTriggerRuns(triggerName, cursor, 2)
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal(1, len(secondPage))
+	assert.Equal("", cursor)
+	assert.Equal(runs[2].ExecutionID, secondPage[0].ExecutionID)
+
+	_, _, err = ListTriggerRuns(triggerName, "invalid", 2)
+	assert.True(perr.IsBadRequest(err))
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/turbot/pipe-fittings/perr"
+	putils "github.com/turbot/pipe-fittings/utils"
+)
+
+// triggerScheduleTableSQL creates the table of the last time the scheduled triggers fired, created by the store migrations
+const triggerScheduleTableSQL = `
+	create table if not exists trigger_schedule (
+		trigger_name text primary key,
+		last_fired_at text not null
+	)
+	`
+
+// SetTriggerLastFired records the time the scheduled trigger last fired. Unlike the trigger runs, it's always recorded:
+// the occurrences missed while the scheduler is down are found from it.
+func SetTriggerLastFired(triggerName string, firedAt time.Time) error {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	upsertSQL := `insert into trigger_schedule (trigger_name, last_fired_at) values (?, ?)
+		on conflict (trigger_name) do update set last_fired_at = excluded.last_fired_at`
+
+	_, err = db.Exec(upsertSQL, triggerName, firedAt.UTC().Format(putils.RFC3339WithMS))
+	if err != nil {
+		slog.Error("error saving trigger last fired time", "trigger", triggerName, "error", err)
+		return perr.InternalWithMessage("error saving trigger last fired time " + err.Error())
+	}
+
+	return nil
+}
+
+// GetTriggerLastFired returns the time the scheduled trigger last fired, nil if it never fired
+func GetTriggerLastFired(triggerName string) (*time.Time, error) {
+	db, err := OpenFlowpipeDB()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var lastFiredAt string
+	err = db.QueryRow(`select last_fired_at from trigger_schedule where trigger_name = ?`, triggerName).Scan(&lastFiredAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		slog.Error("error reading trigger last fired time", "trigger", triggerName, "error", err)
+		return nil, perr.InternalWithMessage("error reading trigger last fired time " + err.Error())
+	}
+
+	firedAt := timestampValue(lastFiredAt)
+	return &firedAt, nil
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTriggerLastFired(t *testing.T) {
+
+	assert := assert.New(t)
+
+	err := copyNewFlowpipeDbCleanFile("./clean_test_files/flowpipe_clean_2.db")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	lastFired, err := GetTriggerLastFired("local.trigger.schedule.nightly")
+	assert.Nil(err)
+	assert.Nil(lastFired, "the trigger never fired")
+
+	firedAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Millisecond)
+	assert.Nil(SetTriggerLastFired("local.trigger.schedule.nightly", firedAt))
+
+	lastFired, err = GetTriggerLastFired("local.trigger.schedule.nightly")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	if assert.NotNil(lastFired) {
+		assert.True(firedAt.Equal(*lastFired))
+	}
+
+	// the next firing replaces the time
+	firedAt = firedAt.Add(time.Hour)
+	assert.Nil(SetTriggerLastFired("local.trigger.schedule.nightly", firedAt))
+
+	lastFired, err = GetTriggerLastFired("local.trigger.schedule.nightly")
+	assert.Nil(err)
+	if assert.NotNil(lastFired) {
+		assert.True(firedAt.Equal(*lastFired))
+	}
+}
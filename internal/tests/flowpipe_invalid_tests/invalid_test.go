@@ -58,6 +58,21 @@ var tests = []testSetup{
 		file:          "./pipelines/invalid_schedule_trigger.fp",
 		containsError: `Unsupported argument: An argument named "execution_mode" is not expected here.`,
 	},
+	{
+		title:         "invalid schedule trigger misfire policy",
+		file:          "./pipelines/invalid_schedule_trigger_misfire_policy.fp",
+		containsError: "The misfire_policy attribute must be one of: skip, fire_once, fire_all",
+	},
+	{
+		title:         "invalid schedule trigger timezone",
+		file:          "./pipelines/invalid_schedule_trigger_timezone.fp",
+		containsError: "Invalid timezone: Mars/Olympus_Mons",
+	},
+	{
+		title:         "invalid schedule trigger calendar",
+		file:          "./pipelines/invalid_schedule_trigger_calendar.fp",
+		containsError: "line 2 is not a YYYY-MM-DD date: Christmas Day",
+	},
 	{
 		title:         "invalid step attribute (transform)",
 		file:          "./pipelines/invalid_step_attribute.fp",
@@ -163,6 +178,36 @@ var tests = []testSetup{
 		file:          "./pipelines/invalid_http_timeout.fp",
 		containsError: "Value of the attribute 'timeout' must be a string or a whole number",
 	},
+	{
+		title:         "invalid http step pagination type",
+		file:          "./pipelines/invalid_http_pagination.fp",
+		containsError: "Invalid pagination type: token. Specify one of link, cursor, offset, page",
+	},
+	{
+		title:         "invalid http step offset pagination without page_size",
+		file:          "./pipelines/invalid_http_pagination_offset.fp",
+		containsError: "The offset pagination must define the page_size attribute",
+	},
+	{
+		title:         "invalid http step oauth2 without client_secret",
+		file:          "./pipelines/invalid_http_oauth2.fp",
+		containsError: "The oauth2 block must define the client_secret attribute",
+	},
+	{
+		title:         "invalid http step with basic_auth and oauth2",
+		file:          "./pipelines/invalid_http_oauth2_basic_auth.fp",
+		containsError: "Step http should have either basic_auth or oauth2 but not both",
+	},
+	{
+		title:         "invalid http step with request_body and request_body_file",
+		file:          "./pipelines/invalid_http_request_body_file.fp",
+		containsError: "Step http should have only one of request_body, request_body_file or form_fields and form_files",
+	},
+	{
+		title:         "invalid http step with response_to_file and pagination",
+		file:          "./pipelines/invalid_http_response_to_file.fp",
+		containsError: "Step http can't write the response to a file with pagination",
+	},
 	{
 		title:         "invalid schedule in query trigger",
 		file:          "./pipelines/invalid_query_trigger.fp",
@@ -190,6 +235,56 @@ var tests = []testSetup{
 		file:          "./pipelines/invalid_http_trigger_duplicate_method.fp",
 		containsError: "Duplicate method block for type: post",
 	},
+	{
+		title:         "invalid http trigger auth type",
+		file:          "./pipelines/invalid_http_trigger_auth.fp",
+		containsError: "The auth type must be one of: hmac,bearer,ip_allow_list",
+	},
+	{
+		title:         "invalid http trigger allowed IP",
+		file:          "./pipelines/invalid_http_trigger_auth_ip.fp",
+		containsError: "Invalid IP address or CIDR range in allowed_ips",
+	},
+	{
+		title:         "invalid http trigger idempotency ttl",
+		file:          "./pipelines/invalid_http_trigger_idempotency_ttl.fp",
+		containsError: "The idempotency_ttl must be a positive duration, for example 24h",
+	},
+	{
+		title:         "invalid queue trigger source",
+		file:          "./pipelines/invalid_queue_trigger_source.fp",
+		containsError: "The queue source must be one of: directory,nats",
+	},
+	{
+		title:         "invalid queue trigger - nats without consumer",
+		file:          "./pipelines/invalid_queue_trigger_nats.fp",
+		containsError: "The nats source requires a url, a stream and a consumer",
+	},
+	{
+		title:         "invalid file trigger event",
+		file:          "./pipelines/invalid_file_trigger_event.fp",
+		containsError: "The file events must be one of: create,modify,delete",
+	},
+	{
+		title:         "invalid query trigger - delete capture with watermark",
+		file:          "./pipelines/invalid_query_trigger_watermark_delete.fp",
+		containsError: "The delete capture is not supported with watermark_column",
+	},
+	{
+		title:         "invalid query trigger capture batch size",
+		file:          "./pipelines/invalid_query_trigger_batch_size.fp",
+		containsError: "The batch_size attribute must be a number greater than 0",
+	},
+	{
+		title:         "invalid query step with sql and statements",
+		file:          "./pipelines/invalid_query_statements.fp",
+		containsError: "Query step should have either sql or statements but not both",
+	},
+	{
+		title:         "invalid query step with args and batch",
+		file:          "./pipelines/invalid_query_batch.fp",
+		containsError: "Query step should have either args or batch but not both",
+	},
 	{
 		title:         "invalid query trigger - missing required field sql",
 		file:          "./pipelines/query_trigger_missing_sql.fp",
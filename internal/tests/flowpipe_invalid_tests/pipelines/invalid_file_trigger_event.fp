@@ -0,0 +1,12 @@
+pipeline "process_file" {
+  step "transform" "echo" {
+    value = "file"
+  }
+}
+
+trigger "file" "invalid_file_trigger_event" {
+  path   = "./files"
+  events = ["create", "rename"]
+
+  pipeline = pipeline.process_file
+}
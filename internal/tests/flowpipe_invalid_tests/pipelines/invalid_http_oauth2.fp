@@ -0,0 +1,10 @@
+pipeline "invalid_http_oauth2" {
+  step "http" "http_test" {
+    url = "https://somerandomsite.com"
+
+    oauth2 {
+      token_url = "https://somerandomsite.com/oauth2/token"
+      client_id = "my-client"
+    }
+  }
+}
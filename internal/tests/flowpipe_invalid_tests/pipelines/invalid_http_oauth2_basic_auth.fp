@@ -0,0 +1,16 @@
+pipeline "invalid_http_oauth2_basic_auth" {
+  step "http" "http_test" {
+    url = "https://somerandomsite.com"
+
+    basic_auth {
+      username = "user"
+      password = "pass"
+    }
+
+    oauth2 {
+      token_url     = "https://somerandomsite.com/oauth2/token"
+      client_id     = "my-client"
+      client_secret = "my-secret"
+    }
+  }
+}
@@ -0,0 +1,9 @@
+pipeline "invalid_http_pagination" {
+  step "http" "http_test" {
+    url = "https://somerandomsite.com"
+
+    pagination {
+      type = "token"
+    }
+  }
+}
@@ -0,0 +1,8 @@
+pipeline "invalid_http_request_body_file" {
+  step "http" "http_test" {
+    url               = "https://somerandomsite.com"
+    method            = "post"
+    request_body      = "body"
+    request_body_file = "body.json"
+  }
+}
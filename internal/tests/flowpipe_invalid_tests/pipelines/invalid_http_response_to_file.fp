@@ -0,0 +1,10 @@
+pipeline "invalid_http_response_to_file" {
+  step "http" "http_test" {
+    url              = "https://somerandomsite.com"
+    response_to_file = true
+
+    pagination {
+      type = "link"
+    }
+  }
+}
@@ -0,0 +1,13 @@
+pipeline "http_webhook_pipeline" {
+  step "transform" "simple_echo" {
+    value = "foo"
+  }
+}
+
+trigger "http" "invalid_http_trigger_auth" {
+  pipeline = pipeline.http_webhook_pipeline
+
+  auth {
+    type = "basic"
+  }
+}
@@ -0,0 +1,14 @@
+pipeline "http_webhook_pipeline" {
+  step "transform" "simple_echo" {
+    value = "foo"
+  }
+}
+
+trigger "http" "invalid_http_trigger_auth_ip" {
+  pipeline = pipeline.http_webhook_pipeline
+
+  auth {
+    type        = "ip_allow_list"
+    allowed_ips = ["10.0.0.300"]
+  }
+}
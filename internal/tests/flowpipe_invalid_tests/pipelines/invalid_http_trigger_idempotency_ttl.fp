@@ -0,0 +1,23 @@
+pipeline "http_webhook_pipeline" {
+  param "event" {
+    type = string
+  }
+  step "transform" "simple_echo" {
+    value = "event is: ${param.event}"
+  }
+}
+
+trigger "http" "invalid_http_trigger_idempotency_ttl" {
+
+  method "post" {
+    pipeline = pipeline.http_webhook_pipeline
+
+    args = {
+      event = "test"
+    }
+
+    idempotency_key = self.request_headers["X-Request-Id"]
+    idempotency_ttl = "one day"
+  }
+
+}
@@ -0,0 +1,11 @@
+pipeline "invalid_query_batch" {
+  step "query" "query_test" {
+    database = "sqlite:./accounts.db"
+    sql      = "insert into accounts (name, balance) values ($1, $2)"
+    args     = ["alice", 10]
+
+    batch = [
+      ["bob", 20]
+    ]
+  }
+}
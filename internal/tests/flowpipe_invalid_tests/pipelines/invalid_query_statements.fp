@@ -0,0 +1,10 @@
+pipeline "invalid_query_statements" {
+  step "query" "query_test" {
+    database = "sqlite:./accounts.db"
+    sql      = "select * from accounts"
+
+    statements = [
+      "update accounts set balance = 0"
+    ]
+  }
+}
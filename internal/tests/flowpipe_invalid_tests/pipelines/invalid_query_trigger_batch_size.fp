@@ -0,0 +1,23 @@
+pipeline "process_rows" {
+  param "rows" {
+    type = any
+  }
+
+  step "transform" "echo" {
+    value = param.rows
+  }
+}
+
+trigger "query" "invalid_query_trigger_batch_size" {
+  database    = "sqlite:./orders.db"
+  sql         = "select * from orders"
+  primary_key = "id"
+
+  capture "insert" {
+    pipeline   = pipeline.process_rows
+    batch_size = 0
+    args = {
+      rows = self.inserted_rows
+    }
+  }
+}
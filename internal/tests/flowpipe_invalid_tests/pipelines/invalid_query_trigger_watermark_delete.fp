@@ -0,0 +1,23 @@
+pipeline "process_rows" {
+  param "rows" {
+    type = any
+  }
+
+  step "transform" "echo" {
+    value = param.rows
+  }
+}
+
+trigger "query" "invalid_query_trigger_watermark_delete" {
+  database         = "sqlite:./orders.db"
+  sql              = "select * from orders where updated_at > coalesce(?, 0)"
+  primary_key      = "id"
+  watermark_column = "updated_at"
+
+  capture "delete" {
+    pipeline = pipeline.process_rows
+    args = {
+      rows = self.deleted_rows
+    }
+  }
+}
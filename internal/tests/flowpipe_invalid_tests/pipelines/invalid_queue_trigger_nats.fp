@@ -0,0 +1,13 @@
+pipeline "process_message" {
+  step "transform" "echo" {
+    value = "message"
+  }
+}
+
+trigger "queue" "invalid_queue_trigger_nats" {
+  source = "nats"
+  url    = "nats://localhost:4222"
+  stream = "ORDERS"
+
+  pipeline = pipeline.process_message
+}
@@ -0,0 +1,12 @@
+pipeline "process_message" {
+  step "transform" "echo" {
+    value = "message"
+  }
+}
+
+trigger "queue" "invalid_queue_trigger_source" {
+  source = "kafka"
+  path   = "./spool"
+
+  pipeline = pipeline.process_message
+}
@@ -0,0 +1,13 @@
+pipeline "simple_with_trigger" {
+  description = "simple pipeline that will be referred to by a trigger"
+
+  step "transform" "simple_echo" {
+    value = "foo bar"
+  }
+}
+
+trigger "schedule" "invalid_calendar" {
+  schedule = "0 9 * * 1-5"
+  calendar = "invalid_holidays.txt"
+  pipeline = pipeline.simple_with_trigger
+}
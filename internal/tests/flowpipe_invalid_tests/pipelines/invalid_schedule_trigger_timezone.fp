@@ -0,0 +1,13 @@
+pipeline "simple_with_trigger" {
+  description = "simple pipeline that will be referred to by a trigger"
+
+  step "transform" "simple_echo" {
+    value = "foo bar"
+  }
+}
+
+trigger "schedule" "invalid_timezone" {
+  schedule = "0 9 * * 1-5"
+  timezone = "Mars/Olympus_Mons"
+  pipeline = pipeline.simple_with_trigger
+}
@@ -1879,6 +1879,62 @@ func (suite *FlowpipeModTestSuite) TestModWithCredsNoEnvVarSet() {
 	assert.Equal("", stepInputs["value"])
 }
 
+func (suite *FlowpipeModTestSuite) TestModWithOAuth2Creds() {
+	assert := assert.New(suite.T())
+	require := require.New(suite.T())
+
+	credentials := map[string]credential.Credential{
+		"azure.default": &credential.AzureCredential{
+			CredentialImpl: credential.CredentialImpl{
+				HclResourceImpl: modconfig.HclResourceImpl{
+					FullName:        "azure.default",
+					ShortName:       "default",
+					UnqualifiedName: "azure.default",
+				},
+				Type: "azure",
+			},
+		},
+	}
+
+	w, errorAndWarning := workspace.Load(suite.ctx, "./mod_with_oauth2_creds", workspace.WithDecoderOptions(fparse.WithCredentials(credentials)))
+
+	require.NotNil(w)
+	require.Nil(errorAndWarning.Error)
+
+	pipelines := w.Mod.GetModResources().(*resources.FlowpipeModResources).Pipelines
+	pipeline := pipelines["mod_with_oauth2_creds.pipeline.with_oauth2_creds"]
+	if pipeline == nil {
+		assert.Fail("pipeline with_oauth2_creds not found")
+		return
+	}
+
+	// the credential is added to the eval context of the step when it runs
+	step := pipeline.Steps[0]
+	assert.Equal([]string{"azure.default"}, step.GetCredentialDependsOn())
+
+	evalContext := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"credential": cty.ObjectVal(map[string]cty.Value{
+				"azure": cty.ObjectVal(map[string]cty.Value{
+					"default": cty.ObjectVal(map[string]cty.Value{
+						"client_id":     cty.StringVal("my-client"),
+						"client_secret": cty.StringVal("my-secret"),
+					}),
+				}),
+			}),
+		},
+	}
+
+	stepInputs, err := step.GetInputs(evalContext)
+	require.Nil(err)
+
+	oauth2 := stepInputs[resources.BlockTypeOAuth2].(map[string]any)
+	assert.Equal("https://login.myapi.com/oauth2/token", oauth2[resources.AttributeTypeTokenUrl])
+	assert.Equal("my-client", oauth2[resources.AttributeTypeClientId])
+	assert.Equal("my-secret", oauth2[resources.AttributeTypeClientSecret])
+	assert.Equal([]string{"items.read"}, oauth2[resources.AttributeTypeScopes])
+}
+
 func (suite *FlowpipeModTestSuite) TestModWithConn() {
 	assert := assert.New(suite.T())
 	require := require.New(suite.T())
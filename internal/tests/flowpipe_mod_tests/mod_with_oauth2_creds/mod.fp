@@ -0,0 +1,16 @@
+mod "mod_with_oauth2_creds" {
+  title = "mod_with_oauth2_creds"
+}
+
+pipeline "with_oauth2_creds" {
+  step "http" "list_items" {
+    url = "https://myapi.com/vi/api/items"
+
+    oauth2 {
+      token_url     = "https://login.myapi.com/oauth2/token"
+      client_id     = credential.azure.default.client_id
+      client_secret = credential.azure.default.client_secret
+      scopes        = ["items.read"]
+    }
+  }
+}
@@ -0,0 +1,63 @@
+package pipeline_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/flowpipe/internal/parse"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/flowpipe/internal/trigger"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestFileTrigger(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+	_, triggers, err := parse.LoadPipelines(ctx, "./pipelines/file_trigger.fp")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	csvTrigger := triggers["local.trigger.file.csv_exports"]
+	if csvTrigger == nil {
+		assert.FailNow("csv_exports trigger not found")
+	}
+
+	assert.Equal(resources.TriggerTypeFile, resources.GetTriggerTypeFromTriggerConfig(csvTrigger.Config))
+
+	csvConfig := csvTrigger.Config.(*resources.TriggerFile)
+	assert.Equal("./exports", csvConfig.Path)
+	assert.Equal("*.csv", csvConfig.Pattern)
+	assert.Equal([]string{resources.FileEventCreate, resources.FileEventModify}, csvConfig.Events)
+	assert.Equal(5*time.Second, csvConfig.Debounce)
+	assert.False(csvConfig.HasEvent(resources.FileEventDelete))
+
+	anyTrigger := triggers["local.trigger.file.any_change"]
+	if anyTrigger == nil {
+		assert.FailNow("any_change trigger not found")
+	}
+
+	anyConfig := anyTrigger.Config.(*resources.TriggerFile)
+	assert.Equal("/mnt/share", anyConfig.Path)
+	assert.Equal(resources.DefaultFilePattern, anyConfig.Pattern)
+	assert.Equal([]string{resources.FileEventCreate, resources.FileEventModify, resources.FileEventDelete}, anyConfig.Events)
+	assert.Equal(resources.DefaultFileDebounce, anyConfig.Debounce)
+	assert.False(csvConfig.Equals(anyConfig))
+
+	evalContext := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"self": trigger.FileEventSelf(trigger.FileEvent{
+				Path:  "/mnt/share/report.csv",
+				Event: resources.FileEventDelete,
+			}),
+		},
+	}
+
+	args, diags := anyTrigger.GetArgs(evalContext)
+	assert.False(diags.HasErrors())
+	assert.Equal("delete: report.csv", args["path"])
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/turbot/flowpipe/internal/parse"
+	"github.com/turbot/flowpipe/internal/resources"
 	"github.com/turbot/pipe-fittings/schema"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -149,3 +150,144 @@ func TestHttpStepLoadTimeoutStringUnresolved(t *testing.T) {
 	assert.Equal("post", stepInputs[schema.AttributeTypeMethod], "wrong method")
 	assert.Equal("2s", stepInputs[schema.AttributeTypeTimeout], "wrong cert")
 }
+
+func TestHttpStepLoadPagination(t *testing.T) {
+	assert := assert.New(t)
+
+	pipelines, _, err := parse.LoadPipelines(context.TODO(), "./pipelines/http_step.fp")
+	assert.Nil(err, "error found")
+
+	pipelineHcl := pipelines["local.pipeline.http_step_pagination"]
+	if pipelineHcl == nil {
+		assert.Fail("http_step_pagination pipeline not found")
+		return
+	}
+
+	step := pipelineHcl.GetStep("http.list_items")
+	if step == nil {
+		assert.Fail("http.list_items step not found")
+		return
+	}
+
+	httpStep := step.(*resources.PipelineStepHttp)
+	if httpStep.Pagination == nil {
+		assert.Fail("pagination not found")
+		return
+	}
+	assert.Equal("cursor", *httpStep.Pagination.Type)
+	assert.Equal(int64(20), *httpStep.Pagination.MaxPages)
+	assert.Nil(httpStep.Pagination.PageSize, "page_size should be unresolved")
+	assert.NotNil(httpStep.UnresolvedAttributes["pagination.page_size"])
+
+	paramVal := cty.ObjectVal(map[string]cty.Value{
+		"page_size": cty.NumberIntVal(50),
+	})
+
+	evalContext := &hcl.EvalContext{}
+	evalContext.Variables = map[string]cty.Value{}
+	evalContext.Variables["param"] = paramVal
+
+	stepInputs, err := step.GetInputs(evalContext)
+	assert.Nil(err, "error found")
+
+	pagination := stepInputs[resources.BlockTypePagination].(map[string]any)
+	assert.Equal("cursor", pagination[schema.AttributeTypeType])
+	assert.Equal("$.meta.next_cursor", pagination[resources.AttributeTypeCursor])
+	assert.Equal("after", pagination[resources.AttributeTypeCursorParam])
+	assert.Equal("$.items", pagination[resources.AttributeTypeItems])
+	assert.Equal("limit", pagination[resources.AttributeTypeLimitParam])
+	assert.Equal(50, pagination[resources.AttributeTypePageSize])
+	assert.Equal(int64(20), pagination[resources.AttributeTypeMaxPages])
+}
+
+func TestHttpStepLoadOAuth2(t *testing.T) {
+	assert := assert.New(t)
+
+	pipelines, _, err := parse.LoadPipelines(context.TODO(), "./pipelines/http_step.fp")
+	assert.Nil(err, "error found")
+
+	pipelineHcl := pipelines["local.pipeline.http_step_oauth2"]
+	if pipelineHcl == nil {
+		assert.Fail("http_step_oauth2 pipeline not found")
+		return
+	}
+
+	step := pipelineHcl.GetStep("http.list_items")
+	if step == nil {
+		assert.Fail("http.list_items step not found")
+		return
+	}
+
+	httpStep := step.(*resources.PipelineStepHttp)
+	if httpStep.OAuth2 == nil {
+		assert.Fail("oauth2 not found")
+		return
+	}
+	assert.Equal("https://login.myapi.com/oauth2/token", *httpStep.OAuth2.TokenUrl)
+	assert.Equal([]string{"items.read"}, httpStep.OAuth2.Scopes)
+
+	// the client credentials are resolved at runtime
+	assert.Nil(httpStep.OAuth2.ClientId)
+	assert.NotNil(httpStep.UnresolvedAttributes["oauth2.client_id"])
+
+	paramVal := cty.ObjectVal(map[string]cty.Value{
+		"client_id":     cty.StringVal("my-client"),
+		"client_secret": cty.StringVal("my-secret"),
+	})
+
+	evalContext := &hcl.EvalContext{}
+	evalContext.Variables = map[string]cty.Value{}
+	evalContext.Variables["param"] = paramVal
+
+	stepInputs, err := step.GetInputs(evalContext)
+	assert.Nil(err, "error found")
+
+	oauth2 := stepInputs[resources.BlockTypeOAuth2].(map[string]any)
+	assert.Equal("https://login.myapi.com/oauth2/token", oauth2[resources.AttributeTypeTokenUrl])
+	assert.Equal("my-client", oauth2[resources.AttributeTypeClientId])
+	assert.Equal("my-secret", oauth2[resources.AttributeTypeClientSecret])
+	assert.Equal([]string{"items.read"}, oauth2[resources.AttributeTypeScopes])
+}
+
+func TestHttpStepLoadFiles(t *testing.T) {
+	assert := assert.New(t)
+
+	pipelines, _, err := parse.LoadPipelines(context.TODO(), "./pipelines/http_step.fp")
+	assert.Nil(err, "error found")
+
+	pipelineHcl := pipelines["local.pipeline.http_step_files"]
+	if pipelineHcl == nil {
+		assert.Fail("http_step_files pipeline not found")
+		return
+	}
+
+	step := pipelineHcl.GetStep("http.download")
+	if step == nil {
+		assert.Fail("http.download step not found")
+		return
+	}
+
+	stepInputs, err := step.GetInputs(nil)
+	assert.Nil(err, "error found")
+	assert.Equal(true, stepInputs[resources.AttributeTypeResponseToFile])
+
+	step = pipelineHcl.GetStep("http.upload")
+	if step == nil {
+		assert.Fail("http.upload step not found")
+		return
+	}
+
+	paramVal := cty.ObjectVal(map[string]cty.Value{
+		"report": cty.StringVal("reports/monthly.csv"),
+	})
+
+	evalContext := &hcl.EvalContext{}
+	evalContext.Variables = map[string]cty.Value{}
+	evalContext.Variables["param"] = paramVal
+
+	stepInputs, err = step.GetInputs(evalContext)
+	assert.Nil(err, "error found")
+	assert.Equal(map[string]string{"title": "Monthly report"}, stepInputs[resources.AttributeTypeFormFields])
+	assert.Equal(map[string]string{"attachment": "reports/monthly.csv"}, stepInputs[resources.AttributeTypeFormFiles])
+	assert.Nil(stepInputs[resources.AttributeTypeRequestBodyFile])
+}
@@ -0,0 +1,69 @@
+package pipeline_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/flowpipe/internal/parse"
+	"github.com/turbot/flowpipe/internal/resources"
+)
+
+func TestHTTPTriggerAuth(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+	_, triggers, err := parse.LoadPipelines(ctx, "./pipelines/http_trigger_auth.fp")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	trigger := triggers["local.trigger.http.github_signed"]
+	if trigger == nil {
+		assert.FailNow("github_signed trigger not found")
+	}
+
+	httpTrigConfig := trigger.Config.(*resources.TriggerHttp)
+	assert.Equal(1, len(httpTrigConfig.Methods))
+	assert.NotNil(httpTrigConfig.Methods["post"])
+	if !assert.Equal(2, len(httpTrigConfig.Auth)) {
+		return
+	}
+
+	assert.Equal(resources.HttpAuthTypeHmac, httpTrigConfig.Auth[0].Type)
+	assert.Equal(resources.HttpAuthSchemeGithub, httpTrigConfig.Auth[0].Scheme, "github is the default hmac scheme")
+	assert.NotNil(httpTrigConfig.Auth[0].SecretRaw)
+	assert.Equal(resources.HttpAuthTypeIpAllowList, httpTrigConfig.Auth[1].Type)
+	assert.Equal([]string{"192.30.252.0/22", "10.0.0.1"}, httpTrigConfig.Auth[1].AllowedIPs)
+
+	trigger = triggers["local.trigger.http.generic_signed"]
+	if trigger == nil {
+		assert.FailNow("generic_signed trigger not found")
+	}
+
+	httpTrigConfig = trigger.Config.(*resources.TriggerHttp)
+	assert.Equal(1, len(httpTrigConfig.Methods))
+	assert.NotNil(httpTrigConfig.Methods["get"])
+	if !assert.Equal(1, len(httpTrigConfig.Auth)) {
+		return
+	}
+
+	auth := httpTrigConfig.Auth[0]
+	assert.Equal(resources.HttpAuthSchemeGeneric, auth.Scheme)
+	assert.Equal("X-Signature", auth.Header)
+	assert.Equal("sha512", auth.Algorithm)
+	assert.Equal("sha512=", auth.Prefix)
+	assert.Equal("hex", auth.Encoding)
+
+	trigger = triggers["local.trigger.http.bearer"]
+	if trigger == nil {
+		assert.FailNow("bearer trigger not found")
+	}
+
+	httpTrigConfig = trigger.Config.(*resources.TriggerHttp)
+	if !assert.Equal(1, len(httpTrigConfig.Auth)) {
+		return
+	}
+	assert.Equal(resources.HttpAuthTypeBearer, httpTrigConfig.Auth[0].Type)
+	assert.NotNil(httpTrigConfig.Auth[0].TokenRaw)
+}
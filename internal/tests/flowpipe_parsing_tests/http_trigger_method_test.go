@@ -3,11 +3,15 @@ package pipeline_test
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/turbot/flowpipe/internal/parse"
 	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/pipe-fittings/funcs"
 	"github.com/turbot/pipe-fittings/schema"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestPipelineWithoutHTTPTriggerMethod(t *testing.T) {
@@ -237,3 +241,62 @@ func TestPipelineWithHTTPTriggerPrecedence(t *testing.T) {
 	assert.Equal(3, argsInfo["param_two_int"])
 	assert.Equal("synchronous", methodInfo.ExecutionMode)
 }
+
+func TestPipelineWithHTTPTriggerIdempotencyKey(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+	_, triggers, err := parse.LoadPipelines(ctx, "./pipelines/http_trigger_method.fp")
+	assert.Nil(err, "error found")
+
+	httpTrigger := triggers["local.trigger.http.trigger_with_idempotency_key"]
+	if httpTrigger == nil {
+		assert.Fail("trigger_with_idempotency_key trigger not found")
+		return
+	}
+
+	httpTrigConfig, ok := httpTrigger.Config.(*resources.TriggerHttp)
+	if !ok {
+		assert.Fail("trigger_with_idempotency_key trigger is not a HTTP trigger")
+		return
+	}
+
+	postMethod := httpTrigConfig.Methods["post"]
+	if postMethod == nil {
+		assert.Fail("method 'post' not found")
+		return
+	}
+	assert.Equal(2*time.Hour, postMethod.IdempotencyTtl)
+
+	evalContext := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"self": cty.ObjectVal(map[string]cty.Value{
+				"request_body": cty.StringVal(`{"id": "delivery-body"}`),
+				"request_headers": cty.MapVal(map[string]cty.Value{
+					"X-GitHub-Delivery": cty.StringVal("delivery-header"),
+				}),
+			}),
+		},
+		Functions: funcs.ContextFunctions("."),
+	}
+
+	key, diags := postMethod.GetIdempotencyKey(evalContext)
+	assert.False(diags.HasErrors())
+	assert.Equal("delivery-header", key)
+
+	getMethod := httpTrigConfig.Methods["get"]
+	if getMethod == nil {
+		assert.Fail("method 'get' not found")
+		return
+	}
+	assert.Equal(resources.DefaultIdempotencyTtl, getMethod.IdempotencyTtl)
+
+	key, diags = getMethod.GetIdempotencyKey(evalContext)
+	assert.False(diags.HasErrors())
+	assert.Equal("delivery-body", key)
+
+	// a method without an idempotency key runs the pipeline on each delivery
+	key, diags = triggers["local.trigger.http.trigger_with_get_method"].Config.(*resources.TriggerHttp).Methods["get"].GetIdempotencyKey(evalContext)
+	assert.False(diags.HasErrors())
+	assert.Equal("", key)
+}
@@ -0,0 +1,38 @@
+pipeline "import_csv" {
+  param "path" {
+    type = string
+  }
+
+  param "size" {
+    type    = number
+    default = 0
+  }
+
+  step "transform" "echo" {
+    value = param.path
+  }
+}
+
+trigger "file" "csv_exports" {
+  path     = "./exports"
+  pattern  = "*.csv"
+  events   = ["create", "modify"]
+  debounce = "5s"
+
+  pipeline = pipeline.import_csv
+
+  args = {
+    path = self.path
+    size = self.size
+  }
+}
+
+trigger "file" "any_change" {
+  path = "/mnt/share"
+
+  pipeline = pipeline.import_csv
+
+  args = {
+    path = "${self.event}: ${self.name}"
+  }
+}
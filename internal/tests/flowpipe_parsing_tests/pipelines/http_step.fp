@@ -54,3 +54,73 @@ pipeline "http_step_timeout_string_unresolved" {
     timeout = param.timeout
   }
 }
+
+pipeline "http_step_pagination" {
+
+  param "page_size" {
+    type    = number
+    default = 50
+  }
+
+  step "http" "list_items" {
+    url = "https://myapi.com/vi/api/items"
+
+    pagination {
+      type         = "cursor"
+      cursor       = "$.meta.next_cursor"
+      cursor_param = "after"
+      items        = "$.items"
+      page_size    = param.page_size
+      limit_param  = "limit"
+      max_pages    = 20
+    }
+  }
+}
+
+pipeline "http_step_oauth2" {
+
+  param "client_id" {
+    type = string
+  }
+
+  param "client_secret" {
+    type = string
+  }
+
+  step "http" "list_items" {
+    url = "https://myapi.com/vi/api/items"
+
+    oauth2 {
+      token_url     = "https://login.myapi.com/oauth2/token"
+      client_id     = param.client_id
+      client_secret = param.client_secret
+      scopes        = ["items.read"]
+    }
+  }
+}
+
+pipeline "http_step_files" {
+
+  param "report" {
+    type    = string
+    default = "reports/monthly.csv"
+  }
+
+  step "http" "download" {
+    url              = "https://myapi.com/vi/api/export.zip"
+    response_to_file = true
+  }
+
+  step "http" "upload" {
+    url    = "https://myapi.com/vi/api/upload"
+    method = "post"
+
+    form_fields = {
+      title = "Monthly report"
+    }
+
+    form_files = {
+      attachment = param.report
+    }
+  }
+}
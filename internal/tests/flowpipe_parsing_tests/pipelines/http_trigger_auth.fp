@@ -0,0 +1,43 @@
+pipeline "http_auth_pipeline" {
+  step "transform" "simple_echo" {
+    value = "foo bar"
+  }
+}
+
+trigger "http" "github_signed" {
+  pipeline = pipeline.http_auth_pipeline
+
+  auth {
+    type   = "hmac"
+    secret = "my-secret"
+  }
+
+  auth {
+    type        = "ip_allow_list"
+    allowed_ips = ["192.30.252.0/22", "10.0.0.1"]
+  }
+}
+
+trigger "http" "generic_signed" {
+  auth {
+    type      = "hmac"
+    scheme    = "generic"
+    secret    = "my-secret"
+    header    = "X-Signature"
+    algorithm = "sha512"
+    prefix    = "sha512="
+  }
+
+  method "get" {
+    pipeline = pipeline.http_auth_pipeline
+  }
+}
+
+trigger "http" "bearer" {
+  pipeline = pipeline.http_auth_pipeline
+
+  auth {
+    type  = "bearer"
+    token = "my-token"
+  }
+}
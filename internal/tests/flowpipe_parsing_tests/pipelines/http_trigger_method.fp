@@ -81,3 +81,23 @@ trigger "http" "test_method_precedence" {
   }
 
 }
+
+trigger "http" "trigger_with_idempotency_key" {
+  enabled = true
+
+  method "post" {
+    pipeline = pipeline.simple_with_trigger
+
+    args = {
+      param_one = jsondecode(self.request_body).id
+    }
+
+    idempotency_key = self.request_headers["X-GitHub-Delivery"]
+    idempotency_ttl = "2h"
+  }
+
+  method "get" {
+    pipeline        = pipeline.simple_with_trigger
+    idempotency_key = jsondecode(self.request_body).id
+  }
+}
@@ -40,3 +40,34 @@ pipeline "query_with_args_expr" {
     ]
   }
 }
+
+pipeline "query_with_statements" {
+  step "query" "query_1" {
+    database    = "this is a connection string"
+    transaction = true
+
+    statements = [
+      "update accounts set balance = balance - 10 where id = 1",
+      {
+        sql  = "update accounts set balance = balance + $1 where id = $2"
+        args = [10, 2]
+      }
+    ]
+  }
+}
+
+pipeline "query_with_batch" {
+  param "rows" {
+    default = [
+      ["alice", 10],
+      ["bob", 20]
+    ]
+  }
+
+  step "query" "query_1" {
+    database    = "this is a connection string"
+    sql         = "insert into accounts (name, balance) values ($1, $2)"
+    transaction = true
+    batch       = param.rows
+  }
+}
@@ -60,3 +60,44 @@ trigger "query" "query_trigger_no_schedule" {
         where create_date < now() - interval '90 days'
     EOQ
 }
+
+trigger "query" "query_trigger_watermark" {
+  database         = "postgres://steampipe:@host.docker.internal:9193/steampipe"
+  primary_key      = "id"
+  watermark_column = "updated_at"
+
+  sql = <<EOQ
+        select id, status, updated_at
+        from orders
+        where $1::timestamptz is null or updated_at >= $1
+    EOQ
+
+  capture "insert" {
+    pipeline = pipeline.simple_with_trigger
+    args = {
+      rows = self.inserted_rows
+    }
+  }
+}
+
+trigger "query" "query_trigger_batched" {
+  database    = "postgres://steampipe:@host.docker.internal:9193/steampipe"
+  primary_key = "id"
+  sql         = "select id, status from orders"
+
+  capture "insert" {
+    pipeline         = pipeline.simple_with_trigger
+    batch_size       = 500
+    max_rows_per_run = 10000
+    args = {
+      rows = self.inserted_rows
+    }
+  }
+
+  capture "delete" {
+    pipeline = pipeline.simple_with_trigger
+    args = {
+      rows = self.deleted_rows
+    }
+  }
+}
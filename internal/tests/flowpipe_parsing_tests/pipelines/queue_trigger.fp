@@ -0,0 +1,43 @@
+pipeline "process_order" {
+  param "order" {
+    type = string
+  }
+
+  param "attempt" {
+    type = number
+  }
+
+  step "transform" "echo" {
+    value = param.order
+  }
+}
+
+trigger "queue" "orders_spool" {
+  source      = "directory"
+  path        = "./spool/orders"
+  dead_letter = "./spool/orders_dead"
+  concurrency = 4
+
+  pipeline = pipeline.process_order
+
+  args = {
+    order   = self.message.data
+    attempt = self.message.deliveries
+  }
+}
+
+trigger "queue" "orders_nats" {
+  source         = "nats"
+  url            = "nats://localhost:4222"
+  stream         = "ORDERS"
+  consumer       = "flowpipe"
+  max_deliveries = 3
+  dead_letter    = "orders.dead"
+
+  pipeline = pipeline.process_order
+
+  args = {
+    order   = jsondecode(self.message.data).id
+    attempt = self.message.deliveries
+  }
+}
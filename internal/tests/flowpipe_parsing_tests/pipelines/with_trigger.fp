@@ -13,12 +13,22 @@ trigger "schedule" "my_hourly_trigger" {
 }
 
 trigger "schedule" "my_hourly_trigger_interval" {
-  enabled  = true
-  schedule = "daily"
-  pipeline = pipeline.simple_with_trigger
+  enabled        = true
+  schedule       = "daily"
+  misfire_policy = "fire_once"
+  pipeline       = pipeline.simple_with_trigger
 }
 
 
+trigger "schedule" "business_days" {
+  schedule = "0 9 * * 1-5"
+  timezone = "America/New_York"
+  exclude  = ["2024-12-24", "2024-12-31"]
+  calendar = "us_holidays.txt"
+  jitter   = "5m"
+  pipeline = pipeline.simple_with_trigger
+}
+
 trigger "schedule" "trigger_with_args" {
   schedule = "5 * * * *"
   pipeline = pipeline.simple_with_trigger
@@ -64,6 +74,7 @@ trigger "query" "query_trigger" {
 trigger "query" "query_trigger_interval" {
   enabled  = true
   schedule = "daily"
+  timezone = "Europe/London"
   database = "postgres://steampipe:@host.docker.internal:9193/steampipe"
 
   sql = <<EOQ
@@ -4,9 +4,12 @@ import (
 	"context"
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/turbot/flowpipe/internal/parse"
+	"github.com/turbot/flowpipe/internal/resources"
 	"github.com/turbot/pipe-fittings/schema"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestQueryStep(t *testing.T) {
@@ -76,3 +79,86 @@ func TestQueryStepWithArgs(t *testing.T) {
 	assert.Equal("two", args[0])
 	assert.Equal(10, args[1])
 }
+
+func TestQueryStepWithStatements(t *testing.T) {
+	assert := assert.New(t)
+
+	pipelines, _, err := parse.LoadPipelines(context.TODO(), "./pipelines/query.fp")
+	assert.Nil(err, "error found")
+
+	if pipelines["local.pipeline.query_with_statements"] == nil {
+		assert.Fail("query_with_statements pipeline not found")
+		return
+	}
+
+	step := pipelines["local.pipeline.query_with_statements"].GetStep("query.query_1")
+	if step == nil {
+		assert.Fail("query step not found")
+		return
+	}
+
+	inputs, err := step.GetInputs(nil)
+	if err != nil {
+		assert.Fail("error getting inputs")
+		return
+	}
+	assert.Nil(inputs[schema.AttributeTypeSql])
+	assert.Equal(true, inputs[resources.AttributeTypeTransaction])
+
+	statements, ok := inputs[resources.AttributeTypeStatements].([]interface{})
+	if !ok {
+		assert.Fail("statements not found")
+		return
+	}
+	assert.Equal(2, len(statements))
+	assert.Equal("update accounts set balance = balance - 10 where id = 1", statements[0])
+
+	statement, ok := statements[1].(map[string]interface{})
+	if !ok {
+		assert.Fail("statement with args not found")
+		return
+	}
+	assert.Equal("update accounts set balance = balance + $1 where id = $2", statement[schema.AttributeTypeSql])
+	assert.Equal([]interface{}{10, 2}, statement[schema.AttributeTypeArgs])
+}
+
+func TestQueryStepWithBatch(t *testing.T) {
+	assert := assert.New(t)
+
+	pipelines, _, err := parse.LoadPipelines(context.TODO(), "./pipelines/query.fp")
+	assert.Nil(err, "error found")
+
+	if pipelines["local.pipeline.query_with_batch"] == nil {
+		assert.Fail("query_with_batch pipeline not found")
+		return
+	}
+
+	step := pipelines["local.pipeline.query_with_batch"].GetStep("query.query_1")
+	if step == nil {
+		assert.Fail("query step not found")
+		return
+	}
+
+	paramVal := cty.ObjectVal(map[string]cty.Value{
+		"rows": cty.TupleVal([]cty.Value{
+			cty.TupleVal([]cty.Value{cty.StringVal("alice"), cty.NumberIntVal(10)}),
+			cty.TupleVal([]cty.Value{cty.StringVal("bob"), cty.NumberIntVal(20)}),
+		}),
+	})
+
+	evalContext := &hcl.EvalContext{}
+	evalContext.Variables = map[string]cty.Value{}
+	evalContext.Variables["param"] = paramVal
+
+	inputs, err := step.GetInputs(evalContext)
+	if err != nil {
+		assert.Fail("error getting inputs: " + err.Error())
+		return
+	}
+	assert.Equal("insert into accounts (name, balance) values ($1, $2)", inputs[schema.AttributeTypeSql])
+	assert.Equal(true, inputs[resources.AttributeTypeTransaction])
+	assert.Equal([]interface{}{
+		[]interface{}{"alice", 10},
+		[]interface{}{"bob", 20},
+	}, inputs[resources.AttributeTypeBatch])
+}
@@ -33,3 +33,55 @@ func TestQueryTriggerParse(t *testing.T) {
 
 	assert.Equal("", st.Schedule)
 }
+
+func TestQueryTriggerWatermarkParse(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+	_, triggers, err := parse.LoadPipelines(ctx, "./pipelines/query_trigger.fp")
+	assert.Nil(err, "error found")
+
+	queryTrigger := triggers["local.trigger.query.query_trigger_watermark"]
+	if queryTrigger == nil {
+		assert.Fail("query_trigger_watermark trigger not found")
+		return
+	}
+
+	st, ok := queryTrigger.Config.(*resources.TriggerQuery)
+	if !ok {
+		assert.Fail("query_trigger_watermark trigger is not a query trigger")
+		return
+	}
+
+	assert.Equal("updated_at", st.WatermarkColumn)
+	assert.Equal("id", st.PrimaryKey)
+	assert.Equal(1, len(st.Captures))
+}
+
+func TestQueryTriggerBatchParse(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+	_, triggers, err := parse.LoadPipelines(ctx, "./pipelines/query_trigger.fp")
+	assert.Nil(err, "error found")
+
+	queryTrigger := triggers["local.trigger.query.query_trigger_batched"]
+	if queryTrigger == nil {
+		assert.Fail("query_trigger_batched trigger not found")
+		return
+	}
+
+	st, ok := queryTrigger.Config.(*resources.TriggerQuery)
+	if !ok {
+		assert.Fail("query_trigger_batched trigger is not a query trigger")
+		return
+	}
+
+	insertCapture := st.Captures["insert"]
+	assert.True(insertCapture.IsBatched())
+	assert.Equal(500, insertCapture.BatchSize)
+	assert.Equal(10000, insertCapture.MaxRowsPerRun)
+
+	deleteCapture := st.Captures["delete"]
+	assert.False(deleteCapture.IsBatched())
+}
@@ -0,0 +1,73 @@
+package pipeline_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/flowpipe/internal/parse"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/flowpipe/internal/trigger"
+	"github.com/turbot/pipe-fittings/funcs"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestQueueTrigger(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+	_, triggers, err := parse.LoadPipelines(ctx, "./pipelines/queue_trigger.fp")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	spoolTrigger := triggers["local.trigger.queue.orders_spool"]
+	if spoolTrigger == nil {
+		assert.FailNow("orders_spool trigger not found")
+	}
+
+	assert.Equal(resources.TriggerTypeQueue, resources.GetTriggerTypeFromTriggerConfig(spoolTrigger.Config))
+
+	spoolConfig := spoolTrigger.Config.(*resources.TriggerQueue)
+	assert.Equal(resources.QueueSourceDirectory, spoolConfig.Source)
+	assert.Equal("./spool/orders", spoolConfig.Path)
+	assert.Equal("./spool/orders_dead", spoolConfig.DeadLetter)
+	assert.Equal(4, spoolConfig.Concurrency)
+	assert.Equal(resources.DefaultQueueMaxDeliveries, spoolConfig.MaxDeliveries)
+	assert.Equal("local.pipeline.process_order", spoolTrigger.Pipeline.AsValueMap()["name"].AsString())
+
+	natsTrigger := triggers["local.trigger.queue.orders_nats"]
+	if natsTrigger == nil {
+		assert.FailNow("orders_nats trigger not found")
+	}
+
+	natsConfig := natsTrigger.Config.(*resources.TriggerQueue)
+	assert.Equal(resources.QueueSourceNats, natsConfig.Source)
+	assert.Equal("nats://localhost:4222", natsConfig.Url)
+	assert.Equal("ORDERS", natsConfig.Stream)
+	assert.Equal("flowpipe", natsConfig.Consumer)
+	assert.Equal(resources.DefaultQueueConcurrency, natsConfig.Concurrency)
+	assert.Equal(3, natsConfig.MaxDeliveries)
+	assert.Equal("orders.dead", natsConfig.DeadLetter)
+	assert.False(spoolConfig.Equals(natsConfig))
+
+	evalContext := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"self": trigger.QueueMessageSelf(&trigger.QueueMessage{
+				ID:         "ORDERS.42",
+				Subject:    "orders.new",
+				Data:       []byte(`{"id": "order-42"}`),
+				Deliveries: 2,
+				ReceivedAt: time.Now(),
+			}),
+		},
+		Functions: funcs.ContextFunctions("."),
+	}
+
+	args, diags := natsTrigger.GetArgs(evalContext)
+	assert.False(diags.HasErrors())
+	assert.Equal("order-42", args["order"])
+	assert.Equal(2, args["attempt"])
+}
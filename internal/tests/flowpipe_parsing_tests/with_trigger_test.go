@@ -3,6 +3,7 @@ package pipeline_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/turbot/flowpipe/internal/parse"
@@ -47,6 +48,7 @@ func TestPipelineWithTrigger(t *testing.T) {
 	}
 
 	assert.Equal("5 * * * *", st.Schedule)
+	assert.Equal(resources.MisfirePolicySkip, st.GetMisfirePolicy())
 
 	scheduleTrigger = triggers["local.trigger.schedule.my_hourly_trigger_interval"]
 	if scheduleTrigger == nil {
@@ -62,6 +64,30 @@ func TestPipelineWithTrigger(t *testing.T) {
 	}
 
 	assert.Equal("daily", st.Schedule)
+	assert.Equal(resources.MisfirePolicyFireOnce, st.GetMisfirePolicy())
+
+	businessDays := triggers["local.trigger.schedule.business_days"]
+	if businessDays == nil {
+		assert.Fail("business_days trigger not found")
+		return
+	}
+
+	st, ok = businessDays.Config.(*resources.TriggerSchedule)
+	if !ok {
+		assert.Fail("business_days trigger is not a schedule trigger")
+		return
+	}
+
+	assert.Equal("America/New_York", st.Timezone)
+	assert.Equal([]string{"2024-12-24", "2024-12-31"}, st.Exclude)
+	assert.Equal("us_holidays.txt", st.Calendar)
+	assert.Equal(5*time.Minute, st.Jitter)
+
+	newYork, _ := time.LoadLocation("America/New_York")
+	assert.True(st.IsExcluded(time.Date(2024, 12, 31, 9, 0, 0, 0, newYork)))
+	assert.True(st.IsExcluded(time.Date(2024, 7, 4, 9, 0, 0, 0, newYork)), "calendar holiday")
+	assert.True(st.IsExcluded(time.Date(2024, 11, 28, 9, 0, 0, 0, newYork)), "calendar holiday")
+	assert.False(st.IsExcluded(time.Date(2024, 7, 5, 9, 0, 0, 0, newYork)))
 
 	triggerWithArgs := triggers["local.trigger.schedule.trigger_with_args"]
 	if triggerWithArgs == nil {
@@ -137,6 +163,7 @@ func TestPipelineWithTrigger(t *testing.T) {
 	assert.Equal("access_key_id", qt.PrimaryKey)
 	assert.Contains(qt.Sql, "where create_date < now() - interval")
 	assert.Equal("daily", qt.Schedule)
+	assert.Equal("Europe/London", qt.Timezone)
 
 	triggerWithExecutionMode := triggers["local.trigger.http.trigger_with_execution_mode"]
 	if triggerWithExecutionMode == nil {
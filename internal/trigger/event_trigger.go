@@ -0,0 +1,63 @@
+package trigger
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/turbot/flowpipe/internal/es/db"
+	"github.com/turbot/flowpipe/internal/es/event"
+	"github.com/turbot/flowpipe/internal/output"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/flowpipe/internal/types"
+	"github.com/turbot/flowpipe/internal/util"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/perr"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// newEventTriggerExecution builds the execution that runs the pipeline of a trigger fired by an external event, such
+// as a queue message or a file change, with the event available to the args as self. The latest definition of the
+// trigger is used, the mod may have been updated since the trigger started listening.
+func newEventTriggerExecution(ctx context.Context, triggerName, triggerType string, self cty.Value, logArgs ...any) (*event.ExecutionQueue, error) {
+	t, err := db.GetTrigger(triggerName)
+	if err != nil {
+		return nil, err
+	}
+
+	if resources.GetTriggerTypeFromTriggerConfig(t.Config) != triggerType {
+		return nil, perr.BadRequestWithMessage("trigger " + triggerName + " is not a " + triggerType + " trigger")
+	}
+
+	evalContext, err := buildEvalContextForTriggerExecution(t.GetMod(), t.Params, t.Config, nil)
+	if err != nil {
+		slog.Error("Error building eval context", "trigger", triggerName, "error", err)
+		return nil, perr.InternalWithMessage("Error building eval context")
+	}
+	evalContext.Variables["self"] = self
+
+	pipelineArgs, diags := t.GetArgs(evalContext)
+	if diags.HasErrors() {
+		return nil, error_helpers.HclDiagsToError("trigger", diags)
+	}
+
+	pipelineName := t.Pipeline.AsValueMap()[schema.LabelName].AsString()
+
+	executionCmd := event.NewExecutionQueueForPipeline("", pipelineName)
+	executionCmd.PipelineQueue = &event.PipelineQueue{
+		Event:               event.NewFlowEvent(executionCmd.Event),
+		PipelineExecutionID: util.NewPipelineExecutionId(),
+		Name:                pipelineName,
+		Args:                pipelineArgs,
+		Trigger:             t.Name(),
+	}
+
+	slog.Info("Trigger fired", append([]any{"trigger", t.Name(), "pipeline", pipelineName, "execution_id", executionCmd.Event.ExecutionID}, logArgs...)...)
+
+	if output.IsServerMode {
+		output.RenderServerOutput(ctx, types.NewServerOutputTriggerExecution(time.Now(), executionCmd.Event.ExecutionID, t.Name(), pipelineName))
+	}
+
+	return executionCmd, nil
+}
@@ -0,0 +1,47 @@
+package trigger
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/turbot/flowpipe/internal/es/event"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// FileEventSelf is the self object available to the args of a file trigger:
+//
+//	self.path
+//	self.event
+//	self.name
+//	self.directory
+//	self.size
+//	self.mode
+//	self.mod_time
+//
+// size, mode and mod_time are null for a deleted file.
+func FileEventSelf(ev FileEvent) cty.Value {
+	self := map[string]cty.Value{
+		"path":      cty.StringVal(ev.Path),
+		"event":     cty.StringVal(ev.Event),
+		"name":      cty.StringVal(filepath.Base(ev.Path)),
+		"directory": cty.StringVal(filepath.Dir(ev.Path)),
+		"size":      cty.NullVal(cty.Number),
+		"mode":      cty.NullVal(cty.String),
+		"mod_time":  cty.NullVal(cty.String),
+	}
+
+	if ev.Info != nil {
+		self["size"] = cty.NumberIntVal(ev.Info.Size())
+		self["mode"] = cty.StringVal(ev.Info.Mode().String())
+		self["mod_time"] = cty.StringVal(ev.Info.ModTime().UTC().Format(time.RFC3339))
+	}
+
+	return cty.ObjectVal(self)
+}
+
+// NewFileEventExecution builds the execution that runs the pipeline of the file trigger for a file event
+func NewFileEventExecution(ctx context.Context, triggerName string, ev FileEvent) (*event.ExecutionQueue, error) {
+	return newEventTriggerExecution(ctx, triggerName, resources.TriggerTypeFile, FileEventSelf(ev), "path", ev.Path, "event", ev.Event)
+}
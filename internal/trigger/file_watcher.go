@@ -0,0 +1,209 @@
+package trigger
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+// FileEvent is a debounced change of a file watched by a file trigger
+type FileEvent struct {
+	Path  string
+	Event string
+
+	// Info is nil for a deleted file
+	Info fs.FileInfo
+}
+
+// FileWatcher watches the directory of a file trigger, the files of its sub directories are not watched. The events
+// of a file are gathered until the file has been left alone for the debounce window, then reported as a single event:
+//
+//   - created then modified is reported as created
+//   - deleted then created, e.g. an editor replacing the file, is reported as modified
+//   - created then deleted is not reported
+type FileWatcher struct {
+	path     string
+	config   *resources.TriggerFile
+	onEvent  func(FileEvent)
+	watcher  *fsnotify.Watcher
+	pending  map[string]*pendingFileEvent
+	lock     sync.Mutex
+	closed   bool
+	finished chan struct{}
+}
+
+type pendingFileEvent struct {
+	event string
+	timer *time.Timer
+}
+
+// NewFileWatcher starts watching the directory of the file trigger, a relative path is relative to the mod location.
+// onEvent is called for each event the trigger fires on, possibly concurrently.
+func NewFileWatcher(config *resources.TriggerFile, onEvent func(FileEvent)) (*FileWatcher, error) {
+	path := modRelativePath(config.Path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, perr.BadRequestWithMessage("unable to watch " + path + ": " + err.Error())
+	}
+	if !info.IsDir() {
+		return nil, perr.BadRequestWithMessage("unable to watch " + path + ": not a directory")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, perr.InternalWithMessage("unable to create file watcher: " + err.Error())
+	}
+
+	err = watcher.Add(path)
+	if err != nil {
+		watcher.Close()
+		return nil, perr.InternalWithMessage("unable to watch " + path + ": " + err.Error())
+	}
+
+	w := &FileWatcher{
+		path:     path,
+		config:   config,
+		onEvent:  onEvent,
+		watcher:  watcher,
+		pending:  map[string]*pendingFileEvent{},
+		finished: make(chan struct{}),
+	}
+
+	go w.run()
+	return w, nil
+}
+
+func (w *FileWatcher) run() {
+	defer close(w.finished)
+
+	for {
+		select {
+		case ev, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			w.handle(ev)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn("File watcher error", "path", w.path, "error", err)
+		}
+	}
+}
+
+func (w *FileWatcher) handle(ev fsnotify.Event) {
+	var event string
+	switch {
+	case ev.Has(fsnotify.Create):
+		event = resources.FileEventCreate
+	case ev.Has(fsnotify.Write):
+		event = resources.FileEventModify
+	case ev.Has(fsnotify.Remove), ev.Has(fsnotify.Rename):
+		// a file renamed within the directory is also reported as created under its new name
+		event = resources.FileEventDelete
+	default:
+		return
+	}
+
+	matched, err := filepath.Match(w.config.Pattern, filepath.Base(ev.Name))
+	if err != nil || !matched {
+		return
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.closed {
+		return
+	}
+
+	p, ok := w.pending[ev.Name]
+	if !ok {
+		p = &pendingFileEvent{event: event}
+		w.pending[ev.Name] = p
+		p.timer = time.AfterFunc(w.config.Debounce, func() { w.fire(ev.Name) })
+		return
+	}
+
+	switch {
+	case p.event == resources.FileEventCreate && event == resources.FileEventModify:
+		// still created
+	case p.event == resources.FileEventCreate && event == resources.FileEventDelete:
+		p.timer.Stop()
+		delete(w.pending, ev.Name)
+		return
+	case p.event == resources.FileEventDelete && event == resources.FileEventCreate:
+		p.event = resources.FileEventModify
+	default:
+		p.event = event
+	}
+
+	p.timer.Reset(w.config.Debounce)
+}
+
+func (w *FileWatcher) fire(name string) {
+	w.lock.Lock()
+	p, ok := w.pending[name]
+	delete(w.pending, name)
+	closed := w.closed
+	w.lock.Unlock()
+
+	if !ok || closed {
+		return
+	}
+
+	ev := FileEvent{
+		Path:  name,
+		Event: p.event,
+	}
+
+	if ev.Event != resources.FileEventDelete {
+		info, err := os.Stat(name)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			if ev.Event == resources.FileEventCreate {
+				// created then deleted
+				return
+			}
+			ev.Event = resources.FileEventDelete
+		case err != nil:
+			slog.Warn("Unable to stat watched file", "path", name, "error", err)
+			return
+		case info.IsDir():
+			return
+		default:
+			ev.Info = info
+		}
+	}
+
+	if !w.config.HasEvent(ev.Event) {
+		return
+	}
+
+	w.onEvent(ev)
+}
+
+// Close stops watching, the pending events are dropped
+func (w *FileWatcher) Close() error {
+	w.lock.Lock()
+	w.closed = true
+	for name, p := range w.pending {
+		p.timer.Stop()
+		delete(w.pending, name)
+	}
+	w.lock.Unlock()
+
+	err := w.watcher.Close()
+	<-w.finished
+	return err
+}
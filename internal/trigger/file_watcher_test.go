@@ -0,0 +1,80 @@
+package trigger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/flowpipe/internal/resources"
+)
+
+func waitForFileEvent(t *testing.T, events chan FileEvent) *FileEvent {
+	select {
+	case ev := <-events:
+		return &ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("file event not received")
+		return nil
+	}
+}
+
+func TestFileWatcher(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	events := make(chan FileEvent, 16)
+
+	watcher, err := NewFileWatcher(&resources.TriggerFile{
+		Path:     dir,
+		Pattern:  "*.csv",
+		Events:   []string{resources.FileEventCreate, resources.FileEventDelete},
+		Debounce: 200 * time.Millisecond,
+	}, func(ev FileEvent) {
+		events <- ev
+	})
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	defer watcher.Close()
+
+	// written in several chunks, reported once as created
+	path := filepath.Join(dir, "report.csv")
+	f, err := os.Create(path)
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	_, _ = f.WriteString("a,b\n")
+	_, _ = f.WriteString("1,2\n")
+	f.Close()
+
+	// not matching the pattern
+	assert.Nil(os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0600))
+
+	ev := waitForFileEvent(t, events)
+	assert.Equal(path, ev.Path)
+	assert.Equal(resources.FileEventCreate, ev.Event)
+	if assert.NotNil(ev.Info) {
+		assert.Equal(int64(8), ev.Info.Size())
+	}
+
+	// modify isn't one of the events of the trigger
+	assert.Nil(os.WriteFile(path, []byte("a,b\n1,2\n3,4\n"), 0600))
+	time.Sleep(500 * time.Millisecond)
+
+	assert.Nil(os.Remove(path))
+	ev = waitForFileEvent(t, events)
+	assert.Equal(resources.FileEventDelete, ev.Event)
+	assert.Nil(ev.Info)
+
+	// created then deleted within the debounce window
+	assert.Nil(os.WriteFile(filepath.Join(dir, "temp.csv"), []byte("x"), 0600))
+	assert.Nil(os.Remove(filepath.Join(dir, "temp.csv")))
+
+	select {
+	case ev := <-events:
+		assert.Fail("unexpected file event", "%s %s", ev.Event, ev.Path)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
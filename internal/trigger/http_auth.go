@@ -0,0 +1,304 @@
+package trigger
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // sha1 signatures are still used by some webhook providers
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+// VerifyHttpAuth checks a request to an HTTP trigger against the auth blocks of the trigger, the request must pass all
+// of them. The error is unauthorized, or forbidden if the client IP is not allowed.
+func VerifyHttpAuth(rootMod *modconfig.Mod, t *resources.Trigger, header http.Header, clientIP string, body []byte) error {
+	httpConfig, ok := t.Config.(*resources.TriggerHttp)
+	if !ok || len(httpConfig.Auth) == 0 {
+		return nil
+	}
+
+	// the secrets are resolved on each request, so a rotated secret is used as soon as it's updated
+	var evalContext *hcl.EvalContext
+	resolve := func(expr hcl.Expression) (string, error) {
+		if evalContext == nil {
+			var err error
+			evalContext, err = buildEvalContextForTriggerExecution(rootMod, nil, httpConfig, nil)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		val, diags := expr.Value(evalContext)
+		if diags.HasErrors() {
+			return "", error_helpers.HclDiagsToError("trigger", diags)
+		}
+		return hclhelpers.CtyToString(val)
+	}
+
+	for _, auth := range httpConfig.Auth {
+		switch auth.Type {
+		case resources.HttpAuthTypeIpAllowList:
+			err := verifyClientIP(auth, clientIP)
+			if err != nil {
+				return err
+			}
+		case resources.HttpAuthTypeBearer:
+			token, err := resolve(auth.TokenRaw)
+			if err != nil {
+				return perr.InternalWithMessage("unable to resolve the bearer token: " + err.Error())
+			}
+
+			err = verifyBearerToken(header, token)
+			if err != nil {
+				return err
+			}
+		case resources.HttpAuthTypeHmac:
+			secret, err := resolve(auth.SecretRaw)
+			if err != nil {
+				return perr.InternalWithMessage("unable to resolve the signature secret: " + err.Error())
+			}
+
+			err = verifyHmacSignature(auth, header, body, secret, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// RequestClientIP returns the IP of the client of the request. The X-Forwarded-For header is set by the sender and
+// would let anyone pass the IP allow list, it's only honoured when the peer is one of the trusted proxies: the client is
+// the rightmost address of the header that isn't a trusted proxy. X-Real-IP is ignored.
+func RequestClientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	clientIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		clientIP = r.RemoteAddr
+	}
+
+	if !isTrustedProxy(clientIP, trustedProxies) {
+		return clientIP
+	}
+
+	// each proxy appends the address of its peer
+	var hops []string
+	for _, value := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(hops[i])
+		if err != nil {
+			// not set by a proxy, the last trusted proxy is the client
+			break
+		}
+
+		clientIP = addr.Unmap().String()
+		if !isTrustedProxy(clientIP, trustedProxies) {
+			break
+		}
+	}
+
+	return clientIP
+}
+
+func isTrustedProxy(ip string, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTrustedProxies parses the comma separated IPs and CIDRs of the reverse proxies whose X-Forwarded-For header is
+// honoured
+func ParseTrustedProxies(value string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, proxy := range strings.Split(value, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if strings.Contains(proxy, "/") {
+			prefix, err := netip.ParsePrefix(proxy)
+			if err != nil {
+				return nil, perr.BadRequestWithMessage("invalid trusted proxy " + proxy + ": " + err.Error())
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return nil, perr.BadRequestWithMessage("invalid trusted proxy " + proxy + ": " + err.Error())
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+func verifyClientIP(auth *resources.TriggerHttpAuth, clientIP string) error {
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return perr.ForbiddenWithMessage("invalid client IP " + clientIP)
+	}
+	addr = addr.Unmap()
+
+	prefixes, err := auth.AllowedPrefixes()
+	if err != nil {
+		return perr.InternalWithMessage("invalid allowed_ips: " + err.Error())
+	}
+
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+
+	return perr.ForbiddenWithMessage("client IP " + clientIP + " is not allowed")
+}
+
+func verifyBearerToken(header http.Header, token string) error {
+	scheme, value, found := strings.Cut(header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return perr.UnauthorizedWithMessage("missing bearer token")
+	}
+
+	if token == "" || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(value)), []byte(token)) != 1 {
+		return perr.UnauthorizedWithMessage("invalid bearer token")
+	}
+
+	return nil
+}
+
+func verifyHmacSignature(auth *resources.TriggerHttpAuth, header http.Header, body []byte, secret string, now time.Time) error {
+	if secret == "" {
+		return perr.InternalWithMessage("the signature secret is empty")
+	}
+
+	switch auth.Scheme {
+	case resources.HttpAuthSchemeGithub:
+		// X-Hub-Signature-256: sha256=<hex hmac of the body>
+		signature, found := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+		if !found {
+			return perr.UnauthorizedWithMessage("missing X-Hub-Signature-256 header")
+		}
+
+		if !hexSignatureEqual(signature, hmacSum(sha256.New, secret, body)) {
+			return perr.UnauthorizedWithMessage("invalid signature")
+		}
+	case resources.HttpAuthSchemeStripe:
+		// Stripe-Signature: t=<timestamp>,v1=<hex hmac of timestamp.body>[,v1=...]
+		var timestamp string
+		var signatures []string
+		for _, part := range strings.Split(header.Get("Stripe-Signature"), ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch key {
+			case "t":
+				timestamp = value
+			case "v1":
+				signatures = append(signatures, value)
+			}
+		}
+
+		if timestamp == "" || len(signatures) == 0 {
+			return perr.UnauthorizedWithMessage("missing Stripe-Signature header")
+		}
+
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return perr.UnauthorizedWithMessage("invalid signature timestamp")
+		}
+
+		age := now.Sub(time.Unix(seconds, 0))
+		if age < 0 {
+			age = -age
+		}
+		if age > time.Duration(auth.Tolerance)*time.Second {
+			return perr.UnauthorizedWithMessage("signature timestamp outside of the tolerance")
+		}
+
+		expected := hmacSum(sha256.New, secret, append([]byte(timestamp+"."), body...))
+		for _, signature := range signatures {
+			if hexSignatureEqual(signature, expected) {
+				return nil
+			}
+		}
+		return perr.UnauthorizedWithMessage("invalid signature")
+	case resources.HttpAuthSchemeGeneric:
+		value := header.Get(auth.Header)
+		if value == "" {
+			return perr.UnauthorizedWithMessage("missing " + auth.Header + " header")
+		}
+
+		signature, found := strings.CutPrefix(value, auth.Prefix)
+		if !found {
+			return perr.UnauthorizedWithMessage("invalid signature")
+		}
+
+		var h func() hash.Hash
+		switch auth.Algorithm {
+		case "sha1":
+			h = sha1.New
+		case "sha512":
+			h = sha512.New
+		default:
+			h = sha256.New
+		}
+		expected := hmacSum(h, secret, body)
+
+		var valid bool
+		if auth.Encoding == "base64" {
+			decoded, err := base64.StdEncoding.DecodeString(signature)
+			valid = err == nil && hmac.Equal(decoded, expected)
+		} else {
+			valid = hexSignatureEqual(signature, expected)
+		}
+
+		if !valid {
+			return perr.UnauthorizedWithMessage("invalid signature")
+		}
+	default:
+		return perr.InternalWithMessage("unsupported signature scheme " + auth.Scheme)
+	}
+
+	return nil
+}
+
+func hmacSum(h func() hash.Hash, secret string, data []byte) []byte {
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func hexSignatureEqual(signature string, expected []byte) bool {
+	decoded, err := hex.DecodeString(signature)
+	return err == nil && hmac.Equal(decoded, expected)
+}
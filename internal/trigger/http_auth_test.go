@@ -0,0 +1,166 @@
+package trigger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+func TestVerifyHmacSignature(t *testing.T) {
+	assert := assert.New(t)
+
+	secret := "my-secret"
+	body := []byte(`{"action":"opened"}`)
+	now := time.Unix(1722480000, 0)
+
+	sign := func(data []byte) []byte {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(data)
+		return mac.Sum(nil)
+	}
+
+	github := &resources.TriggerHttpAuth{Type: resources.HttpAuthTypeHmac, Scheme: resources.HttpAuthSchemeGithub}
+
+	header := http.Header{}
+	header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(sign(body)))
+	assert.Nil(verifyHmacSignature(github, header, body, secret, now))
+	assert.True(perr.IsUnauthorized(verifyHmacSignature(github, header, []byte(`{"action":"closed"}`), secret, now)))
+	assert.True(perr.IsUnauthorized(verifyHmacSignature(github, header, body, "other-secret", now)))
+	assert.True(perr.IsUnauthorized(verifyHmacSignature(github, http.Header{}, body, secret, now)))
+
+	stripe := &resources.TriggerHttpAuth{Type: resources.HttpAuthTypeHmac, Scheme: resources.HttpAuthSchemeStripe, Tolerance: 300}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := hex.EncodeToString(sign(append([]byte(timestamp+"."), body...)))
+
+	header = http.Header{}
+	header.Set("Stripe-Signature", "t="+timestamp+",v1=bad,v1="+signature)
+	assert.Nil(verifyHmacSignature(stripe, header, body, secret, now))
+	assert.Nil(verifyHmacSignature(stripe, header, body, secret, now.Add(299*time.Second)))
+	assert.True(perr.IsUnauthorized(verifyHmacSignature(stripe, header, body, secret, now.Add(301*time.Second))), "the signature is too old")
+
+	header.Set("Stripe-Signature", "t="+timestamp+",v1=bad")
+	assert.True(perr.IsUnauthorized(verifyHmacSignature(stripe, header, body, secret, now)))
+
+	generic := &resources.TriggerHttpAuth{
+		Type:      resources.HttpAuthTypeHmac,
+		Scheme:    resources.HttpAuthSchemeGeneric,
+		Header:    "X-Signature",
+		Algorithm: "sha512",
+		Prefix:    "v1=",
+		Encoding:  "base64",
+	}
+	mac := hmac.New(sha512.New, []byte(secret))
+	mac.Write(body)
+
+	header = http.Header{}
+	header.Set("X-Signature", "v1="+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	assert.Nil(verifyHmacSignature(generic, header, body, secret, now))
+
+	header.Set("X-Signature", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	assert.True(perr.IsUnauthorized(verifyHmacSignature(generic, header, body, secret, now)), "the prefix is missing")
+}
+
+func TestVerifyBearerToken(t *testing.T) {
+	assert := assert.New(t)
+
+	header := http.Header{}
+	assert.True(perr.IsUnauthorized(verifyBearerToken(header, "my-token")))
+
+	header.Set("Authorization", "Bearer my-token")
+	assert.Nil(verifyBearerToken(header, "my-token"))
+	assert.True(perr.IsUnauthorized(verifyBearerToken(header, "other-token")))
+	assert.True(perr.IsUnauthorized(verifyBearerToken(header, "")), "an empty token never matches")
+
+	header.Set("Authorization", "Basic my-token")
+	assert.True(perr.IsUnauthorized(verifyBearerToken(header, "my-token")))
+}
+
+func TestVerifyClientIP(t *testing.T) {
+	assert := assert.New(t)
+
+	auth := &resources.TriggerHttpAuth{
+		Type:       resources.HttpAuthTypeIpAllowList,
+		AllowedIPs: []string{"192.30.252.0/22", "10.0.0.1", "2001:db8::/32"},
+	}
+
+	assert.Nil(verifyClientIP(auth, "192.30.253.10"))
+	assert.Nil(verifyClientIP(auth, "10.0.0.1"))
+	assert.Nil(verifyClientIP(auth, "::ffff:10.0.0.1"))
+	assert.Nil(verifyClientIP(auth, "2001:db8::1"))
+	assert.True(perr.IsForbidden(verifyClientIP(auth, "10.0.0.2")))
+	assert.True(perr.IsForbidden(verifyClientIP(auth, "192.30.256.1")))
+	assert.True(perr.IsForbidden(verifyClientIP(auth, "not an ip")))
+}
+
+func TestRequestClientIP(t *testing.T) {
+	assert := assert.New(t)
+
+	auth := &resources.TriggerHttpAuth{
+		Type:       resources.HttpAuthTypeIpAllowList,
+		AllowedIPs: []string{"10.0.0.1"},
+	}
+
+	// the forwarding headers are set by the sender, only the peer address is checked
+	req := httptest.NewRequest(http.MethodPost, "/api/latest/hook/http.my_trigger/hash", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set("X-Forwarded-For", "10.0.0.1")
+	req.Header.Set("X-Real-IP", "10.0.0.1")
+
+	clientIP := RequestClientIP(req, nil)
+	assert.Equal("203.0.113.7", clientIP)
+	assert.True(perr.IsForbidden(verifyClientIP(auth, clientIP)))
+
+	req.RemoteAddr = "10.0.0.1:51234"
+	assert.Nil(verifyClientIP(auth, RequestClientIP(req, nil)))
+
+	req.RemoteAddr = "[2001:db8::1]:443"
+	assert.Equal("2001:db8::1", RequestClientIP(req, nil))
+}
+
+func TestRequestClientIPTrustedProxies(t *testing.T) {
+	assert := assert.New(t)
+
+	trustedProxies, err := ParseTrustedProxies("10.0.0.0/8, 192.168.1.1")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/latest/hook/http.my_trigger/hash", nil)
+	req.Header.Set("X-Forwarded-For", "10.0.0.1, 203.0.113.7, 10.1.2.3")
+	req.Header.Set("X-Real-IP", "10.0.0.1")
+
+	// the header of an untrusted peer is ignored
+	req.RemoteAddr = "198.51.100.2:51234"
+	assert.Equal("198.51.100.2", RequestClientIP(req, trustedProxies))
+
+	// the rightmost untrusted hop is the client, the leftmost hops are set by the sender
+	req.RemoteAddr = "192.168.1.1:51234"
+	assert.Equal("203.0.113.7", RequestClientIP(req, trustedProxies))
+
+	// the proxies may each add a header
+	req.Header.Del("X-Forwarded-For")
+	req.Header.Add("X-Forwarded-For", "203.0.113.9")
+	req.Header.Add("X-Forwarded-For", "10.1.2.3")
+	assert.Equal("203.0.113.9", RequestClientIP(req, trustedProxies))
+
+	// without a valid forwarded address the last trusted proxy is the client
+	req.Header.Set("X-Forwarded-For", "unknown, 10.1.2.3")
+	assert.Equal("10.1.2.3", RequestClientIP(req, trustedProxies))
+
+	req.Header.Del("X-Forwarded-For")
+	assert.Equal("192.168.1.1", RequestClientIP(req, trustedProxies))
+
+	_, err = ParseTrustedProxies("10.0.0.0/33")
+	assert.True(perr.IsBadRequest(err))
+}
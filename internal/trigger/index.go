@@ -42,6 +42,12 @@ type TriggerRunner interface {
 	GetTriggerResponse([]*event.PipelineQueue) (types.TriggerExecutionResponse, error)
 }
 
+// PipelineQueuedNotifier is implemented by the trigger runners that record the progress of their fan-out, they are
+// notified of each of their pipelines once it's queued
+type PipelineQueuedNotifier interface {
+	PipelineQueued(cmd *event.PipelineQueue) error
+}
+
 func NewTriggerRunner(trigger *resources.Trigger, executionID, triggerExecutionID string) TriggerRunner {
 
 	switch trigger.Config.(type) {
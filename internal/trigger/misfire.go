@@ -0,0 +1,57 @@
+package trigger
+
+import (
+	"time"
+
+	"github.com/turbot/flowpipe/internal/resources"
+)
+
+// MaxMisfireFirings caps the missed occurrences fired by the fire_all policy, only the most recent ones are fired
+const MaxMisfireFirings = 100
+
+// MissedOccurrences returns the occurrences of the schedule trigger after its last firing and up to now, oldest first,
+// and the number of missed occurrences. The occurrences on the excluded dates are not missed. At most
+// MaxMisfireFirings occurrences, the most recent ones, are returned.
+func MissedOccurrences(t *resources.Trigger, lastFired, now time.Time) ([]time.Time, int, error) {
+	if _, ok := t.Config.(*resources.TriggerSchedule); !ok {
+		return nil, 0, nil
+	}
+
+	cronSchedule, options, err := parseSchedule(t)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var occurrences []time.Time
+	count := 0
+	for next := cronSchedule.Next(lastFired); !next.IsZero() && !next.After(now); next = cronSchedule.Next(next) {
+		if options.IsExcluded(next) {
+			continue
+		}
+
+		count++
+		occurrences = append(occurrences, next)
+		if len(occurrences) > MaxMisfireFirings {
+			occurrences = occurrences[1:]
+		}
+	}
+
+	return occurrences, count, nil
+}
+
+// MisfireFirings returns the missed occurrences to fire according to the misfire policy: none, the latest one, or all
+// of them
+func MisfireFirings(policy string, missed []time.Time) []time.Time {
+	if len(missed) == 0 {
+		return nil
+	}
+
+	switch policy {
+	case resources.MisfirePolicyFireOnce:
+		return missed[len(missed)-1:]
+	case resources.MisfirePolicyFireAll:
+		return missed
+	default:
+		return nil
+	}
+}
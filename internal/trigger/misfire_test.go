@@ -0,0 +1,67 @@
+package trigger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/pipe-fittings/modconfig"
+)
+
+func TestMissedOccurrences(t *testing.T) {
+	assert := assert.New(t)
+
+	trigger := &resources.Trigger{
+		HclResourceImpl: modconfig.HclResourceImpl{
+			FullName: "local.trigger.schedule.end_of_day",
+		},
+		Config: &resources.TriggerSchedule{
+			Schedule:      "0 23 * * *",
+			MisfirePolicy: resources.MisfirePolicyFireAll,
+		},
+	}
+
+	lastFired := time.Date(2024, 3, 1, 23, 0, 0, 100, time.UTC)
+
+	// down for less than a day, nothing missed
+	missed, count, err := MissedOccurrences(trigger, lastFired, lastFired.Add(20*time.Hour))
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal(0, count)
+	assert.Empty(missed)
+
+	// down over three end of days, the occurrence at now is missed too
+	missed, count, err = MissedOccurrences(trigger, lastFired, time.Date(2024, 3, 4, 23, 0, 0, 0, time.UTC))
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal(3, count)
+	assert.Equal([]time.Time{
+		time.Date(2024, 3, 2, 23, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 3, 23, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 4, 23, 0, 0, 0, time.UTC),
+	}, missed)
+
+	assert.Empty(MisfireFirings(resources.MisfirePolicySkip, missed))
+	assert.Equal(missed[2:], MisfireFirings(resources.MisfirePolicyFireOnce, missed))
+	assert.Equal(missed, MisfireFirings(resources.MisfirePolicyFireAll, missed))
+
+	// the intervals are missed too, only the most recent occurrences are kept
+	trigger.Config = &resources.TriggerSchedule{Schedule: "5m"}
+	missed, count, err = MissedOccurrences(trigger, lastFired, lastFired.Add(24*time.Hour))
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal(288, count)
+	assert.Equal(MaxMisfireFirings, len(missed))
+	assert.True(missed[len(missed)-1].After(lastFired.Add(23 * time.Hour)))
+
+	// not a schedule trigger
+	trigger.Config = &resources.TriggerHttp{}
+	missed, count, err = MissedOccurrences(trigger, lastFired, lastFired.Add(24*time.Hour))
+	assert.Nil(err)
+	assert.Equal(0, count)
+	assert.Nil(missed)
+}
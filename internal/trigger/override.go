@@ -0,0 +1,55 @@
+package trigger
+
+import (
+	"time"
+
+	"github.com/turbot/flowpipe/internal/constants"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/flowpipe/internal/store"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+// IsEnabled returns true if the trigger is enabled, by its definition or by its runtime override, which may be nil.
+// A paused trigger is enabled, it stays scheduled but doesn't fire.
+func IsEnabled(t *resources.Trigger, override *store.TriggerOverride) bool {
+	if override != nil {
+		switch override.State {
+		case constants.TriggerOverrideEnabled:
+			return true
+		case constants.TriggerOverrideDisabled:
+			return false
+		}
+	}
+
+	return t.Enabled == nil || *t.Enabled
+}
+
+// IsPaused returns true if the runtime override of the trigger pauses it
+func IsPaused(override *store.TriggerOverride) bool {
+	return override != nil && override.State == constants.TriggerOverridePaused
+}
+
+// IsFiring returns true if the trigger is enabled and not paused
+func IsFiring(t *resources.Trigger, override *store.TriggerOverride) bool {
+	return IsEnabled(t, override) && !IsPaused(override)
+}
+
+// SetOverride applies the enable, disable or pause command to the trigger, until the given time if not nil. Enabling
+// a trigger enabled by its definition, with no end time, removes the runtime override.
+func SetOverride(t *resources.Trigger, state string, until *time.Time) error {
+	if until != nil && !until.After(time.Now()) {
+		return perr.BadRequestWithMessage("the until time must be in the future")
+	}
+
+	switch state {
+	case constants.TriggerOverrideEnabled:
+		if until == nil && IsEnabled(t, nil) {
+			return store.DeleteTriggerOverride(t.Name())
+		}
+	case constants.TriggerOverrideDisabled, constants.TriggerOverridePaused:
+	default:
+		return perr.BadRequestWithMessage("invalid trigger state: " + state)
+	}
+
+	return store.SetTriggerOverride(t.Name(), state, until)
+}
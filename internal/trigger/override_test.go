@@ -0,0 +1,86 @@
+package trigger
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/flowpipe/internal/constants"
+	"github.com/turbot/flowpipe/internal/filepaths"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/flowpipe/internal/store"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+func TestTriggerOverride(t *testing.T) {
+	assert := assert.New(t)
+
+	flowpipeDbFilename := filepaths.FlowpipeDBFileName()
+	_, err := os.Stat(flowpipeDbFilename)
+	if !os.IsNotExist(err) {
+		err = os.Remove(flowpipeDbFilename)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	err = store.InitializeFlowpipeDB()
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	disabled := false
+	trigger := &resources.Trigger{
+		HclResourceImpl: modconfig.HclResourceImpl{
+			FullName: "local.trigger.schedule.test_override",
+		},
+		Enabled: &disabled,
+	}
+
+	assert.False(IsEnabled(trigger, nil))
+	assert.False(IsFiring(trigger, nil))
+
+	// enabled at runtime, whatever the definition says
+	assert.Nil(SetOverride(trigger, constants.TriggerOverrideEnabled, nil))
+	override, err := store.GetTriggerOverride(trigger.Name())
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.True(IsEnabled(trigger, override))
+	assert.True(IsFiring(trigger, override))
+
+	// paused triggers stay enabled, they just don't fire
+	until := time.Now().Add(time.Hour)
+	assert.Nil(SetOverride(trigger, constants.TriggerOverridePaused, &until))
+	override, err = store.GetTriggerOverride(trigger.Name())
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.True(IsPaused(override))
+	assert.False(IsFiring(trigger, override))
+	assert.False(IsEnabled(trigger, override), "pausing a trigger disabled by its definition doesn't enable it")
+
+	// enabling a trigger enabled by its definition removes the override
+	trigger.Enabled = nil
+	assert.Nil(SetOverride(trigger, constants.TriggerOverrideDisabled, nil))
+	override, err = store.GetTriggerOverride(trigger.Name())
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.False(IsEnabled(trigger, override))
+
+	assert.Nil(SetOverride(trigger, constants.TriggerOverrideEnabled, nil))
+	override, err = store.GetTriggerOverride(trigger.Name())
+	assert.Nil(err)
+	assert.Nil(override)
+	assert.True(IsFiring(trigger, override))
+
+	past := time.Now().Add(-time.Minute)
+	err = SetOverride(trigger, constants.TriggerOverrideDisabled, &past)
+	assert.True(perr.IsBadRequest(err))
+
+	err = SetOverride(trigger, "stopped", nil)
+	assert.True(perr.IsBadRequest(err))
+}
@@ -3,7 +3,6 @@ package trigger
 import (
 	"context"
 	"crypto/sha256"
-	"database/sql"
 	"encoding/hex"
 	"fmt"
 	"github.com/turbot/flowpipe/internal/resources"
@@ -30,6 +29,9 @@ import (
 
 type TriggerRunnerQuery struct {
 	TriggerRunnerBase
+
+	// the batches of the batched captures, keyed by pipeline execution id
+	batches map[string]*queryTriggerBatch
 }
 
 type queryTriggerMetadata struct {
@@ -125,7 +127,9 @@ func queuePipeline(capture *resources.TriggerQueryCapture, executionID string, t
 	return pipelineCmd, nil
 }
 
-func calculatedNewUpdatedDeletedData(db *sql.DB, triggerName string, controlItems []queryTriggerMetadata) ([]string, []string, []string, error) {
+// calculatedNewUpdatedDeletedData records the rows in the control table and returns the primary keys of the new, updated
+// and deleted rows. The deleted rows are only detected with detectDeleted, when the rows are the full query result.
+func calculatedNewUpdatedDeletedData(db *store.DB, triggerName string, controlItems []queryTriggerMetadata, detectDeleted bool) ([]string, []string, []string, error) {
 	if len(controlItems) == 0 {
 		return nil, nil, nil, nil
 	}
@@ -191,6 +195,24 @@ func calculatedNewUpdatedDeletedData(db *sql.DB, triggerName string, controlItem
 
 	slog.Debug("updatedItems", "updatedItems", updatedItems)
 
+	if !detectDeleted {
+		_, err = tx.Exec(`drop table ` + triggerName + `_temp_items`)
+		if err != nil {
+			err2 := tx.Rollback()
+			if err2 != nil {
+				slog.Error("Error rolling back transaction", "error", err2)
+			}
+			return nil, nil, nil, err
+		}
+
+		if err := tx.Commit(); err != nil {
+			slog.Error("Error committing transaction", "error", err)
+			return nil, nil, nil, err
+		}
+
+		return newItems, updatedItems, nil, nil
+	}
+
 	// Find deleted items by comparing with the main table
 	//nolint:gosec // TODO: investigate string concat
 	deletedItemsSQL := `select primary_key from query_trigger_captured_row
@@ -261,7 +283,7 @@ func calculatedNewUpdatedDeletedData(db *sql.DB, triggerName string, controlItem
 	return newItems, updatedItems, deletedItems, nil
 }
 
-func insertNewItems(tx *sql.Tx, triggerName string) ([]string, error) {
+func insertNewItems(tx *store.Tx, triggerName string) ([]string, error) {
 	// Find new items by comparing with the main table
 	//nolint:gosec // TODO: investigate string concat
 	newItemsSQL := `
@@ -288,7 +310,7 @@ func insertNewItems(tx *sql.Tx, triggerName string) ([]string, error) {
 	return newItems, nil
 }
 
-func updatedItems(tx *sql.Tx, triggerName string) ([]string, error) {
+func updatedItems(tx *store.Tx, triggerName string) ([]string, error) {
 
 	sourceTable := triggerName + "_temp_items"
 
@@ -387,6 +409,24 @@ func (tr *TriggerRunnerQuery) execute(ctx context.Context, executionID string, t
 		schema.AttributeTypeDatabase: resolvedTriggerConfig.Database,
 	}
 
+	// in watermark mode the query only returns the rows changed since the greatest watermark seen so far
+	var watermark *queryWatermark
+	if resolvedTriggerConfig.WatermarkColumn != "" {
+		watermark, err = loadQueryWatermark(tr.Trigger.Name())
+		if err != nil {
+			slog.Error("Error loading trigger watermark", "trigger", tr.Trigger.Name(), "error", err)
+			return nil, err
+		}
+
+		bindValue, err := watermark.bindValue()
+		if err != nil {
+			return nil, perr.InternalWithMessage("invalid watermark for trigger " + tr.Trigger.Name() + ": " + err.Error())
+		}
+
+		slog.Debug("Running query trigger from watermark", "trigger", tr.Trigger.Name(), "watermark", bindValue)
+		input[schema.AttributeTypeArgs] = []interface{}{bindValue}
+	}
+
 	output, _, err := queryPrimitive.RunWithMetadata(context.Background(), input)
 	if err != nil {
 		slog.Error("Error running trigger query", "error", err)
@@ -396,6 +436,16 @@ func (tr *TriggerRunnerQuery) execute(ctx context.Context, executionID string, t
 		return nil, err
 	}
 
+	batched := false
+	for _, capture := range resolvedTriggerConfig.Captures {
+		batched = batched || capture.IsBatched()
+	}
+
+	// the rows left pending by the previous runs of batched captures are delivered even if the query returns nothing
+	if output.Data["rows"] == nil && batched {
+		output.Data["rows"] = []map[string]interface{}{}
+	}
+
 	if output.Data["rows"] == nil {
 		slog.Info("No rows returned from trigger query", "trigger", tr.Trigger.Name())
 		if o.IsServerMode {
@@ -464,6 +514,18 @@ func (tr *TriggerRunnerQuery) execute(ctx context.Context, executionID string, t
 		}
 	}
 
+	var nextWatermark *queryWatermark
+	if resolvedTriggerConfig.WatermarkColumn != "" {
+		nextWatermark, err = maxQueryWatermark(watermark, rows, resolvedTriggerConfig.WatermarkColumn)
+		if err != nil {
+			slog.Error("Error calculating trigger watermark", "trigger", tr.Trigger.Name(), "error", err)
+			if o.IsServerMode {
+				o.RenderServerOutput(context.TODO(), types.NewServerOutputError(types.NewServerOutputPrefix(time.Now(), "flowpipe"), "error calculating watermark of query trigger "+tr.Trigger.Name(), err))
+			}
+			return nil, err
+		}
+	}
+
 	safeTriggerName := strings.ReplaceAll(tr.Trigger.FullName, ".", "_")
 
 	db, err := store.OpenFlowpipeDB()
@@ -473,12 +535,21 @@ func (tr *TriggerRunnerQuery) execute(ctx context.Context, executionID string, t
 	}
 	defer db.Close()
 
-	newItemPrimaryKeys, updatedItemPrimaryKeys, deletedPrimaryKeys, err := calculatedNewUpdatedDeletedData(db, safeTriggerName, controlItems)
+	newItemPrimaryKeys, updatedItemPrimaryKeys, deletedPrimaryKeys, err := calculatedNewUpdatedDeletedData(db, safeTriggerName, controlItems, resolvedTriggerConfig.WatermarkColumn == "")
 	if err != nil {
 		slog.Error("Error storing slice", "error", err)
 		return nil, err
 	}
 
+	// a query using >= returns the rows at the watermark again, the control table filters out the unchanged ones
+	if nextWatermark != nil {
+		err = saveQueryWatermark(tr.Trigger.Name(), nextWatermark)
+		if err != nil {
+			slog.Error("Error saving trigger watermark", "trigger", tr.Trigger.Name(), "error", err)
+			return nil, err
+		}
+	}
+
 	newRows := []map[string]interface{}{}
 	for _, k := range newItemPrimaryKeys {
 		slog.Debug("New item key", "key", k)
@@ -555,12 +626,41 @@ func (tr *TriggerRunnerQuery) execute(ctx context.Context, executionID string, t
 	}
 
 	slog.Info("Trigger stats", "stats", queryStat)
+	err = store.SetTriggerRunCaptureCounts(executionID, queryStat["insert"], queryStat["update"], queryStat["delete"])
+	if err != nil {
+		slog.Error("Error recording trigger run capture counts", "trigger", tr.Trigger.Name(), "error", err)
+	}
 	if o.IsServerMode {
 		o.RenderServerOutput(context.TODO(), types.NewServerOutputQueryTriggerRun(tr.Trigger.Name(), len(newRows), len(updatedRows), len(deletedPrimaryKeys)))
 	}
 
 	var pipelineCmds []*event.PipelineQueue
 	for _, capture := range resolvedTriggerConfig.Captures {
+		if capture.IsBatched() {
+			var captured []store.QueryTriggerPendingRow
+			switch capture.Type {
+			case "insert":
+				captured, err = pendingRowsFromCaptured(newItemPrimaryKeys, primaryKeyRowMap)
+			case "update":
+				captured, err = pendingRowsFromCaptured(updatedItemPrimaryKeys, primaryKeyRowMap)
+			case "delete":
+				captured, err = pendingRowsFromCaptured(deletedPrimaryKeys, nil)
+			}
+			if err != nil {
+				slog.Error("Error encoding captured rows", "error", err)
+				return nil, err
+			}
+
+			cmds, err := tr.queueBatchedPipelines(capture, executionID, evalContext, selfVars, captured)
+			if err != nil {
+				slog.Error("Error running pipeline", "error", err)
+				return nil, err
+			}
+
+			pipelineCmds = append(pipelineCmds, cmds...)
+			continue
+		}
+
 		cmd, err := queuePipeline(capture, executionID, tr, evalContext, queryStat)
 		if err != nil {
 			slog.Error("Error running pipeline", "error", err)
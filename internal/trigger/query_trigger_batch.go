@@ -0,0 +1,153 @@
+package trigger
+
+import (
+	"encoding/json"
+	"log/slog"
+	"maps"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/flowpipe/internal/es/event"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/flowpipe/internal/store"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/perr"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// the self attribute holding the rows of each capture type
+var captureRowsAttribute = map[string]string{
+	"insert": "inserted_rows",
+	"update": "updated_rows",
+	"delete": "deleted_rows",
+}
+
+// queryTriggerBatch is the rows of a capture delivered to one pipeline run. They are removed from the pending rows once
+// the pipeline is queued, so a crash during the fan-out only delivers the batches that were not queued yet again.
+type queryTriggerBatch struct {
+	captureType string
+	primaryKeys []string
+}
+
+// pendingRowsFromCaptured encodes the captured rows for the pending rows table, rows being nil for the delete capture
+func pendingRowsFromCaptured(primaryKeys []string, rows map[string]interface{}) ([]store.QueryTriggerPendingRow, error) {
+	var pending []store.QueryTriggerPendingRow
+
+	for _, pk := range primaryKeys {
+		var value interface{} = pk
+		if rows != nil {
+			value = rows[pk]
+			if value == nil {
+				continue
+			}
+		}
+
+		// encoded through cty so the rows are the same whether they're delivered right away or in a later run
+		ctyVal, err := hclhelpers.ConvertInterfaceToCtyValue(value)
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := hclhelpers.CtyToJSON(ctyVal)
+		if err != nil {
+			return nil, err
+		}
+
+		pending = append(pending, store.QueryTriggerPendingRow{PrimaryKey: pk, Row: encoded})
+	}
+
+	return pending, nil
+}
+
+// queueBatchedPipelines adds the captured rows to the pending rows of the capture, then queues a pipeline run per batch
+// of the oldest pending rows, up to max_rows_per_run rows. self.batch describes the batch of the run.
+func (tr *TriggerRunnerQuery) queueBatchedPipelines(capture *resources.TriggerQueryCapture, executionID string, evalContext *hcl.EvalContext, selfVars map[string]cty.Value, captured []store.QueryTriggerPendingRow) ([]*event.PipelineQueue, error) {
+	triggerName := tr.Trigger.Name()
+
+	err := store.AddQueryTriggerPendingRows(triggerName, capture.Type, captured)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, total, err := store.ListQueryTriggerPendingRows(triggerName, capture.Type, capture.MaxRowsPerRun)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	batchSize := capture.BatchSize
+	if batchSize == 0 {
+		batchSize = len(pending)
+	}
+	batchCount := (len(pending) + batchSize - 1) / batchSize
+
+	slog.Info("Queueing trigger batches", "trigger", triggerName, "capture_type", capture.Type, "pending", total, "rows", len(pending), "batches", batchCount)
+
+	originalSelf := evalContext.Variables["self"]
+	defer func() {
+		evalContext.Variables["self"] = originalSelf
+	}()
+
+	var pipelineCmds []*event.PipelineQueue
+	for i := 0; i < batchCount; i++ {
+		batchRows := pending[i*batchSize : min((i+1)*batchSize, len(pending))]
+
+		var rows []interface{}
+		batch := &queryTriggerBatch{captureType: capture.Type}
+		for _, r := range batchRows {
+			var row interface{}
+			err := json.Unmarshal([]byte(r.Row), &row)
+			if err != nil {
+				return nil, perr.InternalWithMessage("invalid pending row " + r.PrimaryKey + ": " + err.Error())
+			}
+			rows = append(rows, row)
+			batch.primaryKeys = append(batch.primaryKeys, r.PrimaryKey)
+		}
+
+		rowsCty, err := hclhelpers.ConvertInterfaceToCtyValue(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		batchSelf := maps.Clone(selfVars)
+		batchSelf[captureRowsAttribute[capture.Type]] = rowsCty
+		batchSelf["batch"] = cty.ObjectVal(map[string]cty.Value{
+			"number": cty.NumberIntVal(int64(i + 1)),
+			"count":  cty.NumberIntVal(int64(batchCount)),
+			"size":   cty.NumberIntVal(int64(len(batchRows))),
+			// the rows left for the next trigger runs
+			"remaining": cty.NumberIntVal(int64(total - len(pending))),
+		})
+		evalContext.Variables["self"] = cty.ObjectVal(batchSelf)
+
+		cmd, err := queuePipeline(capture, executionID, tr, evalContext, map[string]int{capture.Type: len(batchRows)})
+		if err != nil {
+			return nil, err
+		}
+
+		if tr.batches == nil {
+			tr.batches = map[string]*queryTriggerBatch{}
+		}
+		tr.batches[cmd.PipelineExecutionID] = batch
+		pipelineCmds = append(pipelineCmds, cmd)
+	}
+
+	return pipelineCmds, nil
+}
+
+// PipelineQueued records the delivery of the batch of the queued pipeline
+func (tr *TriggerRunnerQuery) PipelineQueued(cmd *event.PipelineQueue) error {
+	batch, ok := tr.batches[cmd.PipelineExecutionID]
+	if !ok {
+		return nil
+	}
+
+	err := store.DeleteQueryTriggerPendingRows(tr.Trigger.Name(), batch.captureType, batch.primaryKeys)
+	if err != nil {
+		return err
+	}
+
+	delete(tr.batches, cmd.PipelineExecutionID)
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -1712,3 +1713,319 @@ func updateTestTableB(db *sql.DB, tableName string, data map[string]interface{})
 
 	return nil
 }
+
+func TestTriggerQueryWatermark(t *testing.T) {
+	ctx := context.Background()
+
+	assert := assert.New(t)
+
+	sourceDbFilename := filepath.Join(t.TempDir(), "test_trigger_query_watermark.db")
+
+	db, err := sql.Open("sqlite3", sourceDbFilename)
+	if err != nil {
+		assert.Fail("Error initializing db", err)
+		return
+	}
+	defer db.Close()
+
+	flowpipeDbFilename := filepaths.FlowpipeDBFileName()
+
+	_, err = os.Stat(flowpipeDbFilename)
+	if !os.IsNotExist(err) {
+		err = os.Remove(flowpipeDbFilename)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	err = store.InitializeFlowpipeDB()
+	if err != nil {
+		assert.Fail("Error initializing db", err)
+		return
+	}
+
+	_, err = db.Exec(`create table orders (id text primary key, status text, updated_at integer)`)
+	if err != nil {
+		assert.Fail("Error creating test table", err)
+		return
+	}
+
+	_, err = db.Exec(`insert into orders (id, status, updated_at) values ('1', 'new', 100), ('2', 'new', 200)`)
+	if err != nil {
+		assert.Fail("Error populating test table", err)
+		return
+	}
+
+	var generatedEvalContext *hcl.EvalContext
+	hclExpressionMock := &util.HclExpressionMock{
+		ValueFunc: func(evalCtx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+			generatedEvalContext = evalCtx
+			return cty.ObjectVal(map[string]cty.Value{}), nil
+		},
+	}
+
+	trigger := &resources.Trigger{
+		HclResourceImpl: modconfig.HclResourceImpl{
+			FullName: "query.test_trigger_watermark",
+		},
+		ArgsRaw: hclExpressionMock,
+	}
+
+	trigger.Config = &resources.TriggerQuery{
+		Database:        "sqlite:" + sourceDbFilename,
+		Sql:             "select * from orders where updated_at >= coalesce(?, 0)",
+		PrimaryKey:      "id",
+		WatermarkColumn: "updated_at",
+		Captures: map[string]*resources.TriggerQueryCapture{
+			"insert": {
+				Type:     "insert",
+				Pipeline: cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("insert_pipe")}),
+				ArgsRaw:  hclExpressionMock,
+			},
+			"update": {
+				Type:     "update",
+				Pipeline: cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("update_pipe")}),
+				ArgsRaw:  hclExpressionMock,
+			},
+		},
+	}
+
+	cache.GetCache().SetWithTTL(trigger.Name(), trigger, 10*time.Minute)
+
+	triggerRunner := NewTriggerRunner(trigger, util.NewExecutionId(), util.NewTriggerExecutionId())
+
+	// first run, no watermark yet: all the rows are inserted
+	pipelineQueues, err := triggerRunner.GetPipelineQueuesWithArgs(ctx, nil, nil)
+	if err != nil {
+		assert.Fail("Error executing trigger", err)
+		return
+	}
+
+	assert.Equal(1, len(pipelineQueues))
+	assert.Equal("insert_pipe", pipelineQueues[0].Name)
+	assert.Equal(2, len(generatedEvalContext.Variables["self"].AsValueMap()["inserted_rows"].AsValueSlice()))
+
+	watermark, err := loadQueryWatermark(trigger.Name())
+	assert.Nil(err)
+	assert.Equal(&queryWatermark{Type: watermarkTypeInt, Value: "200"}, watermark)
+
+	// the row at the watermark is fetched again but hasn't changed
+	pipelineQueues, err = triggerRunner.GetPipelineQueuesWithArgs(ctx, nil, nil)
+	if err != nil {
+		assert.Fail("Error executing trigger", err)
+		return
+	}
+	assert.Equal(0, len(pipelineQueues))
+
+	// rows older than the watermark are not fetched, so their deletion isn't seen either
+	_, err = db.Exec(`update orders set status = 'shipped', updated_at = 300 where id = '2'`)
+	assert.Nil(err)
+	_, err = db.Exec(`insert into orders (id, status, updated_at) values ('3', 'new', 300)`)
+	assert.Nil(err)
+	_, err = db.Exec(`delete from orders where id = '1'`)
+	assert.Nil(err)
+
+	generatedEvalContext = nil
+	pipelineQueues, err = triggerRunner.GetPipelineQueuesWithArgs(ctx, nil, nil)
+	if err != nil {
+		assert.Fail("Error executing trigger", err)
+		return
+	}
+
+	assert.Equal(2, len(pipelineQueues))
+
+	selfVarMap := generatedEvalContext.Variables["self"].AsValueMap()
+	insertedRows := selfVarMap["inserted_rows"].AsValueSlice()
+	updatedRows := selfVarMap["updated_rows"].AsValueSlice()
+	assert.Equal(1, len(insertedRows))
+	assert.Equal("3", insertedRows[0].AsValueMap()["id"].AsString())
+	assert.Equal(1, len(updatedRows))
+	assert.Equal("shipped", updatedRows[0].AsValueMap()["status"].AsString())
+	assert.Equal(0, len(selfVarMap["deleted_rows"].AsValueSlice()))
+
+	watermark, err = loadQueryWatermark(trigger.Name())
+	assert.Nil(err)
+	assert.Equal("300", watermark.Value)
+}
+
+func TestMaxQueryWatermark(t *testing.T) {
+	assert := assert.New(t)
+
+	older := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	w, err := maxQueryWatermark(nil, []map[string]interface{}{
+		{"updated_at": older},
+		{"updated_at": nil},
+		{"updated_at": newer},
+	}, "updated_at")
+	assert.Nil(err)
+	assert.Equal(&queryWatermark{Type: watermarkTypeTime, Value: "2024-01-01T11:00:00Z"}, w)
+
+	bindValue, err := w.bindValue()
+	assert.Nil(err)
+	assert.Equal(newer, bindValue)
+
+	// the watermark never goes backwards
+	w, err = maxQueryWatermark(w, []map[string]interface{}{{"updated_at": older}}, "updated_at")
+	assert.Nil(err)
+	assert.Equal("2024-01-01T11:00:00Z", w.Value)
+
+	_, err = maxQueryWatermark(w, []map[string]interface{}{{"updated_at": "2024-01-02"}}, "updated_at")
+	assert.NotNil(err, "the watermark column can't change type")
+
+	_, err = maxQueryWatermark(nil, []map[string]interface{}{{"id": 1}}, "updated_at")
+	assert.NotNil(err, "the watermark column must be returned by the query")
+}
+
+func TestTriggerQueryBatch(t *testing.T) {
+	ctx := context.Background()
+
+	assert := assert.New(t)
+
+	sourceDbFilename := filepath.Join(t.TempDir(), "test_trigger_query_batch.db")
+
+	db, err := sql.Open("sqlite3", sourceDbFilename)
+	if err != nil {
+		assert.Fail("Error initializing db", err)
+		return
+	}
+	defer db.Close()
+
+	flowpipeDbFilename := filepaths.FlowpipeDBFileName()
+
+	_, err = os.Stat(flowpipeDbFilename)
+	if !os.IsNotExist(err) {
+		err = os.Remove(flowpipeDbFilename)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	err = store.InitializeFlowpipeDB()
+	if err != nil {
+		assert.Fail("Error initializing db", err)
+		return
+	}
+
+	err = createTestTableA(db, "test_batch")
+	if err != nil {
+		assert.Fail("Error creating test table", err)
+		return
+	}
+
+	var data []map[string]interface{}
+	for i := 1; i <= 7; i++ {
+		data = append(data, map[string]interface{}{
+			"id":                fmt.Sprintf("%d", i),
+			"name":              fmt.Sprintf("name_%d", i),
+			"age":               20 + i,
+			"registration_date": "2020-01-01",
+			"is_active":         true,
+		})
+	}
+
+	err = populateTestTableA(db, "test_batch", data)
+	if err != nil {
+		assert.Fail("Error populating test table", err)
+		return
+	}
+
+	var selfVars []map[string]cty.Value
+	hclExpressionMock := &util.HclExpressionMock{
+		ValueFunc: func(evalCtx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+			selfVars = append(selfVars, evalCtx.Variables["self"].AsValueMap())
+			return cty.ObjectVal(map[string]cty.Value{}), nil
+		},
+	}
+
+	trigger := &resources.Trigger{
+		HclResourceImpl: modconfig.HclResourceImpl{
+			FullName: "query.test_trigger_batch",
+		},
+		ArgsRaw: &util.HclExpressionMock{
+			ValueFunc: func(evalCtx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+				return cty.ObjectVal(map[string]cty.Value{}), nil
+			},
+		},
+	}
+
+	trigger.Config = &resources.TriggerQuery{
+		Database:   "sqlite:" + sourceDbFilename,
+		Sql:        "select * from test_batch",
+		PrimaryKey: "id",
+		Captures: map[string]*resources.TriggerQueryCapture{
+			"insert": {
+				Type:          "insert",
+				Pipeline:      cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("insert_pipe")}),
+				ArgsRaw:       hclExpressionMock,
+				BatchSize:     3,
+				MaxRowsPerRun: 5,
+			},
+		},
+	}
+
+	cache.GetCache().SetWithTTL(trigger.Name(), trigger, 10*time.Minute)
+
+	batchIds := func(self map[string]cty.Value) []string {
+		var ids []string
+		for _, row := range self["inserted_rows"].AsValueSlice() {
+			ids = append(ids, row.AsValueMap()["id"].AsString())
+		}
+		return ids
+	}
+
+	// first run: 5 of the 7 rows, in batches of 3 and 2
+	triggerRunner := NewTriggerRunner(trigger, util.NewExecutionId(), util.NewTriggerExecutionId())
+	pipelineQueues, err := triggerRunner.GetPipelineQueuesWithArgs(ctx, nil, nil)
+	if err != nil {
+		assert.Fail("Error executing trigger", err)
+		return
+	}
+
+	assert.Equal(2, len(pipelineQueues))
+	assert.Equal([]string{"1", "2", "3"}, batchIds(selfVars[0]))
+	assert.Equal([]string{"4", "5"}, batchIds(selfVars[1]))
+
+	batch := selfVars[1]["batch"].AsValueMap()
+	assert.Equal(int64(2), util.BigFloatToInt64(batch["number"].AsBigFloat()))
+	assert.Equal(int64(2), util.BigFloatToInt64(batch["count"].AsBigFloat()))
+	assert.Equal(int64(2), util.BigFloatToInt64(batch["size"].AsBigFloat()))
+	assert.Equal(int64(2), util.BigFloatToInt64(batch["remaining"].AsBigFloat()))
+
+	// only the first batch is queued before a crash
+	notifier, ok := triggerRunner.(PipelineQueuedNotifier)
+	if !ok {
+		assert.Fail("query trigger runner should be notified of the queued pipelines")
+		return
+	}
+	assert.Nil(notifier.PipelineQueued(pipelineQueues[0]))
+
+	// second run: no new rows, the rows of the batch that wasn't queued are delivered again with the remaining ones
+	selfVars = nil
+	triggerRunner = NewTriggerRunner(trigger, util.NewExecutionId(), util.NewTriggerExecutionId())
+	pipelineQueues, err = triggerRunner.GetPipelineQueuesWithArgs(ctx, nil, nil)
+	if err != nil {
+		assert.Fail("Error executing trigger", err)
+		return
+	}
+
+	assert.Equal(2, len(pipelineQueues))
+	assert.Equal([]string{"4", "5", "6"}, batchIds(selfVars[0]))
+	assert.Equal([]string{"7"}, batchIds(selfVars[1]))
+	assert.Equal("name_7", selfVars[1]["inserted_rows"].AsValueSlice()[0].AsValueMap()["name"].AsString())
+
+	notifier = triggerRunner.(PipelineQueuedNotifier)
+	for _, pipelineQueue := range pipelineQueues {
+		assert.Nil(notifier.PipelineQueued(pipelineQueue))
+	}
+
+	// third run: everything was delivered
+	triggerRunner = NewTriggerRunner(trigger, util.NewExecutionId(), util.NewTriggerExecutionId())
+	pipelineQueues, err = triggerRunner.GetPipelineQueuesWithArgs(ctx, nil, nil)
+	if err != nil {
+		assert.Fail("Error executing trigger", err)
+		return
+	}
+	assert.Equal(0, len(pipelineQueues))
+}
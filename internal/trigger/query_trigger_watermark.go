@@ -0,0 +1,168 @@
+package trigger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turbot/flowpipe/internal/store"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+const (
+	watermarkTypeTime   = "time"
+	watermarkTypeInt    = "int"
+	watermarkTypeFloat  = "float"
+	watermarkTypeString = "string"
+)
+
+// queryWatermark is the greatest value of the watermark column of a query trigger seen so far. It's persisted with its
+// type, so it's bound to the next query with the type the database returned it with.
+type queryWatermark struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func newQueryWatermark(value any) (*queryWatermark, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return &queryWatermark{Type: watermarkTypeTime, Value: v.UTC().Format(time.RFC3339Nano)}, nil
+	case int:
+		return &queryWatermark{Type: watermarkTypeInt, Value: strconv.FormatInt(int64(v), 10)}, nil
+	case int32:
+		return &queryWatermark{Type: watermarkTypeInt, Value: strconv.FormatInt(int64(v), 10)}, nil
+	case int64:
+		return &queryWatermark{Type: watermarkTypeInt, Value: strconv.FormatInt(v, 10)}, nil
+	case float32:
+		return &queryWatermark{Type: watermarkTypeFloat, Value: strconv.FormatFloat(float64(v), 'g', -1, 64)}, nil
+	case float64:
+		return &queryWatermark{Type: watermarkTypeFloat, Value: strconv.FormatFloat(v, 'g', -1, 64)}, nil
+	case string:
+		return &queryWatermark{Type: watermarkTypeString, Value: v}, nil
+	case []byte:
+		return &queryWatermark{Type: watermarkTypeString, Value: string(v)}, nil
+	default:
+		return nil, perr.BadRequestWithMessage(fmt.Sprintf("unsupported watermark column type %T, the watermark column must be a timestamp, a number or a string", value))
+	}
+}
+
+// bindValue is the value bound to the query parameter
+func (w *queryWatermark) bindValue() (any, error) {
+	if w == nil {
+		return nil, nil
+	}
+
+	switch w.Type {
+	case watermarkTypeTime:
+		return time.Parse(time.RFC3339Nano, w.Value)
+	case watermarkTypeInt:
+		return strconv.ParseInt(w.Value, 10, 64)
+	case watermarkTypeFloat:
+		return strconv.ParseFloat(w.Value, 64)
+	default:
+		return w.Value, nil
+	}
+}
+
+// after returns true if w is greater than other, a nil watermark is lower than any value
+func (w *queryWatermark) after(other *queryWatermark) (bool, error) {
+	if other == nil {
+		return true, nil
+	}
+
+	a, err := w.bindValue()
+	if err != nil {
+		return false, perr.InternalWithMessage("invalid watermark " + w.Value + ": " + err.Error())
+	}
+	b, err := other.bindValue()
+	if err != nil {
+		return false, perr.InternalWithMessage("invalid watermark " + other.Value + ": " + err.Error())
+	}
+
+	switch a := a.(type) {
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.After(b), nil
+		}
+	case int64:
+		switch b := b.(type) {
+		case int64:
+			return a > b, nil
+		case float64:
+			return float64(a) > b, nil
+		}
+	case float64:
+		switch b := b.(type) {
+		case int64:
+			return a > float64(b), nil
+		case float64:
+			return a > b, nil
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b) > 0, nil
+		}
+	}
+
+	return false, perr.BadRequestWithMessage("the watermark column changed type from " + other.Type + " to " + w.Type)
+}
+
+// maxQueryWatermark returns the greatest of the current watermark and the values of the watermark column in the rows.
+// The rows with a null watermark are ignored.
+func maxQueryWatermark(current *queryWatermark, rows []map[string]interface{}, column string) (*queryWatermark, error) {
+	result := current
+
+	for _, row := range rows {
+		value, ok := row[column]
+		if !ok {
+			return nil, perr.BadRequestWithMessage("watermark column " + column + " not found in query row")
+		}
+
+		if helpers.IsNil(value) {
+			continue
+		}
+
+		w, err := newQueryWatermark(value)
+		if err != nil {
+			return nil, err
+		}
+
+		after, err := w.after(result)
+		if err != nil {
+			return nil, err
+		}
+		if after {
+			result = w
+		}
+	}
+
+	return result, nil
+}
+
+// loadQueryWatermark returns the persisted watermark of the trigger, nil before the first run
+func loadQueryWatermark(triggerName string) (*queryWatermark, error) {
+	encoded, found, err := store.GetQueryTriggerWatermark(triggerName)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	var w queryWatermark
+	err = json.Unmarshal([]byte(encoded), &w)
+	if err != nil {
+		return nil, perr.InternalWithMessage("invalid watermark for trigger " + triggerName + ": " + err.Error())
+	}
+
+	return &w, nil
+}
+
+func saveQueryWatermark(triggerName string, w *queryWatermark) error {
+	encoded, err := json.Marshal(w)
+	if err != nil {
+		return perr.InternalWithMessage("unable to encode watermark: " + err.Error())
+	}
+
+	return store.SaveQueryTriggerWatermark(triggerName, string(encoded))
+}
@@ -0,0 +1,67 @@
+package trigger
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+// QueueMessage is a message received from a queue source. It must be acked, nacked or dead lettered once processed.
+type QueueMessage struct {
+	ID      string
+	Subject string
+	Data    []byte
+	Headers map[string]string
+
+	// Deliveries is the number of times the message has been delivered, including this delivery
+	Deliveries int
+	ReceivedAt time.Time
+
+	// the source specific handle used to settle the message
+	handle string
+}
+
+// QueueSource is a queue a queue trigger consumes from. Fetch is only called by one goroutine at a time, the
+// messages may be settled concurrently.
+type QueueSource interface {
+	// Fetch waits up to wait for the next message, it returns nil if no message is available
+	Fetch(ctx context.Context, wait time.Duration) (*QueueMessage, error)
+
+	// Ack removes the processed message from the queue
+	Ack(msg *QueueMessage) error
+
+	// Nack returns the message to the queue to be delivered again
+	Nack(msg *QueueMessage) error
+
+	// InProgress tells the queue the message is still being processed, so it's not delivered again in the meantime
+	InProgress(msg *QueueMessage) error
+
+	// DeadLetter removes the message from the queue, moving it to the dead letter destination if there's one
+	DeadLetter(msg *QueueMessage, reason string) error
+
+	Close() error
+}
+
+// NewQueueSource connects to the source of the queue trigger. A relative directory is relative to the mod location.
+func NewQueueSource(ctx context.Context, config *resources.TriggerQueue) (QueueSource, error) {
+	switch config.Source {
+	case resources.QueueSourceDirectory:
+		return newDirectoryQueueSource(modRelativePath(config.Path), modRelativePath(config.DeadLetter))
+	case resources.QueueSourceNats:
+		return newNatsQueueSource(ctx, config.Url, config.Stream, config.Consumer, config.DeadLetter)
+	default:
+		return nil, perr.BadRequestWithMessage("unsupported queue source " + config.Source)
+	}
+}
+
+func modRelativePath(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(viper.GetString(constants.ArgModLocation), path)
+}
@@ -0,0 +1,240 @@
+package trigger
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+const (
+	directoryQueueProcessingDir = ".processing"
+	directoryQueueDeliveriesDir = ".deliveries"
+
+	directoryQueuePollInterval = 500 * time.Millisecond
+)
+
+// directoryQueueSource is a spool directory, each file is a message. A fetched message is moved to the .processing
+// directory until it's settled, and the number of deliveries of a message is kept in the .deliveries directory.
+//
+// Files starting with a dot are ignored, so a message should be written under a dot name then renamed to be
+// delivered whole. The directory must have a single consumer: the messages left in .processing by a consumer that
+// stopped are returned to the queue when the next one starts.
+type directoryQueueSource struct {
+	path       string
+	deadLetter string
+
+	lock sync.Mutex
+}
+
+func newDirectoryQueueSource(path, deadLetter string) (*directoryQueueSource, error) {
+	s := &directoryQueueSource{
+		path:       path,
+		deadLetter: deadLetter,
+	}
+
+	for _, dir := range []string{path, s.processingDir(), s.deliveriesDir()} {
+		err := os.MkdirAll(dir, 0755)
+		if err != nil {
+			return nil, perr.InternalWithMessage("unable to create queue directory " + dir + ": " + err.Error())
+		}
+	}
+
+	if deadLetter != "" {
+		err := os.MkdirAll(deadLetter, 0755)
+		if err != nil {
+			return nil, perr.InternalWithMessage("unable to create dead letter directory " + deadLetter + ": " + err.Error())
+		}
+	}
+
+	// return the messages that were being processed when the previous consumer stopped
+	entries, err := os.ReadDir(s.processingDir())
+	if err != nil {
+		return nil, perr.InternalWithMessage("unable to read queue directory " + s.processingDir() + ": " + err.Error())
+	}
+	for _, entry := range entries {
+		err := os.Rename(filepath.Join(s.processingDir(), entry.Name()), filepath.Join(path, entry.Name()))
+		if err != nil {
+			return nil, perr.InternalWithMessage("unable to return message " + entry.Name() + " to the queue: " + err.Error())
+		}
+		slog.Info("Returned unsettled message to the queue", "path", path, "message", entry.Name())
+	}
+
+	return s, nil
+}
+
+func (s *directoryQueueSource) processingDir() string {
+	return filepath.Join(s.path, directoryQueueProcessingDir)
+}
+
+func (s *directoryQueueSource) deliveriesDir() string {
+	return filepath.Join(s.path, directoryQueueDeliveriesDir)
+}
+
+func (s *directoryQueueSource) Fetch(ctx context.Context, wait time.Duration) (*QueueMessage, error) {
+	deadline := time.Now().Add(wait)
+
+	for {
+		msg, err := s.next()
+		if err != nil || msg != nil {
+			return msg, err
+		}
+
+		if !time.Now().Before(deadline) {
+			return nil, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(min(directoryQueuePollInterval, time.Until(deadline))):
+		}
+	}
+}
+
+// next claims the oldest message of the directory, nil if the directory is empty
+func (s *directoryQueueSource) next() (*QueueMessage, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	entries, err := os.ReadDir(s.path)
+	if err != nil {
+		return nil, perr.InternalWithMessage("unable to read queue directory " + s.path + ": " + err.Error())
+	}
+
+	type pending struct {
+		name    string
+		modTime time.Time
+	}
+
+	var messages []pending
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			// removed since the directory was read
+			continue
+		}
+		messages = append(messages, pending{name: entry.Name(), modTime: info.ModTime()})
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		if messages[i].modTime.Equal(messages[j].modTime) {
+			return messages[i].name < messages[j].name
+		}
+		return messages[i].modTime.Before(messages[j].modTime)
+	})
+
+	for _, m := range messages {
+		processingPath := filepath.Join(s.processingDir(), m.name)
+		err := os.Rename(filepath.Join(s.path, m.name), processingPath)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, perr.InternalWithMessage("unable to claim message " + m.name + ": " + err.Error())
+		}
+
+		data, err := os.ReadFile(processingPath)
+		if err != nil {
+			return nil, perr.InternalWithMessage("unable to read message " + m.name + ": " + err.Error())
+		}
+
+		deliveries := s.deliveries(m.name) + 1
+		err = os.WriteFile(filepath.Join(s.deliveriesDir(), m.name), []byte(strconv.Itoa(deliveries)), 0600)
+		if err != nil {
+			return nil, perr.InternalWithMessage("unable to record delivery of message " + m.name + ": " + err.Error())
+		}
+
+		return &QueueMessage{
+			ID:         m.name,
+			Subject:    m.name,
+			Data:       data,
+			Headers:    map[string]string{},
+			Deliveries: deliveries,
+			ReceivedAt: time.Now().UTC(),
+			handle:     m.name,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+func (s *directoryQueueSource) deliveries(name string) int {
+	data, err := os.ReadFile(filepath.Join(s.deliveriesDir(), name))
+	if err != nil {
+		return 0
+	}
+
+	deliveries, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+	return deliveries
+}
+
+func (s *directoryQueueSource) Ack(msg *QueueMessage) error {
+	err := os.Remove(filepath.Join(s.processingDir(), msg.handle))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return perr.InternalWithMessage("unable to ack message " + msg.handle + ": " + err.Error())
+	}
+
+	s.forgetDeliveries(msg)
+	return nil
+}
+
+func (s *directoryQueueSource) Nack(msg *QueueMessage) error {
+	err := os.Rename(filepath.Join(s.processingDir(), msg.handle), filepath.Join(s.path, msg.handle))
+	if err != nil {
+		return perr.InternalWithMessage("unable to nack message " + msg.handle + ": " + err.Error())
+	}
+	return nil
+}
+
+func (s *directoryQueueSource) InProgress(msg *QueueMessage) error {
+	// a message in .processing is never delivered again by this consumer
+	return nil
+}
+
+func (s *directoryQueueSource) DeadLetter(msg *QueueMessage, reason string) error {
+	if s.deadLetter == "" {
+		return s.Ack(msg)
+	}
+
+	err := os.Rename(filepath.Join(s.processingDir(), msg.handle), filepath.Join(s.deadLetter, msg.handle))
+	if err != nil {
+		return perr.InternalWithMessage("unable to dead letter message " + msg.handle + ": " + err.Error())
+	}
+
+	// the reason is kept next to the message, as a dot file so the dead letter directory can be used as a queue
+	err = os.WriteFile(filepath.Join(s.deadLetter, "."+msg.handle+".error"), []byte(reason+"\n"), 0600)
+	if err != nil {
+		slog.Warn("Unable to write the dead letter reason", "message", msg.handle, "error", err)
+	}
+
+	s.forgetDeliveries(msg)
+	return nil
+}
+
+func (s *directoryQueueSource) forgetDeliveries(msg *QueueMessage) {
+	err := os.Remove(filepath.Join(s.deliveriesDir(), msg.handle))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Warn("Unable to remove the delivery count of message", "message", msg.handle, "error", err)
+	}
+}
+
+func (s *directoryQueueSource) Close() error {
+	return nil
+}
@@ -0,0 +1,86 @@
+package trigger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeQueueMessage(t *testing.T, dir, name, data string, modTime time.Time) {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDirectoryQueueSource(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	dir := filepath.Join(t.TempDir(), "queue")
+	deadLetter := filepath.Join(t.TempDir(), "dead")
+
+	source, err := newDirectoryQueueSource(dir, deadLetter)
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	now := time.Now()
+	writeQueueMessage(t, dir, "second.json", `{"n": 2}`, now)
+	writeQueueMessage(t, dir, "first.json", `{"n": 1}`, now.Add(-time.Minute))
+	writeQueueMessage(t, dir, ".incomplete.json", `{"n": 3}`, now.Add(-time.Hour))
+
+	// oldest first, dot files ignored
+	msg, err := source.Fetch(ctx, time.Second)
+	assert.Nil(err)
+	if msg == nil {
+		assert.FailNow("expected a message")
+	}
+	assert.Equal("first.json", msg.ID)
+	assert.Equal(`{"n": 1}`, string(msg.Data))
+	assert.Equal(1, msg.Deliveries)
+	assert.FileExists(filepath.Join(dir, directoryQueueProcessingDir, "first.json"))
+
+	// nacked messages are delivered again with their delivery count incremented
+	assert.Nil(source.Nack(msg))
+	msg, err = source.Fetch(ctx, time.Second)
+	assert.Nil(err)
+	assert.Equal("first.json", msg.ID)
+	assert.Equal(2, msg.Deliveries)
+
+	assert.Nil(source.DeadLetter(msg, "pipeline failed"))
+	assert.FileExists(filepath.Join(deadLetter, "first.json"))
+	reason, err := os.ReadFile(filepath.Join(deadLetter, ".first.json.error"))
+	assert.Nil(err)
+	assert.Equal("pipeline failed\n", string(reason))
+	assert.NoFileExists(filepath.Join(dir, directoryQueueDeliveriesDir, "first.json"))
+
+	msg, err = source.Fetch(ctx, time.Second)
+	assert.Nil(err)
+	assert.Equal("second.json", msg.ID)
+
+	// a new consumer returns the unsettled messages to the queue
+	source, err = newDirectoryQueueSource(dir, deadLetter)
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	msg, err = source.Fetch(ctx, time.Second)
+	assert.Nil(err)
+	assert.Equal("second.json", msg.ID)
+	assert.Equal(2, msg.Deliveries)
+
+	assert.Nil(source.Ack(msg))
+	assert.NoFileExists(filepath.Join(dir, directoryQueueProcessingDir, "second.json"))
+
+	// empty queue
+	msg, err = source.Fetch(ctx, 100*time.Millisecond)
+	assert.Nil(err)
+	assert.Nil(msg)
+}
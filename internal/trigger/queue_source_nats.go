@@ -0,0 +1,219 @@
+package trigger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+const (
+	natsDialTimeout = 10 * time.Second
+
+	natsHeaderDeadLetterReason  = "Flowpipe-Dead-Letter-Reason"
+	natsHeaderDeadLetterSubject = "Flowpipe-Original-Subject"
+)
+
+// natsQueueSource pulls messages from a NATS JetStream pull consumer, one message per request. The stream and the
+// consumer must exist, the consumer's ack wait and max deliver apply on top of the trigger's max_deliveries.
+//
+// The connection uses TLS with a tls:// url or when the server requires it, the credentials are taken from the url.
+type natsQueueSource struct {
+	stream     string
+	consumer   string
+	deadLetter string
+
+	conn *nats.Conn
+	pull jetstream.Consumer
+
+	// the messages fetched and not settled yet, keyed by handle
+	lock    sync.Mutex
+	pending map[string]jetstream.Msg
+}
+
+func newNatsQueueSource(ctx context.Context, serverUrl, stream, consumer, deadLetter string) (*natsQueueSource, error) {
+	conn, err := nats.Connect(serverUrl, nats.Name("flowpipe"), nats.Timeout(natsDialTimeout))
+	if err != nil {
+		return nil, perr.ServiceUnavailableWithMessage("unable to connect to nats server: " + err.Error())
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, perr.InternalWithMessage("unable to create nats jetstream context: " + err.Error())
+	}
+
+	pull, err := js.Consumer(ctx, stream, consumer)
+	if err != nil {
+		conn.Close()
+		if errors.Is(err, jetstream.ErrConsumerNotFound) || errors.Is(err, jetstream.ErrStreamNotFound) || errors.Is(err, nats.ErrNoResponders) {
+			return nil, perr.BadRequestWithMessage("nats consumer not found, check the stream " + stream + " and the consumer " + consumer + " exist: " + err.Error())
+		}
+		return nil, perr.ServiceUnavailableWithMessage("unable to get nats consumer: " + err.Error())
+	}
+
+	return &natsQueueSource{
+		stream:     stream,
+		consumer:   consumer,
+		deadLetter: deadLetter,
+		conn:       conn,
+		pull:       pull,
+		pending:    map[string]jetstream.Msg{},
+	}, nil
+}
+
+func (s *natsQueueSource) Fetch(ctx context.Context, wait time.Duration) (*QueueMessage, error) {
+	wait = max(wait, time.Second)
+
+	batch, err := s.pull.Fetch(1, jetstream.FetchMaxWait(wait))
+	if err != nil {
+		return nil, perr.ServiceUnavailableWithMessage("unable to fetch nats message: " + err.Error())
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, nil
+	case msg, ok := <-batch.Messages():
+		if !ok {
+			// no message before the request expired
+			err = batch.Error()
+			if err != nil {
+				return nil, perr.ServiceUnavailableWithMessage("unable to fetch nats message: " + err.Error())
+			}
+			return nil, nil
+		}
+
+		queueMessage, err := natsQueueMessage(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		s.lock.Lock()
+		s.pending[queueMessage.handle] = msg
+		s.lock.Unlock()
+
+		return queueMessage, nil
+	}
+}
+
+// natsQueueMessage converts a message delivered by a JetStream consumer, the handle is its reply subject which is
+// unique to the delivery
+func natsQueueMessage(msg jetstream.Msg) (*QueueMessage, error) {
+	metadata, err := msg.Metadata()
+	if err != nil {
+		return nil, perr.InternalWithMessage("invalid nats JetStream message: " + err.Error())
+	}
+
+	headers := map[string]string{}
+	for k, v := range msg.Headers() {
+		if len(v) > 0 {
+			headers[k] = v[0]
+		}
+	}
+
+	return &QueueMessage{
+		ID:         fmt.Sprintf("%s.%d", metadata.Stream, metadata.Sequence.Stream),
+		Subject:    msg.Subject(),
+		Data:       msg.Data(),
+		Headers:    headers,
+		Deliveries: int(metadata.NumDelivered),
+		ReceivedAt: time.Now().UTC(),
+		handle:     msg.Reply(),
+	}, nil
+}
+
+// pendingMessage returns the message to settle, it's forgotten unless keep is set
+func (s *natsQueueSource) pendingMessage(msg *QueueMessage, keep bool) (jetstream.Msg, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	pending, ok := s.pending[msg.handle]
+	if !ok {
+		return nil, perr.InternalWithMessage("unknown nats message " + msg.ID)
+	}
+	if !keep {
+		delete(s.pending, msg.handle)
+	}
+	return pending, nil
+}
+
+func (s *natsQueueSource) Ack(msg *QueueMessage) error {
+	pending, err := s.pendingMessage(msg, false)
+	if err != nil {
+		return err
+	}
+	err = pending.Ack()
+	if err != nil {
+		return perr.ServiceUnavailableWithMessage("unable to ack nats message " + msg.ID + ": " + err.Error())
+	}
+	return nil
+}
+
+func (s *natsQueueSource) Nack(msg *QueueMessage) error {
+	pending, err := s.pendingMessage(msg, false)
+	if err != nil {
+		return err
+	}
+	err = pending.Nak()
+	if err != nil {
+		return perr.ServiceUnavailableWithMessage("unable to nack nats message " + msg.ID + ": " + err.Error())
+	}
+	return nil
+}
+
+func (s *natsQueueSource) InProgress(msg *QueueMessage) error {
+	pending, err := s.pendingMessage(msg, true)
+	if err != nil {
+		return err
+	}
+	err = pending.InProgress()
+	if err != nil {
+		return perr.ServiceUnavailableWithMessage("unable to extend nats message " + msg.ID + ": " + err.Error())
+	}
+	return nil
+}
+
+func (s *natsQueueSource) DeadLetter(msg *QueueMessage, reason string) error {
+	pending, err := s.pendingMessage(msg, false)
+	if err != nil {
+		return err
+	}
+
+	if s.deadLetter != "" {
+		deadLetter := nats.NewMsg(s.deadLetter)
+		deadLetter.Data = msg.Data
+		for k, v := range pending.Headers() {
+			deadLetter.Header[k] = v
+		}
+		// header values can't span lines
+		deadLetter.Header.Set(natsHeaderDeadLetterReason, strings.ReplaceAll(strings.ReplaceAll(reason, "\r", " "), "\n", " "))
+		deadLetter.Header.Set(natsHeaderDeadLetterSubject, msg.Subject)
+
+		err = s.conn.PublishMsg(deadLetter)
+		if err != nil {
+			return perr.ServiceUnavailableWithMessage("unable to publish nats dead letter: " + err.Error())
+		}
+	}
+
+	// terminate the message so the consumer doesn't deliver it again
+	err = pending.Term()
+	if err != nil {
+		return perr.ServiceUnavailableWithMessage("unable to terminate nats message " + msg.ID + ": " + err.Error())
+	}
+	return nil
+}
+
+func (s *natsQueueSource) Close() error {
+	// the acks already published are sent before the connection is closed
+	err := s.conn.Drain()
+	if err != nil {
+		s.conn.Close()
+	}
+	return nil
+}
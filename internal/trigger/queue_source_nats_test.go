@@ -0,0 +1,213 @@
+package trigger
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const fakeNatsConsumerInfo = `{"type":"io.nats.jetstream.api.v1.consumer_info_response","stream_name":"ORDERS","name":"flowpipe",` +
+	`"config":{"durable_name":"flowpipe","ack_policy":"explicit"},"created":"2024-01-01T00:00:00Z"}`
+
+// fakeNatsServer answers the JetStream consumer info and pull requests, the pull requests with the given replies in
+// turn, and records the other messages published, e.g. the acks
+type fakeNatsServer struct {
+	listener     net.Listener
+	consumerInfo string
+	replies      []func() (headers string, data string)
+	published    chan string
+
+	lock sync.Mutex
+	subs map[string]string
+}
+
+func newFakeNatsServer(t *testing.T, consumerInfo string, replies ...func() (string, string)) *fakeNatsServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("unable to listen: " + err.Error())
+	}
+
+	s := &fakeNatsServer{
+		listener:     listener,
+		consumerInfo: consumerInfo,
+		replies:      replies,
+		published:    make(chan string, 16),
+		subs:         map[string]string{},
+	}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *fakeNatsServer) url() string {
+	return "nats://" + s.listener.Addr().String()
+}
+
+// sid returns the subscription receiving the subject, the inboxes are subscribed to with a trailing wildcard
+func (s *fakeNatsServer) sid(subject string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for pattern, sid := range s.subs {
+		if pattern == subject || (strings.HasSuffix(pattern, ".*") && strings.HasPrefix(subject, strings.TrimSuffix(pattern, "*"))) {
+			return sid
+		}
+	}
+	return ""
+}
+
+func (s *fakeNatsServer) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	fmt.Fprint(conn, `INFO {"server_id":"fake","version":"2.10.0","proto":1,"headers":true,"max_payload":1048576,"jetstream":true}`+"\r\n")
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch strings.ToUpper(fields[0]) {
+		case "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+		case "SUB":
+			// SUB <subject> [queue group] <sid>
+			s.lock.Lock()
+			s.subs[fields[1]] = fields[len(fields)-1]
+			s.lock.Unlock()
+		case "PUB", "HPUB":
+			// PUB <subject> [reply-to] <#bytes>, HPUB <subject> [reply-to] <#header bytes> <#total bytes>
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, size+2)
+			_, err := io.ReadFull(reader, payload)
+			if err != nil {
+				return
+			}
+			payload = payload[:size]
+
+			subject := fields[1]
+			switch {
+			case strings.HasPrefix(subject, "$JS.API.CONSUMER.INFO."):
+				reply := fields[2]
+				fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", reply, s.sid(reply), len(s.consumerInfo), s.consumerInfo)
+			case strings.HasPrefix(subject, "$JS.API.CONSUMER.MSG.NEXT."):
+				if len(s.replies) == 0 {
+					continue
+				}
+				headers, data := s.replies[0]()
+				s.replies = s.replies[1:]
+
+				// the messages are delivered with their ack subject as the reply subject
+				reply := fields[2]
+				msgSubject, ackSubject := "orders.new", "$JS.ACK.ORDERS.flowpipe.3.42.7.1700000000000000000.0"
+				if strings.HasPrefix(headers, "NATS/1.0 ") {
+					msgSubject, ackSubject = reply, ""
+				}
+				fmt.Fprintf(conn, "HMSG %s %s %s %d %d\r\n%s%s\r\n", msgSubject, s.sid(reply), ackSubject, len(headers), len(headers)+len(data), headers, data)
+			default:
+				s.published <- subject + " " + string(payload)
+			}
+		}
+	}
+}
+
+func (s *fakeNatsServer) nextPublished(t *testing.T) string {
+	select {
+	case published := <-s.published:
+		return published
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "message not published")
+		return ""
+	}
+}
+
+func TestNatsQueueSource(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	server := newFakeNatsServer(t, fakeNatsConsumerInfo,
+		func() (string, string) {
+			return "NATS/1.0\r\nOrder-Type: new\r\n\r\n", `{"id": "order-42"}`
+		},
+		func() (string, string) {
+			return "NATS/1.0 408 Request Timeout\r\n\r\n", ""
+		},
+		func() (string, string) {
+			return "NATS/1.0\r\nOrder-Type: new\r\n\r\n", `{"id": "order-42"}`
+		},
+	)
+
+	source, err := newNatsQueueSource(ctx, server.url(), "ORDERS", "flowpipe", "orders.dead")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	defer source.Close()
+
+	msg, err := source.Fetch(ctx, time.Second)
+	assert.Nil(err)
+	if msg == nil {
+		assert.FailNow("expected a message")
+	}
+	assert.Equal("ORDERS.42", msg.ID)
+	assert.Equal("orders.new", msg.Subject)
+	assert.Equal(`{"id": "order-42"}`, string(msg.Data))
+	assert.Equal("new", msg.Headers["Order-Type"])
+	assert.Equal(3, msg.Deliveries)
+
+	assert.Nil(source.Ack(msg))
+	assert.Equal("$JS.ACK.ORDERS.flowpipe.3.42.7.1700000000000000000.0 +ACK", server.nextPublished(t))
+
+	// the message is already settled
+	assert.NotNil(source.Ack(msg))
+
+	// the request expired without a message
+	msg, err = source.Fetch(ctx, time.Second)
+	assert.Nil(err)
+	assert.Nil(msg)
+
+	// the message is published to the dead letter subject with the reason, then terminated
+	msg, err = source.Fetch(ctx, time.Second)
+	assert.Nil(err)
+	if msg == nil {
+		assert.FailNow("expected a message")
+	}
+	assert.Nil(source.DeadLetter(msg, "pipeline failed\nthree times"))
+
+	deadLetter := server.nextPublished(t)
+	assert.True(strings.HasPrefix(deadLetter, "orders.dead NATS/1.0\r\n"), deadLetter)
+	assert.Contains(deadLetter, "Order-Type: new\r\n")
+	assert.Contains(deadLetter, natsHeaderDeadLetterReason+": pipeline failed three times\r\n")
+	assert.Contains(deadLetter, natsHeaderDeadLetterSubject+": orders.new\r\n")
+	assert.True(strings.HasSuffix(deadLetter, `{"id": "order-42"}`), deadLetter)
+
+	assert.Equal("$JS.ACK.ORDERS.flowpipe.3.42.7.1700000000000000000.0 +TERM", server.nextPublished(t))
+}
+
+func TestNatsQueueSourceConsumerNotFound(t *testing.T) {
+	assert := assert.New(t)
+
+	server := newFakeNatsServer(t, `{"type":"io.nats.jetstream.api.v1.consumer_info_response","error":{"code":404,"err_code":10014,"description":"consumer not found"}}`)
+
+	_, err := newNatsQueueSource(context.Background(), server.url(), "ORDERS", "missing", "")
+	if assert.NotNil(err) {
+		assert.Contains(err.Error(), "check the stream ORDERS and the consumer missing exist")
+	}
+}
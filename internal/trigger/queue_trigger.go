@@ -0,0 +1,45 @@
+package trigger
+
+import (
+	"context"
+	"time"
+
+	"github.com/turbot/flowpipe/internal/es/event"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// QueueMessageSelf is the self object available to the args of a queue trigger:
+//
+//	self.message.id
+//	self.message.subject
+//	self.message.data
+//	self.message.headers
+//	self.message.deliveries
+func QueueMessageSelf(msg *QueueMessage) cty.Value {
+	headers := map[string]cty.Value{}
+	for k, v := range msg.Headers {
+		headers[k] = cty.StringVal(v)
+	}
+
+	headersVal := cty.MapValEmpty(cty.String)
+	if len(headers) > 0 {
+		headersVal = cty.MapVal(headers)
+	}
+
+	return cty.ObjectVal(map[string]cty.Value{
+		"message": cty.ObjectVal(map[string]cty.Value{
+			"id":          cty.StringVal(msg.ID),
+			"subject":     cty.StringVal(msg.Subject),
+			"data":        cty.StringVal(string(msg.Data)),
+			"headers":     headersVal,
+			"deliveries":  cty.NumberIntVal(int64(msg.Deliveries)),
+			"received_at": cty.StringVal(msg.ReceivedAt.Format(time.RFC3339)),
+		}),
+	})
+}
+
+// NewQueueMessageExecution builds the execution that runs the pipeline of the queue trigger for a message
+func NewQueueMessageExecution(ctx context.Context, triggerName string, msg *QueueMessage) (*event.ExecutionQueue, error) {
+	return newEventTriggerExecution(ctx, triggerName, resources.TriggerTypeQueue, QueueMessageSelf(msg), "message", msg.ID, "deliveries", msg.Deliveries)
+}
@@ -0,0 +1,81 @@
+package trigger
+
+import (
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/flowpipe/internal/schedule"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+// maxExcludedOccurrences stops the search for the next fire times of a schedule whose occurrences are all excluded
+const maxExcludedOccurrences = 10000
+
+// CronExpression returns the cron expression the scheduled trigger runs on, prefixed with its time zone. The intervals,
+// e.g. daily, are converted to cron expressions distributed by the trigger name.
+func CronExpression(t *resources.Trigger) (string, error) {
+	config, ok := t.Config.(resources.ScheduledTriggerConfig)
+	if !ok {
+		return "", perr.BadRequestWithMessage("trigger " + t.Name() + " is not a scheduled trigger")
+	}
+
+	expression := config.GetSchedule()
+	if strings.HasPrefix(expression, "TZ=") || strings.HasPrefix(expression, "CRON_TZ=") {
+		return expression, nil
+	}
+
+	if _, err := cron.ParseStandard(expression); err != nil {
+		expression, err = schedule.IntervalToCronExpression(t.FullName, expression)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return "CRON_TZ=" + config.GetScheduleOptions().Location().String() + " " + expression, nil
+}
+
+func parseSchedule(t *resources.Trigger) (cron.Schedule, *resources.ScheduleOptions, error) {
+	cronExpression, err := CronExpression(t)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cronSchedule, err := cron.ParseStandard(cronExpression)
+	if err != nil {
+		return nil, nil, perr.BadRequestWithMessage("invalid schedule for trigger " + t.Name() + ": " + err.Error())
+	}
+
+	return cronSchedule, t.Config.(resources.ScheduledTriggerConfig).GetScheduleOptions(), nil
+}
+
+// NextFireTimes returns the next n times the scheduled trigger fires after the given time, skipping the excluded
+// dates. The jitter is not included.
+func NextFireTimes(t *resources.Trigger, after time.Time, n int) ([]time.Time, error) {
+	cronSchedule, options, err := parseSchedule(t)
+	if err != nil {
+		return nil, err
+	}
+
+	var fireTimes []time.Time
+	excluded := 0
+	for next := cronSchedule.Next(after); !next.IsZero() && len(fireTimes) < n; next = cronSchedule.Next(next) {
+		if options.IsExcluded(next) {
+			excluded++
+			if excluded > maxExcludedOccurrences {
+				break
+			}
+			continue
+		}
+		fireTimes = append(fireTimes, next)
+	}
+
+	return fireTimes, nil
+}
+
+// IsExcluded returns true if the scheduled trigger doesn't fire at the given time, its date is excluded
+func IsExcluded(t *resources.Trigger, at time.Time) bool {
+	config, ok := t.Config.(resources.ScheduledTriggerConfig)
+	return ok && config.GetScheduleOptions().IsExcluded(at)
+}
@@ -0,0 +1,75 @@
+package trigger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/flowpipe/internal/resources"
+	"github.com/turbot/pipe-fittings/modconfig"
+)
+
+func TestNextFireTimes(t *testing.T) {
+	assert := assert.New(t)
+
+	trigger := &resources.Trigger{
+		HclResourceImpl: modconfig.HclResourceImpl{
+			FullName: "local.trigger.schedule.business_days",
+		},
+		Config: &resources.TriggerSchedule{
+			Schedule: "0 9 * * 1-5",
+			ScheduleOptions: resources.ScheduleOptions{
+				Timezone: "America/New_York",
+				Exclude:  []string{"2024-07-04"},
+			},
+		},
+	}
+
+	cronExpression, err := CronExpression(trigger)
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Equal("CRON_TZ=America/New_York 0 9 * * 1-5", cronExpression)
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+
+	// Wednesday, the 4th of July is excluded and the weekend is skipped
+	fireTimes, err := NextFireTimes(trigger, time.Date(2024, 7, 3, 12, 0, 0, 0, time.UTC), 3)
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	if assert.Equal(3, len(fireTimes)) {
+		assert.True(time.Date(2024, 7, 3, 9, 0, 0, 0, newYork).Equal(fireTimes[0]))
+		assert.True(time.Date(2024, 7, 5, 9, 0, 0, 0, newYork).Equal(fireTimes[1]))
+		assert.True(time.Date(2024, 7, 8, 9, 0, 0, 0, newYork).Equal(fireTimes[2]))
+	}
+
+	assert.True(IsExcluded(trigger, time.Date(2024, 7, 4, 13, 0, 0, 0, time.UTC)))
+	// still the 3rd in New York
+	assert.False(IsExcluded(trigger, time.Date(2024, 7, 4, 2, 0, 0, 0, time.UTC)))
+
+	// the intervals are evaluated in the time zone too
+	trigger.Config = &resources.TriggerSchedule{
+		Schedule:        "hourly",
+		ScheduleOptions: resources.ScheduleOptions{Timezone: "Asia/Kolkata"},
+	}
+	cronExpression, err = CronExpression(trigger)
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Regexp(`^CRON_TZ=Asia/Kolkata \d+ \* \* \* \*$`, cronExpression)
+
+	// no time zone, UTC
+	trigger.Config = &resources.TriggerQuery{}
+	cronExpression, err = CronExpression(trigger)
+	if err != nil {
+		assert.FailNow(err.Error())
+	}
+	assert.Regexp(`^CRON_TZ=UTC \d+ \* \* \* \*$`, cronExpression)
+
+	_, err = CronExpression(&resources.Trigger{Config: &resources.TriggerHttp{}})
+	assert.NotNil(err)
+}
@@ -58,6 +58,17 @@ func (c *WebhookRequestQuery) GetWaitTime() int {
 	return localconstants.DefaultWaitRetry
 }
 
+type TriggerRequestQuery struct {
+	FireTimes *int `json:"fire_times" form:"fire_times" binding:"omitempty,min=0,max=100"`
+}
+
+func (c *TriggerRequestQuery) GetFireTimes() int {
+	if c.FireTimes != nil {
+		return *c.FireTimes
+	}
+	return localconstants.DefaultTriggerFireTimes
+}
+
 type PipelineRequestQuery struct {
 	ExecutionMode *string `json:"execution_mode" form:"execution_mode" binding:"omitempty,oneof=synchronous asynchronous"`
 }
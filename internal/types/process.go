@@ -1,10 +1,12 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/turbot/flowpipe/internal/es/event"
+	"github.com/turbot/flowpipe/internal/store"
 	"github.com/turbot/pipe-fittings/printers"
 	"github.com/turbot/pipe-fittings/sanitize"
 
@@ -17,10 +19,22 @@ import (
 type Process struct {
 	ID        string    `json:"execution_id"`
 	Pipeline  string    `json:"pipeline"`
+	Trigger   string    `json:"trigger,omitempty"`
 	Status    string    `json:"status"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// The filters of the process list, on top of the paging parameters (ListRequestQuery)
+type ListProcessRequestQuery struct {
+	Status   string `json:"status" form:"status" binding:"omitempty,oneof=queued started paused finished failed canceled"`
+	Pipeline string `json:"pipeline" form:"pipeline" binding:"omitempty"`
+	Trigger  string `json:"trigger" form:"trigger" binding:"omitempty"`
+	// Either a RFC 3339 timestamp or a duration before now, e.g. 24h or 7d
+	Since string `json:"since" form:"since" binding:"omitempty"`
+	Until string `json:"until" form:"until" binding:"omitempty"`
+	Sort  string `json:"sort" form:"sort" binding:"omitempty,oneof=asc desc"`
+}
+
 func (p Process) String(sanitizer *sanitize.Sanitizer, opts sanitize.RenderOptions) string {
 	au := aurora.NewAurora(opts.ColorEnabled)
 	keyWidth := 14
@@ -33,6 +47,9 @@ func (p Process) String(sanitizer *sanitize.Sanitizer, opts sanitize.RenderOptio
 
 	output += fmt.Sprintf("%-*s%s\n", keyWidth, au.Blue("Execution ID:"), p.ID)
 	output += fmt.Sprintf("%-*s%s\n", keyWidth, au.Blue("Pipeline:"), p.Pipeline)
+	if p.Trigger != "" {
+		output += fmt.Sprintf("%-*s%s\n", keyWidth, au.Blue("Trigger:"), p.Trigger)
+	}
 	output += fmt.Sprintf("%-*s%s\n", keyWidth, au.Blue("Status:"), p.Status)
 	output += fmt.Sprintf("%-*s%s\n", keyWidth, au.Blue("Created:"), p.CreatedAt.Local().Format(time.DateTime))
 	return output
@@ -143,7 +160,102 @@ type ListProcessLogJSONResponse struct {
 }
 
 type CmdProcess struct {
-	Command             string `json:"command" binding:"required,oneof=resume"`
+	Command             string `json:"command" binding:"required,oneof=resume pause cancel"`
 	PipelineExecutionID string `json:"pipeline_execution_id,omitempty" format:"^(pexec)_[0-9a-v]{20}$"`
 	Reason              string `json:"reason,omitempty"`
 }
+
+type CmdReplayProcess struct {
+	// The step to continue live from, the steps started before it are served their recorded output. All the recorded
+	// outputs are served if not set.
+	FromStep string `json:"from_step,omitempty"`
+}
+
+type CmdPruneProcess struct {
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// A process deleted, or that would be deleted in a dry run, by the retention policies
+type PrunedProcess struct {
+	Process
+	UpdatedAt time.Time `json:"updated_at"`
+	Reason    string    `json:"reason"`
+	Archived  bool      `json:"archived"`
+}
+
+func (p PrunedProcess) String(sanitizer *sanitize.Sanitizer, opts sanitize.RenderOptions) string {
+	au := aurora.NewAurora(opts.ColorEnabled)
+	// deliberately shadow the receiver with a sanitized version of the struct
+	var err error
+	if p, err = sanitize.SanitizeStruct(sanitizer, p); err != nil {
+		return ""
+	}
+
+	output := fmt.Sprintf("%s %s (%s, updated %s)", au.Blue(p.ID), p.Pipeline, p.Reason, p.UpdatedAt.Local().Format(time.DateTime))
+	if p.Archived {
+		output += " archived"
+	}
+	return output + "\n"
+}
+
+type PruneProcessResponse struct {
+	DryRun bool            `json:"dry_run"`
+	Items  []PrunedProcess `json:"items"`
+}
+
+type PrintablePrunedProcess struct {
+	Items []PrunedProcess
+}
+
+func NewPrintablePrunedProcess(resp *PruneProcessResponse) *PrintablePrunedProcess {
+	return &PrintablePrunedProcess{
+		Items: resp.Items,
+	}
+}
+
+func (p PrintablePrunedProcess) GetItems() []PrunedProcess {
+	return p.Items
+}
+
+func (p PrintablePrunedProcess) GetTable() (*printers.Table, error) {
+	var tableRows []printers.TableRow
+	for _, item := range p.Items {
+		cells := []any{
+			item.ID,
+			item.Pipeline,
+			item.Status,
+			item.UpdatedAt.Local().Format(time.DateTime),
+			item.Reason,
+			item.Archived,
+		}
+		tableRows = append(tableRows, printers.TableRow{Cells: cells})
+	}
+
+	return printers.NewTable().WithData(tableRows, p.getColumns()), nil
+}
+
+func (PrintablePrunedProcess) getColumns() (columns []string) {
+	return []string{"EXECUTION_ID", "PIPELINE", "STATUS", "UPDATED_AT", "REASON", "ARCHIVED"}
+}
+
+// The version of the process bundle layout, a bundle of another version is rejected on import
+const ProcessBundleVersion = "1"
+
+// A process exported from a server, self-contained so it can be imported into another server. The secrets are
+// redacted.
+type ProcessBundle struct {
+	Version         string    `json:"version"`
+	FlowpipeVersion string    `json:"flowpipe_version"`
+	ExportedAt      time.Time `json:"exported_at"`
+
+	Process Process `json:"process"`
+
+	// The definitions (FpPipeline) of the pipelines run by the process, by pipeline name, as they were when exported.
+	// They are kept as JSON as the steps don't unmarshal.
+	Pipelines map[string]json.RawMessage `json:"pipelines,omitempty"`
+
+	// The outputs of the pipeline executions, by pipeline execution id
+	Outputs map[string]map[string]any `json:"outputs,omitempty"`
+
+	Events []store.EventRecord `json:"events"`
+}
@@ -238,6 +238,46 @@ func (o ServerOutputTriggerExecution) String(sanitizer *sanitize.Sanitizer, opts
 	return fmt.Sprintf("%s%s%s%s fired, executing %s\n", o.ServerOutputPrefix.String(sanitize.NullSanitizer, opts), left, au.Index(triggerColor, shortTrigger), right, au.Index(c, shortPipeline))
 }
 
+type ServerOutputTriggerRejected struct {
+	ServerOutputPrefix
+	TriggerName string
+	ClientIP    string
+	Error       error
+}
+
+func NewServerOutputTriggerRejected(ts time.Time, name string, clientIP string, err error) *ServerOutputTriggerRejected {
+	return &ServerOutputTriggerRejected{
+		ServerOutputPrefix: NewServerOutputPrefix(ts, "trigger"),
+		TriggerName:        name,
+		ClientIP:           clientIP,
+		Error:              err,
+	}
+}
+
+func (o ServerOutputTriggerRejected) String(sanitizer *sanitize.Sanitizer, opts sanitize.RenderOptions) string {
+	au := aurora.NewAurora(opts.ColorEnabled)
+	left := au.BrightBlack("[")
+	right := au.BrightBlack("]")
+
+	// deliberately shadow the receiver with a sanitized version of the struct
+	var err error
+	if o, err = sanitize.SanitizeStruct(sanitizer, o); err != nil {
+		return ""
+	}
+	triggerSplit := strings.Split(o.TriggerName, ".")
+	triggerType := triggerSplit[len(triggerSplit)-2]
+	triggerName := triggerSplit[len(triggerSplit)-1]
+	shortTrigger := fmt.Sprintf("trigger.%s.%s", triggerType, triggerName)
+	triggerColor := opts.ColorGenerator.GetColorForElement(shortTrigger)
+
+	reason := "unknown error"
+	if o.Error != nil {
+		reason = o.Error.Error()
+	}
+
+	return fmt.Sprintf("%s%s%s%s %s request from %s: %s\n", o.ServerOutputPrefix.String(sanitize.NullSanitizer, opts), left, au.Index(triggerColor, shortTrigger), right, au.Red("rejected"), o.ClientIP, au.BrightRed(reason))
+}
+
 type ServerOutputQueryTriggerRun struct {
 	ServerOutputPrefix
 	TriggerName string
@@ -286,6 +326,8 @@ type ServerOutputTrigger struct {
 	Method   *string
 	Url      *string
 	Sql      *string
+	Source   *string
+	Path     *string
 }
 
 func NewServerOutputTrigger(prefix ServerOutputPrefix, n string, t string, e *bool) *ServerOutputTrigger {
@@ -326,6 +368,10 @@ func (o ServerOutputTrigger) String(sanitizer *sanitize.Sanitizer, opts sanitize
 		s := kitTypes.SafeString(o.Schedule)
 		q := kitTypes.SafeString(o.Sql)
 		suffix = fmt.Sprintf("Schedule: %s - Query: %s", au.Blue(s), au.Blue(q))
+	case "queue":
+		suffix = fmt.Sprintf("Queue: %s", au.Blue(kitTypes.SafeString(o.Source)))
+	case "file":
+		suffix = fmt.Sprintf("Path: %s", au.Blue(kitTypes.SafeString(o.Path)))
 	default:
 		suffix = "loaded"
 	}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -26,6 +27,8 @@ type FpTrigger struct {
 	Mod             string              `json:"mod"`
 	Type            string              `json:"type"`
 	Enabled         bool                `json:"enabled"`
+	Paused          bool                `json:"paused,omitempty"`
+	Until           *time.Time          `json:"until,omitempty"` // end of the runtime enable, disable or pause
 	Description     *string             `json:"description,omitempty"`
 	Pipelines       []FpTriggerPipeline `json:"pipelines,omitempty"`
 	Url             *string             `json:"url,omitempty"`
@@ -36,7 +39,11 @@ type FpTrigger struct {
 	Documentation   *string             `json:"documentation,omitempty"`
 	Tags            map[string]string   `json:"tags,omitempty"`
 	Schedule        *string             `json:"schedule,omitempty"`
+	Timezone        *string             `json:"timezone,omitempty"`
+	NextFireTimes   []time.Time         `json:"next_fire_times,omitempty"`
 	Query           *string             `json:"query,omitempty"`
+	Source          *string             `json:"source,omitempty"`
+	Path            *string             `json:"path,omitempty"`
 	RootMod         string              `json:"root_mod"`
 	Params          []FpPipelineParam   `json:"params,omitempty"`
 }
@@ -59,6 +66,11 @@ func (t FpTrigger) String(_ *sanitize.Sanitizer, opts sanitize.RenderOptions) st
 
 	if !t.Enabled {
 		statusText = fmt.Sprintf("%s%s%s", left, au.Red("disabled"), right)
+	} else if t.Paused {
+		statusText = fmt.Sprintf("%s%s%s", left, au.Yellow("paused"), right)
+	}
+	if statusText != "" && t.Until != nil {
+		statusText += fmt.Sprintf(" until %s", t.Until.Local().Format(time.DateTime))
 	}
 	output += fmt.Sprintf("%-*s%s %s\n", keyWidth, au.Blue("Name:"), t.getTypeAndName(), statusText)
 	if t.Title != nil {
@@ -80,9 +92,7 @@ func (t FpTrigger) String(_ *sanitize.Sanitizer, opts sanitize.RenderOptions) st
 		}
 		// TODO: Add usage section
 	case schema.TriggerTypeQuery:
-		if t.Schedule != nil {
-			output += fmt.Sprintf("%-*s%s\n", keyWidth, au.Blue("Schedule:"), *t.Schedule)
-		}
+		output += t.scheduleDisplay(au, keyWidth)
 		if t.Query != nil {
 			output += fmt.Sprintf("%-*s%s\n", keyWidth, au.Blue("Query:"), *t.Query)
 		}
@@ -91,10 +101,22 @@ func (t FpTrigger) String(_ *sanitize.Sanitizer, opts sanitize.RenderOptions) st
 			output += fmt.Sprintf("  %s %s\n", au.Blue(utils.ToTitleCase(pipeline.CaptureGroup)+":"), t.getPipelineDisplay(pipeline.Pipeline))
 		}
 	case schema.TriggerTypeSchedule:
-		if t.Schedule != nil {
-			output += fmt.Sprintf("%-*s%s\n", keyWidth, au.Blue("Schedule:"), *t.Schedule)
-		}
+		output += t.scheduleDisplay(au, keyWidth)
 		output += fmt.Sprintf("%-*s%s\n", keyWidth, au.Blue("Pipeline:"), t.getPipelineDisplay(t.Pipelines[0].Pipeline))
+	case resources.TriggerTypeQueue:
+		if t.Source != nil {
+			output += fmt.Sprintf("%-*s%s\n", keyWidth, au.Blue("Source:"), *t.Source)
+		}
+		if len(t.Pipelines) > 0 {
+			output += fmt.Sprintf("%-*s%s\n", keyWidth, au.Blue("Pipeline:"), t.getPipelineDisplay(t.Pipelines[0].Pipeline))
+		}
+	case resources.TriggerTypeFile:
+		if t.Path != nil {
+			output += fmt.Sprintf("%-*s%s\n", keyWidth, au.Blue("Path:"), *t.Path)
+		}
+		if len(t.Pipelines) > 0 {
+			output += fmt.Sprintf("%-*s%s\n", keyWidth, au.Blue("Pipeline:"), t.getPipelineDisplay(t.Pipelines[0].Pipeline))
+		}
 	}
 
 	if len(t.Tags) > 0 {
@@ -110,6 +132,31 @@ func (t FpTrigger) String(_ *sanitize.Sanitizer, opts sanitize.RenderOptions) st
 	return output
 }
 
+// scheduleDisplay shows the schedule of a scheduled trigger and its next fire times, in the time zone of the trigger
+func (t FpTrigger) scheduleDisplay(au aurora.Aurora, keyWidth int) string {
+	var output string
+	if t.Schedule != nil {
+		output += fmt.Sprintf("%-*s%s\n", keyWidth, au.Blue("Schedule:"), *t.Schedule)
+	}
+
+	location := time.Local
+	if t.Timezone != nil {
+		output += fmt.Sprintf("%-*s%s\n", keyWidth, au.Blue("Timezone:"), *t.Timezone)
+		if l, err := time.LoadLocation(*t.Timezone); err == nil {
+			location = l
+		}
+	}
+
+	if len(t.NextFireTimes) > 0 {
+		output += fmt.Sprintf("%s\n", au.Blue("Next fire times:"))
+		for _, fireTime := range t.NextFireTimes {
+			output += fmt.Sprintf("  %s\n", fireTime.In(location).Format("2006-01-02 15:04:05 MST"))
+		}
+	}
+
+	return output
+}
+
 func (t FpTrigger) getTypeAndName() string {
 	shortName := strings.Split(t.Name, ".")[len(strings.Split(t.Name, "."))-1]
 	return fmt.Sprintf("%s.%s", t.Type, shortName)
@@ -274,7 +321,11 @@ type FlowpipeTriggerResponseMetadata struct {
 }
 
 type CmdTrigger struct {
-	Command string `json:"command" binding:"required,oneof=run reset"`
+	Command string `json:"command" binding:"required,oneof=run reset enable disable pause"`
+
+	// The end of an enable, disable or pause command, either a RFC 3339 timestamp or a duration such as 2h or 1d. The
+	// command lasts until the next one if not set.
+	Until string `json:"until,omitempty"`
 
 	// Sepcify execution id, if not specified, a new execution id will be created
 	ExecutionID   string                 `json:"execution_id,omitempty"`
@@ -350,6 +401,9 @@ func FpTriggerFromModTrigger(t resources.Trigger, rootMod string) (*FpTrigger, e
 	case schema.TriggerTypeQuery:
 		cfg := t.Config.(*resources.TriggerQuery)
 		fpTrigger.Schedule = &cfg.Schedule
+		if cfg.Timezone != "" {
+			fpTrigger.Timezone = &cfg.Timezone
+		}
 		fpTrigger.Query = &cfg.Sql
 		for _, capture := range cfg.Captures {
 			pipelineInfo := capture.Pipeline.AsValueMap()
@@ -362,6 +416,29 @@ func FpTriggerFromModTrigger(t resources.Trigger, rootMod string) (*FpTrigger, e
 	case schema.TriggerTypeSchedule:
 		cfg := t.Config.(*resources.TriggerSchedule)
 		fpTrigger.Schedule = &cfg.Schedule
+		if cfg.Timezone != "" {
+			fpTrigger.Timezone = &cfg.Timezone
+		}
+		pipelineInfo := t.GetPipeline().AsValueMap()
+		pipelineName := pipelineInfo["name"].AsString()
+		fpTrigger.Pipelines = append(fpTrigger.Pipelines, FpTriggerPipeline{
+			CaptureGroup: "default",
+			Pipeline:     pipelineName,
+		})
+	case resources.TriggerTypeQueue:
+		cfg := t.Config.(*resources.TriggerQueue)
+		source := cfg.Source
+		fpTrigger.Source = &source
+		pipelineInfo := t.GetPipeline().AsValueMap()
+		pipelineName := pipelineInfo["name"].AsString()
+		fpTrigger.Pipelines = append(fpTrigger.Pipelines, FpTriggerPipeline{
+			CaptureGroup: "default",
+			Pipeline:     pipelineName,
+		})
+	case resources.TriggerTypeFile:
+		cfg := t.Config.(*resources.TriggerFile)
+		path := filepath.Join(cfg.Path, cfg.Pattern)
+		fpTrigger.Path = &path
 		pipelineInfo := t.GetPipeline().AsValueMap()
 		pipelineName := pipelineInfo["name"].AsString()
 		fpTrigger.Pipelines = append(fpTrigger.Pipelines, FpTriggerPipeline{
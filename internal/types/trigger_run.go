@@ -0,0 +1,110 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/turbot/pipe-fittings/printers"
+	"github.com/turbot/pipe-fittings/sanitize"
+)
+
+// A firing of a trigger and the process it started
+type TriggerRun struct {
+	ExecutionID string    `json:"execution_id"`
+	Source      string    `json:"source"`
+	FiredAt     time.Time `json:"fired_at"`
+	Status      string    `json:"status,omitempty"`
+
+	// The rows seen by a query trigger run
+	InsertedCount *int `json:"inserted_count,omitempty"`
+	UpdatedCount  *int `json:"updated_count,omitempty"`
+	DeletedCount  *int `json:"deleted_count,omitempty"`
+}
+
+func (r TriggerRun) String(sanitizer *sanitize.Sanitizer, opts sanitize.RenderOptions) string {
+	au := aurora.NewAurora(opts.ColorEnabled)
+	// deliberately shadow the receiver with a sanitized version of the struct
+	var err error
+	if r, err = sanitize.SanitizeStruct(sanitizer, r); err != nil {
+		return ""
+	}
+
+	output := fmt.Sprintf("%s %s %s %s", r.FiredAt.Local().Format(time.DateTime), au.Blue(r.ExecutionID), r.Source, r.displayStatus())
+	if counts := r.captureCounts(); counts != "" {
+		output += " " + counts
+	}
+	return output + "\n"
+}
+
+func (r TriggerRun) displayStatus() string {
+	if r.Status == "" {
+		return "unknown"
+	}
+	return r.Status
+}
+
+// captureCounts is empty for the runs of the triggers other than query triggers
+func (r TriggerRun) captureCounts() string {
+	if r.InsertedCount == nil && r.UpdatedCount == nil && r.DeletedCount == nil {
+		return ""
+	}
+
+	count := func(c *int) int {
+		if c == nil {
+			return 0
+		}
+		return *c
+	}
+	return fmt.Sprintf("inserted: %d, updated: %d, deleted: %d", count(r.InsertedCount), count(r.UpdatedCount), count(r.DeletedCount))
+}
+
+// This type is used by the API to return the run history of a trigger. The fire times are only set for the scheduled
+// triggers, by the node running the schedule.
+type ListTriggerRunResponse struct {
+	Trigger          string       `json:"trigger"`
+	PreviousFireTime *time.Time   `json:"previous_fire_time,omitempty"`
+	NextFireTime     *time.Time   `json:"next_fire_time,omitempty"`
+	Items            []TriggerRun `json:"items"`
+	NextToken        *string      `json:"next_token,omitempty"`
+}
+
+type PrintableTriggerRun struct {
+	Items []TriggerRun
+}
+
+func NewPrintableTriggerRun(resp *ListTriggerRunResponse) *PrintableTriggerRun {
+	result := &PrintableTriggerRun{
+		Items: []TriggerRun{},
+	}
+
+	if resp.Items != nil {
+		result.Items = resp.Items
+	}
+
+	return result
+}
+
+func (p PrintableTriggerRun) GetItems() []TriggerRun {
+	return p.Items
+}
+
+func (p PrintableTriggerRun) GetTable() (*printers.Table, error) {
+	var tableRows []printers.TableRow
+	for _, item := range p.Items {
+		cells := []any{
+			item.FiredAt.Local().Format(time.DateTime),
+			item.Source,
+			item.ExecutionID,
+			item.displayStatus(),
+			item.captureCounts(),
+		}
+		tableRows = append(tableRows, printers.TableRow{Cells: cells})
+	}
+
+	return printers.NewTable().WithData(tableRows, p.getColumns()), nil
+}
+
+func (PrintableTriggerRun) getColumns() (columns []string) {
+	return []string{"FIRED_AT", "SOURCE", "EXECUTION_ID", "STATUS", "ROWS"}
+}